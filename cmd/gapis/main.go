@@ -33,6 +33,8 @@ import (
 	"github.com/google/gapid/core/os/file"
 	"github.com/google/gapid/core/text"
 	"github.com/google/gapid/gapir/client"
+	"github.com/google/gapid/gapis/api/transform"
+	"github.com/google/gapid/gapis/config"
 	"github.com/google/gapid/gapis/database"
 	"github.com/google/gapid/gapis/replay"
 	"github.com/google/gapid/gapis/server"
@@ -55,6 +57,17 @@ var (
 	idleTimeout      = flag.Duration("idle-timeout", 0, "Closes GAPIS if the server is not repeatedly pinged within this duration")
 	adbPath          = flag.String("adb", "", "Path to the adb executable; leave empty to search the environment")
 	enableLocalFiles = flag.Bool("enable-local-files", false, "Allow clients to access local .gfxtrace files by path")
+
+	debugReplay        = flag.Bool("debug-replay", false, "Log the progress and transforms of each replay")
+	debugReplayBuilder = flag.Bool("debug-replay-builder", false, "Log the layout of each replay payload")
+	disableDCE         = flag.Bool("disable-dce", false, "Disable the dead code elimination of replays")
+	debugDCE           = flag.Bool("debug-dce", false, "Log the dead code elimination of replays")
+	logExtras          = flag.Bool("log-extras-in-transforms", false, "Log the extras of each command with -log-transforms-to-file")
+	logMemory          = flag.Bool("log-memory-in-extras", false, "Log the memory observations of each command with -log-extras-in-transforms")
+	logTransforms      = flag.Bool("log-transforms-to-file", false, "Log the commands output by each replay transform to a file")
+	separateStates     = flag.Bool("separate-mutate-states", false, "Mutate a separate state for each replay transform")
+	profTransforms     = flag.Bool("profile-transforms", false, "Record the time, commands and allocations of each replay transform")
+	transformTrace     = flag.String("transform-trace", "", "Write the replay transform profiles to this Chrome trace JSON file as they finish. Implies -profile-transforms")
 )
 
 func main() {
@@ -87,6 +100,30 @@ func run(ctx context.Context) error {
 		adb.ADB = file.Abs(*adbPath)
 	}
 
+	config.SetGlobal(config.Debug{
+		DebugReplay:                *debugReplay,
+		DebugReplayBuilder:         *debugReplayBuilder,
+		DisableDeadCodeElimination: *disableDCE,
+		DebugDeadCodeElimination:   *debugDCE,
+		LogExtrasInTransforms:      *logExtras,
+		LogMemoryInExtras:          *logMemory,
+		LogTransformsToFile:        *logTransforms,
+		SeparateMutateStates:       *separateStates,
+		ProfileTransforms:          *profTransforms || *transformTrace != "",
+		TransformTraceFile:         *transformTrace,
+	})
+	if *transformTrace != "" {
+		stop, err := transform.StartProfileTrace(*transformTrace)
+		if err != nil {
+			return log.Errf(ctx, err, "Failed to create transform trace file %v", *transformTrace)
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				log.E(ctx, "Failed to write transform trace file %v: %v", *transformTrace, err)
+			}
+		}()
+	}
+
 	r := bind.NewRegistry()
 	ctx = bind.PutRegistry(ctx, r)
 	m := replay.New(ctx)
@@ -0,0 +1,102 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+type bisectVerb struct{ BisectFlags }
+
+func init() {
+	verb := &bisectVerb{
+		BisectFlags{
+			At:        -1,
+			Threshold: 0.001,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "bisect",
+		ShortHelp: "Finds the first draw call of a frame where the replay diverges",
+		Action:    verb,
+	})
+}
+
+func (verb *bisectVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, capture, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	device, err := getDevice(ctx, client, capture, verb.Gapir)
+	if err != nil {
+		return err
+	}
+
+	var reference *path.Device
+	if verb.Reference.Device != "" {
+		flags := GapirFlags{DeviceFlags: DeviceFlags{Device: verb.Reference.Device}}
+		if reference, err = getDevice(ctx, client, capture, flags); err != nil {
+			return err
+		}
+	}
+
+	var end *path.Command
+	if verb.At >= 0 {
+		end = capture.Command(uint64(verb.At))
+	} else {
+		events, err := getEvents(ctx, client, &path.Events{
+			Capture:                 capture,
+			FramebufferObservations: true,
+		})
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return log.Err(ctx, nil, "Capture contains no framebuffer observations")
+		}
+		end = events[len(events)-1].Command
+	}
+
+	boxedResult, err := client.Get(ctx, end.Bisect(device, reference, float32(verb.Threshold)).Path())
+	if err != nil {
+		return log.Errf(ctx, err, "Failed to bisect the frame ending at %v", end.Indices)
+	}
+	result := boxedResult.(*service.BisectResult)
+
+	for _, s := range result.Steps {
+		status := "ok"
+		if s.Diverged {
+			status = "diverged"
+		}
+		fmt.Printf("%v: %f %v\n", s.Command.Indices, s.Difference, status)
+	}
+
+	if result.FirstBadCommand == nil {
+		fmt.Printf("Frame ending at %v did not diverge (difference: %f)\n", end.Indices, result.FrameDifference)
+		return nil
+	}
+
+	fmt.Printf("First divergent command (difference: %f):\n", result.Difference)
+	return getAndPrintCommand(ctx, client, result.FirstBadCommand, ObservationFlags{})
+}
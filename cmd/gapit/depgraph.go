@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type depGraphVerb struct{ DepGraphFlags }
+
+func init() {
+	verb := &depGraphVerb{
+		DepGraphFlags{
+			To:     -1,
+			Format: DotGraph,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "depgraph",
+		ShortHelp: "Exports the dependency graph of a range of commands",
+		Action:    verb,
+	})
+}
+
+func (verb *depGraphVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	if verb.From < 0 {
+		return log.Errf(ctx, nil, "Invalid -from command index: %v", verb.From)
+	}
+	if verb.To < -1 {
+		return log.Errf(ctx, nil, "Invalid -to command index: %v", verb.To)
+	}
+
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	to := uint64(verb.To)
+	if verb.To < 0 {
+		boxedCapture, err := client.Get(ctx, c.Path())
+		if err != nil {
+			return log.Err(ctx, err, "Failed to load the capture")
+		}
+		to = uint64(boxedCapture.(*service.Capture).NumCommands) - 1
+	}
+
+	boxedGraph, err := client.Get(ctx, c.DependencyGraph(uint64(verb.From), to, verb.Footprint).Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the dependency graph")
+	}
+	graph := boxedGraph.(*service.DependencyGraph)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open graph output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch verb.Format {
+	case JsonGraph:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, graph); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		writeDot(w, graph)
+	}
+	return nil
+}
+
+// writeDot writes the graph in the GraphViz DOT format, clustering the
+// commands of each frame together.
+func writeDot(w io.Writer, graph *service.DependencyGraph) {
+	fmt.Fprintln(w, "digraph dependencies {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for i, n := range graph.Nodes {
+		if i == 0 || graph.Nodes[i-1].Frame != n.Frame {
+			if i > 0 {
+				fmt.Fprintln(w, "  }")
+			}
+			fmt.Fprintf(w, "  subgraph cluster_frame_%d {\n", n.Frame)
+			fmt.Fprintf(w, "    label=\"Frame %d\";\n", n.Frame)
+		}
+		fmt.Fprintf(w, "    n%d [label=\"%v: %v\"];\n", i, n.Command.Indices[0], n.Name)
+	}
+	if len(graph.Nodes) > 0 {
+		fmt.Fprintln(w, "  }")
+	}
+	for _, e := range graph.Edges {
+		label := dotEscape(e.States[0])
+		if len(e.States) > 1 {
+			label = fmt.Sprintf("%v (+%d)", label, len(e.States)-1)
+		}
+		fmt.Fprintf(w, "  n%d -> n%d [label=\"%v\", tooltip=\"%v\"];\n",
+			e.From, e.To, label, dotEscape(strings.Join(e.States, "\n")))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func dotEscape(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
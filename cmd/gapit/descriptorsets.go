@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/app/flags"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+)
+
+type descriptorSetsVerb struct{ DescriptorSetsFlags }
+
+func init() {
+	verb := &descriptorSetsVerb{
+		DescriptorSetsFlags{
+			At:     flags.U64Slice{},
+			Format: CsvTable,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "descriptorsets",
+		ShortHelp: "Prints the descriptor sets bound for a draw or dispatch in a command buffer",
+		Action:    verb,
+	})
+}
+
+func (verb *descriptorSetsVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	if len(verb.At) < 2 {
+		app.Usage(ctx, "The command/subcommand index of a draw or dispatch is required")
+		return nil
+	}
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	requested := c.Command(verb.At[0], verb.At[1:]...)
+	boxedSets, err := client.Get(ctx, requested.DescriptorSets().Path())
+	if err != nil {
+		return log.Errf(ctx, err, "Failed to get the descriptor sets at %v", requested.Indices)
+	}
+	sets := boxedSets.(*api.DescriptorSets)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open descriptor sets output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch verb.Format {
+	case JsonTable:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, sets); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		if err := writeDescriptorSetsCSV(w, sets); err != nil {
+			return log.Err(ctx, err, "Failed to write descriptor sets")
+		}
+	}
+	return nil
+}
+
+// writeDescriptorSetsCSV writes a row for each descriptor of the sets.
+func writeDescriptorSetsCSV(w io.Writer, sets *api.DescriptorSets) error {
+	out := csv.NewWriter(w)
+	header := []string{
+		"set", "descriptor set", "binding", "array index", "type",
+		"sampler", "image view", "image layout", "buffer view", "buffer",
+		"offset", "range", "dynamic offset",
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, s := range sets.Sets {
+		for _, d := range s.Descriptors {
+			row := []string{
+				fmt.Sprint(s.Set), s.Handle, fmt.Sprint(d.Binding), fmt.Sprint(d.ArrayIndex), d.Type,
+				d.Sampler, d.ImageView, d.ImageLayout, d.BufferView, d.Buffer,
+				fmt.Sprint(d.Offset), fmt.Sprint(d.Range), fmt.Sprint(d.DynamicOffset),
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type determinismVerb struct{ DeterminismFlags }
+
+func init() {
+	verb := &determinismVerb{
+		DeterminismFlags{
+			Replays: 3,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "determinism",
+		ShortHelp: "Replays the frames of a capture multiple times and reports any that differ",
+		Action:    verb,
+	})
+}
+
+func (verb *determinismVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, capture, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	device, err := getDevice(ctx, client, capture, verb.Gapir)
+	if err != nil {
+		return err
+	}
+
+	report, err := client.CheckReplayDeterminism(ctx,
+		&service.ReplaySettings{Device: device},
+		capture,
+		nil,
+		verb.Draws,
+		uint32(verb.Replays),
+		float32(verb.Tolerance))
+	if err != nil {
+		return log.Err(ctx, err, "Failed to check the replay determinism")
+	}
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open report output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	nondeterministic, failed := 0, 0
+	for _, r := range report.Results {
+		switch {
+		case r.Error != "":
+			failed++
+			fmt.Fprintf(w, "%v: ERROR %v\n", r.Command.Indices, r.Error)
+		case !r.BitExact:
+			nondeterministic++
+			status := "within tolerance"
+			if !r.WithinTolerance {
+				status = "NONDETERMINISTIC"
+			}
+			fmt.Fprintf(w, "%v: %v (%d distinct results, max difference: %f)\n",
+				r.Command.Indices, status, r.DistinctResults, r.MaxDifference)
+		}
+	}
+	fmt.Fprintf(w, "Checked %d commands with %d replays each: %d not bit-exact, %d failed\n",
+		len(report.Results), report.Replays, nondeterministic, failed)
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/app/flags"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+type explainDCEVerb struct{ ExplainDCEFlags }
+
+func init() {
+	verb := &explainDCEVerb{
+		ExplainDCEFlags{
+			At:     flags.U64Slice{},
+			Target: flags.U64Slice{},
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "explain-dce",
+		ShortHelp: "Prints why the dead code elimination keeps each command of a replay alive",
+		Action:    verb,
+	})
+}
+
+func (verb *explainDCEVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if len(verb.At) == 0 {
+		boxedCapture, err := client.Get(ctx, c.Path())
+		if err != nil {
+			return log.Err(ctx, err, "Failed to load the capture")
+		}
+		verb.At = []uint64{uint64(boxedCapture.(*service.Capture).NumCommands) - 1}
+	}
+
+	var target *path.Command
+	if len(verb.Target) > 0 {
+		target = c.Command(verb.Target[0], verb.Target[1:]...)
+	}
+
+	requested := c.Command(verb.At[0], verb.At[1:]...)
+	boxedExplanation, err := client.Get(ctx, requested.DCEExplanation(target).Path())
+	if err != nil {
+		return log.Errf(ctx, err, "Failed to explain the dead code elimination at %v", requested.Indices)
+	}
+	explanation := boxedExplanation.(*service.DCEExplanation)
+
+	if target != nil {
+		if len(explanation.Commands) == 0 {
+			fmt.Printf("Command %v is not live\n", target.Indices)
+			return nil
+		}
+		// Print the chain with the requested command as the root.
+		for i := len(explanation.Commands) - 1; i >= 0; i-- {
+			verb.print(explanation.Commands[i], len(explanation.Commands)-1-i)
+		}
+		return nil
+	}
+
+	// Build the tree of commands, each command being the child of the command
+	// keeping it alive.
+	children := map[string][]*service.LiveCommand{}
+	printed := map[string]bool{}
+	roots := []*service.LiveCommand{}
+	for _, l := range explanation.Commands {
+		if l.KeptAliveBy == nil {
+			roots = append(roots, l)
+		} else {
+			key := fmt.Sprint(l.KeptAliveBy.Indices)
+			children[key] = append(children[key], l)
+		}
+	}
+
+	type item struct {
+		cmd   *service.LiveCommand
+		depth int
+	}
+	stack := []item{}
+	for i := len(roots) - 1; i >= 0; i-- {
+		stack = append(stack, item{roots[i], 0})
+	}
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		verb.print(it.cmd, it.depth)
+
+		if it.cmd.Command == nil || (verb.Depth > 0 && it.depth+1 >= verb.Depth) {
+			continue
+		}
+		// Commands with more than one live behavior list their children once.
+		key := fmt.Sprint(it.cmd.Command.Indices)
+		if printed[key] {
+			continue
+		}
+		printed[key] = true
+		kids := children[key]
+		for i := len(kids) - 1; i >= 0; i-- {
+			stack = append(stack, item{kids[i], it.depth + 1})
+		}
+	}
+	return nil
+}
+
+func (verb *explainDCEVerb) print(l *service.LiveCommand, depth int) {
+	indent := strings.Repeat("  ", depth)
+	command := "initial"
+	if l.Command != nil {
+		command = fmt.Sprint(l.Command.Indices)
+	}
+	behavior := ""
+	if l.Behavior >= 0 {
+		behavior = fmt.Sprintf(" behavior %d", l.Behavior)
+	}
+	switch {
+	case l.Requested:
+		fmt.Printf("%s%v %v%s: requested\n", indent, command, l.Name, behavior)
+	default:
+		fmt.Printf("%s%v %v%s: %v\n", indent, command, l.Name, behavior, l.State)
+	}
+}
@@ -28,6 +28,16 @@ const (
 	IndividualFrames
 )
 
+const (
+	DotGraph GraphFormat = iota
+	JsonGraph
+)
+
+const (
+	CsvTable TableFormat = iota
+	JsonTable
+)
+
 const (
 	Json PackagesOutput = iota
 	Proto
@@ -51,6 +61,34 @@ func (v VideoType) String() string {
 	return videoTypeNames[v]
 }
 
+type GraphFormat uint8
+
+var graphFormatNames = map[GraphFormat]string{
+	DotGraph:  "dot",
+	JsonGraph: "json",
+}
+
+func (v *GraphFormat) Choose(c interface{}) {
+	*v = c.(GraphFormat)
+}
+func (v GraphFormat) String() string {
+	return graphFormatNames[v]
+}
+
+type TableFormat uint8
+
+var tableFormatNames = map[TableFormat]string{
+	CsvTable:  "csv",
+	JsonTable: "json",
+}
+
+func (v *TableFormat) Choose(c interface{}) {
+	*v = c.(TableFormat)
+}
+func (v TableFormat) String() string {
+	return tableFormatNames[v]
+}
+
 type PackagesOutput uint8
 
 var packagesOutputNames = map[PackagesOutput]string{
@@ -95,9 +133,14 @@ type (
 		DeviceFlags
 	}
 	ReportFlags struct {
-		Gapis GapisFlags
-		Gapir GapirFlags
-		Out   string `help:"output report path"`
+		Gapis           GapisFlags
+		Gapir           GapirFlags
+		Out             string `help:"output report path"`
+		Performance     bool   `help:"report the performance issues instead of the errors (GLES only)"`
+		UnusedResources bool   `help:"report the unused resources instead of the errors"`
+		ShaderCheck     bool   `help:"report the offline shader compilation issues instead of the errors"`
+		SyncHazards     bool   `help:"also report the synchronization hazards (Vulkan only)"`
+		RenderPassWaste bool   `help:"also report the wasteful render pass attachment operations (Vulkan only)"`
 		CommandFilterFlags
 	}
 	VideoFlags struct {
@@ -112,6 +155,7 @@ type (
 		Type     VideoType `help:"type of output to produce"`
 		Text     string    `help:"summary prefix (use '║' for aligned columns, '¶' for new line)"`
 		Commands bool      `help:"Treat every command as its own frame"`
+		Pool     bool      `help:"share the replay across all the devices compatible with the selected device"`
 		Frames   struct {
 			Start   int `help:"frame to start capture from"`
 			Count   int `help:"number of frames after Start to capture: -1 for all frames"`
@@ -119,6 +163,82 @@ type (
 		}
 		CommandFilterFlags
 	}
+	BisectFlags struct {
+		Gapis     GapisFlags
+		Gapir     GapirFlags
+		At        int     `help:"command index of the framebuffer observation ending the frame. -1 for the last observation"`
+		Threshold float64 `help:"normalized square error above which the replay is considered divergent"`
+		Reference struct {
+			Device string `help:"device to compare the replay against instead of the framebuffer observation"`
+		}
+	}
+	DeterminismFlags struct {
+		Gapis     GapisFlags
+		Gapir     GapirFlags
+		Replays   int     `help:"number of times to replay each command"`
+		Tolerance float64 `help:"normalized square error below which differing postbacks are considered equivalent"`
+		Draws     bool    `help:"check every draw call as well as the end of every frame"`
+		Out       string  `help:"output report path, standard output if none"`
+	}
+	ExplainDCEFlags struct {
+		Gapis  GapisFlags
+		At     flags.U64Slice `help:"command/subcommand index of the requested command. Empty for last"`
+		Target flags.U64Slice `help:"command/subcommand index of the command to explain. Empty for all live commands"`
+		Depth  int            `help:"maximum depth of the printed tree, 0 for unlimited"`
+	}
+	DepGraphFlags struct {
+		Gapis     GapisFlags
+		From      int         `help:"index of the first command to export"`
+		To        int         `help:"index of the last command to export. -1 for the last command"`
+		Footprint bool        `help:"export the execution footprint instead of the dependency graph"`
+		Format    GraphFormat `help:"output format"`
+		Out       string      `help:"output file, standard output if none"`
+	}
+	DescriptorSetsFlags struct {
+		Gapis  GapisFlags
+		At     flags.U64Slice `help:"command/subcommand index of the draw or dispatch command"`
+		Format TableFormat    `help:"output format"`
+		Out    string         `help:"output file, standard output if none"`
+	}
+	LifetimesFlags struct {
+		Gapis GapisFlags
+		Out   string `help:"output file, standard output if none"`
+	}
+	TimelineFlags struct {
+		Gapis GapisFlags
+		Out   string `help:"output file, standard output if none"`
+	}
+	SyncGraphFlags struct {
+		Gapis  GapisFlags
+		Format GraphFormat `help:"output format"`
+		Trace  bool        `help:"export the graph as trace events, ignoring the format"`
+		Out    string      `help:"output file, standard output if none"`
+	}
+	MemUsageFlags struct {
+		Gapis  GapisFlags
+		Top    int         `help:"number of largest objects to list for each frame"`
+		Format TableFormat `help:"output format"`
+		Out    string      `help:"output file, standard output if none"`
+	}
+	UnusedFlags struct {
+		Gapis GapisFlags
+	}
+	RenderPassesFlags struct {
+		Gapis  GapisFlags
+		Waste  bool        `help:"only list the attachments with wasteful load or store operations"`
+		Format TableFormat `help:"output format"`
+		Out    string      `help:"output file, standard output if none"`
+	}
+	ShaderCheckFlags struct {
+		Gapis  GapisFlags
+		All    bool   `help:"list the shaders that compile without errors or warnings too"`
+		Device string `help:"device whose profile the shaders are checked against. One of: 'host', 'android' or <device-serial>. The replay profile if none"`
+	}
+	ShaderComplexityFlags struct {
+		Gapis  GapisFlags
+		Format TableFormat `help:"output format"`
+		Out    string      `help:"output file, standard output if none"`
+	}
 	DumpShadersFlags struct {
 		Gapis GapisFlags
 		Gapir GapirFlags
@@ -154,6 +274,12 @@ type (
 		Gapir GapirFlags
 		At    flags.U64Slice `help:"command/subcommand index to get the state after. Empty for last"`
 	}
+	StatsFlags struct {
+		Gapis     GapisFlags
+		Redundant bool `help:"list the state-setting commands that do not change the state"`
+		Commands  bool `help:"list the count and observation bytes of each command name"`
+		Frames    bool `help:"list the commands, draw calls and observation bytes of each frame"`
+	}
 	StressTestFlags struct {
 		Gapis GapisFlags
 		Gapir GapirFlags
@@ -0,0 +1,165 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type lifetimesVerb struct{ LifetimesFlags }
+
+func init() {
+	verb := &lifetimesVerb{}
+	app.AddVerb(&app.Verb{
+		Name:      "lifetimes",
+		ShortHelp: "Exports the resource lifetimes of a capture as a Chrome trace",
+		Action:    verb,
+	})
+}
+
+func (verb *lifetimesVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedCapture, err := client.Get(ctx, c.Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to load the capture")
+	}
+	end := boxedCapture.(*service.Capture).NumCommands
+
+	boxedLifetimes, err := client.Get(ctx, c.ResourceLifetimes().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the resource lifetimes")
+	}
+	lifetimes := boxedLifetimes.(*service.ResourceLifetimes)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open trace output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(lifetimesTrace(lifetimes, end)); err != nil {
+		return log.Err(ctx, err, "marshal json")
+	}
+	return nil
+}
+
+// traceEvent is a single event of the Chrome trace event format.
+type traceEvent struct {
+	Name  string            `json:"name"`
+	Cat   string            `json:"cat,omitempty"`
+	Phase string            `json:"ph"`
+	Ts    uint64            `json:"ts"`
+	Dur   uint64            `json:"dur,omitempty"`
+	Pid   int               `json:"pid"`
+	Tid   int               `json:"tid"`
+	Scope string            `json:"s,omitempty"`
+	ID    int               `json:"id,omitempty"`
+	Bind  string            `json:"bp,omitempty"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// lifetimesTrace returns the trace events of the resource lifetimes, using
+// command indices as timestamps. Each kind of object is shown as a process,
+// and each resource as a thread of this process, spanning from the command
+// creating the resource to the command destroying it. Uses of the resource
+// are shown as instant events.
+func lifetimesTrace(lifetimes *service.ResourceLifetimes, end uint64) []traceEvent {
+	events := []traceEvent{}
+	pids := map[string]int{}
+	for tid, r := range lifetimes.Resources {
+		ty := r.Kind
+		pid, ok := pids[ty]
+		if !ok {
+			pid = len(pids) + 1
+			pids[ty] = pid
+			events = append(events, traceEvent{
+				Name:  "process_name",
+				Phase: "M",
+				Pid:   pid,
+				Args:  map[string]string{"name": ty},
+			})
+		}
+		name := r.Handle
+		if r.Label != "" {
+			name = fmt.Sprintf("%v %v", r.Handle, r.Label)
+		}
+		events = append(events, traceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Pid:   pid,
+			Tid:   tid,
+			Args:  map[string]string{"name": name},
+		})
+
+		from, to := uint64(0), end
+		if r.Created != nil {
+			from = r.Created.Indices[0]
+		}
+		if r.Destroyed != nil {
+			to = r.Destroyed.Indices[0] + 1
+		}
+		events = append(events, traceEvent{
+			Name:  name,
+			Cat:   ty,
+			Phase: "X",
+			Ts:    from,
+			Dur:   to - from,
+			Pid:   pid,
+			Tid:   tid,
+		})
+
+		for _, u := range r.Uses {
+			access := "access"
+			switch {
+			case u.Read && u.Write:
+				access = "read-write"
+			case u.Read:
+				access = "read"
+			case u.Write:
+				access = "write"
+			}
+			events = append(events, traceEvent{
+				Name:  access,
+				Cat:   ty,
+				Phase: "i",
+				Ts:    u.Command.Indices[0],
+				Pid:   pid,
+				Tid:   tid,
+				Scope: "t",
+			})
+		}
+	}
+	return events
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type memUsageVerb struct{ MemUsageFlags }
+
+func init() {
+	verb := &memUsageVerb{
+		MemUsageFlags{
+			Top:    10,
+			Format: CsvTable,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "memusage",
+		ShortHelp: "Prints the estimated GPU memory usage at the end of each frame",
+		Action:    verb,
+	})
+}
+
+func (verb *memUsageVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if verb.Top < 0 {
+		verb.Top = 0
+	}
+	boxedUsage, err := client.Get(ctx, c.MemoryUsage(uint32(verb.Top)).Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the memory usage")
+	}
+	usage := boxedUsage.(*service.MemoryUsage)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open memory usage output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch verb.Format {
+	case JsonTable:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, usage); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		if err := writeMemUsageCSV(w, usage); err != nil {
+			return log.Err(ctx, err, "Failed to write memory usage")
+		}
+	}
+	return nil
+}
+
+// writeMemUsageCSV writes a row for each frame, with a column for the live
+// bytes of each category, and a last column listing the largest objects.
+func writeMemUsageCSV(w io.Writer, usage *service.MemoryUsage) error {
+	categories := []string{}
+	seen := map[string]bool{}
+	for _, f := range usage.Frames {
+		for _, c := range f.Categories {
+			if !seen[c.Category] {
+				seen[c.Category] = true
+				categories = append(categories, c.Category)
+			}
+		}
+	}
+	sort.Strings(categories)
+
+	out := csv.NewWriter(w)
+	header := append([]string{"frame", "command"}, categories...)
+	header = append(header, "total", "largest")
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, f := range usage.Frames {
+		bytes := map[string]uint64{}
+		total := uint64(0)
+		for _, c := range f.Categories {
+			bytes[c.Category] = c.Bytes
+			total += c.Bytes
+		}
+		row := []string{fmt.Sprint(f.Frame), fmt.Sprint(f.Command.Indices[0])}
+		for _, c := range categories {
+			row = append(row, fmt.Sprint(bytes[c]))
+		}
+		largest := make([]string, len(f.Largest))
+		for i, o := range f.Largest {
+			largest[i] = fmt.Sprintf("%v: %v", o.Name, o.Bytes)
+		}
+		row = append(row, fmt.Sprint(total), strings.Join(largest, "; "))
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
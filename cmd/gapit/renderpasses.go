@@ -0,0 +1,130 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+)
+
+type renderPassesVerb struct{ RenderPassesFlags }
+
+func init() {
+	verb := &renderPassesVerb{
+		RenderPassesFlags{
+			Format: CsvTable,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "renderpasses",
+		ShortHelp: "Prints the load and store operations of the attachments of each render pass instance",
+		Action:    verb,
+	})
+}
+
+func (verb *renderPassesVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedInstances, err := client.Get(ctx, c.RenderPassInstances().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the render pass instances")
+	}
+	instances := boxedInstances.(*api.RenderPassInstances)
+
+	if verb.Waste {
+		wasteful := []*api.RenderPassInstance{}
+		for _, i := range instances.Instances {
+			attachments := []*api.RenderPassAttachment{}
+			for _, a := range i.Attachments {
+				if len(a.Waste) > 0 {
+					attachments = append(attachments, a)
+				}
+			}
+			if len(attachments) > 0 {
+				i.Attachments = attachments
+				wasteful = append(wasteful, i)
+			}
+		}
+		instances.Instances = wasteful
+	}
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open render passes output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch verb.Format {
+	case JsonTable:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, instances); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		if err := writeRenderPassesCSV(w, instances); err != nil {
+			return log.Err(ctx, err, "Failed to write render passes")
+		}
+	}
+	return nil
+}
+
+// writeRenderPassesCSV writes a row for each attachment of the instances.
+func writeRenderPassesCSV(w io.Writer, instances *api.RenderPassInstances) error {
+	out := csv.NewWriter(w)
+	header := []string{
+		"command", "render pass", "framebuffer", "attachment", "image",
+		"format", "samples", "width", "height",
+		"load", "store", "stencil load", "stencil store", "waste",
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, i := range instances.Instances {
+		for _, a := range i.Attachments {
+			waste := make([]string, len(a.Waste))
+			for j, w := range a.Waste {
+				waste[j] = w.Description()
+			}
+			row := []string{
+				fmt.Sprint(i.Command.Indices), i.RenderPass, i.Framebuffer, fmt.Sprint(a.Index), a.Image,
+				a.Format, a.Samples, fmt.Sprint(a.Width), fmt.Sprint(a.Height),
+				a.LoadOp, a.StoreOp, a.StencilLoadOp, a.StencilStoreOp, strings.Join(waste, "; "),
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
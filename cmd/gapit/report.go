@@ -25,6 +25,7 @@ import (
 	"github.com/google/gapid/core/app"
 	"github.com/google/gapid/core/log"
 	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
 	"github.com/google/gapid/gapis/stringtable"
 )
 
@@ -81,9 +82,15 @@ func (verb *reportVerb) Run(ctx context.Context, flags flag.FlagSet) error {
 		return log.Err(ctx, err, "Failed to load the capture file")
 	}
 
-	device, err := getDevice(ctx, client, capturePath, verb.Gapir)
-	if err != nil {
-		return err
+	// The performance lint pass, the unused resources analysis and the shader
+	// check do not need to replay the capture. The shader check uses the
+	// device as the target profile when one is given.
+	var device *path.Device
+	if !verb.Performance && !verb.UnusedResources && (!verb.ShaderCheck || verb.Gapir.Device != "") {
+		device, err = getDevice(ctx, client, capturePath, verb.Gapir)
+		if err != nil {
+			return err
+		}
 	}
 
 	filter, err := verb.commandFilter(ctx, client, capturePath)
@@ -97,7 +104,13 @@ func (verb *reportVerb) Run(ctx context.Context, flags flag.FlagSet) error {
 	}
 	commands := boxedCommands.(*service.Commands).List
 
-	boxedReport, err := client.Get(ctx, capturePath.Report(device, filter).Path())
+	reportPath := capturePath.Report(device, filter)
+	reportPath.Performance = verb.Performance
+	reportPath.UnusedResources = verb.UnusedResources
+	reportPath.ShaderCheck = verb.ShaderCheck
+	reportPath.SyncHazards = verb.SyncHazards
+	reportPath.RenderPassWaste = verb.RenderPassWaste
+	boxedReport, err := client.Get(ctx, reportPath.Path())
 	if err != nil {
 		return log.Err(ctx, err, "Failed to acquire the capture's report")
 	}
@@ -0,0 +1,90 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+type shaderCheckVerb struct{ ShaderCheckFlags }
+
+func init() {
+	verb := &shaderCheckVerb{}
+	app.AddVerb(&app.Verb{
+		Name:      "shadercheck",
+		ShortHelp: "Compiles offline the shaders of a capture and prints their errors and warnings",
+		Action:    verb,
+	})
+}
+
+func (verb *shaderCheckVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var device *path.Device
+	if verb.Device != "" {
+		device, err = getDevice(ctx, client, c, GapirFlags{DeviceFlags: DeviceFlags{Device: verb.Device}})
+		if err != nil {
+			return err
+		}
+	}
+
+	boxedCheck, err := client.Get(ctx, c.ShaderCheck(device).Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to check the shaders")
+	}
+	check := boxedCheck.(*api.ShaderCheck)
+
+	failed, warned := 0, 0
+	for _, s := range check.Shaders {
+		switch {
+		case len(s.Errors) > 0:
+			failed++
+		case len(s.Warnings) > 0:
+			warned++
+		case !verb.All:
+			continue
+		}
+		created := "initial state"
+		if s.Created != nil {
+			created = fmt.Sprintf("created by %v", s.Created.Indices)
+		}
+		fmt.Printf("%v %v (%v, built by %v):\n", s.Type, s.Handle, created, s.Built.Indices)
+		for _, e := range s.Errors {
+			fmt.Printf("  error: %v\n", indentLines(e))
+		}
+		for _, w := range s.Warnings {
+			fmt.Printf("  warning: %v\n", indentLines(w))
+		}
+	}
+	fmt.Printf("%d shaders checked, %d with errors, %d with warnings only\n", len(check.Shaders), failed, warned)
+	return nil
+}
+
+// indentLines indents the lines of the message after the first one.
+func indentLines(msg string) string {
+	return strings.Replace(strings.TrimSpace(msg), "\n", "\n    ", -1)
+}
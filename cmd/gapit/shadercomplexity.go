@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+)
+
+type shaderComplexityVerb struct{ ShaderComplexityFlags }
+
+func init() {
+	verb := &shaderComplexityVerb{
+		ShaderComplexityFlags{
+			Format: CsvTable,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "shadercomplexity",
+		ShortHelp: "Prints the instruction counts of the shaders of a capture, most costly first",
+		Action:    verb,
+	})
+}
+
+func (verb *shaderComplexityVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedComplexities, err := client.Get(ctx, c.ShaderComplexities().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the shader complexities")
+	}
+	complexities := boxedComplexities.(*api.ShaderComplexities)
+
+	// The shaders run the most often are the most costly, whatever their size.
+	sort.SliceStable(complexities.Shaders, func(i, j int) bool {
+		return weightedCost(complexities.Shaders[i]) > weightedCost(complexities.Shaders[j])
+	})
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open shader complexity output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch verb.Format {
+	case JsonTable:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, complexities); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		if err := writeShaderComplexityCSV(w, complexities); err != nil {
+			return log.Err(ctx, err, "Failed to write shader complexities")
+		}
+	}
+	return nil
+}
+
+// weightedCost returns the number of instructions of the shader multiplied by
+// the number of draw calls using it.
+func weightedCost(s *api.ShaderComplexityEntry) uint64 {
+	if s.Complexity == nil {
+		return 0
+	}
+	return uint64(s.Complexity.Instructions()) * s.DrawCalls
+}
+
+// writeShaderComplexityCSV writes a row for each shader entry point.
+func writeShaderComplexityCSV(w io.Writer, complexities *api.ShaderComplexities) error {
+	out := csv.NewWriter(w)
+	header := []string{
+		"shader", "type", "entry point", "built", "draw calls",
+		"alu", "texture", "control flow", "other",
+		"loops", "samplers", "interpolants", "weighted",
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, s := range complexities.Shaders {
+		c := s.Complexity
+		if c == nil {
+			c = &api.ShaderComplexity{}
+		}
+		built := "initial state"
+		if s.Built != nil {
+			built = fmt.Sprint(s.Built.Indices[0])
+		}
+		row := []string{
+			s.Handle, fmt.Sprint(s.Type), s.EntryPoint, built, fmt.Sprint(s.DrawCalls),
+			fmt.Sprint(c.AluInstructions), fmt.Sprint(c.TextureInstructions),
+			fmt.Sprint(c.ControlFlowInstructions), fmt.Sprint(c.OtherInstructions),
+			fmt.Sprint(c.Loops), fmt.Sprint(c.Samplers), fmt.Sprint(c.Interpolants),
+			fmt.Sprint(weightedCost(s)),
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -27,7 +27,7 @@ import (
 	"github.com/google/gapid/gapis/service/path"
 )
 
-type infoVerb struct{ Gapis GapisFlags }
+type infoVerb struct{ StatsFlags }
 
 func init() {
 	verb := &infoVerb{}
@@ -69,26 +69,83 @@ func (verb *infoVerb) Run(ctx context.Context, flags flag.FlagSet) error {
 	}
 	defer client.Close()
 
+	boxedStats, err := client.Get(ctx, capture.Stats().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Couldn't get the capture statistics")
+	}
+	stats := boxedStats.(*service.Stats)
+
 	events, err := getEvents(ctx, client, &path.Events{
 		Capture:                 capture,
-		AllCommands:             true,
-		DrawCalls:               true,
-		FirstInFrame:            true,
 		FramebufferObservations: true,
 	})
-
 	if err != nil {
 		return log.Err(ctx, err, "Couldn't get events")
 	}
 
-	counts := map[service.EventKind]int{}
-	for _, e := range events {
-		counts[e.Kind] = counts[e.Kind] + 1
+	commands, draws := uint64(0), uint64(0)
+	for _, a := range stats.Apis {
+		commands += a.Commands
+		draws += a.DrawCalls
+	}
+	frames, unfinished := 0, ""
+	for _, f := range stats.Frames {
+		if f.Unfinished {
+			unfinished = " (+1 unfinished)"
+		} else {
+			frames++
+		}
+	}
+
+	fmt.Println("Commands: ", commands)
+	fmt.Printf("Frames:    %d%s\n", frames, unfinished)
+	fmt.Println("Draws:    ", draws)
+	fmt.Println("FBO:      ", len(events))
+	fmt.Println("Threads:  ", stats.Threads)
+	fmt.Println("Contexts: ", stats.Contexts)
+	fmt.Println("Observations read:    ", stats.ObservationBytesRead, "bytes")
+	fmt.Println("Observations written: ", stats.ObservationBytesWritten, "bytes")
+	fmt.Println("Resources:            ", stats.Resources, "/", stats.ResourceBytes, "bytes")
+	for _, a := range stats.Apis {
+		fmt.Printf("%v: %d commands, %d draws, %d bytes read, %d bytes written\n",
+			a.Name, a.Commands, a.DrawCalls, a.ObservationBytesRead, a.ObservationBytesWritten)
+	}
+
+	if verb.Commands {
+		fmt.Println("Commands by name:")
+		for _, c := range stats.Commands {
+			fmt.Printf("  %v: %d, %d bytes read, %d bytes written\n",
+				c.Name, c.Count, c.ObservationBytesRead, c.ObservationBytesWritten)
+		}
 	}
 
-	fmt.Println("Commands: ", counts[service.EventKind_AllCommands])
-	fmt.Println("Frames:   ", counts[service.EventKind_FirstInFrame])
-	fmt.Println("Draws:    ", counts[service.EventKind_DrawCall])
-	fmt.Println("FBO:      ", counts[service.EventKind_FramebufferObservation])
+	if verb.Frames {
+		fmt.Println("Frames:")
+		for _, f := range stats.Frames {
+			label := ""
+			if f.Unfinished {
+				label = " (unfinished)"
+			}
+			fmt.Printf("  %d%s [%v-%v]: %d commands, %d draws, %d bytes read, %d bytes written\n",
+				f.Frame, label, f.First.Indices[0], f.Last.Indices[0], f.Commands, f.DrawCalls,
+				f.ObservationBytesRead, f.ObservationBytesWritten)
+		}
+	}
+
+	if verb.Redundant {
+		boxedRedundant, err := client.Get(ctx, capture.RedundantStateChanges().Path())
+		if err != nil {
+			return log.Err(ctx, err, "Couldn't get the redundant state changes")
+		}
+		redundant := boxedRedundant.(*service.RedundantStateChanges)
+		fmt.Println("Redundant state changes:")
+		for _, c := range redundant.Commands {
+			ids := make([]uint64, len(c.Redundant))
+			for i, p := range c.Redundant {
+				ids[i] = p.Indices[0]
+			}
+			fmt.Printf("  %v: %d/%d %v\n", c.Name, len(c.Redundant), c.Total, ids)
+		}
+	}
 	return err
 }
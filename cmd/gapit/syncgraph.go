@@ -0,0 +1,198 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+)
+
+type syncGraphVerb struct{ SyncGraphFlags }
+
+func init() {
+	verb := &syncGraphVerb{
+		SyncGraphFlags{
+			Format: DotGraph,
+		},
+	}
+	app.AddVerb(&app.Verb{
+		Name:      "syncgraph",
+		ShortHelp: "Exports the queue submissions of a capture and their synchronization",
+		Action:    verb,
+	})
+}
+
+func (verb *syncGraphVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedGraph, err := client.Get(ctx, c.SyncGraph().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the synchronization graph")
+	}
+	graph := boxedGraph.(*api.SyncGraph)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open graph output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch {
+	case verb.Trace:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if err := e.Encode(syncGraphTrace(graph)); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	case verb.Format == JsonGraph:
+		m := jsonpb.Marshaler{Indent: "  "}
+		if err := m.Marshal(w, graph); err != nil {
+			return log.Err(ctx, err, "marshal json")
+		}
+	default:
+		writeSyncGraphDot(w, graph)
+	}
+	return nil
+}
+
+// writeSyncGraphDot writes the graph in the GraphViz DOT format, clustering
+// the nodes of each queue together.
+func writeSyncGraphDot(w io.Writer, graph *api.SyncGraph) {
+	fmt.Fprintln(w, "digraph synchronization {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for q, queue := range graph.Queues {
+		fmt.Fprintf(w, "  subgraph cluster_queue_%d {\n", q)
+		fmt.Fprintf(w, "    label=\"%v\";\n", dotEscape(queue.Name))
+		for i, n := range graph.Nodes {
+			if n.Queue == uint32(q) {
+				fmt.Fprintf(w, "    n%d [label=\"%v: %v\"];\n", i, n.Command.Indices[0], n.Name)
+			}
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, e := range graph.Edges {
+		label := e.Kind.String()
+		if e.Name != "" {
+			label = fmt.Sprintf("%v %v", label, e.Name)
+		}
+		style := "solid"
+		if e.Kind == api.SyncEdgeKind_UnblockEdge {
+			style = "dashed"
+		}
+		fmt.Fprintf(w, "  n%d -> n%d [label=\"%v\", style=%v];\n", e.From, e.To, dotEscape(label), style)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// syncGraphTrace returns the trace events of the graph, using command indices
+// as timestamps. Each queue is shown as a thread, with a slice per node
+// lasting until the completion of the node, and the edges as flow events.
+//
+// The nodes of a queue complete in order, a node submitted before the
+// completion of the previous node of its queue starts at that completion, so
+// that the slices of a queue do not overlap.
+func syncGraphTrace(graph *api.SyncGraph) []traceEvent {
+	events := []traceEvent{
+		{Name: "process_name", Phase: "M", Pid: 1, Args: map[string]string{"name": "Queues"}},
+	}
+	for i, q := range graph.Queues {
+		events = append(events, traceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Pid:   1,
+			Tid:   i,
+			Args:  map[string]string{"name": q.Name},
+		})
+	}
+
+	starts := make([]uint64, len(graph.Nodes))
+	ends := make([]uint64, len(graph.Nodes))
+	queueEnds := map[uint32]uint64{}
+	for i, n := range graph.Nodes {
+		start, end := n.Command.Indices[0], n.Completed.Indices[0]+1
+		if start < queueEnds[n.Queue] {
+			start = queueEnds[n.Queue]
+		}
+		if end <= start {
+			end = start + 1
+		}
+		starts[i], ends[i] = start, end
+		queueEnds[n.Queue] = end
+		events = append(events, traceEvent{
+			Name:  n.Name,
+			Cat:   n.Kind.String(),
+			Phase: "X",
+			Ts:    start,
+			Dur:   end - start,
+			Pid:   1,
+			Tid:   int(n.Queue),
+			Args:  map[string]string{"subcommands": fmt.Sprint(n.Subcommands)},
+		})
+	}
+
+	for i, e := range graph.Edges {
+		from, to := graph.Nodes[e.From], graph.Nodes[e.To]
+		ts := starts[e.From]
+		end := starts[e.To]
+		if end < ts {
+			// The destination was blocked until the source, bind the end of
+			// the flow to the slice of the destination at the source.
+			end = ts
+		}
+		if end >= ends[e.To] {
+			end = ends[e.To] - 1
+		}
+		name := e.Kind.String()
+		if e.Name != "" {
+			name = e.Name
+		}
+		events = append(events, traceEvent{
+			Name:  name,
+			Cat:   e.Kind.String(),
+			Phase: "s",
+			Ts:    ts,
+			Pid:   1,
+			Tid:   int(from.Queue),
+			ID:    i + 1,
+		}, traceEvent{
+			Name:  name,
+			Cat:   e.Kind.String(),
+			Phase: "f",
+			Ts:    end,
+			Pid:   1,
+			Tid:   int(to.Queue),
+			ID:    i + 1,
+			Bind:  "e",
+		})
+	}
+	return events
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type timelineVerb struct{ TimelineFlags }
+
+func init() {
+	verb := &timelineVerb{}
+	app.AddVerb(&app.Verb{
+		Name:      "timeline",
+		ShortHelp: "Exports the commands of a capture by thread and context as a Chrome trace",
+		Action:    verb,
+	})
+}
+
+func (verb *timelineVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedTimeline, err := client.Get(ctx, c.Timeline().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the timeline")
+	}
+	timeline := boxedTimeline.(*service.Timeline)
+
+	var w io.Writer = os.Stdout
+	if verb.Out != "" {
+		f, err := os.OpenFile(verb.Out, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return log.Err(ctx, err, "Failed to open trace output file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(timelineTrace(timeline)); err != nil {
+		return log.Err(ctx, err, "marshal json")
+	}
+	return nil
+}
+
+// timelineTrace returns the trace events of the timeline, using command
+// indices as timestamps. The thread tracks and the context and frame tracks
+// are shown as the threads of two processes, and the flows as flow events.
+func timelineTrace(timeline *service.Timeline) []traceEvent {
+	events := []traceEvent{
+		{Name: "process_name", Phase: "M", Pid: 1, Args: map[string]string{"name": "Threads"}},
+		{Name: "process_name", Phase: "M", Pid: 2, Args: map[string]string{"name": "Contexts"}},
+	}
+	pid := func(track uint32) int {
+		if timeline.Tracks[track].Kind == service.TimelineTrackKind_ThreadTrack {
+			return 1
+		}
+		return 2
+	}
+	for i, t := range timeline.Tracks {
+		events = append(events, traceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Pid:   pid(uint32(i)),
+			Tid:   i,
+			Args:  map[string]string{"name": t.Name},
+		})
+	}
+
+	for _, s := range timeline.Slices {
+		first, last := s.First.Indices[0], s.Last.Indices[0]
+		events = append(events, traceEvent{
+			Name:  s.Name,
+			Cat:   s.Kind.String(),
+			Phase: "X",
+			Ts:    first,
+			Dur:   last - first + 1,
+			Pid:   pid(s.Track),
+			Tid:   int(s.Track),
+		})
+	}
+
+	for i, f := range timeline.Flows {
+		events = append(events, traceEvent{
+			Name:  f.Name,
+			Cat:   f.Kind.String(),
+			Phase: "s",
+			Ts:    f.From.Indices[0],
+			Pid:   pid(f.FromTrack),
+			Tid:   int(f.FromTrack),
+			ID:    i + 1,
+		}, traceEvent{
+			Name:  f.Name,
+			Cat:   f.Kind.String(),
+			Phase: "f",
+			Ts:    f.To.Indices[0],
+			Pid:   pid(f.ToTrack),
+			Tid:   int(f.ToTrack),
+			ID:    i + 1,
+			Bind:  "e",
+		})
+	}
+	return events
+}
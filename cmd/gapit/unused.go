@@ -0,0 +1,76 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/google/gapid/core/app"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/service"
+)
+
+type unusedVerb struct{ UnusedFlags }
+
+func init() {
+	verb := &unusedVerb{}
+	app.AddVerb(&app.Verb{
+		Name:      "unused",
+		ShortHelp: "Prints the unused, write-only and duplicate-uploaded resources of a capture",
+		Action:    verb,
+	})
+}
+
+func (verb *unusedVerb) Run(ctx context.Context, flags flag.FlagSet) error {
+	client, c, err := loadCapture(ctx, flags, verb.Gapis)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	boxedUnused, err := client.Get(ctx, c.UnusedResources().Path())
+	if err != nil {
+		return log.Err(ctx, err, "Failed to get the unused resources")
+	}
+	unused := boxedUnused.(*service.UnusedResources)
+
+	for _, a := range unused.UncheckedApis {
+		fmt.Printf("The %v resources are not checked for unread writes and duplicate uploads\n", a)
+	}
+
+	for _, r := range unused.Resources {
+		name := r.Handle
+		if r.Label != "" {
+			name = fmt.Sprintf("%v %v", r.Handle, r.Label)
+		}
+		if r.Created != nil {
+			fmt.Printf("%v (created by %v):\n", name, r.Created.Indices)
+		} else {
+			fmt.Printf("%v (initial state):\n", name)
+		}
+		if r.NeverDrawn {
+			fmt.Println("  never used by a draw call")
+		}
+		if r.UnreadWrite != nil {
+			fmt.Printf("  written by %v but never read afterwards\n", r.UnreadWrite.Indices)
+		}
+		for _, d := range r.DuplicateUploads {
+			fmt.Printf("  uploaded by %v with the same content as by %v\n", d.Command.Indices, d.Original.Indices)
+		}
+	}
+	return nil
+}
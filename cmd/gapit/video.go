@@ -128,7 +128,7 @@ func (verb *videoVerb) regularVideoSource(
 	for i, e := range eofEvents {
 		i, e := i, e
 		executor(ctx, func(ctx context.Context) error {
-			if frame, err := getFrame(ctx, verb.Max.Width, verb.Max.Height, e.Command, device, client); err == nil {
+			if frame, err := getFrame(ctx, verb.Max.Width, verb.Max.Height, e.Command, verb.replaySettings(device), client); err == nil {
 				rendered[i] = flipImg(frame)
 			} else {
 				errors[i] = err
@@ -326,12 +326,19 @@ func (verb *videoVerb) encodeVideo(ctx context.Context, filepath string, vidFun
 	return nil
 }
 
-func getFrame(ctx context.Context, maxWidth, maxHeight int, cmd *path.Command, device *path.Device, client service.Service) (*image.NRGBA, error) {
+// replaySettings returns the replay settings to use for replaying frames on
+// device.
+func (verb *videoVerb) replaySettings(device *path.Device) *service.ReplaySettings {
+	return &service.ReplaySettings{
+		Device:        device,
+		UseDevicePool: verb.Pool,
+	}
+}
+
+func getFrame(ctx context.Context, maxWidth, maxHeight int, cmd *path.Command, replaySettings *service.ReplaySettings, client service.Service) (*image.NRGBA, error) {
 	ctx = log.V{"cmd": cmd.Indices}.Bind(ctx)
 	settings := &service.RenderSettings{MaxWidth: uint32(maxWidth), MaxHeight: uint32(maxHeight)}
-	iip, err := client.GetFramebufferAttachment(ctx, &service.ReplaySettings{
-		Device: device,
-	}, cmd, api.FramebufferAttachment_Color0, settings, nil)
+	iip, err := client.GetFramebufferAttachment(ctx, replaySettings, cmd, api.FramebufferAttachment_Color0, settings, nil)
 	if err != nil {
 		return nil, log.Errf(ctx, err, "GetFramebufferAttachment failed at %v", cmd)
 	}
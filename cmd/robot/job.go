@@ -100,6 +100,7 @@ func (v *workerSearchFlags) Run(ctx context.Context, flags flag.FlagSet) error {
 
 type workerStartFlags struct {
 	RobotOptions
+	DeterminismReplays int `help:"Replays of each trace checking the determinism of the replay, 0 to disable"`
 }
 
 func (v *workerStartFlags) Run(ctx context.Context, flags flag.FlagSet) error {
@@ -116,7 +117,7 @@ func (v *workerStartFlags) Run(ctx context.Context, flags flag.FlagSet) error {
 			Report: report.NewRemote(ctx, conn),
 			Replay: replay.NewRemote(ctx, conn),
 		}
-		if err := startAllWorkers(ctx, managers, tempDir); err != nil {
+		if err := startAllWorkers(ctx, managers, tempDir, v.DeterminismReplays); err != nil {
 			return err
 		}
 		shutdown, err := m.Orbit(ctx, master.ServiceList{Worker: true})
@@ -130,11 +131,11 @@ func (v *workerStartFlags) Run(ctx context.Context, flags flag.FlagSet) error {
 	}, grpc.WithInsecure())
 }
 
-func startAllWorkers(ctx context.Context, managers monitor.Managers, tempDir file.Path) error {
+func startAllWorkers(ctx context.Context, managers monitor.Managers, tempDir file.Path, determinismReplays int) error {
 	ctx = job.BindRegistry(ctx)
 	// TODO: not just ignore all the errors...
 	crash.Go(func() { trace.Run(ctx, managers.Stash, managers.Trace, tempDir) })
 	crash.Go(func() { report.Run(ctx, managers.Stash, managers.Report, tempDir) })
-	crash.Go(func() { replay.Run(ctx, managers.Stash, managers.Replay, tempDir) })
+	crash.Go(func() { replay.Run(ctx, managers.Stash, managers.Replay, tempDir, determinismReplays) })
 	return nil
 }
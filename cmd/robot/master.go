@@ -73,14 +73,15 @@ func init() {
 }
 
 type masterVerb struct {
-	BaseAddr     file.Path `help:"The base path for all robot files"`
-	StashAddr    string    `help:"The address of the stash, defaults to a directory below base"`
-	ShelfAddr    string    `help:"The path to the persisted data, defaults to a directory below base"`
-	Port         int       `help:"The port to serve the master on"`
-	StartWorkers bool      `help:"Enables local workers"`
-	StartWeb     bool      `help:"Enables serving the web client"`
-	WebPort      int       `help:"The port to serve the website on"`
-	Root         file.Path `help:"The directory to use as the root of static content"`
+	BaseAddr           file.Path `help:"The base path for all robot files"`
+	StashAddr          string    `help:"The address of the stash, defaults to a directory below base"`
+	ShelfAddr          string    `help:"The path to the persisted data, defaults to a directory below base"`
+	Port               int       `help:"The port to serve the master on"`
+	StartWorkers       bool      `help:"Enables local workers"`
+	StartWeb           bool      `help:"Enables serving the web client"`
+	WebPort            int       `help:"The port to serve the website on"`
+	Root               file.Path `help:"The directory to use as the root of static content"`
+	DeterminismReplays int       `help:"Replays of each trace checking the determinism of the replay, 0 to disable"`
 }
 
 func (v *masterVerb) Run(ctx context.Context, flags flag.FlagSet) error {
@@ -141,7 +142,7 @@ func (v *masterVerb) Run(ctx context.Context, flags flag.FlagSet) error {
 			return log.Errf(ctx, err, "Could not remove FLock files for all devices")
 		}
 		if v.StartWorkers {
-			if err := startAllWorkers(ctx, managers, tempDir); err != nil {
+			if err := startAllWorkers(ctx, managers, tempDir, v.DeterminismReplays); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,29 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DescriptorSetsProvider is the interface implemented by APIs that can list
+// the descriptor sets bound for a draw or dispatch command.
+type DescriptorSetsProvider interface {
+	// DescriptorSets returns the descriptor sets bound for the command at p.
+	// If nil, nil then the command is not a draw or dispatch of the API.
+	DescriptorSets(ctx context.Context, p *path.DescriptorSets) (*DescriptorSets, error)
+}
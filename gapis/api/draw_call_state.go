@@ -0,0 +1,29 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DrawCallStateProvider is the interface implemented by APIs that can
+// summarize the pipeline state used by a draw call.
+type DrawCallStateProvider interface {
+	// DrawCallState returns the pipeline state used by the draw call at p.
+	// If nil, nil then the command is not a draw call of the API.
+	DrawCallState(ctx context.Context, p *path.DrawCallState) (*DrawCallState, error)
+}
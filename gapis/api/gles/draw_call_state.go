@@ -0,0 +1,306 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/resolve"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DrawCallState implements the api.DrawCallStateProvider interface.
+func (API) DrawCallState(ctx context.Context, p *path.DrawCallState) (*api.DrawCallState, error) {
+	cmd, err := resolve.Cmd(ctx, p.Command)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cmd.(drawCall); !ok {
+		return nil, nil
+	}
+	s, err := resolve.GlobalState(ctx, p.Command.GlobalStateAfter())
+	if err != nil {
+		return nil, err
+	}
+	c := GetContext(s, cmd.Thread())
+	if c == nil || !c.Other.Initialized {
+		return nil, nil
+	}
+
+	out := &api.DrawCallState{
+		Textures:     c.textureBindings(),
+		Blend:        c.blendAttachments(),
+		DepthStencil: c.depthStencilState(),
+		Rasterizer:   c.rasterizerState(),
+		Attachments:  c.attachmentBindings(GetState(s), cmd.Thread()),
+	}
+	if prog := c.Bound.Program; prog != nil {
+		out.Program = prog.ResourceHandle()
+		for _, ty := range prog.Shaders.Keys() {
+			shader := prog.Shaders.Get(ty)
+			out.Shaders = append(out.Shaders, &api.ShaderStage{
+				Type:       shaderType(ty),
+				Shader:     shader.ResourceHandle(),
+				EntryPoint: "main",
+			})
+		}
+	}
+	out.VertexAttributes = c.vertexAttributes(c.Bound.Program)
+	return out, nil
+}
+
+// shaderType returns the api shader type of the GLES shader type ty.
+func shaderType(ty GLenum) api.ShaderType {
+	switch ty {
+	case GLenum_GL_GEOMETRY_SHADER:
+		return api.ShaderType_Geometry
+	case GLenum_GL_TESS_CONTROL_SHADER:
+		return api.ShaderType_TessControl
+	case GLenum_GL_TESS_EVALUATION_SHADER:
+		return api.ShaderType_TessEvaluation
+	case GLenum_GL_FRAGMENT_SHADER:
+		return api.ShaderType_Fragment
+	case GLenum_GL_COMPUTE_SHADER:
+		return api.ShaderType_Compute
+	default:
+		return api.ShaderType_Vertex
+	}
+}
+
+// vertexAttributes returns the enabled vertex attribute arrays, and the
+// inputs of the program prog, in location order.
+func (c *Context) vertexAttributes(prog *Program) []*api.VertexAttribute {
+	names := map[AttributeLocation]string{}
+	if prog != nil && prog.ActiveResources != nil {
+		for _, input := range prog.ActiveResources.ProgramInputs.Range() {
+			if loc, ok := input.Locations.Lookup(0); ok && loc >= 0 {
+				names[AttributeLocation(loc)] = input.Name
+			}
+		}
+	}
+
+	out := []*api.VertexAttribute{}
+	arrays := c.Bound.VertexArray.VertexAttributeArrays
+	for _, loc := range arrays.Keys() {
+		arr := arrays.Get(loc)
+		name, used := names[loc]
+		enabled := arr.Enabled == GLboolean_GL_TRUE
+		if !enabled && !used {
+			continue
+		}
+		format := fmt.Sprintf("%v x%d", arr.Type, arr.Size)
+		switch {
+		case arr.Integer == GLboolean_GL_TRUE:
+			format += " integer"
+		case arr.Normalized == GLboolean_GL_TRUE:
+			format += " normalized"
+		}
+		attr := &api.VertexAttribute{
+			Location: uint32(loc),
+			Name:     name,
+			Enabled:  enabled,
+			Format:   format,
+			Offset:   uint64(arr.RelativeOffset),
+		}
+		if b := arr.Binding; b != nil {
+			attr.Binding = uint32(b.Id)
+			attr.Offset += uint64(b.Offset)
+			attr.Stride = uint32(b.Stride)
+			attr.PerInstance = b.Divisor != 0
+			if b.Buffer != nil {
+				attr.Buffer = fmt.Sprintf("Buffer<%d>", b.Buffer.ID)
+			} else {
+				attr.Buffer = "client memory"
+			}
+		}
+		out = append(out, attr)
+	}
+	return out
+}
+
+// textureBindings returns the textures bound to the texture units, in unit
+// order. The sampling parameters are those of the bound sampler object, if
+// any.
+func (c *Context) textureBindings() []*api.TextureBinding {
+	out := []*api.TextureBinding{}
+	for _, id := range c.Objects.TextureUnits.Keys() {
+		unit := c.Objects.TextureUnits.Get(id)
+		for _, t := range []*Texture{
+			unit.Binding2d,
+			unit.Binding3d,
+			unit.Binding2dArray,
+			unit.BindingBuffer,
+			unit.BindingCubeMap,
+			unit.BindingCubeMapArray,
+			unit.Binding2dMultisample,
+			unit.Binding2dMultisampleArray,
+			unit.BindingExternalOes,
+		} {
+			if t == nil || t.ID == 0 {
+				continue
+			}
+			binding := &api.TextureBinding{
+				Unit:     uint32(id),
+				Target:   t.Kind.String(),
+				Texture:  t.ResourceHandle(),
+				Sampling: t.samplingParameters(),
+			}
+			if img := t.Levels.Get(0).Layers.Get(0); img != nil {
+				binding.Format = img.SizedFormat.String()
+			}
+			if sampler := unit.SamplerBinding; sampler != nil {
+				binding.Sampler = fmt.Sprintf("Sampler<%d>", sampler.ID)
+				binding.Sampling.MinFilter = filterModes[sampler.MinFilter]
+				binding.Sampling.MagFilter = filterModes[sampler.MagFilter]
+				binding.Sampling.WrapS = wrapModes[sampler.WrapS]
+				binding.Sampling.WrapT = wrapModes[sampler.WrapT]
+				binding.Sampling.WrapR = wrapModes[sampler.WrapR]
+				binding.Sampling.MinLod = float32(sampler.MinLod)
+				binding.Sampling.MaxLod = float32(sampler.MaxLod)
+				binding.Sampling.MaxAnisotropy = float32(sampler.MaxAnisotropy)
+			}
+			out = append(out, binding)
+		}
+	}
+	return out
+}
+
+// blendAttachments returns the blend state of each draw buffer.
+func (c *Context) blendAttachments() []*api.BlendAttachment {
+	out := []*api.BlendAttachment{}
+	for _, i := range c.Pixel.Blend.Keys() {
+		b := c.Pixel.Blend.Get(i)
+		mask := ""
+		if m, ok := c.Pixel.ColorWritemask.Lookup(i); ok {
+			for _, ch := range []struct {
+				enabled GLboolean
+				name    string
+			}{{m.R, "R"}, {m.G, "G"}, {m.B, "B"}, {m.A, "A"}} {
+				if ch.enabled == GLboolean_GL_TRUE {
+					mask += ch.name
+				}
+			}
+		}
+		out = append(out, &api.BlendAttachment{
+			Attachment: uint32(i),
+			Enabled:    b.Enabled == GLboolean_GL_TRUE,
+			SrcColor:   b.SrcRgb.String(),
+			DstColor:   b.DstRgb.String(),
+			ColorOp:    b.EquationRgb.String(),
+			SrcAlpha:   b.SrcAlpha.String(),
+			DstAlpha:   b.DstAlpha.String(),
+			AlphaOp:    b.EquationAlpha.String(),
+			WriteMask:  mask,
+		})
+	}
+	return out
+}
+
+// depthStencilState returns the depth and stencil test state.
+func (c *Context) depthStencilState() *api.DepthStencilState {
+	p, st := c.Pixel, c.Pixel.Stencil
+	return &api.DepthStencilState{
+		DepthTest:   p.Depth.Test == GLboolean_GL_TRUE,
+		DepthWrite:  p.DepthWritemask == GLboolean_GL_TRUE,
+		DepthFunc:   p.Depth.Func.String(),
+		DepthNear:   float32(c.Rasterization.DepthRange[0]),
+		DepthFar:    float32(c.Rasterization.DepthRange[1]),
+		StencilTest: st.Test == GLboolean_GL_TRUE,
+		Front: &api.StencilFace{
+			Func:        st.Func.String(),
+			Reference:   uint32(st.Ref),
+			CompareMask: uint32(st.ValueMask),
+			WriteMask:   uint32(p.StencilWritemask),
+			FailOp:      st.Fail.String(),
+			DepthFailOp: st.PassDepthFail.String(),
+			PassOp:      st.PassDepthPass.String(),
+		},
+		Back: &api.StencilFace{
+			Func:        st.BackFunc.String(),
+			Reference:   uint32(st.BackRef),
+			CompareMask: uint32(st.BackValueMask),
+			WriteMask:   uint32(p.StencilBackWritemask),
+			FailOp:      st.BackFail.String(),
+			DepthFailOp: st.BackPassDepthFail.String(),
+			PassOp:      st.BackPassDepthPass.String(),
+		},
+	}
+}
+
+// rasterizerState returns the rasterization state.
+func (c *Context) rasterizerState() *api.RasterizerState {
+	r, scissor := c.Rasterization, c.Pixel.Scissor
+	return &api.RasterizerState{
+		Viewport: &api.Viewport{
+			X:      float32(r.Viewport.X),
+			Y:      float32(r.Viewport.Y),
+			Width:  float32(r.Viewport.Width),
+			Height: float32(r.Viewport.Height),
+		},
+		Scissor: &api.Scissor{
+			Enabled: scissor.Test == GLboolean_GL_TRUE,
+			X:       int32(scissor.Box.X),
+			Y:       int32(scissor.Box.Y),
+			Width:   uint32(scissor.Box.Width),
+			Height:  uint32(scissor.Box.Height),
+		},
+		Cull:                r.CullFace == GLboolean_GL_TRUE,
+		CullFace:            r.CullFaceMode.String(),
+		FrontFace:           r.FrontFace.String(),
+		LineWidth:           float32(r.LineWidth),
+		PolygonOffset:       r.PolygonOffsetFill == GLboolean_GL_TRUE,
+		PolygonOffsetFactor: float32(r.PolygonOffsetFactor),
+		PolygonOffsetUnits:  float32(r.PolygonOffsetUnits),
+		RasterizerDiscard:   r.RasterizerDiscard == GLboolean_GL_TRUE,
+	}
+}
+
+// attachmentBindings returns the attachments of the bound draw framebuffer.
+func (c *Context) attachmentBindings(s *State, thread uint64) []*api.AttachmentBinding {
+	fb := c.Bound.DrawFramebuffer
+	if fb == nil {
+		return nil
+	}
+	atts := []GLenum{}
+	for _, i := range fb.ColorAttachments.Keys() {
+		atts = append(atts, GLenum_GL_COLOR_ATTACHMENT0+GLenum(i))
+	}
+	atts = append(atts, GLenum_GL_DEPTH_ATTACHMENT, GLenum_GL_STENCIL_ATTACHMENT)
+
+	out := []*api.AttachmentBinding{}
+	for _, att := range atts {
+		a, err := fb.getAttachment(att)
+		if err != nil || a.Type == GLenum_GL_NONE {
+			continue
+		}
+		binding := &api.AttachmentBinding{Attachment: att.String()}
+		switch {
+		case a.Texture != nil:
+			binding.Resource = a.Texture.ResourceHandle()
+		case a.Renderbuffer != nil && fb.ID == 0:
+			binding.Resource = "Backbuffer"
+		case a.Renderbuffer != nil:
+			binding.Resource = fmt.Sprintf("Renderbuffer<%d>", a.Renderbuffer.ID)
+		}
+		if info, err := s.getFramebufferAttachmentInfo(thread, fb.ID, att); err == nil {
+			binding.Width, binding.Height = info.width, info.height
+			binding.Format = info.format.String()
+		}
+		out = append(out, binding)
+	}
+	return out
+}
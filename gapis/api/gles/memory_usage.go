@@ -0,0 +1,104 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+)
+
+// EstimateMemoryUsage returns the textures, buffers and renderbuffers of all
+// the contexts, with their estimated size.
+// Objects shared between contexts are only returned once.
+func (API) EstimateMemoryUsage(ctx context.Context, s *api.GlobalState) []api.MemoryAllocation {
+	out := []api.MemoryAllocation{}
+	seen := map[interface{}]bool{}
+	for _, c := range GetState(s).EGLContexts.Range() {
+		for _, t := range c.Objects.Textures.Range() {
+			if !seen[t] {
+				seen[t] = true
+				out = append(out, api.MemoryAllocation{
+					Category: "Texture",
+					Name:     t.ResourceHandle(),
+					Size:     t.estimatedSize(),
+				})
+			}
+		}
+		for _, b := range c.Objects.Buffers.Range() {
+			if !seen[b] {
+				seen[b] = true
+				out = append(out, api.MemoryAllocation{
+					Category: "Buffer",
+					Name:     fmt.Sprintf("Buffer<%d>", b.ID),
+					Size:     uint64(b.Size),
+				})
+			}
+		}
+		for _, r := range c.Objects.Renderbuffers.Range() {
+			if !seen[r] {
+				seen[r] = true
+				var size uint64
+				if r.Image != nil {
+					size = r.Image.estimatedSize()
+				}
+				out = append(out, api.MemoryAllocation{
+					Category: "Renderbuffer",
+					Name:     fmt.Sprintf("Renderbuffer<%d>", r.ID),
+					Size:     size,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// estimatedSize returns the sum of the estimated sizes of all the images of
+// the texture.
+func (t *Texture) estimatedSize() uint64 {
+	size := uint64(0)
+	for _, l := range t.Levels.Range() {
+		for _, i := range l.Layers.Range() {
+			if i != nil {
+				size += i.estimatedSize()
+			}
+		}
+	}
+	return size
+}
+
+// estimatedSize returns the estimated size of the image in GPU memory, using
+// its sized format if known, or the size of its data otherwise.
+func (i *Image) estimatedSize() uint64 {
+	size := i.Data.count
+	format, ty := i.DataFormat, i.DataType
+	if format == GLenum_GL_NONE && ty == GLenum_GL_NONE {
+		info, _ := subGetSizedFormatInfo(nil, nil, api.CmdNoID, nil, &api.GlobalState{}, nil, 0, nil, i.SizedFormat)
+		format, ty = info.UnsizedFormat, info.DataType
+		if info.SizedFormat == GLenum_GL_NONE || format == GLenum_GL_NONE {
+			return size
+		}
+	}
+	if f, err := getImageFormat(format, ty); err == nil {
+		if s := f.Size(int(i.Width), int(i.Height), 1); s > 0 {
+			size = uint64(s)
+		}
+	}
+	if i.Samples > 1 {
+		size *= uint64(i.Samples)
+	}
+	return size
+}
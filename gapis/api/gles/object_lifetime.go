@@ -0,0 +1,199 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+)
+
+// newObject returns the api.Object for the object o of the given kind and name.
+// Objects are identified by their pointer, as their names are only unique in
+// their share group.
+func newObject(o interface{}, kind string, name interface{}) api.Object {
+	return api.Object{Key: o, Value: o, Kind: kind, Handle: fmt.Sprintf("%v<%v>", kind, name)}
+}
+
+// CreatedObjects implements the api.ObjectLifetimeTracker interface.
+// GLES objects are created when their name is first bound.
+// Textures, shaders and programs are resources, and are reported by the
+// state OnResourceCreated callback instead.
+func (API) CreatedObjects(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) []api.Object {
+	c := GetContext(s, cmd.Thread())
+	if c == nil {
+		return nil
+	}
+	o := c.Objects
+	out := []api.Object{}
+	switch cmd := cmd.(type) {
+	case *GlBindBuffer:
+		if b := o.Buffers.Get(cmd.Buffer); b != nil {
+			out = append(out, newObject(b, "Buffer", b.ID))
+		}
+	case *GlBindBufferBase:
+		if b := o.Buffers.Get(cmd.Buffer); b != nil {
+			out = append(out, newObject(b, "Buffer", b.ID))
+		}
+	case *GlBindBufferRange:
+		if b := o.Buffers.Get(cmd.Buffer); b != nil {
+			out = append(out, newObject(b, "Buffer", b.ID))
+		}
+	case *GlBindVertexBuffer:
+		if b := o.Buffers.Get(cmd.Buffer); b != nil {
+			out = append(out, newObject(b, "Buffer", b.ID))
+		}
+	case *GlBindFramebuffer:
+		if f := o.Framebuffers.Get(cmd.Framebuffer); f != nil {
+			out = append(out, newObject(f, "Framebuffer", f.ID))
+		}
+	case *GlBindRenderbuffer:
+		if r := o.Renderbuffers.Get(cmd.Renderbuffer); r != nil {
+			out = append(out, newObject(r, "Renderbuffer", r.ID))
+		}
+	case *GlBindVertexArray:
+		if v := o.VertexArrays.Get(cmd.Array); v != nil {
+			out = append(out, newObject(v, "VertexArray", v.ID))
+		}
+	case *GlBindVertexArrayOES:
+		if v := o.VertexArrays.Get(cmd.Array); v != nil {
+			out = append(out, newObject(v, "VertexArray", v.ID))
+		}
+	case *GlBindSampler:
+		if x := o.Samplers.Get(cmd.Sampler); x != nil {
+			out = append(out, newObject(x, "Sampler", x.ID))
+		}
+	case *GlBindTransformFeedback:
+		if t := o.TransformFeedbacks.Get(cmd.Id); t != nil {
+			out = append(out, newObject(t, "TransformFeedback", t.ID))
+		}
+	case *GlBindProgramPipeline:
+		if p := o.Pipelines.Get(cmd.Pipeline); p != nil {
+			out = append(out, newObject(p, "ProgramPipeline", p.ID))
+		}
+	case *GlBeginQuery:
+		if q := o.Queries.Get(cmd.Query); q != nil {
+			out = append(out, newObject(q, "Query", q.ID))
+		}
+	case *GlBeginQueryEXT:
+		if q := o.Queries.Get(cmd.Query); q != nil {
+			out = append(out, newObject(q, "Query", q.ID))
+		}
+	}
+	return out
+}
+
+// DestroyedObjects implements the api.ObjectLifetimeTracker interface.
+// Shaders and programs are considered destroyed when they are deleted, even
+// if their deletion is deferred until they are no longer in use.
+func (API) DestroyedObjects(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) []api.Object {
+	c := GetContext(s, cmd.Thread())
+	if c == nil {
+		return nil
+	}
+	o := c.Objects
+	l := s.MemoryLayout
+	out := []api.Object{}
+	switch cmd := cmd.(type) {
+	case *GlDeleteBuffers:
+		for _, n := range cmd.Buffers.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if b := o.Buffers.Get(n); b != nil {
+				out = append(out, newObject(b, "Buffer", n))
+			}
+		}
+	case *GlDeleteFramebuffers:
+		for _, n := range cmd.Framebuffers.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if f := o.Framebuffers.Get(n); f != nil {
+				out = append(out, newObject(f, "Framebuffer", n))
+			}
+		}
+	case *GlDeleteRenderbuffers:
+		for _, n := range cmd.Renderbuffers.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if r := o.Renderbuffers.Get(n); r != nil {
+				out = append(out, newObject(r, "Renderbuffer", n))
+			}
+		}
+	case *GlDeleteVertexArrays:
+		out = deletedVertexArrays(o, cmd.Arrays.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteVertexArraysOES:
+		out = deletedVertexArrays(o, cmd.Arrays.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteSamplers:
+		for _, n := range cmd.Samplers.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if x := o.Samplers.Get(n); x != nil {
+				out = append(out, newObject(x, "Sampler", n))
+			}
+		}
+	case *GlDeleteTransformFeedbacks:
+		for _, n := range cmd.Ids.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if t := o.TransformFeedbacks.Get(n); t != nil {
+				out = append(out, newObject(t, "TransformFeedback", n))
+			}
+		}
+	case *GlDeleteProgramPipelines:
+		out = deletedPipelines(o, cmd.Pipelines.Slice(0, uint64(cmd.N), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteProgramPipelinesEXT:
+		out = deletedPipelines(o, cmd.Pipelines.Slice(0, uint64(cmd.N), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteQueries:
+		out = deletedQueries(o, cmd.Queries.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteQueriesEXT:
+		out = deletedQueries(o, cmd.Queries.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil))
+	case *GlDeleteTextures:
+		for _, n := range cmd.Textures.Slice(0, uint64(cmd.Count), l).MustRead(ctx, cmd, s, nil) {
+			if t := o.Textures.Get(n); t != nil {
+				out = append(out, newObject(t, "Texture", n))
+			}
+		}
+	case *GlDeleteShader:
+		if x := o.Shaders.Get(cmd.Shader); x != nil {
+			out = append(out, newObject(x, "Shader", cmd.Shader))
+		}
+	case *GlDeleteProgram:
+		if p := o.Programs.Get(cmd.Program); p != nil {
+			out = append(out, newObject(p, "Program", cmd.Program))
+		}
+	}
+	return out
+}
+
+func deletedVertexArrays(o Objects, names []VertexArrayId) []api.Object {
+	out := []api.Object{}
+	for _, n := range names {
+		if v := o.VertexArrays.Get(n); v != nil {
+			out = append(out, newObject(v, "VertexArray", n))
+		}
+	}
+	return out
+}
+
+func deletedPipelines(o Objects, names []PipelineId) []api.Object {
+	out := []api.Object{}
+	for _, n := range names {
+		if p := o.Pipelines.Get(n); p != nil {
+			out = append(out, newObject(p, "ProgramPipeline", n))
+		}
+	}
+	return out
+}
+
+func deletedQueries(o Objects, names []QueryId) []api.Object {
+	out := []api.Object{}
+	for _, n := range names {
+		if q := o.Queries.Get(n); q != nil {
+			out = append(out, newObject(q, "Query", n))
+		}
+	}
+	return out
+}
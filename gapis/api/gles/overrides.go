@@ -0,0 +1,394 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// filterModes maps the GLES texture filters to the api filter modes.
+var filterModes = map[GLenum]api.FilterMode{
+	GLenum_GL_NEAREST:                api.FilterMode_Nearest,
+	GLenum_GL_LINEAR:                 api.FilterMode_Linear,
+	GLenum_GL_NEAREST_MIPMAP_NEAREST: api.FilterMode_NearestMipmapNearest,
+	GLenum_GL_LINEAR_MIPMAP_NEAREST:  api.FilterMode_LinearMipmapNearest,
+	GLenum_GL_NEAREST_MIPMAP_LINEAR:  api.FilterMode_NearestMipmapLinear,
+	GLenum_GL_LINEAR_MIPMAP_LINEAR:   api.FilterMode_LinearMipmapLinear,
+}
+
+// wrapModes maps the GLES texture wrap modes to the api wrap modes.
+var wrapModes = map[GLenum]api.WrapMode{
+	GLenum_GL_REPEAT:          api.WrapMode_Repeat,
+	GLenum_GL_CLAMP_TO_EDGE:   api.WrapMode_ClampToEdge,
+	GLenum_GL_CLAMP_TO_BORDER: api.WrapMode_ClampToBorder,
+	GLenum_GL_MIRRORED_REPEAT: api.WrapMode_MirroredRepeat,
+}
+
+// samplingParameters returns the sampling parameters of the texture object.
+func (t *Texture) samplingParameters() *api.SamplingParameters {
+	return &api.SamplingParameters{
+		MinFilter:     filterModes[t.MinFilter],
+		MagFilter:     filterModes[t.MagFilter],
+		WrapS:         wrapModes[t.WrapS],
+		WrapT:         wrapModes[t.WrapT],
+		WrapR:         wrapModes[t.WrapR],
+		MinLod:        float32(t.MinLod),
+		MaxLod:        float32(t.MaxLod),
+		BaseLevel:     int32(t.BaseLevel),
+		MaxLevel:      int32(t.MaxLevel),
+		MaxAnisotropy: float32(t.MaxAnisotropy),
+	}
+}
+
+// glFilterMode returns the GLES texture filter for the api filter mode.
+func glFilterMode(m api.FilterMode) (GLenum, bool) {
+	for f, mode := range filterModes {
+		if mode == m {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// glWrapMode returns the GLES texture wrap mode for the api wrap mode.
+func glWrapMode(m api.WrapMode) (GLenum, bool) {
+	for w, mode := range wrapModes {
+		if mode == m {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// recorder is a transform.Writer that mutates its state and collects the
+// written commands.
+type recorder struct {
+	state *api.GlobalState
+	cmds  []api.Cmd
+}
+
+func (r *recorder) State() *api.GlobalState { return r.state }
+
+func (r *recorder) MutateAndWrite(ctx context.Context, id api.CmdID, cmd api.Cmd) {
+	cmd.Mutate(ctx, id, r.state, nil)
+	r.cmds = append(r.cmds, cmd)
+}
+
+// override returns a tweaker writing to a recorder whose state is the state
+// before the command at. The tweaker uses the context of the thread of the
+// command.
+func override(ctx context.Context, at *path.Command) (*tweaker, *recorder, error) {
+	if len(at.Indices) > 1 {
+		return nil, nil, fmt.Errorf("Subcommands currently not supported for GLES resources") // TODO: Subcommands
+	}
+	c, err := capture.ResolveFromPath(ctx, at.Capture)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmdIdx := at.Indices[0]
+	if cmdIdx >= uint64(len(c.Commands)) {
+		return nil, nil, fmt.Errorf("Command %v is out of range", cmdIdx)
+	}
+
+	s := c.NewState(ctx)
+	err = api.ForeachCmd(ctx, c.Commands[:cmdIdx], func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thread := c.Commands[cmdIdx].Thread()
+	if GetContext(s, thread) == nil {
+		return nil, nil, fmt.Errorf("No context bound on the thread of command %v", cmdIdx)
+	}
+	out := &recorder{state: s}
+	t := newTweaker(out, api.CmdID(cmdIdx), CommandBuilder{Thread: thread})
+	return t, out, nil
+}
+
+// OverrideResourceData changes the sampling parameters of the texture from
+// the command at on, by inserting glTexParameter commands before it. The
+// sampler objects bound to the texture units of the texture are unbound, as
+// they would hide the texture parameters. The texture data cannot be changed.
+func (t *Texture) OverrideResourceData(ctx context.Context, at *path.Command,
+	data *api.ResourceData, insert api.InsertCallback) error {
+
+	sampling := data.GetTexture().GetSampling()
+	if sampling == nil {
+		return fmt.Errorf("Only the sampling parameters of a Texture can be changed")
+	}
+	switch t.Kind {
+	case GLenum_GL_TEXTURE_2D, GLenum_GL_TEXTURE_3D, GLenum_GL_TEXTURE_2D_ARRAY,
+		GLenum_GL_TEXTURE_CUBE_MAP, GLenum_GL_TEXTURE_CUBE_MAP_ARRAY,
+		GLenum_GL_TEXTURE_EXTERNAL_OES:
+	default:
+		return fmt.Errorf("Cannot change the sampling parameters of %v textures", t.Kind)
+	}
+
+	tw, out, err := override(ctx, at)
+	if err != nil {
+		return err
+	}
+	tex := tw.c.Objects.Textures.Get(t.ID)
+	if tex == nil {
+		return fmt.Errorf("%v is not available in the context of command %v", t.ResourceHandle(), at.Indices[0])
+	}
+
+	minFilter, ok1 := glFilterMode(sampling.MinFilter)
+	magFilter, ok2 := glFilterMode(sampling.MagFilter)
+	wrapS, ok3 := glWrapMode(sampling.WrapS)
+	wrapT, ok4 := glWrapMode(sampling.WrapT)
+	wrapR, ok5 := glWrapMode(sampling.WrapR)
+	if !(ok1 && ok2 && ok3 && ok4 && ok5) {
+		return fmt.Errorf("Invalid sampling parameters %v", sampling)
+	}
+
+	write := func(cmd api.Cmd) { out.MutateAndWrite(ctx, tw.dID, cmd) }
+	for _, unit := range tw.c.Objects.TextureUnits.Keys() {
+		tu := tw.c.Objects.TextureUnits.Get(unit)
+		if tu.SamplerBinding != nil && tu.SamplerBinding.ID != 0 && tu.binds(tex) {
+			write(tw.cb.GlBindSampler(GLuint(unit), 0))
+		}
+	}
+
+	tw.glBindTexture(ctx, tex)
+	setEnum := func(name GLenum, o, n GLenum) {
+		if o != n {
+			write(tw.cb.GlTexParameteri(tex.Kind, name, GLint(n)))
+		}
+	}
+	setInt := func(name GLenum, o, n GLint) {
+		if o != n {
+			write(tw.cb.GlTexParameteri(tex.Kind, name, n))
+		}
+	}
+	setFloat := func(name GLenum, o, n GLfloat) {
+		if o != n {
+			write(tw.cb.GlTexParameterf(tex.Kind, name, n))
+		}
+	}
+	setEnum(GLenum_GL_TEXTURE_MIN_FILTER, tex.MinFilter, minFilter)
+	setEnum(GLenum_GL_TEXTURE_MAG_FILTER, tex.MagFilter, magFilter)
+	setEnum(GLenum_GL_TEXTURE_WRAP_S, tex.WrapS, wrapS)
+	setEnum(GLenum_GL_TEXTURE_WRAP_T, tex.WrapT, wrapT)
+	setEnum(GLenum_GL_TEXTURE_WRAP_R, tex.WrapR, wrapR)
+	setFloat(GLenum_GL_TEXTURE_MIN_LOD, tex.MinLod, GLfloat(sampling.MinLod))
+	setFloat(GLenum_GL_TEXTURE_MAX_LOD, tex.MaxLod, GLfloat(sampling.MaxLod))
+	setInt(GLenum_GL_TEXTURE_BASE_LEVEL, tex.BaseLevel, GLint(sampling.BaseLevel))
+	setInt(GLenum_GL_TEXTURE_MAX_LEVEL, tex.MaxLevel, GLint(sampling.MaxLevel))
+	setFloat(GLenum_GL_TEXTURE_MAX_ANISOTROPY_EXT, tex.MaxAnisotropy, GLfloat(sampling.MaxAnisotropy))
+	tw.revert(ctx)
+
+	insert(at.Indices[0], out.cmds)
+	return nil
+}
+
+// binds returns true if tex is bound to one of the targets of the texture
+// unit.
+func (tu *TextureUnit) binds(tex *Texture) bool {
+	for _, t := range []*Texture{
+		tu.Binding2d,
+		tu.Binding3d,
+		tu.Binding2dArray,
+		tu.BindingCubeMap,
+		tu.BindingCubeMapArray,
+		tu.BindingExternalOes,
+	} {
+		if t == tex {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideResourceData changes the values of the uniforms of the default
+// uniform block of the program from the command at on, by inserting glUniform
+// commands before it. Only the uniform values can be changed.
+func (p *Program) OverrideResourceData(ctx context.Context, at *path.Command,
+	data *api.ResourceData, insert api.InsertCallback) error {
+
+	program := data.GetProgram()
+	if program == nil {
+		return fmt.Errorf("Expected Program, got %T", data.Data)
+	}
+
+	tw, out, err := override(ctx, at)
+	if err != nil {
+		return err
+	}
+	prog := tw.c.Objects.Programs.Get(p.ID)
+	if prog == nil || prog.ActiveResources == nil {
+		return fmt.Errorf("%v is not available in the context of command %v", p.ResourceHandle(), at.Indices[0])
+	}
+
+	active := map[uint32]*ProgramResource{}
+	for _, u := range prog.ActiveResources.DefaultUniformBlock.Range() {
+		if loc, ok := u.Locations.Lookup(0); ok && loc >= 0 {
+			active[uint32(loc)] = u
+		}
+	}
+
+	used := false
+	for _, uniform := range program.Uniforms {
+		u, ok := active[uniform.UniformLocation]
+		if !ok {
+			return fmt.Errorf("No active uniform at location %v", uniform.UniformLocation)
+		}
+		if uniform.Value == nil {
+			continue
+		}
+		format, kind := uniformFormatAndType(u.Type)
+		value := uniform.Value.Get()
+		if reflect.DeepEqual(value, uniformValue(ctx, tw.s, kind, u.Value)) {
+			continue
+		}
+		if !used {
+			tw.glUseProgram(ctx, prog.ID)
+			used = true
+		}
+		if err := setUniform(ctx, tw, out, u.Type, format, UniformLocation(uniform.UniformLocation), value); err != nil {
+			return err
+		}
+	}
+	tw.revert(ctx)
+
+	insert(at.Indices[0], out.cmds)
+	return nil
+}
+
+// setUniform writes the glUniform command setting the uniform of type ty at
+// loc of the bound program to value.
+func setUniform(ctx context.Context, tw *tweaker, out *recorder, ty GLenum,
+	format api.UniformFormat, loc UniformLocation, value interface{}) error {
+
+	var data interface{}
+	var count int
+	switch v := value.(type) {
+	case []float32:
+		d := make([]GLfloat, len(v))
+		for i, f := range v {
+			d[i] = GLfloat(f)
+		}
+		data, count = d, len(d)
+	case []int32:
+		d := make([]GLint, len(v))
+		for i, n := range v {
+			d[i] = GLint(n)
+		}
+		data, count = d, len(d)
+	case []uint32:
+		if format == api.UniformFormat_Sampler {
+			d := make([]GLint, len(v))
+			for i, n := range v {
+				d[i] = GLint(n)
+			}
+			data, count = d, len(d)
+		} else {
+			d := make([]GLuint, len(v))
+			for i, n := range v {
+				d[i] = GLuint(n)
+			}
+			data, count = d, len(d)
+		}
+	case []bool:
+		d := make([]GLint, len(v))
+		for i, b := range v {
+			if b {
+				d[i] = 1
+			}
+		}
+		data, count = d, len(d)
+	default:
+		return fmt.Errorf("Cannot set uniform at location %v to %T", loc, value)
+	}
+
+	columns, rows := uniformDimensions(format)
+	n := GLsizei(count / int(columns*rows))
+	if n == 0 || count%int(columns*rows) != 0 {
+		return fmt.Errorf("Invalid number of values for uniform at location %v: %v", loc, count)
+	}
+
+	tmp := tw.s.AllocDataOrPanic(ctx, data)
+	defer tmp.Free()
+	cmd, err := uniformCmd(tw.cb, ty, loc, n, tmp.Ptr())
+	if err != nil {
+		return err
+	}
+	cmd.Extras().GetOrAppendObservations().AddRead(tmp.Data())
+	out.MutateAndWrite(ctx, tw.dID, cmd)
+	return nil
+}
+
+// uniformCmd returns the glUniform command setting n elements of the uniform
+// of type ty at loc from the values at v.
+func uniformCmd(cb CommandBuilder, ty GLenum, loc UniformLocation, n GLsizei, v memory.Pointer) (api.Cmd, error) {
+	switch ty {
+	case GLenum_GL_FLOAT:
+		return cb.GlUniform1fv(loc, n, v), nil
+	case GLenum_GL_FLOAT_VEC2:
+		return cb.GlUniform2fv(loc, n, v), nil
+	case GLenum_GL_FLOAT_VEC3:
+		return cb.GlUniform3fv(loc, n, v), nil
+	case GLenum_GL_FLOAT_VEC4:
+		return cb.GlUniform4fv(loc, n, v), nil
+	case GLenum_GL_INT, GLenum_GL_BOOL:
+		return cb.GlUniform1iv(loc, n, v), nil
+	case GLenum_GL_INT_VEC2, GLenum_GL_BOOL_VEC2:
+		return cb.GlUniform2iv(loc, n, v), nil
+	case GLenum_GL_INT_VEC3, GLenum_GL_BOOL_VEC3:
+		return cb.GlUniform3iv(loc, n, v), nil
+	case GLenum_GL_INT_VEC4, GLenum_GL_BOOL_VEC4:
+		return cb.GlUniform4iv(loc, n, v), nil
+	case GLenum_GL_UNSIGNED_INT:
+		return cb.GlUniform1uiv(loc, n, v), nil
+	case GLenum_GL_UNSIGNED_INT_VEC2:
+		return cb.GlUniform2uiv(loc, n, v), nil
+	case GLenum_GL_UNSIGNED_INT_VEC3:
+		return cb.GlUniform3uiv(loc, n, v), nil
+	case GLenum_GL_UNSIGNED_INT_VEC4:
+		return cb.GlUniform4uiv(loc, n, v), nil
+	case GLenum_GL_FLOAT_MAT2:
+		return cb.GlUniformMatrix2fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT3:
+		return cb.GlUniformMatrix3fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT4:
+		return cb.GlUniformMatrix4fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT2x3:
+		return cb.GlUniformMatrix2x3fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT2x4:
+		return cb.GlUniformMatrix2x4fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT3x2:
+		return cb.GlUniformMatrix3x2fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT3x4:
+		return cb.GlUniformMatrix3x4fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT4x2:
+		return cb.GlUniformMatrix4x2fv(loc, n, GLboolean_GL_FALSE, v), nil
+	case GLenum_GL_FLOAT_MAT4x3:
+		return cb.GlUniformMatrix4x3fv(loc, n, GLboolean_GL_FALSE, v), nil
+	}
+	if format, _ := uniformFormatAndType(ty); format == api.UniformFormat_Sampler {
+		return cb.GlUniform1iv(loc, n, v), nil
+	}
+	return nil, fmt.Errorf("Cannot set uniforms of type %v", ty)
+}
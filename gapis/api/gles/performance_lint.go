@@ -0,0 +1,244 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/messages"
+)
+
+// tinyDrawCallVertices is the number of vertices under which a draw call is
+// considered too small to be worth its overhead.
+const tinyDrawCallVertices = 16
+
+const (
+	issueReadbackInFrame       = "Readback in frame"
+	issueUploadBetweenDraws    = "Upload between draws"
+	issueFramebufferNotCleared = "Framebuffer not cleared"
+	issueProgramRelink         = "Program relink"
+	issueTinyDrawCall          = "Tiny draw call"
+	issueIncompleteMipmaps     = "Incomplete mipmaps"
+)
+
+// performanceLint is an api.PerformanceLint that detects common performance
+// anti-patterns of GLES applications.
+// Redundant state changes are found by the report with the
+// api.StateWriteInspector of the API.
+type performanceLint struct {
+	frames map[ContextID]*lintFrame
+}
+
+// lintFrame holds what happened in the current frame of a context.
+type lintFrame struct {
+	// draws is the number of draw calls issued in the frame.
+	draws int
+	// prepared holds the framebuffers cleared, invalidated or drawn to in the
+	// frame.
+	prepared map[FramebufferId]bool
+	// incomplete holds the textures already reported as incomplete in the
+	// frame.
+	incomplete map[*Texture]bool
+}
+
+// NewPerformanceLint implements the api.PerformanceLinter interface.
+func (API) NewPerformanceLint(ctx context.Context) api.PerformanceLint {
+	return &performanceLint{frames: map[ContextID]*lintFrame{}}
+}
+
+func (l *performanceLint) frame(c *Context) *lintFrame {
+	f, ok := l.frames[c.Identifier]
+	if !ok {
+		f = &lintFrame{
+			prepared:   map[FramebufferId]bool{},
+			incomplete: map[*Texture]bool{},
+		}
+		l.frames[c.Identifier] = f
+	}
+	return f
+}
+
+// Lint implements the api.PerformanceLint interface.
+func (l *performanceLint) Lint(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState, report api.PerformanceIssueReporter) {
+	c := GetContext(s, cmd.Thread())
+	if c == nil {
+		return
+	}
+	f := l.frame(c)
+	flags := cmd.CmdFlags(ctx, id, s)
+
+	switch cmd := cmd.(type) {
+	case *GlGetError, *GlFinish, *GlClientWaitSync:
+		if f.draws > 0 {
+			report(log.Warning, issueReadbackInFrame, messages.PerfReadbackInFrame(cmd.CmdName()))
+		}
+	case *GlReadPixels:
+		// Reads into a pixel pack buffer are asynchronous.
+		if f.draws > 0 && c.Bound.PixelPackBuffer == nil {
+			report(log.Warning, issueReadbackInFrame, messages.PerfReadbackInFrame(cmd.CmdName()))
+		}
+	case *GlMapBufferRange:
+		if f.draws > 0 && cmd.Access&GLbitfield_GL_MAP_READ_BIT != 0 {
+			report(log.Warning, issueReadbackInFrame, messages.PerfReadbackInFrame(cmd.CmdName()))
+		}
+
+	case *GlTexImage2D:
+		l.upload(f, cmd.Target, report)
+	case *GlTexSubImage2D:
+		l.upload(f, cmd.Target, report)
+	case *GlTexImage3D:
+		l.upload(f, cmd.Target, report)
+	case *GlTexSubImage3D:
+		l.upload(f, cmd.Target, report)
+	case *GlCompressedTexImage2D:
+		l.upload(f, cmd.Target, report)
+	case *GlCompressedTexSubImage2D:
+		l.upload(f, cmd.Target, report)
+	case *GlBufferData:
+		l.upload(f, cmd.Target, report)
+	case *GlBufferSubData:
+		l.upload(f, cmd.Target, report)
+
+	case *GlInvalidateFramebuffer:
+		f.prepared[boundFramebuffer(c, cmd.Target)] = true
+	case *GlDiscardFramebufferEXT:
+		f.prepared[boundFramebuffer(c, cmd.Target)] = true
+
+	case *GlLinkProgram:
+		if p := c.Objects.Programs.Get(cmd.Program); p != nil && p.LinkStatus == GLboolean_GL_TRUE {
+			report(log.Warning, issueProgramRelink, messages.PerfProgramRelink(uint32(cmd.Program)))
+		}
+
+	case *GlDrawArrays:
+		if cmd.IndicesCount < tinyDrawCallVertices {
+			report(log.Info, issueTinyDrawCall, messages.PerfTinyDrawCall(int64(cmd.IndicesCount)))
+		}
+	case drawElements:
+		if n := cmd.indicesCount(); n < tinyDrawCallVertices {
+			report(log.Info, issueTinyDrawCall, messages.PerfTinyDrawCall(int64(n)))
+		}
+	}
+
+	if flags.IsClear() {
+		// Partial clears are treated as full clears.
+		f.prepared[c.Bound.DrawFramebuffer.GetID()] = true
+	}
+
+	if flags.IsDrawCall() {
+		f.draws++
+		if fb := c.Bound.DrawFramebuffer.GetID(); !f.prepared[fb] {
+			report(log.Warning, issueFramebufferNotCleared, messages.PerfFramebufferNotCleared(uint32(fb)))
+			f.prepared[fb] = true
+		}
+		l.checkMipmaps(ctx, id, cmd, s, c, f, report)
+	}
+
+	// The swaps are flagged as starting the next frame, and end the frame.
+	if flags.IsStartOfFrame() || flags.IsEndOfFrame() {
+		delete(l.frames, c.Identifier)
+	}
+}
+
+// upload reports the update of the data of the object bound to target if a
+// draw call was already issued in the frame.
+func (l *performanceLint) upload(f *lintFrame, target GLenum, report api.PerformanceIssueReporter) {
+	if f.draws > 0 {
+		report(log.Warning, issueUploadBetweenDraws, messages.PerfUploadBetweenDraws(target.String()))
+	}
+}
+
+// checkMipmaps reports the textures sampled by the bound program with a
+// mipmap filter that do not have all their levels defined.
+func (l *performanceLint) checkMipmaps(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState, c *Context, f *lintFrame, report api.PerformanceIssueReporter) {
+	if c.Bound.Program == nil {
+		return
+	}
+	for _, uniform := range c.Bound.Program.UniformLocations.Range() {
+		if uniform.Type == GLenum_GL_FLOAT_VEC4 || uniform.Type == GLenum_GL_FLOAT_MAT4 {
+			continue // Optimization - skip the two most common types which we know are not samplers.
+		}
+		target, _ := subGetTextureTargetFromSamplerType(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, uniform.Type)
+		switch target {
+		case GLenum_GL_TEXTURE_2D, GLenum_GL_TEXTURE_2D_ARRAY, GLenum_GL_TEXTURE_3D, GLenum_GL_TEXTURE_CUBE_MAP:
+		default:
+			continue // Not a sampler type, or a target without mipmaps.
+		}
+		units := AsU32ˢ(uniform.Values, s.MemoryLayout).MustRead(ctx, cmd, s, nil)
+		for _, unit := range units {
+			tu := c.Objects.TextureUnits.Get(TextureUnitId(unit))
+			if tu == nil {
+				continue
+			}
+			tex, err := subGetBoundTextureForUnit(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, tu, target)
+			if tex == nil || err != nil || tex.EGLImage != nil || f.incomplete[tex] {
+				continue
+			}
+			filter := tex.MinFilter
+			if tu.SamplerBinding != nil {
+				filter = tu.SamplerBinding.MinFilter
+			}
+			if !isMipmapFilter(filter) {
+				continue
+			}
+			if levels, expected := tex.mipmapLevels(); levels < expected {
+				f.incomplete[tex] = true
+				report(log.Warning, issueIncompleteMipmaps, messages.PerfIncompleteMipmaps(
+					uint32(tex.ID), filter.String(), int64(levels), int64(expected)))
+			}
+		}
+	}
+}
+
+// mipmapLevels returns the number of consecutive levels defined from the base
+// level of the texture, and the number of levels of its complete mipmap chain.
+func (t *Texture) mipmapLevels() (levels, expected int) {
+	base := t.Levels.Get(t.BaseLevel).Layers.Get(0)
+	if base == nil {
+		return 0, 0
+	}
+	size := base.Width
+	if base.Height > size {
+		size = base.Height
+	}
+	for expected = 1; size > 1; expected++ {
+		size /= 2
+	}
+	if max := int(t.MaxLevel-t.BaseLevel) + 1; max < expected {
+		expected = max
+	}
+	for levels < expected && t.Levels.Contains(t.BaseLevel+GLint(levels)) {
+		levels++
+	}
+	return levels, expected
+}
+
+func isMipmapFilter(filter GLenum) bool {
+	switch filter {
+	case GLenum_GL_NEAREST_MIPMAP_NEAREST, GLenum_GL_NEAREST_MIPMAP_LINEAR,
+		GLenum_GL_LINEAR_MIPMAP_NEAREST, GLenum_GL_LINEAR_MIPMAP_LINEAR:
+		return true
+	}
+	return false
+}
+
+// boundFramebuffer returns the identifier of the framebuffer bound to target.
+func boundFramebuffer(c *Context, target GLenum) FramebufferId {
+	if target == GLenum_GL_READ_FRAMEBUFFER {
+		return c.Bound.ReadFramebuffer.GetID()
+	}
+	return c.Bound.DrawFramebuffer.GetID()
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/gles"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/stringtable"
+)
+
+func TestPerformanceLint(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = bind.PutRegistry(ctx, bind.NewRegistry())
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+
+	programInfoA := &gles.LinkProgramExtra{
+		LinkStatus: gles.GLboolean_GL_TRUE,
+		ActiveResources: &gles.ActiveProgramResources{
+			DefaultUniformBlock: gles.NewUniformIndexːProgramResourceʳᵐ().Add(0, &gles.ProgramResource{
+				Name:      "color",
+				Type:      gles.GLenum_GL_FLOAT_VEC4,
+				Locations: gles.NewU32ːGLintᵐ().Add(0, 0),
+				ArraySize: 1,
+			}),
+		},
+	}
+	programInfoB := &gles.LinkProgramExtra{
+		LinkStatus: gles.GLboolean_GL_TRUE,
+		ActiveResources: &gles.ActiveProgramResources{
+			DefaultUniformBlock: gles.NewUniformIndexːProgramResourceʳᵐ().Add(0, &gles.ProgramResource{
+				Name:      "sampler",
+				Type:      gles.GLenum_GL_SAMPLER_2D,
+				Locations: gles.NewU32ːGLintᵐ().Add(0, 0),
+				ArraySize: 1,
+			}),
+		},
+	}
+
+	ctxHandle := memory.BytePtr(1, memory.ApplicationPool)
+	displayHandle := memory.BytePtr(2, memory.ApplicationPool)
+	surfaceHandle := memory.BytePtr(3, memory.ApplicationPool)
+	cb := gles.CommandBuilder{Thread: 0}
+	prologue := []api.Cmd{
+		cb.EglCreateContext(displayHandle, surfaceHandle, surfaceHandle, memory.Nullptr, ctxHandle),
+		api.WithExtras(
+			cb.EglMakeCurrent(displayHandle, surfaceHandle, surfaceHandle, ctxHandle, 0),
+			gles.NewStaticContextStateForTest(), gles.NewDynamicContextStateForTest(64, 64, false)),
+		cb.GlCreateProgram(1),
+		cb.GlCreateProgram(2),
+		api.WithExtras(cb.GlLinkProgram(1), programInfoA),
+		api.WithExtras(cb.GlLinkProgram(2), programInfoB),
+		cb.GlUseProgram(1),
+	}
+	clear := cb.GlClear(gles.GLbitfield_GL_COLOR_BUFFER_BIT)
+	draw := cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 16)
+	swap := cb.EglSwapBuffers(displayHandle, surfaceHandle, gles.EGLBoolean(1))
+	texImage := func(level gles.GLint, size gles.GLsizei) api.Cmd {
+		return cb.GlTexImage2D(gles.GLenum_GL_TEXTURE_2D, level, gles.GLint(gles.GLenum_GL_RGB), size, size, 0,
+			gles.GLenum_GL_RGB, gles.GLenum_GL_UNSIGNED_SHORT_5_6_5, memory.Nullptr)
+	}
+
+	for _, test := range []struct {
+		name     string
+		cmds     []api.Cmd
+		expected []string
+	}{
+		{"No issue", []api.Cmd{clear, draw, draw, swap}, []string{}},
+		{"Readback in frame", []api.Cmd{
+			cb.GlGetError(0), clear, draw, cb.GlGetError(0), cb.GlFinish(), swap, cb.GlFinish(),
+		}, []string{"Readback in frame", "Readback in frame"}},
+		{"Upload between draws", []api.Cmd{
+			cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10), texImage(0, 64), clear, draw, texImage(0, 64), swap, texImage(0, 64),
+		}, []string{"Upload between draws"}},
+		{"Framebuffer not cleared", []api.Cmd{
+			draw, draw, swap, clear, draw, swap, draw,
+		}, []string{"Framebuffer not cleared", "Framebuffer not cleared"}},
+		{"Program relink", []api.Cmd{
+			cb.GlCreateProgram(3), api.WithExtras(cb.GlLinkProgram(3), programInfoA), api.WithExtras(cb.GlLinkProgram(1), programInfoA),
+		}, []string{"Program relink"}},
+		{"Tiny draw call", []api.Cmd{
+			clear, cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 3), draw,
+		}, []string{"Tiny draw call"}},
+		{"Incomplete mipmaps", []api.Cmd{
+			cb.GlUseProgram(2), cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10), texImage(0, 4), texImage(1, 2),
+			clear, draw, draw, swap, clear, draw,
+		}, []string{"Incomplete mipmaps", "Incomplete mipmaps"}},
+		{"Complete mipmaps", []api.Cmd{
+			cb.GlUseProgram(2), cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10), texImage(0, 4), texImage(1, 2), texImage(2, 1),
+			clear, draw,
+		}, []string{}},
+		{"No mipmap filter", []api.Cmd{
+			cb.GlUseProgram(2), cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10), texImage(0, 4),
+			cb.GlTexParameteri(gles.GLenum_GL_TEXTURE_2D, gles.GLenum_GL_TEXTURE_MIN_FILTER, gles.GLint(gles.GLenum_GL_LINEAR)),
+			clear, draw,
+		}, []string{}},
+	} {
+		cmds := append(append([]api.Cmd{}, prologue...), test.cmds...)
+		h := &capture.Header{Abi: device.WindowsX86_64}
+		capturePath, err := capture.New(ctx, test.name, h, cmds)
+		if !assert.For(ctx, "%v capture", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+		ctx := capture.Put(ctx, capturePath)
+		c, err := capture.Resolve(ctx)
+		if !assert.For(ctx, "%v resolve", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+
+		issues := []string{}
+		lint := gles.API{}.NewPerformanceLint(ctx)
+		s := c.NewState(ctx)
+		api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+			lint.Lint(ctx, id, cmd, s, func(severity log.Severity, issue string, msg *stringtable.Msg) {
+				issues = append(issues, issue)
+			})
+			cmd.Mutate(ctx, id, s, nil)
+			return nil
+		})
+		assert.For(ctx, "%v", test.name).ThatSlice(issues).Equals(test.expected)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/gapid/gapis/api"
+)
+
+// WrittenState implements the api.StateWriteInspector interface.
+//
+// Uniform commands are identified by name as there are too many variants to
+// list. glUniform* commands write the uniforms of the bound program, and as
+// the program is not known for glProgramUniform* commands, the uniforms of
+// all the programs of the context are returned for them.
+func (API) WrittenState(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) (interface{}, bool) {
+	c := GetContext(s, cmd.Thread())
+	if c == nil {
+		return nil, false
+	}
+
+	switch cmd := cmd.(type) {
+	case *GlEnable:
+		v, err := subGetCapability(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, cmd.Capability, 0)
+		return v, err == nil
+	case *GlDisable:
+		v, err := subGetCapability(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, cmd.Capability, 0)
+		return v, err == nil
+	case *GlUseProgram:
+		return c.Bound.Program.GetID(), true
+	case *GlActiveTexture:
+		if c.Bound.TextureUnit == nil {
+			return nil, true
+		}
+		return c.Bound.TextureUnit.ID, true
+	case *GlBindTexture:
+		tex, err := subGetBoundTextureForUnit(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, c.Bound.TextureUnit, cmd.Target)
+		return tex.GetID(), err == nil
+	case *GlBindBuffer:
+		buf, err := subGetBoundBuffer(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, cmd.Target)
+		return buf.GetID(), err == nil
+	case *GlBindFramebuffer:
+		return [2]FramebufferId{c.Bound.DrawFramebuffer.GetID(), c.Bound.ReadFramebuffer.GetID()}, true
+	case *GlBindVertexArray:
+		return c.Bound.VertexArray.GetID(), true
+	case *GlDepthFunc:
+		return c.Pixel.Depth.Func, true
+	case *GlDepthMask:
+		return c.Pixel.DepthWritemask, true
+	case *GlColorMask:
+		masks := []Mask{}
+		for _, i := range c.Pixel.ColorWritemask.Keys() {
+			masks = append(masks, c.Pixel.ColorWritemask.Get(i))
+		}
+		return masks, true
+	case *GlBlendFunc:
+		return blendStates(c), true
+	case *GlBlendFuncSeparate:
+		return blendStates(c), true
+	case *GlBlendEquation:
+		return blendStates(c), true
+	case *GlBlendEquationSeparate:
+		return blendStates(c), true
+	case *GlClearColor:
+		return c.Pixel.ColorClearValue, true
+	case *GlCullFace:
+		return c.Rasterization.CullFaceMode, true
+	case *GlFrontFace:
+		return c.Rasterization.FrontFace, true
+	case *GlViewport:
+		return c.Rasterization.Viewport, true
+	case *GlScissor:
+		return c.Pixel.Scissor.Box, true
+	case *GlBufferSubData:
+		buf, err := subGetBoundBuffer(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, cmd.Target)
+		if err != nil || buf == nil || cmd.Offset < 0 || cmd.Size < 0 || uint64(cmd.Offset)+uint64(cmd.Size) > buf.Data.Count() {
+			return nil, false
+		}
+		start, end := uint64(cmd.Offset), uint64(cmd.Offset)+uint64(cmd.Size)
+		return buf.Data.Slice(start, end, s.MemoryLayout).MustRead(ctx, cmd, s, nil), true
+	case *GlTexSubImage2D:
+		return textureImageData(ctx, id, cmd, s, c, cmd.Target, cmd.Level)
+	case *GlTexSubImage3D:
+		return textureImageData(ctx, id, cmd, s, c, cmd.Target, cmd.Level)
+	case *GlCompressedTexSubImage2D:
+		return textureImageData(ctx, id, cmd, s, c, cmd.Target, cmd.Level)
+	case *GlUniformBlockBinding:
+		return nil, false
+	}
+
+	switch name := cmd.CmdName(); {
+	case strings.HasPrefix(name, "glUniform"):
+		return programUniforms(ctx, cmd, s, c.Bound.Program), true
+	case strings.HasPrefix(name, "glProgramUniform"):
+		uniforms := [][][]byte{}
+		for _, p := range c.Objects.Programs.Keys() {
+			uniforms = append(uniforms, programUniforms(ctx, cmd, s, c.Objects.Programs.Get(p)))
+		}
+		return uniforms, true
+	}
+	return nil, false
+}
+
+// blendStates returns the blend states of all the draw buffers of c.
+func blendStates(c *Context) []BlendState {
+	out := []BlendState{}
+	for _, i := range c.Pixel.Blend.Keys() {
+		out = append(out, c.Pixel.Blend.Get(i))
+	}
+	return out
+}
+
+// programUniforms returns the values of all the uniforms of p, in location
+// order.
+func programUniforms(ctx context.Context, cmd api.Cmd, s *api.GlobalState, p *Program) [][]byte {
+	if p == nil {
+		return nil
+	}
+	out := [][]byte{}
+	for _, l := range p.UniformLocations.Keys() {
+		out = append(out, p.UniformLocations.Get(l).Values.MustRead(ctx, cmd, s, nil))
+	}
+	return out
+}
+
+// textureImageData returns the data of all the layers of the given level of
+// the texture bound to target. The texture data is not known for textures
+// backed by EGL images.
+func textureImageData(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState, c *Context, target GLenum, level GLint) (interface{}, bool) {
+	tex, err := subGetBoundTextureForUnit(ctx, cmd, id, nil, s, GetState(s), cmd.Thread(), nil, c.Bound.TextureUnit, target)
+	if err != nil || tex == nil || tex.EGLImage != nil {
+		return nil, false
+	}
+	l, ok := tex.Levels.Lookup(level)
+	if !ok {
+		return nil, true
+	}
+	out := [][]byte{}
+	for _, i := range l.Layers.Keys() {
+		out = append(out, l.Layers.Get(i).Data.MustRead(ctx, cmd, s, nil))
+	}
+	return out, true
+}
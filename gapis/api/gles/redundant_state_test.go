@@ -0,0 +1,134 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/gles"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+)
+
+func TestRedundantStateWrites(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = bind.PutRegistry(ctx, bind.NewRegistry())
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+
+	programInfo := &gles.LinkProgramExtra{
+		LinkStatus: gles.GLboolean_GL_TRUE,
+		ActiveResources: &gles.ActiveProgramResources{
+			DefaultUniformBlock: gles.NewUniformIndexːProgramResourceʳᵐ().Add(0, &gles.ProgramResource{
+				Name:      "color",
+				Type:      gles.GLenum_GL_FLOAT_VEC4,
+				Locations: gles.NewU32ːGLintᵐ().Add(0, 0),
+				ArraySize: 1,
+			}),
+		},
+	}
+
+	ctxHandle := memory.BytePtr(1, memory.ApplicationPool)
+	displayHandle := memory.BytePtr(2, memory.ApplicationPool)
+	surfaceHandle := memory.BytePtr(3, memory.ApplicationPool)
+	cb := gles.CommandBuilder{Thread: 0}
+	prologue := []api.Cmd{
+		cb.EglCreateContext(displayHandle, surfaceHandle, surfaceHandle, memory.Nullptr, ctxHandle),
+		api.WithExtras(
+			cb.EglMakeCurrent(displayHandle, surfaceHandle, surfaceHandle, ctxHandle, 0),
+			gles.NewStaticContextStateForTest(), gles.NewDynamicContextStateForTest(64, 64, false)),
+		cb.GlCreateProgram(1),
+		cb.GlCreateProgram(2),
+		api.WithExtras(cb.GlLinkProgram(1), programInfo),
+		api.WithExtras(cb.GlLinkProgram(2), programInfo),
+		cb.GlUseProgram(1),
+	}
+
+	const (
+		changed   = "changed"
+		redundant = "redundant"
+		unknown   = "unknown"
+	)
+	for _, test := range []struct {
+		name     string
+		cmds     []api.Cmd
+		expected []string
+	}{
+		{"Capabilities", []api.Cmd{
+			cb.GlEnable(gles.GLenum_GL_DEPTH_TEST),
+			cb.GlEnable(gles.GLenum_GL_DEPTH_TEST),
+			cb.GlDisable(gles.GLenum_GL_DEPTH_TEST),
+			cb.GlDisable(gles.GLenum_GL_BLEND),
+		}, []string{changed, redundant, changed, redundant}},
+		{"Bindings", []api.Cmd{
+			cb.GlUseProgram(1),
+			cb.GlUseProgram(2),
+			cb.GlActiveTexture(gles.GLenum_GL_TEXTURE0),
+			cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10),
+			cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 10),
+		}, []string{redundant, changed, redundant, changed, redundant}},
+		{"Fixed function state", []api.Cmd{
+			cb.GlViewport(0, 0, 32, 32),
+			cb.GlViewport(0, 0, 32, 32),
+			cb.GlDepthFunc(gles.GLenum_GL_LESS),
+			cb.GlDepthFunc(gles.GLenum_GL_ALWAYS),
+		}, []string{changed, redundant, redundant, changed}},
+		{"Uniforms", []api.Cmd{
+			cb.GlUniform4f(0, 1, 2, 3, 4),
+			cb.GlUniform4f(0, 1, 2, 3, 4),
+			cb.GlUniform4f(0, 1, 2, 3, 5),
+		}, []string{changed, redundant, changed}},
+		{"Not state-setting commands", []api.Cmd{
+			cb.GlClear(gles.GLbitfield_GL_COLOR_BUFFER_BIT),
+			cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 3),
+		}, []string{unknown, unknown}},
+	} {
+		cmds := append(append([]api.Cmd{}, prologue...), test.cmds...)
+		h := &capture.Header{Abi: device.WindowsX86_64}
+		capturePath, err := capture.New(ctx, test.name, h, cmds)
+		if !assert.For(ctx, "%v capture", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+		ctx := capture.Put(ctx, capturePath)
+		c, err := capture.Resolve(ctx)
+		if !assert.For(ctx, "%v resolve", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+
+		got := []string{}
+		s := c.NewState(ctx)
+		api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+			if int(id) < len(prologue) {
+				return cmd.Mutate(ctx, id, s, nil)
+			}
+			switch r, known, _ := api.MutateAndCompareState(ctx, id, cmd, s); {
+			case !known:
+				got = append(got, unknown)
+			case r:
+				got = append(got, redundant)
+			default:
+				got = append(got, changed)
+			}
+			return nil
+		})
+		assert.For(ctx, "%v", test.name).ThatSlice(got).Equals(test.expected)
+	}
+}
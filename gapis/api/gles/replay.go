@@ -110,7 +110,7 @@ func (a API) Replay(
 
 	// Skip unnecessary commands.
 	deadCodeElimination := transform.NewDeadCodeElimination(ctx, dependencyGraph)
-	deadCodeElimination.KeepAllAlive = config.DisableDeadCodeElimination
+	deadCodeElimination.KeepAllAlive = config.Get(ctx).DisableDeadCodeElimination
 
 	var rf *readFramebuffer // Transform for all framebuffer reads.
 	var rt *readTexture     // Transform for all texture reads.
@@ -204,14 +204,14 @@ func (a API) Replay(
 	// Cleanup
 	transforms.Add(&destroyResourcesAtEOS{})
 
-	if config.DebugReplay {
+	if config.Get(ctx).DebugReplay {
 		log.I(ctx, "Replaying %d commands using transform chain:", len(cmds))
 		for i, t := range transforms {
 			log.I(ctx, "(%d) %#v", i, t)
 		}
 	}
 
-	if config.LogTransformsToFile {
+	if config.Get(ctx).LogTransformsToFile {
 		newTransforms := transform.Transforms{}
 		for i, t := range transforms {
 			name := fmt.Sprintf("%T", t)
@@ -59,6 +59,16 @@ func (t *Texture) ResourceType(ctx context.Context) api.ResourceType {
 // ResourceData returns the resource data given the current state.
 func (t *Texture) ResourceData(ctx context.Context, s *api.GlobalState) (*api.ResourceData, error) {
 	ctx = log.Enter(ctx, "Texture.ResourceData()")
+	data, err := t.textureData(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	data.GetTexture().Sampling = t.samplingParameters()
+	return data, nil
+}
+
+// textureData returns the texture levels given the current state.
+func (t *Texture) textureData(ctx context.Context, s *api.GlobalState) (*api.ResourceData, error) {
 	switch t.Kind {
 	case GLenum_GL_TEXTURE_1D, GLenum_GL_TEXTURE_2D, GLenum_GL_TEXTURE_2D_MULTISAMPLE:
 		count := GLint(0)
@@ -294,7 +304,13 @@ func (s *Shader) ResourceData(ctx context.Context, t *api.GlobalState) (*api.Res
 		ty = api.ShaderType_Compute
 	}
 
-	return api.NewResourceData(&api.Shader{Type: ty, Source: s.Source}), nil
+	shader := &api.Shader{Type: ty, Source: s.Source}
+	if s.Source != "" {
+		if c, err := s.complexity(ctx); err == nil {
+			shader.EntryPoints = []*api.ShaderEntryPoint{{Name: "main", Type: ty, Complexity: c}}
+		}
+	}
+	return api.NewResourceData(shader), nil
 }
 
 func (shader *Shader) SetResourceData(
@@ -385,24 +401,9 @@ func (p *Program) ResourceData(ctx context.Context, s *api.GlobalState) (*api.Re
 	ctx = log.Enter(ctx, "Program.ResourceData()")
 
 	shaders := make([]*api.Shader, 0, p.Shaders.Len())
-	for shaderType, shader := range p.Shaders.Range() {
-		var ty api.ShaderType
-		switch shaderType {
-		case GLenum_GL_VERTEX_SHADER:
-			ty = api.ShaderType_Vertex
-		case GLenum_GL_GEOMETRY_SHADER:
-			ty = api.ShaderType_Geometry
-		case GLenum_GL_TESS_CONTROL_SHADER:
-			ty = api.ShaderType_TessControl
-		case GLenum_GL_TESS_EVALUATION_SHADER:
-			ty = api.ShaderType_TessEvaluation
-		case GLenum_GL_FRAGMENT_SHADER:
-			ty = api.ShaderType_Fragment
-		case GLenum_GL_COMPUTE_SHADER:
-			ty = api.ShaderType_Compute
-		}
+	for ty, shader := range p.Shaders.Range() {
 		shaders = append(shaders, &api.Shader{
-			Type:   ty,
+			Type:   shaderType(ty),
 			Source: shader.Source,
 		})
 	}
@@ -410,134 +411,7 @@ func (p *Program) ResourceData(ctx context.Context, s *api.GlobalState) (*api.Re
 	uniforms := []*api.Uniform{}
 	if res := p.ActiveResources; res != nil {
 		for _, activeUniform := range res.DefaultUniformBlock.Range() {
-			var uniformFormat api.UniformFormat
-			var uniformType api.UniformType
-
-			switch activeUniform.Type {
-			case GLenum_GL_FLOAT:
-				uniformFormat = api.UniformFormat_Scalar
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_VEC2:
-				uniformFormat = api.UniformFormat_Vec2
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_VEC3:
-				uniformFormat = api.UniformFormat_Vec3
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_VEC4:
-				uniformFormat = api.UniformFormat_Vec4
-				uniformType = api.UniformType_Float
-			case GLenum_GL_INT:
-				uniformFormat = api.UniformFormat_Scalar
-				uniformType = api.UniformType_Int32
-			case GLenum_GL_INT_VEC2:
-				uniformFormat = api.UniformFormat_Vec2
-				uniformType = api.UniformType_Int32
-			case GLenum_GL_INT_VEC3:
-				uniformFormat = api.UniformFormat_Vec3
-				uniformType = api.UniformType_Int32
-			case GLenum_GL_INT_VEC4:
-				uniformFormat = api.UniformFormat_Vec4
-				uniformType = api.UniformType_Int32
-			case GLenum_GL_UNSIGNED_INT:
-				uniformFormat = api.UniformFormat_Scalar
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_VEC2:
-				uniformFormat = api.UniformFormat_Vec2
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_VEC3:
-				uniformFormat = api.UniformFormat_Vec3
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_VEC4:
-				uniformFormat = api.UniformFormat_Vec4
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_BOOL:
-				uniformFormat = api.UniformFormat_Scalar
-				uniformType = api.UniformType_Bool
-			case GLenum_GL_BOOL_VEC2:
-				uniformFormat = api.UniformFormat_Vec2
-				uniformType = api.UniformType_Bool
-			case GLenum_GL_BOOL_VEC3:
-				uniformFormat = api.UniformFormat_Vec3
-				uniformType = api.UniformType_Bool
-			case GLenum_GL_BOOL_VEC4:
-				uniformFormat = api.UniformFormat_Vec4
-				uniformType = api.UniformType_Bool
-			case GLenum_GL_FLOAT_MAT2:
-				uniformFormat = api.UniformFormat_Mat2
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT3:
-				uniformFormat = api.UniformFormat_Mat3
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT4:
-				uniformFormat = api.UniformFormat_Mat4
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT2x3:
-				uniformFormat = api.UniformFormat_Mat2x3
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT2x4:
-				uniformFormat = api.UniformFormat_Mat2x4
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT3x2:
-				uniformFormat = api.UniformFormat_Mat3x2
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT3x4:
-				uniformFormat = api.UniformFormat_Mat3x4
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT4x2:
-				uniformFormat = api.UniformFormat_Mat4x2
-				uniformType = api.UniformType_Float
-			case GLenum_GL_FLOAT_MAT4x3:
-				uniformFormat = api.UniformFormat_Mat4x3
-				uniformType = api.UniformType_Float
-			case GLenum_GL_SAMPLER_2D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_3D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_CUBE:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_2D_SHADOW:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_2D_ARRAY:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_2D_ARRAY_SHADOW:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_SAMPLER_CUBE_SHADOW:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_INT_SAMPLER_2D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_INT_SAMPLER_3D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_INT_SAMPLER_CUBE:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_INT_SAMPLER_2D_ARRAY:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_SAMPLER_2D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_SAMPLER_3D:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_SAMPLER_CUBE:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			case GLenum_GL_UNSIGNED_INT_SAMPLER_2D_ARRAY:
-				uniformFormat = api.UniformFormat_Sampler
-				uniformType = api.UniformType_Uint32
-			default:
-				uniformFormat = api.UniformFormat_Scalar
-				uniformType = api.UniformType_Float
-			}
+			uniformFormat, uniformType := uniformFormatAndType(activeUniform.Type)
 
 			uniforms = append(uniforms, &api.Uniform{
 				UniformLocation: uint32(activeUniform.Locations.Get(0)),
@@ -551,7 +425,13 @@ func (p *Program) ResourceData(ctx context.Context, s *api.GlobalState) (*api.Re
 
 	sort.Slice(shaders, func(i, j int) bool { return shaders[i].Type < shaders[j].Type })
 	sort.Slice(uniforms, func(i, j int) bool { return uniforms[i].UniformLocation < uniforms[j].UniformLocation })
-	return api.NewResourceData(&api.Program{Shaders: shaders, Uniforms: uniforms}), nil
+	uniformBlocks, storageBlocks := p.uniformBlocks(ctx, s)
+	return api.NewResourceData(&api.Program{
+		Shaders:       shaders,
+		Uniforms:      uniforms,
+		UniformBlocks: uniformBlocks,
+		StorageBlocks: storageBlocks,
+	}), nil
 }
 
 func uniformValue(ctx context.Context, s *api.GlobalState, kind api.UniformType, data U8ˢ) interface{} {
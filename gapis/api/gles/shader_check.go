@@ -0,0 +1,151 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/shadertools"
+)
+
+// glslTarget is the GLSL version and flavour the shaders are converted to for
+// a replay device.
+type glslTarget struct {
+	version int
+	es      bool
+}
+
+func (t glslTarget) String() string {
+	if t.es {
+		return fmt.Sprintf("GLSL %d es", t.version)
+	}
+	return fmt.Sprintf("GLSL %d", t.version)
+}
+
+// replayGLSLTarget is the GLSL target of the shaders converted by the
+// compatibility transform.
+var replayGLSLTarget = glslTarget{version: 330}
+
+// deviceGLSLTarget returns the highest GLSL target supported by the OpenGL
+// driver of the replay device d, or replayGLSLTarget if d is nil.
+func deviceGLSLTarget(d *device.Instance) (glslTarget, error) {
+	if d == nil {
+		return replayGLSLTarget, nil
+	}
+	glDev := d.GetConfiguration().GetDrivers().GetOpenGL()
+	if glDev == nil {
+		return glslTarget{}, fmt.Errorf("The replay device has no OpenGL driver")
+	}
+	v, err := ParseVersion(glDev.Version)
+	if err != nil {
+		return glslTarget{}, err
+	}
+	glsl, err := GLSLVersion(glDev.Version)
+	if err != nil {
+		return glslTarget{}, err
+	}
+	return glslTarget{version: glsl.Major*100 + glsl.Minor*10, es: v.IsES}, nil
+}
+
+// CheckShader implements the api.ShaderChecker interface.
+//
+// The source of the shader compiled by glCompileShader is checked with
+// checkShaderSource against the GLSL target of the replay device.
+func (API) CheckShader(ctx context.Context, cmd api.Cmd, s *api.GlobalState, d *device.Instance) *api.ShaderCheckResult {
+	compile, ok := cmd.(*GlCompileShader)
+	if !ok {
+		return nil
+	}
+	c := GetContext(s, cmd.Thread())
+	if c == nil {
+		return nil
+	}
+	shader := c.Objects.Shaders.Get(compile.Shader)
+	if shader == nil {
+		return nil
+	}
+	out := &api.ShaderCheckResult{
+		Handle: shader.ResourceHandle(),
+		Type:   shaderType(shader.Type),
+	}
+	st, err := shader.Type.ShaderType()
+	if err != nil {
+		out.Errors = append(out.Errors, err.Error())
+		return out
+	}
+	target, err := deviceGLSLTarget(d)
+	if err != nil {
+		out.Errors = append(out.Errors, err.Error())
+		return out
+	}
+	errs, warnings := checkShaderSource(shader.Source, st, target)
+	for _, err := range errs {
+		out.Errors = append(out.Errors, compileMessage(err))
+	}
+	for _, err := range warnings {
+		out.Warnings = append(out.Warnings, compileMessage(err))
+	}
+	return out
+}
+
+// checkShaderSource compiles the source of a shader of type st as GLSL ES,
+// and converts it to the GLSL target as done by the compatibility transform.
+// A shader that only converts with the relaxed checks of the replay is
+// reported with a warning.
+func checkShaderSource(source string, st shadertools.ShaderType, target glslTarget) (errs, warnings []error) {
+	// The compatibility transform trims the whitespace before the #version.
+	src := strings.TrimLeft(source, "\n\r\t ")
+	if src != source {
+		warnings = append(warnings, fmt.Errorf("The source starts with whitespace before the #version directive."))
+	}
+
+	_, compileErr := shadertools.CompileGlsl(src, shadertools.CompileOptions{
+		ShaderType: st,
+		ClientType: shadertools.OpenGLES,
+	})
+	_, convertErr := shadertools.ConvertGlsl(src, &shadertools.ConvertOptions{
+		ShaderType:         st,
+		Relaxed:            true,
+		StripOptimizations: true,
+		CheckAfterChanges:  true,
+		TargetGLSLVersion:  target.version,
+		TargetES:           target.es,
+	})
+	switch {
+	case compileErr != nil && convertErr == nil:
+		warnings = append(warnings, compileErr)
+	case compileErr != nil:
+		errs = append(errs, compileErr)
+	case convertErr != nil:
+		errs = append(errs, fmt.Errorf("Failed to convert to %v: %v", target, convertErr))
+	}
+	return errs, warnings
+}
+
+// compileMessage returns the message of the shadertools error, without the
+// listings of the sources.
+func compileMessage(err error) string {
+	msg := err.Error()
+	for _, listing := range []string{"\nSource:\n", "\nTranslated source:\n", "\nOriginal source:\n"} {
+		if i := strings.Index(msg, listing); i >= 0 {
+			msg = msg[:i]
+		}
+	}
+	return msg
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/shadertools"
+)
+
+func glDevice(version string) *device.Instance {
+	return &device.Instance{
+		Configuration: &device.Configuration{
+			Drivers: &device.Drivers{
+				OpenGL: &device.OpenGLDriver{Version: version},
+			},
+		},
+	}
+}
+
+func TestDeviceGLSLTarget(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name     string
+		device   *device.Instance
+		expected glslTarget
+	}{
+		{"no device", nil, replayGLSLTarget},
+		{"OpenGL ES 2.0", glDevice("OpenGL ES 2.0 V@53.0"), glslTarget{version: 100, es: true}},
+		{"OpenGL ES 3.2", glDevice("OpenGL ES 3.2 V@145.0"), glslTarget{version: 300, es: true}},
+		{"OpenGL 3.2", glDevice("3.2.0 NVIDIA 384.111"), glslTarget{version: 150}},
+		{"OpenGL 4.5", glDevice("4.5.0 NVIDIA 384.111"), glslTarget{version: 450}},
+	} {
+		target, err := deviceGLSLTarget(test.device)
+		if assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded() {
+			assert.For(ctx, "%v", test.name).That(target).Equals(test.expected)
+		}
+	}
+
+	_, err := deviceGLSLTarget(&device.Instance{})
+	assert.For(ctx, "no OpenGL driver").ThatError(err).Failed()
+}
+
+func TestCheckShaderSource(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name     string
+		source   string
+		errors   int
+		warnings int
+	}{
+		{"valid", "#version 300 es\nvoid main() { gl_Position = vec4(0.0); }\n", 0, 0},
+		{"leading whitespace", "\n#version 300 es\nvoid main() { gl_Position = vec4(0.0); }\n", 0, 1},
+		{"syntax error", "#version 300 es\nvoid main() { gl_Position = vec4(0.0) }\n", 1, 0},
+	} {
+		errs, warnings := checkShaderSource(test.source, shadertools.TypeVertex, replayGLSLTarget)
+		assert.For(ctx, "%v errors", test.name).ThatSlice(errs).IsLength(test.errors)
+		assert.For(ctx, "%v warnings", test.name).ThatSlice(warnings).IsLength(test.warnings)
+	}
+}
+
+func TestCompileMessage(t *testing.T) {
+	ctx := log.Testing(t)
+	err := fmt.Errorf("Failed to compile Vertex shader.\nERROR: 0:2: '}' : syntax error\nSource:\n1: #version 300 es")
+	assert.For(ctx, "message").ThatString(compileMessage(err)).Equals(
+		"Failed to compile Vertex shader.\nERROR: 0:2: '}' : syntax error")
+}
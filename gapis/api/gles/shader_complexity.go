@@ -0,0 +1,157 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+	"github.com/google/gapid/gapis/shadertools"
+	"github.com/google/gapid/gapis/shadertools/spirv"
+)
+
+// ShaderComplexities implements the api.ShaderComplexityProvider interface.
+//
+// The source of the shaders of the initial state and compiled by
+// glCompileShader is compiled to SPIR-V. A draw call uses the shaders attached
+// to the bound program, as last compiled.
+func (API) ShaderComplexities(ctx context.Context, p *path.Capture) ([]*api.ShaderComplexityEntry, error) {
+	ctx = capture.Put(ctx, p)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []*api.ShaderComplexityEntry{}
+	entries := map[*Shader]*api.ShaderComplexityEntry{}
+	add := func(shader *Shader, built *path.Command) {
+		complexity, err := shader.complexity(ctx)
+		if err != nil {
+			log.W(ctx, "Couldn't measure the complexity of %v: %v", shader.ResourceHandle(), err)
+			delete(entries, shader)
+			return
+		}
+		e := &api.ShaderComplexityEntry{
+			Handle:     shader.ResourceHandle(),
+			Type:       shaderType(shader.Type),
+			EntryPoint: "main",
+			Built:      built,
+			Complexity: complexity,
+		}
+		entries[shader] = e
+		out = append(out, e)
+	}
+
+	s := c.NewState(ctx)
+	st := GetState(s)
+	for _, h := range st.EGLContexts.Keys() {
+		objects := st.EGLContexts.Get(h).Objects
+		for _, i := range objects.Shaders.Keys() {
+			if shader := objects.Shaders.Get(i); shader.Source != "" && entries[shader] == nil {
+				add(shader, nil)
+			}
+		}
+	}
+
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		if err := cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */); err != nil {
+			return nil
+		}
+		gc := GetContext(s, cmd.Thread())
+		if gc == nil {
+			return nil
+		}
+		switch cmd := cmd.(type) {
+		case *GlCompileShader:
+			if shader := gc.Objects.Shaders.Get(cmd.Shader); shader != nil {
+				add(shader, p.Command(uint64(id)))
+			}
+		case drawCall:
+			if prog := gc.Bound.Program; prog != nil {
+				for _, shader := range prog.Shaders.Range() {
+					if e, ok := entries[shader]; ok {
+						e.DrawCalls++
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// complexity returns the complexity of the shader, compiled to SPIR-V from
+// its source. The source is compiled once for each of the shader types.
+func (s *Shader) complexity(ctx context.Context) (*api.ShaderComplexity, error) {
+	obj, err := database.Build(ctx, &ShaderComplexityResolvable{
+		ShaderSource: s.Source,
+		ShaderType:   uint32(s.Type),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.ShaderComplexity), nil
+}
+
+// Resolve implements the database.Resolver interface.
+func (r *ShaderComplexityResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ty := GLenum(r.ShaderType)
+	st, err := ty.ShaderType()
+	if err != nil {
+		return nil, err
+	}
+	words, err := shadertools.CompileGlsl(strings.TrimLeft(r.ShaderSource, "\n\r\t "), shadertools.CompileOptions{
+		ShaderType: st,
+		ClientType: shadertools.OpenGLES,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m, err := spirv.Decode(words)
+	if err != nil {
+		return nil, err
+	}
+	c, err := m.Complexity("main", executionModel(ty))
+	if err != nil {
+		return nil, err
+	}
+	return api.NewShaderComplexity(c), nil
+}
+
+// executionModel returns the SPIR-V execution model of the shader type ty.
+func executionModel(ty GLenum) spirv.ExecutionModel {
+	switch ty {
+	case GLenum_GL_TESS_CONTROL_SHADER:
+		return spirv.ExecutionModelTessellationControl
+	case GLenum_GL_TESS_EVALUATION_SHADER:
+		return spirv.ExecutionModelTessellationEvaluation
+	case GLenum_GL_GEOMETRY_SHADER:
+		return spirv.ExecutionModelGeometry
+	case GLenum_GL_FRAGMENT_SHADER:
+		return spirv.ExecutionModelFragment
+	case GLenum_GL_COMPUTE_SHADER:
+		return spirv.ExecutionModelGLCompute
+	default:
+		return spirv.ExecutionModelVertex
+	}
+}
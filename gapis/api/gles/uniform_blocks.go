@@ -0,0 +1,339 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/google/gapid/core/data/endian"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/math/u64"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/service/box"
+)
+
+// blockMemberLayout is the layout of a member of a uniform or storage block,
+// relative to the start of the block.
+type blockMemberLayout struct {
+	offset       uint64
+	arrayStride  uint64
+	matrixStride uint64
+	rowMajor     bool
+}
+
+// uniformBlocks returns the uniform blocks and the storage blocks of the
+// program p, with the values of their members read from the buffers bound in
+// the context of the thread the resources are resolved for.
+func (p *Program) uniformBlocks(ctx context.Context, s *api.GlobalState) (uniform, storage []*api.UniformBlock) {
+	res := p.ActiveResources
+	if res == nil {
+		return nil, nil
+	}
+	c := p.bindingContext(ctx, s)
+	for _, i := range res.UniformBlocks.Keys() {
+		var binding BufferBinding
+		if c != nil {
+			binding = c.Bound.UniformBuffers.Get(GLuint(res.UniformBlocks.Get(i).Binding))
+		}
+		uniform = append(uniform, uniformBlock(ctx, s, res.UniformBlocks.Get(i), binding, false))
+	}
+	for _, i := range res.ShaderStorageBlocks.Keys() {
+		var binding BufferBinding
+		if c != nil {
+			binding = c.Bound.ShaderStorageBuffers.Get(GLuint(res.ShaderStorageBlocks.Get(i).Binding))
+		}
+		storage = append(storage, uniformBlock(ctx, s, res.ShaderStorageBlocks.Get(i), binding, true))
+	}
+	return uniform, storage
+}
+
+// bindingContext returns the context whose buffer bindings are used to read
+// the blocks of p: the context current on the thread the resources are
+// resolved for, if p is one of its programs.
+func (p *Program) bindingContext(ctx context.Context, s *api.GlobalState) *Context {
+	thread, ok := api.GetResourceThread(ctx)
+	if !ok {
+		return nil
+	}
+	if c := GetContext(s, thread); c != nil && c.Objects.Programs.Get(p.ID) == p {
+		return c
+	}
+	return nil
+}
+
+// uniformBlock returns the block b, with the values of its members read from
+// the buffer binding. The members without a known layout are laid out with
+// the std430 rules for storage blocks, and the std140 rules otherwise.
+func uniformBlock(ctx context.Context, s *api.GlobalState, b *ProgramResourceBlock, binding BufferBinding, std430 bool) *api.UniformBlock {
+	out := &api.UniformBlock{
+		Name:     b.Name,
+		Binding:  uint32(b.Binding),
+		DataSize: uint32(b.DataSize),
+	}
+
+	var data []byte
+	if buf := binding.Binding; buf != nil {
+		out.Buffer = uint32(buf.ID)
+		out.BufferOffset = uint64(binding.Start)
+		out.BufferSize = uint64(binding.Size)
+		start := u64.Min(uint64(binding.Start), buf.Data.count)
+		end := buf.Data.count
+		if binding.Size > 0 {
+			end = u64.Min(start+uint64(binding.Size), end)
+		}
+		var err error
+		if data, err = buf.Data.Slice(start, end, s.MemoryLayout).Read(ctx, nil, s, nil); err != nil {
+			log.W(ctx, "Couldn't read the buffer of block %v: %v", b.Name, err)
+			data = nil
+		}
+	}
+
+	next := uint64(0)
+	for _, k := range b.Resources.Keys() {
+		m := b.Resources.Get(k)
+		format, kind := uniformFormatAndType(m.Type)
+		arraySize := uint64(m.ArraySize)
+		if arraySize < 1 {
+			arraySize = 1
+		}
+
+		var layout blockMemberLayout
+		layout, next = memberLayout(next, m.Layout, format, arraySize, std430)
+
+		member := &api.UniformBlockMember{
+			Name:      m.Name,
+			Format:    format,
+			Type:      kind,
+			Offset:    uint32(layout.offset),
+			ArraySize: uint32(arraySize),
+		}
+		if data != nil {
+			member.Value = box.NewValue(blockMemberValue(s, data, layout, format, kind, arraySize))
+		}
+		out.Members = append(out.Members, member)
+	}
+	return out
+}
+
+// uniformTypes maps the GLSL types of uniforms and block members to their
+// format and component type. Other types are float scalars.
+var uniformTypes = map[GLenum]struct {
+	format api.UniformFormat
+	kind   api.UniformType
+}{
+	GLenum_GL_FLOAT:                         {api.UniformFormat_Scalar, api.UniformType_Float},
+	GLenum_GL_FLOAT_VEC2:                    {api.UniformFormat_Vec2, api.UniformType_Float},
+	GLenum_GL_FLOAT_VEC3:                    {api.UniformFormat_Vec3, api.UniformType_Float},
+	GLenum_GL_FLOAT_VEC4:                    {api.UniformFormat_Vec4, api.UniformType_Float},
+	GLenum_GL_INT:                           {api.UniformFormat_Scalar, api.UniformType_Int32},
+	GLenum_GL_INT_VEC2:                      {api.UniformFormat_Vec2, api.UniformType_Int32},
+	GLenum_GL_INT_VEC3:                      {api.UniformFormat_Vec3, api.UniformType_Int32},
+	GLenum_GL_INT_VEC4:                      {api.UniformFormat_Vec4, api.UniformType_Int32},
+	GLenum_GL_UNSIGNED_INT:                  {api.UniformFormat_Scalar, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_VEC2:             {api.UniformFormat_Vec2, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_VEC3:             {api.UniformFormat_Vec3, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_VEC4:             {api.UniformFormat_Vec4, api.UniformType_Uint32},
+	GLenum_GL_BOOL:                          {api.UniformFormat_Scalar, api.UniformType_Bool},
+	GLenum_GL_BOOL_VEC2:                     {api.UniformFormat_Vec2, api.UniformType_Bool},
+	GLenum_GL_BOOL_VEC3:                     {api.UniformFormat_Vec3, api.UniformType_Bool},
+	GLenum_GL_BOOL_VEC4:                     {api.UniformFormat_Vec4, api.UniformType_Bool},
+	GLenum_GL_FLOAT_MAT2:                    {api.UniformFormat_Mat2, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT3:                    {api.UniformFormat_Mat3, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT4:                    {api.UniformFormat_Mat4, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT2x3:                  {api.UniformFormat_Mat2x3, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT2x4:                  {api.UniformFormat_Mat2x4, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT3x2:                  {api.UniformFormat_Mat3x2, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT3x4:                  {api.UniformFormat_Mat3x4, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT4x2:                  {api.UniformFormat_Mat4x2, api.UniformType_Float},
+	GLenum_GL_FLOAT_MAT4x3:                  {api.UniformFormat_Mat4x3, api.UniformType_Float},
+	GLenum_GL_SAMPLER_2D:                    {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_3D:                    {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_CUBE:                  {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_2D_SHADOW:             {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_2D_ARRAY:              {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_2D_ARRAY_SHADOW:       {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_SAMPLER_CUBE_SHADOW:           {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_INT_SAMPLER_2D:                {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_INT_SAMPLER_3D:                {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_INT_SAMPLER_CUBE:              {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_INT_SAMPLER_2D_ARRAY:          {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_SAMPLER_2D:       {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_SAMPLER_3D:       {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_SAMPLER_CUBE:     {api.UniformFormat_Sampler, api.UniformType_Uint32},
+	GLenum_GL_UNSIGNED_INT_SAMPLER_2D_ARRAY: {api.UniformFormat_Sampler, api.UniformType_Uint32},
+}
+
+// uniformFormatAndType returns the format and the component type of the GLSL
+// type ty.
+func uniformFormatAndType(ty GLenum) (api.UniformFormat, api.UniformType) {
+	if t, ok := uniformTypes[ty]; ok {
+		return t.format, t.kind
+	}
+	return api.UniformFormat_Scalar, api.UniformType_Float
+}
+
+// uniformDimensions returns the number of columns and rows of the format.
+// Scalars and vectors have a single column.
+func uniformDimensions(format api.UniformFormat) (columns, rows uint64) {
+	switch format {
+	case api.UniformFormat_Vec2:
+		return 1, 2
+	case api.UniformFormat_Vec3:
+		return 1, 3
+	case api.UniformFormat_Vec4:
+		return 1, 4
+	case api.UniformFormat_Mat2:
+		return 2, 2
+	case api.UniformFormat_Mat3:
+		return 3, 3
+	case api.UniformFormat_Mat4:
+		return 4, 4
+	case api.UniformFormat_Mat2x3:
+		return 2, 3
+	case api.UniformFormat_Mat2x4:
+		return 2, 4
+	case api.UniformFormat_Mat3x2:
+		return 3, 2
+	case api.UniformFormat_Mat3x4:
+		return 3, 4
+	case api.UniformFormat_Mat4x2:
+		return 4, 2
+	case api.UniformFormat_Mat4x3:
+		return 4, 3
+	default:
+		return 1, 1
+	}
+}
+
+// stdLayout returns the std140 or std430 layout of a column-major member
+// following a member ending at offset, and the offset of the end of the
+// member.
+func stdLayout(offset uint64, format api.UniformFormat, arraySize uint64, std430 bool) (blockMemberLayout, uint64) {
+	columns, rows := uniformDimensions(format)
+	align := uint64(4)
+	switch rows {
+	case 2:
+		align = 8
+	case 3, 4:
+		align = 16
+	}
+	if !std430 && (columns > 1 || arraySize > 1) {
+		// std140 rounds the alignment of matrix columns and of array elements
+		// up to the alignment of a vec4.
+		align = 16
+	}
+
+	layout := blockMemberLayout{}
+	size := rows * 4
+	if columns > 1 {
+		layout.matrixStride = align
+		size = columns * align
+	}
+	if arraySize > 1 {
+		layout.arrayStride = (size + align - 1) / align * align
+		size = layout.arrayStride * arraySize
+	}
+	layout.offset = (offset + align - 1) / align * align
+	return layout, layout.offset + size
+}
+
+// memberLayout returns the layout of a member following a member ending at
+// offset, and the offset of the end of the member. The explicit layout l is
+// used if it has an offset, and the std140 or std430 rules otherwise.
+func memberLayout(offset uint64, l *ProgramResourceLayout, format api.UniformFormat, arraySize uint64, std430 bool) (blockMemberLayout, uint64) {
+	if l == nil || l.Offset < 0 {
+		return stdLayout(offset, format, arraySize, std430)
+	}
+	layout := blockMemberLayout{
+		offset:   uint64(l.Offset),
+		rowMajor: l.IsRowMajor,
+	}
+	if l.ArrayStride > 0 {
+		layout.arrayStride = uint64(l.ArrayStride)
+	}
+	if l.MatrixStride > 0 {
+		layout.matrixStride = uint64(l.MatrixStride)
+	}
+
+	// The vectors of a matrix are its columns, or its rows if it is row-major.
+	vectors, components := uniformDimensions(format)
+	if layout.rowMajor {
+		vectors, components = components, vectors
+	}
+	size := (vectors-1)*layout.matrixStride + components*4
+	size += (arraySize - 1) * layout.arrayStride
+	return layout, layout.offset + size
+}
+
+// blockMemberValue returns the values of the member with the given layout
+// read from the block data, in column-major order. The components outside of
+// data are zero.
+func blockMemberValue(s *api.GlobalState, data []byte, layout blockMemberLayout,
+	format api.UniformFormat, kind api.UniformType, arraySize uint64) interface{} {
+
+	columns, rows := uniformDimensions(format)
+	offsets := make([]uint64, 0, arraySize*columns*rows)
+	for a := uint64(0); a < arraySize; a++ {
+		base := layout.offset + a*layout.arrayStride
+		for c := uint64(0); c < columns; c++ {
+			for r := uint64(0); r < rows; r++ {
+				switch {
+				case columns == 1:
+					offsets = append(offsets, base+r*4)
+				case layout.rowMajor:
+					offsets = append(offsets, base+r*layout.matrixStride+c*4)
+				default:
+					offsets = append(offsets, base+c*layout.matrixStride+r*4)
+				}
+			}
+		}
+	}
+
+	component := func(i int) uint32 {
+		if offsets[i]+4 > uint64(len(data)) {
+			return 0
+		}
+		r := endian.Reader(bytes.NewReader(data[offsets[i]:offsets[i]+4]), s.MemoryLayout.GetEndian())
+		return r.Uint32()
+	}
+	switch kind {
+	case api.UniformType_Int32:
+		a := make([]int32, len(offsets))
+		for i := range a {
+			a[i] = int32(component(i))
+		}
+		return a
+	case api.UniformType_Bool:
+		a := make([]bool, len(offsets))
+		for i := range a {
+			a[i] = component(i) != 0
+		}
+		return a
+	case api.UniformType_Float:
+		a := make([]float32, len(offsets))
+		for i := range a {
+			a[i] = math.Float32frombits(component(i))
+		}
+		return a
+	default:
+		a := make([]uint32, len(offsets))
+		for i := range a {
+			a[i] = component(i)
+		}
+		return a
+	}
+}
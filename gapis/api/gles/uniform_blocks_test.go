@@ -0,0 +1,135 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gles
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/api"
+)
+
+func TestStdLayout(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name      string
+		offset    uint64
+		format    api.UniformFormat
+		arraySize uint64
+		std430    bool
+		layout    blockMemberLayout
+		end       uint64
+	}{
+		{"std140 float", 0, api.UniformFormat_Scalar, 1, false, blockMemberLayout{offset: 0}, 4},
+		{"std140 vec2", 4, api.UniformFormat_Vec2, 1, false, blockMemberLayout{offset: 8}, 16},
+		{"std140 vec3", 4, api.UniformFormat_Vec3, 1, false, blockMemberLayout{offset: 16}, 28},
+		{"std140 float after vec3", 28, api.UniformFormat_Scalar, 1, false, blockMemberLayout{offset: 28}, 32},
+		{"std140 float[3]", 4, api.UniformFormat_Scalar, 3, false, blockMemberLayout{offset: 16, arrayStride: 16}, 64},
+		{"std140 vec2[2]", 0, api.UniformFormat_Vec2, 2, false, blockMemberLayout{offset: 0, arrayStride: 16}, 32},
+		{"std140 mat2", 4, api.UniformFormat_Mat2, 1, false, blockMemberLayout{offset: 16, matrixStride: 16}, 48},
+		{"std140 mat3", 4, api.UniformFormat_Mat3, 1, false, blockMemberLayout{offset: 16, matrixStride: 16}, 64},
+		{"std140 mat3x2", 0, api.UniformFormat_Mat3x2, 1, false, blockMemberLayout{offset: 0, matrixStride: 16}, 48},
+		{"std430 float[3]", 4, api.UniformFormat_Scalar, 3, true, blockMemberLayout{offset: 4, arrayStride: 4}, 16},
+		{"std430 vec2[2]", 4, api.UniformFormat_Vec2, 2, true, blockMemberLayout{offset: 8, arrayStride: 8}, 24},
+		{"std430 vec3[2]", 0, api.UniformFormat_Vec3, 2, true, blockMemberLayout{offset: 0, arrayStride: 16}, 32},
+		{"std430 mat2", 4, api.UniformFormat_Mat2, 1, true, blockMemberLayout{offset: 8, matrixStride: 8}, 24},
+		{"std430 mat3", 4, api.UniformFormat_Mat3, 1, true, blockMemberLayout{offset: 16, matrixStride: 16}, 64},
+		{"std430 mat2x3[2]", 0, api.UniformFormat_Mat2x3, 2, true, blockMemberLayout{offset: 0, arrayStride: 32, matrixStride: 16}, 64},
+	} {
+		layout, end := stdLayout(test.offset, test.format, test.arraySize, test.std430)
+		assert.For(ctx, "%v layout", test.name).That(layout).Equals(test.layout)
+		assert.For(ctx, "%v end", test.name).That(end).Equals(test.end)
+	}
+}
+
+func TestMemberLayout(t *testing.T) {
+	ctx := log.Testing(t)
+	explicit := func(offset, arrayStride, matrixStride GLint, rowMajor bool) *ProgramResourceLayout {
+		return &ProgramResourceLayout{Offset: offset, ArrayStride: arrayStride, MatrixStride: matrixStride, IsRowMajor: rowMajor}
+	}
+	// The members are laid out in order, each following the end of the
+	// previous one.
+	next := uint64(0)
+	for _, test := range []struct {
+		name      string
+		explicit  *ProgramResourceLayout
+		format    api.UniformFormat
+		arraySize uint64
+		layout    blockMemberLayout
+		end       uint64
+	}{
+		{"implicit vec3", nil, api.UniformFormat_Vec3, 1, blockMemberLayout{offset: 0}, 12},
+		{"explicit float", explicit(32, -1, -1, false), api.UniformFormat_Scalar, 1, blockMemberLayout{offset: 32}, 36},
+		{"implicit vec2", nil, api.UniformFormat_Vec2, 1, blockMemberLayout{offset: 40}, 48},
+		{"explicit row-major mat2", explicit(64, -1, 16, true), api.UniformFormat_Mat2, 1,
+			blockMemberLayout{offset: 64, matrixStride: 16, rowMajor: true}, 88},
+		{"implicit float", nil, api.UniformFormat_Scalar, 1, blockMemberLayout{offset: 88}, 92},
+		{"explicit float[2]", explicit(96, 16, -1, false), api.UniformFormat_Scalar, 2,
+			blockMemberLayout{offset: 96, arrayStride: 16}, 116},
+		{"unknown offset vec4", explicit(-1, -1, -1, false), api.UniformFormat_Vec4, 1, blockMemberLayout{offset: 128}, 144},
+		{"explicit mat3x2", explicit(144, -1, 16, false), api.UniformFormat_Mat3x2, 1,
+			blockMemberLayout{offset: 144, matrixStride: 16}, 184},
+	} {
+		layout, end := memberLayout(next, test.explicit, test.format, test.arraySize, false)
+		assert.For(ctx, "%v layout", test.name).That(layout).Equals(test.layout)
+		assert.For(ctx, "%v end", test.name).That(end).Equals(test.end)
+		next = end
+	}
+}
+
+// words returns the little-endian encoding of the given words.
+func words(w ...uint32) []byte {
+	out := make([]byte, len(w)*4)
+	for i, v := range w {
+		binary.LittleEndian.PutUint32(out[i*4:], v)
+	}
+	return out
+}
+
+func TestBlockMemberValue(t *testing.T) {
+	ctx := log.Testing(t)
+	s := api.NewStateWithEmptyAllocator(device.Little32)
+	one, two, three, four := math.Float32bits(1), math.Float32bits(2), math.Float32bits(3), math.Float32bits(4)
+	minusOne := int32(-1)
+	for _, test := range []struct {
+		name      string
+		data      []byte
+		layout    blockMemberLayout
+		format    api.UniformFormat
+		kind      api.UniformType
+		arraySize uint64
+		value     interface{}
+	}{
+		{"vec2", words(0, one, two), blockMemberLayout{offset: 4},
+			api.UniformFormat_Vec2, api.UniformType_Float, 1, []float32{1, 2}},
+		{"column-major mat2", words(one, two, 0, 0, three, four), blockMemberLayout{matrixStride: 16},
+			api.UniformFormat_Mat2, api.UniformType_Float, 1, []float32{1, 2, 3, 4}},
+		{"row-major mat2", words(one, three, two, four), blockMemberLayout{matrixStride: 8, rowMajor: true},
+			api.UniformFormat_Mat2, api.UniformType_Float, 1, []float32{1, 2, 3, 4}},
+		{"int[2]", words(uint32(minusOne), 0, 0, 0, 5), blockMemberLayout{arrayStride: 16},
+			api.UniformFormat_Scalar, api.UniformType_Int32, 2, []int32{-1, 5}},
+		{"bool[2]", words(0, 1), blockMemberLayout{arrayStride: 4},
+			api.UniformFormat_Scalar, api.UniformType_Bool, 2, []bool{false, true}},
+		{"outside of data", words(0, 0, 7), blockMemberLayout{offset: 8},
+			api.UniformFormat_Vec4, api.UniformType_Uint32, 1, []uint32{7, 0, 0, 0}},
+	} {
+		value := blockMemberValue(s, test.data, test.layout, test.format, test.kind, test.arraySize)
+		assert.For(ctx, "%v", test.name).That(value).DeepEquals(test.value)
+	}
+}
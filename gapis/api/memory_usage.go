@@ -0,0 +1,35 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import "context"
+
+// MemoryUsageEstimator is the interface implemented by APIs that can estimate
+// the GPU memory used by the objects of their state.
+type MemoryUsageEstimator interface {
+	// EstimateMemoryUsage returns the objects of the state s that are
+	// backed by GPU memory, with their estimated size.
+	EstimateMemoryUsage(ctx context.Context, s *GlobalState) []MemoryAllocation
+}
+
+// MemoryAllocation is a single object backed by GPU memory.
+type MemoryAllocation struct {
+	// Category is the kind of the object, such as "Texture" or "Buffer".
+	Category string
+	// Name identifies the object within its category.
+	Name string
+	// Size is the estimated size of the object in bytes.
+	Size uint64
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import "context"
+
+// Object is an object of an API state, such as a texture, a buffer or a
+// Vulkan pipeline.
+type Object struct {
+	// Key identifies the object for the whole lifetime of the object.
+	Key interface{}
+	// Value is the object held by the state. For resources, this is the
+	// Resource.
+	Value interface{}
+	// Kind is the name of the type of the object.
+	Kind string
+	// Handle is the UI identity of the object.
+	Handle string
+}
+
+// ObjectLifetimeTracker is the interface implemented by APIs that can tell
+// which objects are created and destroyed by their commands.
+type ObjectLifetimeTracker interface {
+	// CreatedObjects returns the objects that may have been created by cmd.
+	// It is called after cmd is mutated on s, and may also return objects that
+	// existed before cmd.
+	CreatedObjects(ctx context.Context, id CmdID, cmd Cmd, s *GlobalState) []Object
+
+	// DestroyedObjects returns the objects destroyed by cmd.
+	// It is called before cmd is mutated on s.
+	DestroyedObjects(ctx context.Context, id CmdID, cmd Cmd, s *GlobalState) []Object
+}
+
+// DrawnResourceTracker is the interface implemented by APIs with draw calls
+// that are executed by commands that are not draw calls themselves, such as
+// the draws of Vulkan command buffers.
+type DrawnResourceTracker interface {
+	// TrackDrawnResources sets up s so that cb is called with each of the
+	// resources used by the draw calls executed while mutating commands on s.
+	TrackDrawnResources(ctx context.Context, s *GlobalState, cb func(Resource))
+}
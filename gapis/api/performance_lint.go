@@ -0,0 +1,42 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/stringtable"
+)
+
+// PerformanceLinter is the interface implemented by APIs that can detect
+// performance anti-patterns in a command stream.
+type PerformanceLinter interface {
+	// NewPerformanceLint returns a new PerformanceLint for a single pass over
+	// the commands of a capture.
+	NewPerformanceLint(ctx context.Context) PerformanceLint
+}
+
+// PerformanceLint inspects the commands of a capture for performance issues.
+type PerformanceLint interface {
+	// Lint is called with each command of the capture and the state before
+	// the command is mutated. Issues found are passed to report.
+	Lint(ctx context.Context, id CmdID, cmd Cmd, s *GlobalState, report PerformanceIssueReporter)
+}
+
+// PerformanceIssueReporter is the function called by a PerformanceLint for
+// each performance issue found with a command.
+// issue is a short name of the kind of issue, used to group the issues.
+type PerformanceIssueReporter func(severity log.Severity, issue string, msg *stringtable.Msg)
@@ -0,0 +1,50 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"reflect"
+)
+
+// StateWriteInspector is the interface implemented by APIs that can return
+// the state written by their state-setting commands.
+type StateWriteInspector interface {
+	// WrittenState returns a copy of the value of the state that cmd writes,
+	// read from s, and true. If cmd is not a known state-setting command, then
+	// WrittenState returns false.
+	// Comparing the values returned before and after cmd is mutated tells
+	// whether cmd changed the state.
+	WrittenState(ctx context.Context, id CmdID, cmd Cmd, s *GlobalState) (interface{}, bool)
+}
+
+// MutateAndCompareState mutates cmd on s. If the API of cmd implements
+// StateWriteInspector and knows the state cmd writes, then known is true and
+// redundant tells whether cmd set the state to the value it already had.
+func MutateAndCompareState(ctx context.Context, id CmdID, cmd Cmd, s *GlobalState) (redundant, known bool, err error) {
+	inspector, ok := cmd.API().(StateWriteInspector)
+	if !ok {
+		return false, false, cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+	}
+	before, ok := inspector.WrittenState(ctx, id, cmd, s)
+	if err := cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */); err != nil || !ok {
+		return false, false, err
+	}
+	after, ok := inspector.WrittenState(ctx, id, cmd, s)
+	if !ok {
+		return false, false, nil
+	}
+	return reflect.DeepEqual(before, after), true, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/testcmd"
+	"github.com/google/gapid/gapis/replay/builder"
+)
+
+// stateAPI is an api.API with a single state value.
+type stateAPI struct {
+	api.API
+	value interface{}
+	known bool
+}
+
+func (a *stateAPI) WrittenState(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) (interface{}, bool) {
+	if _, ok := cmd.(*setState); !ok {
+		return nil, false
+	}
+	return a.value, a.known
+}
+
+// setState is a command that sets the state value of its API.
+type setState struct {
+	testcmd.A
+	api   *stateAPI
+	value interface{}
+	err   error
+}
+
+func (c *setState) API() api.API { return c.api }
+func (c *setState) Mutate(context.Context, api.CmdID, *api.GlobalState, *builder.Builder) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.api.value = c.value
+	return nil
+}
+
+func TestMutateAndCompareState(t *testing.T) {
+	ctx := log.Testing(t)
+	failure := errors.New("mutation failed")
+	for _, test := range []struct {
+		name     string
+		before   interface{}
+		cmd      func(*stateAPI) api.Cmd
+		expected interface{}
+		// The results of MutateAndCompareState.
+		redundant, known bool
+		err              error
+	}{
+		{"same value", 1,
+			func(a *stateAPI) api.Cmd { return &setState{api: a, value: 1} }, 1, true, true, nil},
+		{"other value", 1,
+			func(a *stateAPI) api.Cmd { return &setState{api: a, value: 2} }, 2, false, true, nil},
+		{"same slice", []int{1, 2},
+			func(a *stateAPI) api.Cmd { return &setState{api: a, value: []int{1, 2}} }, []int{1, 2}, true, true, nil},
+		{"other slice", []int{1, 2},
+			func(a *stateAPI) api.Cmd { return &setState{api: a, value: []int{1, 3}} }, []int{1, 3}, false, true, nil},
+		{"unknown state", 1,
+			func(a *stateAPI) api.Cmd { a.known = false; return &setState{api: a, value: 1} }, 1, false, false, nil},
+		{"mutation error", 1,
+			func(a *stateAPI) api.Cmd { return &setState{api: a, value: 2, err: failure} }, 1, false, false, failure},
+		{"no state inspector", 1,
+			func(a *stateAPI) api.Cmd { return &testcmd.A{} }, 1, false, false, nil},
+	} {
+		a := &stateAPI{value: test.before, known: true}
+		redundant, known, err := api.MutateAndCompareState(ctx, 0, test.cmd(a), nil)
+		assert.For(ctx, "%v err", test.name).That(err).Equals(test.err)
+		assert.For(ctx, "%v redundant", test.name).That(redundant).Equals(test.redundant)
+		assert.For(ctx, "%v known", test.name).That(known).Equals(test.known)
+		assert.For(ctx, "%v state", test.name).That(a.value).DeepEquals(test.expected)
+	}
+}
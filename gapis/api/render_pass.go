@@ -0,0 +1,50 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// RenderPassInstancesProvider is the interface implemented by APIs that can
+// list the render pass instances executed by a capture.
+type RenderPassInstancesProvider interface {
+	// RenderPassInstances returns the render pass instances executed by the
+	// commands of the capture, with the load and store operations of their
+	// attachments, in execution order.
+	RenderPassInstances(ctx context.Context, p *path.Capture) (*RenderPassInstances, error)
+}
+
+// Description returns the description of the reason w shown to the users.
+func (w RenderPassWaste) Description() string {
+	switch w {
+	case RenderPassWaste_LoadUndefined:
+		return "LOAD_OP_LOAD of an attachment in VK_IMAGE_LAYOUT_UNDEFINED"
+	case RenderPassWaste_LoadCleared:
+		return "LOAD_OP_LOAD of an image cleared before the render pass, use LOAD_OP_CLEAR"
+	case RenderPassWaste_LoadFullyCleared:
+		return "LOAD_OP_LOAD of an attachment fully cleared in the render pass"
+	case RenderPassWaste_StoreOverwritten:
+		return "STORE_OP_STORE of contents overwritten before being read"
+	case RenderPassWaste_StoreNeverRead:
+		return "STORE_OP_STORE of contents not read before the end of the capture"
+	case RenderPassWaste_MultisampleUnresolved:
+		return "multisampled color attachment not resolved"
+	default:
+		return w.String()
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/gapid/core/context/keys"
 	"github.com/google/gapid/core/data/id"
 	"github.com/google/gapid/core/data/protoutil"
 	"github.com/google/gapid/core/image"
@@ -58,6 +59,36 @@ type ResourceMeta struct {
 // ReplaceCallback is called from SetResourceData to propagate changes to current command stream.
 type ReplaceCallback func(where uint64, with interface{})
 
+// ResourceOverrider is the interface implemented by resources whose data is
+// changed by inserting commands into the command stream, rather than by
+// replacing the commands that created the resource.
+type ResourceOverrider interface {
+	// OverrideResourceData changes the resource data from the command at on,
+	// by inserting commands before it.
+	OverrideResourceData(ctx context.Context, at *path.Command, data *ResourceData, insert InsertCallback) error
+}
+
+// InsertCallback is called from OverrideResourceData to insert the commands
+// cmds before the command at where.
+type InsertCallback func(where uint64, cmds []Cmd)
+
+type resourceThreadKeyTy string
+
+const resourceThreadKey = resourceThreadKeyTy("resourceThread")
+
+// PutResourceThread attaches to a Context the thread of the command after
+// which resource data is resolved.
+func PutResourceThread(ctx context.Context, thread uint64) context.Context {
+	return keys.WithValue(ctx, resourceThreadKey, thread)
+}
+
+// GetResourceThread returns the thread previously attached to the Context by
+// PutResourceThread, or false if there is none.
+func GetResourceThread(ctx context.Context) (uint64, bool) {
+	thread, ok := ctx.Value(resourceThreadKey).(uint64)
+	return thread, ok
+}
+
 // Interface compliance check
 var _ = image.Convertable((*ResourceData)(nil))
 var _ = image.Thumbnailer((*ResourceData)(nil))
@@ -96,6 +127,8 @@ func NewResourceData(data interface{}) *ResourceData {
 		return &ResourceData{Data: &ResourceData_Shader{data}}
 	case *Program:
 		return &ResourceData{Data: &ResourceData_Program{data}}
+	case *Pipeline:
+		return &ResourceData{Data: &ResourceData_Pipeline{data}}
 	default:
 		panic(fmt.Errorf("%T is not a ResourceData type", data))
 	}
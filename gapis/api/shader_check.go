@@ -0,0 +1,32 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/os/device"
+)
+
+// ShaderChecker is the interface implemented by APIs that can compile offline
+// the shaders built by the commands of a capture.
+type ShaderChecker interface {
+	// CheckShader is called with each command of the capture and the state
+	// after the command is mutated. It returns the result of compiling the
+	// shader built by the command for the profile of the replay device d, or
+	// nil if the command builds no shader. d is nil if no replay device was
+	// given. The commands of the result are set by the caller.
+	CheckShader(ctx context.Context, cmd Cmd, s *GlobalState, d *device.Instance) *ShaderCheckResult
+}
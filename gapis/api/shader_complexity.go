@@ -0,0 +1,50 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/service/path"
+	"github.com/google/gapid/gapis/shadertools/spirv"
+)
+
+// ShaderComplexityProvider is the interface implemented by APIs that can
+// measure the complexity of the shaders of a capture.
+type ShaderComplexityProvider interface {
+	// ShaderComplexities returns the complexity of each of the shaders of the
+	// initial state and built by the commands of the capture, with the number
+	// of draw calls using them, in the order of the commands building them.
+	ShaderComplexities(ctx context.Context, p *path.Capture) ([]*ShaderComplexityEntry, error)
+}
+
+// Instructions returns the number of instructions of the shader.
+func (c *ShaderComplexity) Instructions() uint32 {
+	return c.AluInstructions + c.TextureInstructions + c.ControlFlowInstructions + c.OtherInstructions
+}
+
+// NewShaderComplexity returns the complexity c measured on a SPIR-V entry
+// point.
+func NewShaderComplexity(c *spirv.Complexity) *ShaderComplexity {
+	return &ShaderComplexity{
+		AluInstructions:         c.ALU,
+		TextureInstructions:     c.Texture,
+		ControlFlowInstructions: c.ControlFlow,
+		OtherInstructions:       c.Other,
+		Loops:                   c.Loops,
+		Samplers:                c.Samplers,
+		Interpolants:            c.Interpolants,
+	}
+}
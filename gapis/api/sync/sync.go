@@ -68,6 +68,15 @@ type SynchronizedAPI interface {
 	RecoverMidExecutionCommand(ctx context.Context, c *path.Capture, data interface{}) (api.Cmd, error)
 }
 
+// SyncGraphProvider is the interface implemented by synchronized APIs that can
+// build the graph of their queue submissions.
+type SyncGraphProvider interface {
+	// SyncGraph returns the graph of the queue submissions of the capture and
+	// of the synchronization primitives relating them, using the
+	// synchronization data d resolved for the capture.
+	SyncGraph(ctx context.Context, c *path.Capture, d *Data) (*api.SyncGraph, error)
+}
+
 type writer struct {
 	state *api.GlobalState
 	cmds  []api.Cmd
@@ -0,0 +1,41 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// SyncHazardFinder is the interface implemented by APIs that can detect
+// accesses to memory that lack synchronization with earlier accesses.
+type SyncHazardFinder interface {
+	// FindSyncHazards returns the synchronization hazards of the capture, in
+	// execution order.
+	FindSyncHazards(ctx context.Context, p *path.Capture) ([]SyncHazard, error)
+}
+
+// SyncHazard is an access to memory written by an earlier command, without
+// synchronization between the two commands.
+type SyncHazard struct {
+	// Write is the command that wrote the memory first.
+	Write SubCmdIdx
+	// Access is the command that read or wrote the memory afterwards.
+	Access SubCmdIdx
+	// IsWrite is true for a write-after-write hazard, and false for a
+	// read-after-write hazard.
+	IsWrite bool
+}
@@ -240,7 +240,9 @@ func (t *Texture) ConvertTo(ctx context.Context, f *image.Format) (interface{},
 		if err != nil {
 			return nil, err
 		}
-		return NewTexture(data), nil
+		out := NewTexture(data)
+		out.Sampling = t.Sampling
+		return out, nil
 	}
 	return nil, nil
 }
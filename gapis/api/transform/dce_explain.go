@@ -0,0 +1,210 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+)
+
+// LiveCommand explains why a command was kept alive by dead code elimination.
+//
+// The explanation is reconstructed after the liveness has been propagated:
+// a command is considered to be kept alive by the first following live
+// command that reads state written by it.
+type LiveCommand struct {
+	// Command is the index of the live command, counting the initial commands.
+	Command api.SubCmdIdx
+	// Requested is true if the command was requested.
+	Requested bool
+	// KeptAliveBy is the index of the live command that reads the state
+	// written by Command. nil if Requested is true or if no reader was found.
+	KeptAliveBy api.SubCmdIdx
+	// State describes the state written by Command and read by KeptAliveBy,
+	// or the reason for the liveness if KeptAliveBy is nil.
+	State string
+	// Behavior is the index of the footprint behavior that was live, or -1 if
+	// the liveness was determined using a dependency graph.
+	Behavior int64
+}
+
+// Explain propagates the liveness from the requested commands, and returns
+// the reason each of the live commands was kept alive.
+func (t *DeadCodeElimination) Explain(ctx context.Context) []LiveCommand {
+	g := t.depGraph
+	isLive := t.propagateLiveness(ctx)
+	parents := g.GetHierarchyStateMap()
+
+	// reads holds the indices of the live commands reading each state, and
+	// subtreeReads the indices of the live commands reading each state or
+	// any of its descendants. Both are in ascending order.
+	reads := map[dependencygraph.StateAddress][]int{}
+	subtreeReads := map[dependencygraph.StateAddress][]int{}
+	requested := []int{}
+	for i, live := range isLive {
+		if !live {
+			continue
+		}
+		if t.requests.Contains(g.GetCmdID(i)) {
+			requested = append(requested, i)
+		}
+		b := &g.Behaviours[i]
+		for _, l := range [][]dependencygraph.StateAddress{b.Reads, b.Modifies} {
+			for _, a := range l {
+				reads[a] = appendIndex(reads[a], i)
+				for p := a; ; p = parents[p] {
+					subtreeReads[p] = appendIndex(subtreeReads[p], i)
+					if p == dependencygraph.NullStateAddress {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	out := []LiveCommand{}
+	for i, live := range isLive {
+		if !live {
+			continue
+		}
+		lc := LiveCommand{Command: api.SubCmdIdx{uint64(i)}, Behavior: -1}
+		b := &g.Behaviours[i]
+		switch {
+		case t.requests.Contains(g.GetCmdID(i)):
+			lc.Requested = true
+		case b.KeepAlive:
+			lc.State = "command has no dependency information"
+		default:
+			reader, state := -1, dependencygraph.NullStateAddress
+			for _, l := range [][]dependencygraph.StateAddress{b.Writes, b.Modifies} {
+				for _, a := range l {
+					// The state is read by reads of itself, its descendants
+					// and its ancestors.
+					r := nextIndex(subtreeReads[a], i)
+					for p := parents[a]; ; p = parents[p] {
+						if n := nextIndex(reads[p], i); n >= 0 && (r < 0 || n < r) {
+							r = n
+						}
+						if p == dependencygraph.NullStateAddress {
+							break
+						}
+					}
+					if r >= 0 && (reader < 0 || r < reader) {
+						reader, state = r, a
+					}
+				}
+			}
+			if reader < 0 {
+				// Root state is read by the requested commands.
+				for _, l := range [][]dependencygraph.StateAddress{b.Writes, b.Modifies} {
+					for _, a := range l {
+						if g.Roots[a] {
+							reader, state = nextIndex(requested, i), a
+						}
+					}
+				}
+			}
+			if reader >= 0 {
+				lc.KeptAliveBy = api.SubCmdIdx{uint64(reader)}
+				lc.State = g.DescribeState(state)
+			} else {
+				lc.State = "no live reader of the written state found"
+			}
+		}
+		out = append(out, lc)
+	}
+	return out
+}
+
+// Explain back-propagates the liveness from the requested commands, and
+// returns the reason each of the live behaviors was kept alive.
+func (t *DCE) Explain(ctx context.Context) []LiveCommand {
+	livenessBoard, _ := t.backPropagate(ctx)
+	behaviors := t.footprint.Behaviors
+
+	// readers holds the indices of the live behaviors reading each variable,
+	// in ascending order. Variables of types that cannot be used as map keys
+	// are not tracked.
+	readers := map[dependencygraph.DefUseVariable][]int{}
+	for bi, live := range livenessBoard {
+		if !live {
+			continue
+		}
+		for _, r := range behaviors[bi].Reads {
+			if isComparable(r) {
+				readers[r] = appendIndex(readers[r], bi)
+			}
+		}
+	}
+
+	out := []LiveCommand{}
+	for bi, live := range livenessBoard {
+		if !live {
+			continue
+		}
+		bh := behaviors[bi]
+		lc := LiveCommand{Command: bh.Owner, Behavior: int64(bi)}
+		switch {
+		case t.requests.contains(bh.Owner) || t.requests.contains(api.SubCmdIdx{bh.Owner[0]}):
+			lc.Requested = true
+		default:
+			reader := -1
+			var state dependencygraph.DefUseVariable
+			for _, w := range bh.Writes {
+				if !isComparable(w) {
+					continue
+				}
+				if r := nextIndex(readers[w], bi); r >= 0 && (reader < 0 || r < reader) {
+					reader, state = r, w
+				}
+			}
+			if reader >= 0 {
+				lc.KeptAliveBy = behaviors[reader].Owner
+				lc.State = fmt.Sprintf("%T%+v", state, state)
+			} else {
+				lc.State = fmt.Sprintf("kept alive by %T", bh.Machine)
+			}
+		}
+		out = append(out, lc)
+	}
+	return out
+}
+
+// appendIndex appends i to the ascending list l, unless it is already the
+// last element.
+func appendIndex(l []int, i int) []int {
+	if n := len(l); n > 0 && l[n-1] == i {
+		return l
+	}
+	return append(l, i)
+}
+
+// nextIndex returns the first element of the ascending list l greater than i,
+// or -1 if there is none.
+func nextIndex(l []int, i int) int {
+	if n := sort.SearchInts(l, i+1); n < len(l) {
+		return l[n]
+	}
+	return -1
+}
+
+func isComparable(v interface{}) bool {
+	return v != nil && reflect.TypeOf(v).Comparable()
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/testcmd"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+)
+
+func TestAppendIndex(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name     string
+		l        []int
+		i        int
+		expected []int
+	}{
+		{"empty", nil, 3, []int{3}},
+		{"greater", []int{1, 2}, 3, []int{1, 2, 3}},
+		{"duplicate", []int{1, 3}, 3, []int{1, 3}},
+	} {
+		assert.For(ctx, "%v", test.name).ThatSlice(appendIndex(test.l, test.i)).Equals(test.expected)
+	}
+}
+
+func TestNextIndex(t *testing.T) {
+	ctx := log.Testing(t)
+	l := []int{2, 5, 9}
+	for _, test := range []struct {
+		i        int
+		expected int
+	}{
+		{0, 2},
+		{2, 5},
+		{4, 5},
+		{8, 9},
+		{9, -1},
+		{10, -1},
+	} {
+		assert.For(ctx, "nextIndex(%v)", test.i).That(nextIndex(l, test.i)).Equals(test.expected)
+	}
+	assert.For(ctx, "empty").That(nextIndex(nil, 0)).Equals(-1)
+}
+
+type explainState string
+
+func (explainState) Parent() dependencygraph.StateKey { return nil }
+
+func TestDeadCodeEliminationExplain(t *testing.T) {
+	ctx := log.Testing(t)
+
+	g := dependencygraph.NewDependencyGraph([]api.Cmd{
+		&testcmd.A{ID: 0},
+		&testcmd.A{ID: 1},
+		&testcmd.A{ID: 2},
+		&testcmd.A{ID: 3},
+		&testcmd.A{ID: 4},
+	}, 0)
+	a, b, c := explainState("A"), explainState("B"), explainState("C")
+	// 1 is dead as nothing reads B.
+	g.Behaviours[0].Write(g, a)
+	g.Behaviours[1].Write(g, b)
+	g.Behaviours[2].Read(g, a)
+	g.Behaviours[2].Write(g, c)
+	g.Behaviours[3].KeepAlive = true
+	g.Behaviours[4].Read(g, c)
+
+	dce := NewDeadCodeElimination(ctx, g)
+	dce.Request(4)
+
+	describe := func(s dependencygraph.StateKey) string {
+		return g.DescribeState(g.GetStateAddressOf(s))
+	}
+	assert.For(ctx, "explanation").ThatSlice(dce.Explain(ctx)).DeepEquals([]LiveCommand{
+		{Command: api.SubCmdIdx{0}, KeptAliveBy: api.SubCmdIdx{2}, State: describe(a), Behavior: -1},
+		{Command: api.SubCmdIdx{2}, KeptAliveBy: api.SubCmdIdx{4}, State: describe(c), Behavior: -1},
+		{Command: api.SubCmdIdx{3}, State: "command has no dependency information", Behavior: -1},
+		{Command: api.SubCmdIdx{4}, Requested: true, Behavior: -1},
+	})
+}
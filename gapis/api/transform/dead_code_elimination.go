@@ -104,6 +104,7 @@ func (t *DeadCodeElimination) Flush(ctx context.Context, out Writer) {
 func (t *DeadCodeElimination) propagateLiveness(ctx context.Context) []bool {
 	isLive := make([]bool, t.depGraph.NumInitialCommands+int(t.lastRequest)+1)
 	state := newLivenessTree(t.depGraph.GetHierarchyStateMap())
+	debug := config.Get(ctx).DebugDeadCodeElimination
 	for i := len(isLive) - 1; i >= 0; i-- {
 		b := t.depGraph.Behaviours[i]
 		isLive[i] = b.KeepAlive
@@ -146,7 +147,7 @@ func (t *DeadCodeElimination) propagateLiveness(ctx context.Context) []bool {
 			}
 		}
 		// Debug output
-		if config.DebugDeadCodeElimination && t.requests.Contains(id) {
+		if debug && t.requests.Contains(id) {
 			log.I(ctx, "DCE: Requested cmd %v: %v", id, t.depGraph.Commands[i])
 			t.depGraph.Print(ctx, &b)
 		}
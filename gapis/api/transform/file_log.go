@@ -45,11 +45,11 @@ func (t *fileLog) Transform(ctx context.Context, id api.CmdID, cmd api.Cmd, out
 	} else {
 		t.file.WriteString(fmt.Sprintf("%T\n", cmd))
 	}
-	if config.LogExtrasInTransforms {
+	if debug := config.Get(ctx); debug.LogExtrasInTransforms {
 		if extras := cmd.Extras(); extras != nil {
 			for _, e := range extras.All() {
 				if o, ok := e.(*api.CmdObservations); ok {
-					if config.LogMemoryInExtras {
+					if debug.LogMemoryInExtras {
 						t.file.WriteString(o.DataString(ctx))
 					} else {
 						t.file.WriteString(o.String())
@@ -0,0 +1,322 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gapid/core/app/benchmark"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/config"
+)
+
+// outputStageName is the name of the profile stage for the final Writer of
+// the transform chain, usually the replay builder.
+const outputStageName = "output"
+
+// allocSamplePeriod is the minimum duration between two reads of the
+// allocation count. Reading it stops the world, so it cannot be done on every
+// transition between stages.
+const allocSamplePeriod = 10 * time.Millisecond
+
+// profile records the time spent, the commands processed and the memory
+// allocations made by each of the transformers of a single Transform call.
+//
+// Time is attributed to the innermost executing stage, so the time a
+// transformer spends waiting on the transformers it writes to is not counted
+// against it. The allocation count is only sampled every allocSamplePeriod,
+// and the allocations made between two samples are shared between the stages
+// in proportion to the time they executed for. The sampling overhead is
+// excluded from the recorded durations.
+//
+// The allocation count is the one of the whole process, so the allocations
+// made by concurrent replays and requests are included. They are reported as
+// processAllocations to make this clear.
+type profile struct {
+	stages  []*stageProfile
+	stack   []*stageProfile // The executing stages, innermost last.
+	start   time.Time       // Time the Transform call started.
+	last    time.Time       // Time of the last transition between stages.
+	sampled time.Time       // Time of the last allocation count sample.
+	mallocs uint64          // Number of allocations at the last sample.
+}
+
+// stageProfile holds the statistics of a single transformer.
+type stageProfile struct {
+	name        string
+	duration    time.Duration
+	unsampled   time.Duration // Part of duration since the last sample.
+	commandsIn  int64
+	commandsOut int64
+	// processAllocations are the allocations of the process attributed to
+	// the stage.
+	processAllocations uint64
+}
+
+// profileWriter is a Writer that attributes the work done writing to w to
+// stage.
+type profileWriter struct {
+	Writer
+	p     *profile
+	stage int
+}
+
+func (w profileWriter) MutateAndWrite(ctx context.Context, id api.CmdID, cmd api.Cmd) {
+	w.p.stages[w.stage].commandsIn++
+	w.p.enter(w.stage)
+	w.Writer.MutateAndWrite(ctx, id, cmd)
+	w.p.exit()
+}
+
+func newProfile(l Transforms) *profile {
+	p := &profile{stages: make([]*stageProfile, len(l)+1)}
+	for i, t := range l {
+		p.stages[i] = &stageProfile{name: transformName(t)}
+	}
+	p.stages[len(l)] = &stageProfile{name: outputStageName}
+	p.sampleAllocations()
+	p.start = time.Now()
+	p.last = p.start
+	return p
+}
+
+// wrap returns a Writer that profiles the writes to w as the given stage.
+func (p *profile) wrap(stage int, w Writer) Writer {
+	return profileWriter{w, p, stage}
+}
+
+// enter marks the start of work by the given stage.
+func (p *profile) enter(stage int) {
+	p.transition()
+	p.stack = append(p.stack, p.stages[stage])
+}
+
+// exit marks the end of work by the innermost executing stage.
+func (p *profile) exit() {
+	p.transition()
+	p.stack = p.stack[:len(p.stack)-1]
+}
+
+// transition attributes the time since the last transition to the innermost
+// executing stage.
+func (p *profile) transition() {
+	now := time.Now()
+	if n := len(p.stack); n > 0 {
+		s := p.stack[n-1]
+		d := now.Sub(p.last)
+		s.duration += d
+		s.unsampled += d
+	}
+	p.last = now
+	if now.Sub(p.sampled) >= allocSamplePeriod {
+		p.sampleAllocations()
+		p.last = time.Now()
+	}
+}
+
+// sampleAllocations reads the allocation count, and shares the allocations
+// made since the last sample between the stages in proportion to the time they
+// executed for since then.
+func (p *profile) sampleAllocations() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	allocs := m.Mallocs - p.mallocs
+	total := time.Duration(0)
+	for _, s := range p.stages {
+		total += s.unsampled
+	}
+	for _, s := range p.stages {
+		if total > 0 {
+			s.processAllocations += uint64(float64(allocs) * float64(s.unsampled) / float64(total))
+		}
+		s.unsampled = 0
+	}
+	p.mallocs = m.Mallocs
+	p.sampled = time.Now()
+}
+
+// finish publishes the profile to the performance counters, and adds it to the
+// trace if TransformTraceFile is set in the debug configuration.
+func (p *profile) finish(ctx context.Context) {
+	p.sampleAllocations()
+	for i, s := range p.stages {
+		if i+1 < len(p.stages) {
+			s.commandsOut = p.stages[i+1].commandsIn
+		}
+		prefix := "transform." + s.name
+		benchmark.Duration(prefix + ".duration").Add(s.duration)
+		benchmark.Integer(prefix + ".commandsIn").Add(s.commandsIn)
+		benchmark.Integer(prefix + ".commandsOut").Add(s.commandsOut)
+		benchmark.Integer(prefix + ".processAllocations").Add(int64(s.processAllocations))
+	}
+
+	debug := config.Get(ctx)
+	if debug.DebugReplay {
+		for _, s := range p.stages {
+			log.I(ctx, "Transform %v: %v, %d commands in, %d commands out, %d process-wide allocations",
+				s.name, s.duration, s.commandsIn, s.commandsOut, s.processAllocations)
+		}
+	}
+
+	if debug.TransformTraceFile != "" {
+		if err := profileTrace.add(p); err != nil {
+			log.W(ctx, "Failed to write the transform trace: %v", err)
+		}
+	}
+}
+
+// traceEvent is a single complete ("X") event of the Chrome trace event
+// format. Times are in microseconds.
+type traceEvent struct {
+	Name     string                 `json:"name"`
+	Phase    string                 `json:"ph"`
+	Time     int64                  `json:"ts"`
+	Duration int64                  `json:"dur"`
+	Process  int                    `json:"pid"`
+	Thread   int                    `json:"tid"`
+	Args     map[string]interface{} `json:"args,omitempty"`
+}
+
+// traceFile writes the profiles of the Transform calls to a Chrome trace
+// JSON file as they finish, so that no events are held in memory.
+//
+// The events are written in the JSON array format, whose closing bracket is
+// optional, so that the file can be loaded while gapis is still running.
+type traceFile struct {
+	mutex   sync.Mutex
+	epoch   time.Time
+	batches int
+	w       io.Writer // nil if no trace is being written.
+	events  int       // Number of events written to w.
+}
+
+var profileTrace = traceFile{epoch: time.Now()}
+
+// start starts writing the trace to w.
+func (t *traceFile) start(w io.Writer) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.w, t.events = w, 0
+	_, err := io.WriteString(w, "[")
+	return err
+}
+
+// stop terminates the trace started by start.
+func (t *traceFile) stop() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.w == nil {
+		return nil
+	}
+	_, err := io.WriteString(t.w, "\n]\n")
+	t.w = nil
+	return err
+}
+
+// add writes the events of the profile p to the trace, if one is started.
+// Each Transform call is given its own track, with the time spent in each of
+// the transformers laid out one after the other beneath the call.
+func (t *traceFile) add(p *profile) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.w == nil {
+		return nil
+	}
+
+	t.batches++
+	ts := p.start.Sub(t.epoch).Nanoseconds() / 1000
+	events := []traceEvent{{
+		Name:     fmt.Sprintf("Transforms #%d", t.batches),
+		Phase:    "X",
+		Time:     ts,
+		Duration: p.last.Sub(p.start).Nanoseconds() / 1000,
+		Thread:   t.batches,
+	}}
+	for _, s := range p.stages {
+		dur := s.duration.Nanoseconds() / 1000
+		events = append(events, traceEvent{
+			Name:     s.name,
+			Phase:    "X",
+			Time:     ts,
+			Duration: dur,
+			Thread:   t.batches,
+			Args: map[string]interface{}{
+				"commandsIn":         s.commandsIn,
+				"commandsOut":        s.commandsOut,
+				"processAllocations": s.processAllocations,
+			},
+		})
+		ts += dur
+	}
+
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		separator := ",\n"
+		if t.events == 0 {
+			separator = "\n"
+		}
+		if _, err := io.WriteString(t.w, separator); err != nil {
+			return err
+		}
+		if _, err := t.w.Write(data); err != nil {
+			return err
+		}
+		t.events++
+	}
+	return nil
+}
+
+// StartProfileTrace creates the Chrome trace JSON file at path, and writes the
+// profiles of the Transform calls made with TransformTraceFile set to it as
+// they finish. The returned function terminates the trace and closes the file.
+func StartProfileTrace(path string) (stop func() error, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := profileTrace.start(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		err := profileTrace.stop()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// transformName returns the name used to identify the transformer t.
+func transformName(t Transformer) string {
+	if n, ok := t.(interface {
+		Name() string
+	}); ok {
+		return n.Name()
+	}
+	return strings.Replace(fmt.Sprintf("%T", t), "*", "", -1)
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/gapid/core/app/benchmark"
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/testcmd"
+	"github.com/google/gapid/gapis/config"
+)
+
+// dropOdd drops the commands with an odd identifier.
+type dropOdd struct{}
+
+func (dropOdd) Name() string { return "dropOdd" }
+func (dropOdd) Transform(ctx context.Context, id api.CmdID, cmd api.Cmd, out Writer) {
+	if id%2 == 0 {
+		out.MutateAndWrite(ctx, id, cmd)
+	}
+}
+func (dropOdd) Flush(ctx context.Context, out Writer) {}
+
+// duplicate writes each of the commands twice.
+type duplicate struct{}
+
+func (duplicate) Name() string { return "duplicate" }
+func (duplicate) Transform(ctx context.Context, id api.CmdID, cmd api.Cmd, out Writer) {
+	out.MutateAndWrite(ctx, id, cmd)
+	out.MutateAndWrite(ctx, id, cmd)
+}
+func (duplicate) Flush(ctx context.Context, out Writer) {}
+
+func TestProfileCounters(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = config.Put(ctx, config.Debug{ProfileTransforms: true})
+	stages := []string{"dropOdd", "duplicate", outputStageName}
+	for _, name := range stages {
+		benchmark.Integer("transform." + name + ".commandsIn").Reset()
+		benchmark.Integer("transform." + name + ".commandsOut").Reset()
+	}
+
+	out := &testcmd.Writer{}
+	cmds := []api.Cmd{&testcmd.A{ID: 0}, &testcmd.A{ID: 1}, &testcmd.A{ID: 2}, &testcmd.A{ID: 3}}
+	Transforms{dropOdd{}, duplicate{}}.Transform(ctx, cmds, out)
+	assert.For(ctx, "output").ThatSlice(out.Cmds).IsLength(4)
+
+	for i, expected := range []struct{ in, out int64 }{{4, 2}, {2, 4}, {4, 0}} {
+		name := stages[i]
+		assert.For(ctx, "%v commands in", name).That(
+			benchmark.Integer("transform." + name + ".commandsIn").Get()).Equals(expected.in)
+		assert.For(ctx, "%v commands out", name).That(
+			benchmark.Integer("transform." + name + ".commandsOut").Get()).Equals(expected.out)
+	}
+}
+
+func TestProfileTrace(t *testing.T) {
+	ctx := log.Testing(t)
+	trace := &traceFile{epoch: time.Now()}
+	p := newProfile(Transforms{dropOdd{}})
+	p.stages[0].commandsIn = 2
+
+	assert.For(ctx, "add before start").ThatError(trace.add(p)).Succeeded()
+
+	buf := &bytes.Buffer{}
+	assert.For(ctx, "start").ThatError(trace.start(buf)).Succeeded()
+	for i := 0; i < 2; i++ {
+		assert.For(ctx, "add %d", i).ThatError(trace.add(p)).Succeeded()
+	}
+
+	// The trace can be loaded before it is stopped, by closing the array.
+	events := []traceEvent{}
+	err := json.Unmarshal(append(buf.Bytes(), ']'), &events)
+	if !assert.For(ctx, "unterminated trace").ThatError(err).Succeeded() {
+		return
+	}
+	names := []string{}
+	threads := []int{}
+	for _, e := range events {
+		names = append(names, e.Name)
+		threads = append(threads, e.Thread)
+	}
+	assert.For(ctx, "names").ThatSlice(names).Equals([]string{
+		"Transforms #1", "dropOdd", outputStageName,
+		"Transforms #2", "dropOdd", outputStageName,
+	})
+	assert.For(ctx, "threads").ThatSlice(threads).Equals([]int{1, 1, 1, 2, 2, 2})
+	assert.For(ctx, "commands in").That(events[1].Args["commandsIn"]).Equals(float64(2))
+
+	assert.For(ctx, "stop").ThatError(trace.stop()).Succeeded()
+	written := buf.Len()
+	assert.For(ctx, "add after stop").ThatError(trace.add(p)).Succeeded()
+	assert.For(ctx, "written after stop").That(buf.Len()).Equals(written)
+	events = nil
+	err = json.Unmarshal(buf.Bytes(), &events)
+	assert.For(ctx, "terminated trace").ThatError(err).Succeeded()
+	assert.For(ctx, "events").ThatSlice(events).IsLength(6)
+}
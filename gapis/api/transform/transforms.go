@@ -26,22 +26,43 @@ type Transforms []Transformer
 
 // Transform sequentially transforms the commands by each of the transformers in
 // the list, before writing the final output to the output command Writer.
+// If ProfileTransforms is set in the debug configuration then the work done by
+// each of the transformers is recorded to the performance counters.
 func (l Transforms) Transform(ctx context.Context, cmds []api.Cmd, out Writer) {
+	debug := config.Get(ctx)
+	var prof *profile
+	if debug.ProfileTransforms {
+		prof = newProfile(l)
+		out = prof.wrap(len(l), out)
+	}
+	writers := make([]TransformWriter, len(l))
 	chain := out
 	for i := len(l) - 1; i >= 0; i-- {
 		s := out.State()
-		if config.SeparateMutateStates {
+		if debug.SeparateMutateStates {
 			s = api.NewStateWithAllocator(s.Allocator, s.MemoryLayout)
 		}
-		chain = TransformWriter{s, l[i], chain}
+		writers[i] = TransformWriter{s, l[i], chain, debug.SeparateMutateStates}
+		chain = writers[i]
+		if prof != nil {
+			chain = prof.wrap(i, chain)
+		}
 	}
 	api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
 		chain.MutateAndWrite(ctx, id, cmd)
 		return nil
 	})
-	for p, ok := chain.(TransformWriter); ok; p, ok = chain.(TransformWriter) {
-		chain = p.O
-		p.T.Flush(ctx, chain)
+	for i, p := range writers {
+		if prof != nil {
+			prof.enter(i)
+		}
+		p.T.Flush(ctx, p.O)
+		if prof != nil {
+			prof.exit()
+		}
+	}
+	if prof != nil {
+		prof.finish(ctx)
 	}
 }
 
@@ -85,6 +106,8 @@ type TransformWriter struct {
 	S *api.GlobalState
 	T Transformer
 	O Writer
+
+	mutate bool // If true, each command mutates S before it is transformed.
 }
 
 func (p TransformWriter) State() *api.GlobalState {
@@ -92,7 +115,7 @@ func (p TransformWriter) State() *api.GlobalState {
 }
 
 func (p TransformWriter) MutateAndWrite(ctx context.Context, id api.CmdID, cmd api.Cmd) {
-	if config.SeparateMutateStates {
+	if p.mutate {
 		cmd.Mutate(ctx, id, p.S, nil /* no builder, just mutate */)
 	}
 	p.T.Transform(ctx, id, cmd, p.O)
@@ -0,0 +1,278 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/resolve"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DescriptorSets implements the api.DescriptorSetsProvider interface.
+func (API) DescriptorSets(ctx context.Context, p *path.DescriptorSets) (*api.DescriptorSets, error) {
+	if len(p.Command.Indices) < 2 {
+		return nil, nil
+	}
+	obj, err := database.Build(ctx, &DescriptorSetsResolvable{Command: p.Command})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.DescriptorSets), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The commands are mutated up to the submission of the command, and the
+// descriptor sets bound by the vkCmdBindDescriptorSets commands of the command
+// buffer are tracked for each bind point until the command is executed.
+func (r *DescriptorSetsResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	idx := api.SubCmdIdx(r.Command.Indices)
+	ctx = capture.Put(ctx, r.Command.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if idx[0] >= uint64(len(c.Commands)) {
+		return nil, fmt.Errorf("Command %v is out of range", idx)
+	}
+	var out *api.DescriptorSets
+	if _, ok := c.Commands[idx[0]].(*VkQueueSubmit); !ok {
+		return out, nil
+	}
+
+	bindings := descriptorSetBindings{}
+	s := c.NewState(ctx)
+	st := GetState(s)
+	err = mutateSubcommands(ctx, s, c.Commands[:idx[0]+1], nil, func(subIdx api.SubCmdIdx, ref *CommandReference) {
+		if subIdx[0] != idx[0] {
+			return
+		}
+		switch ref.Type {
+		case CommandType_cmd_vkCmdBindDescriptorSets:
+			bindings.bindArgs(st, ref.Buffer, GetCommandArgs(ctx, ref, st).(*VkCmdBindDescriptorSetsArgs))
+		case CommandType_cmd_vkCmdDraw,
+			CommandType_cmd_vkCmdDrawIndexed,
+			CommandType_cmd_vkCmdDrawIndirect,
+			CommandType_cmd_vkCmdDrawIndexedIndirect,
+			CommandType_cmd_vkCmdDispatch,
+			CommandType_cmd_vkCmdDispatchIndirect:
+			if out == nil && idx.Equals(subIdx) {
+				root := resolve.APIStateAfter(r.Command.Capture.Command(idx[0]), ID)
+				out = descriptorSets(st, bindings.sets(ref), root)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// descriptorSetBinding is a descriptor set bound to a set number of a bind
+// point by vkCmdBindDescriptorSets.
+type descriptorSetBinding struct {
+	set VkDescriptorSet
+	// dynamicOffsets are the dynamic offsets of the dynamic buffer descriptors
+	// of the set, in binding and array element order.
+	dynamicOffsets []uint32
+}
+
+// descriptorSetBindings holds the descriptor sets bound to each bind point of
+// the executed command buffers, by set number. The bindings of a command
+// buffer are not inherited by the secondary command buffers it executes.
+type descriptorSetBindings map[VkCommandBuffer]map[VkPipelineBindPoint]map[uint32]descriptorSetBinding
+
+// bind binds the descriptor sets from firstSet to the bind point of the
+// command buffer. offsets holds the dynamic offsets of each set.
+func (b descriptorSetBindings) bind(cb VkCommandBuffer, bindPoint VkPipelineBindPoint, firstSet uint32, sets []VkDescriptorSet, offsets [][]uint32) {
+	points, ok := b[cb]
+	if !ok {
+		points = map[VkPipelineBindPoint]map[uint32]descriptorSetBinding{}
+		b[cb] = points
+	}
+	bound, ok := points[bindPoint]
+	if !ok {
+		bound = map[uint32]descriptorSetBinding{}
+		points[bindPoint] = bound
+	}
+	for i, set := range sets {
+		bound[firstSet+uint32(i)] = descriptorSetBinding{set, offsets[i]}
+	}
+}
+
+// bindArgs binds the descriptor sets of the vkCmdBindDescriptorSets arguments
+// to the command buffer, assigning the dynamic offsets to the dynamic buffer
+// descriptors of the sets in order.
+func (b descriptorSetBindings) bindArgs(s *State, cb VkCommandBuffer, args *VkCmdBindDescriptorSetsArgs) {
+	sets := make([]VkDescriptorSet, 0, args.DescriptorSets.Len())
+	counts := make([]int, 0, args.DescriptorSets.Len())
+	for _, i := range args.DescriptorSets.Keys() {
+		set := args.DescriptorSets.Get(i)
+		sets = append(sets, set)
+		counts = append(counts, dynamicDescriptorCount(s, set))
+	}
+	offsets := make([]uint32, 0, args.DynamicOffsets.Len())
+	for _, i := range args.DynamicOffsets.Keys() {
+		offsets = append(offsets, args.DynamicOffsets.Get(i))
+	}
+	b.bind(cb, args.PipelineBindPoint, args.FirstSet, sets, splitDynamicOffsets(counts, offsets))
+}
+
+// sets returns the descriptor sets bound to the bind point used by the draw
+// or dispatch command ref, by set number.
+func (b descriptorSetBindings) sets(ref *CommandReference) map[uint32]descriptorSetBinding {
+	bindPoint := VkPipelineBindPoint_VK_PIPELINE_BIND_POINT_GRAPHICS
+	switch ref.Type {
+	case CommandType_cmd_vkCmdDispatch, CommandType_cmd_vkCmdDispatchIndirect:
+		bindPoint = VkPipelineBindPoint_VK_PIPELINE_BIND_POINT_COMPUTE
+	}
+	return b[ref.Buffer][bindPoint]
+}
+
+// isDynamicBuffer returns true if the descriptors of the type take a dynamic
+// offset when their set is bound.
+func isDynamicBuffer(ty VkDescriptorType) bool {
+	switch ty {
+	case VkDescriptorType_VK_DESCRIPTOR_TYPE_UNIFORM_BUFFER_DYNAMIC,
+		VkDescriptorType_VK_DESCRIPTOR_TYPE_STORAGE_BUFFER_DYNAMIC:
+		return true
+	}
+	return false
+}
+
+// dynamicDescriptorCount returns the number of dynamic buffer descriptors of
+// the descriptor set.
+func dynamicDescriptorCount(s *State, set VkDescriptorSet) int {
+	ds, ok := s.DescriptorSets.Lookup(set)
+	if !ok {
+		return 0
+	}
+	count := 0
+	for _, binding := range ds.Bindings.Keys() {
+		if b := ds.Bindings.Get(binding); isDynamicBuffer(b.BindingType) {
+			count += b.BufferBinding.Len()
+		}
+	}
+	return count
+}
+
+// splitDynamicOffsets splits the dynamic offsets of a vkCmdBindDescriptorSets
+// between the sets, given the number of dynamic descriptors of each set.
+// Missing offsets are zero.
+func splitDynamicOffsets(counts []int, offsets []uint32) [][]uint32 {
+	out := make([][]uint32, len(counts))
+	for i, count := range counts {
+		out[i] = make([]uint32, count)
+		n := copy(out[i], offsets)
+		offsets = offsets[n:]
+	}
+	return out
+}
+
+// descriptorSets returns the descriptors of the bound descriptor sets. root is
+// the path to the state the descriptors link to.
+func descriptorSets(s *State, bound map[uint32]descriptorSetBinding, root path.Node) *api.DescriptorSets {
+	link := func(field string, handle interface{}) *path.Any {
+		return path.NewField(field, root).MapIndex(handle).Path()
+	}
+	numbers := make([]uint32, 0, len(bound))
+	for set := range bound {
+		numbers = append(numbers, set)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	out := &api.DescriptorSets{}
+	for _, set := range numbers {
+		b := bound[set]
+		apiSet := &api.DescriptorSet{Set: set, Handle: fmt.Sprintf("DescriptorSet<0x%x>", b.set)}
+		out.Sets = append(out.Sets, apiSet)
+		ds, ok := s.DescriptorSets.Lookup(b.set)
+		if !ok {
+			continue
+		}
+		dynamic := b.dynamicOffsets
+		for _, binding := range ds.Bindings.Keys() {
+			db := ds.Bindings.Get(binding)
+			ty := db.BindingType.String()
+			for _, i := range db.ImageBinding.Keys() {
+				img := db.ImageBinding.Get(i)
+				if img == nil {
+					continue
+				}
+				d := &api.Descriptor{
+					Binding:     binding,
+					ArrayIndex:  i,
+					Type:        ty,
+					ImageLayout: img.ImageLayout.String(),
+				}
+				if img.Sampler != 0 {
+					d.Sampler = fmt.Sprintf("Sampler<0x%x>", img.Sampler)
+				}
+				if img.ImageView != 0 {
+					d.ImageView = fmt.Sprintf("ImageView<0x%x>", img.ImageView)
+				}
+				if view, ok := s.ImageViews.Lookup(img.ImageView); ok && view.Image != nil {
+					d.Link = link("Images", view.Image.VulkanHandle)
+				}
+				apiSet.Descriptors = append(apiSet.Descriptors, d)
+			}
+			for _, i := range db.BufferBinding.Keys() {
+				buf := db.BufferBinding.Get(i)
+				if buf == nil {
+					continue
+				}
+				d := &api.Descriptor{
+					Binding:    binding,
+					ArrayIndex: i,
+					Type:       ty,
+					Buffer:     fmt.Sprintf("Buffer<0x%x>", buf.Buffer),
+					Offset:     uint64(buf.Offset),
+					Range:      uint64(buf.Range),
+				}
+				if isDynamicBuffer(db.BindingType) && len(dynamic) > 0 {
+					d.DynamicOffset = dynamic[0]
+					d.Offset += uint64(dynamic[0])
+					dynamic = dynamic[1:]
+				}
+				if s.Buffers.Contains(buf.Buffer) {
+					d.Link = link("Buffers", buf.Buffer)
+				}
+				apiSet.Descriptors = append(apiSet.Descriptors, d)
+			}
+			for _, i := range db.BufferViewBindings.Keys() {
+				bufferView := db.BufferViewBindings.Get(i)
+				d := &api.Descriptor{
+					Binding:    binding,
+					ArrayIndex: i,
+					Type:       ty,
+					BufferView: fmt.Sprintf("BufferView<0x%x>", bufferView),
+				}
+				if view, ok := s.BufferViews.Lookup(bufferView); ok && view.Buffer != nil {
+					d.Buffer = fmt.Sprintf("Buffer<0x%x>", view.Buffer.VulkanHandle)
+					d.Offset, d.Range = uint64(view.Offset), uint64(view.Range)
+					d.Link = link("Buffers", view.Buffer.VulkanHandle)
+				}
+				apiSet.Descriptors = append(apiSet.Descriptors, d)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+)
+
+func TestSplitDynamicOffsets(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name     string
+		counts   []int
+		offsets  []uint32
+		expected [][]uint32
+	}{
+		{"no dynamic descriptors", []int{0, 0}, nil, [][]uint32{{}, {}}},
+		{"one per set", []int{1, 1}, []uint32{16, 32}, [][]uint32{{16}, {32}}},
+		{"skipped set", []int{2, 0, 1}, []uint32{16, 32, 48}, [][]uint32{{16, 32}, {}, {48}}},
+		{"missing offsets", []int{1, 2}, []uint32{16, 32}, [][]uint32{{16}, {32, 0}}},
+	} {
+		assert.For(ctx, "%v", test.name).That(splitDynamicOffsets(test.counts, test.offsets)).DeepEquals(test.expected)
+	}
+}
+
+func TestDescriptorSetBindings(t *testing.T) {
+	ctx := log.Testing(t)
+	const (
+		primary   = VkCommandBuffer(1)
+		secondary = VkCommandBuffer(2)
+		graphics  = VkPipelineBindPoint_VK_PIPELINE_BIND_POINT_GRAPHICS
+		compute   = VkPipelineBindPoint_VK_PIPELINE_BIND_POINT_COMPUTE
+	)
+	b := descriptorSetBindings{}
+	b.bind(primary, compute, 0, []VkDescriptorSet{10}, [][]uint32{{64}})
+	b.bind(primary, graphics, 0, []VkDescriptorSet{20, 21}, [][]uint32{{}, {}})
+	b.bind(primary, graphics, 1, []VkDescriptorSet{22}, [][]uint32{{128}})
+	b.bind(secondary, graphics, 0, []VkDescriptorSet{30}, [][]uint32{{}})
+
+	draw := &CommandReference{Buffer: primary, Type: CommandType_cmd_vkCmdDraw}
+	assert.For(ctx, "draw").That(b.sets(draw)).DeepEquals(map[uint32]descriptorSetBinding{
+		0: {20, []uint32{}},
+		1: {22, []uint32{128}},
+	})
+
+	// The later graphics binds do not change the sets of the dispatches.
+	dispatch := &CommandReference{Buffer: primary, Type: CommandType_cmd_vkCmdDispatch}
+	assert.For(ctx, "dispatch").That(b.sets(dispatch)).DeepEquals(map[uint32]descriptorSetBinding{
+		0: {10, []uint32{64}},
+	})
+
+	indirect := &CommandReference{Buffer: secondary, Type: CommandType_cmd_vkCmdDispatchIndirect}
+	assert.For(ctx, "secondary dispatch").That(len(b.sets(indirect))).Equals(0)
+}
@@ -0,0 +1,475 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// dynamicViewport holds the first viewport and scissor set by
+// vkCmdSetViewport and vkCmdSetScissor in a command buffer.
+type dynamicViewport struct {
+	viewport *VkViewport
+	scissor  *VkRect2D
+}
+
+// DrawCallState implements the api.DrawCallStateProvider interface.
+func (API) DrawCallState(ctx context.Context, p *path.DrawCallState) (*api.DrawCallState, error) {
+	obj, err := database.Build(ctx, &DrawCallStateResolvable{Path: p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.DrawCallState), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The state is the state of the bound graphics pipeline and descriptor sets of
+// the draw at the sub-command index of the path, or of the last draw of the
+// submission for a vkQueueSubmit. The viewport and scissor are the ones set
+// in the command buffer of the draw when they are dynamic state.
+func (r *DrawCallStateResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	idx := api.SubCmdIdx(r.Path.Command.Indices)
+	ctx = capture.Put(ctx, r.Path.Command.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out *api.DrawCallState
+	if idx[0] >= uint64(len(c.Commands)) {
+		return out, nil
+	}
+	if _, ok := c.Commands[idx[0]].(*VkQueueSubmit); !ok {
+		return out, nil
+	}
+
+	dynamic := map[VkCommandBuffer]*dynamicViewport{}
+	s := c.NewState(ctx)
+	st := GetState(s)
+	err = mutateSubcommands(ctx, s, c.Commands[:idx[0]+1], nil, func(subIdx api.SubCmdIdx, ref *CommandReference) {
+		if subIdx[0] != idx[0] {
+			return
+		}
+		switch ref.Type {
+		case CommandType_cmd_vkCmdSetViewport, CommandType_cmd_vkCmdSetScissor:
+			setDynamicViewport(st, ref, dynamic)
+		case CommandType_cmd_vkCmdDraw,
+			CommandType_cmd_vkCmdDrawIndexed,
+			CommandType_cmd_vkCmdDrawIndirect,
+			CommandType_cmd_vkCmdDrawIndexedIndirect:
+			// Without a sub-command index, the last draw of the submission
+			// wins.
+			if len(idx) > 1 && !idx.Equals(subIdx) {
+				return
+			}
+			d := dynamic[ref.Buffer]
+			if d == nil {
+				d = &dynamicViewport{}
+			}
+			out = drawCallState(st, d)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// setDynamicViewport records the first viewport or scissor set by the
+// vkCmdSetViewport or vkCmdSetScissor command ref in dynamic.
+func setDynamicViewport(s *State, ref *CommandReference, dynamic map[VkCommandBuffer]*dynamicViewport) {
+	cb, ok := s.CommandBuffers.Lookup(ref.Buffer)
+	if !ok {
+		return
+	}
+	d := dynamic[ref.Buffer]
+	if d == nil {
+		d = &dynamicViewport{}
+		dynamic[ref.Buffer] = d
+	}
+	switch ref.Type {
+	case CommandType_cmd_vkCmdSetViewport:
+		args := cb.BufferCommands.VkCmdSetViewport.Get(ref.MapIndex)
+		if v, ok := args.Viewports.Lookup(0); ok && args.FirstViewport == 0 {
+			d.viewport = &v
+		}
+	case CommandType_cmd_vkCmdSetScissor:
+		args := cb.BufferCommands.VkCmdSetScissor.Get(ref.MapIndex)
+		if sc, ok := args.Scissors.Lookup(0); ok && args.FirstScissor == 0 {
+			d.scissor = &sc
+		}
+	}
+}
+
+// drawCallState returns the state of the draw just executed on the last
+// bound queue, or nil if there is none.
+func drawCallState(c *State, dynamic *dynamicViewport) *api.DrawCallState {
+	if c.LastBoundQueue == nil {
+		return nil
+	}
+	info, ok := c.LastDrawInfos.Lookup(c.LastBoundQueue.VulkanHandle)
+	if !ok || info.GraphicsPipeline == nil {
+		return nil
+	}
+	pipe := info.GraphicsPipeline
+	viewport, scissor := viewportAndScissor(pipe, dynamic)
+
+	out := &api.DrawCallState{
+		Program:          fmt.Sprintf("Pipeline<0x%x>", pipe.VulkanHandle),
+		VertexAttributes: vertexAttributes(pipe, info),
+		Textures:         textureBindings(c, info),
+		Blend:            blendAttachments(pipe),
+		DepthStencil:     depthStencilState(pipe, viewport),
+		Rasterizer:       rasterizerState(pipe, viewport, scissor),
+		Attachments:      attachmentBindings(info),
+	}
+	for _, i := range pipe.Stages.Keys() {
+		stage := pipe.Stages.Get(i)
+		shader := &api.ShaderStage{
+			Type:       shaderStageType(stage.Stage),
+			EntryPoint: stage.EntryPoint,
+		}
+		if stage.Module != nil {
+			shader.Shader = stage.Module.ResourceHandle()
+		}
+		out.Shaders = append(out.Shaders, shader)
+	}
+	if d := pipe.DynamicState; d != nil {
+		for _, i := range d.DynamicStates.Keys() {
+			out.DynamicState = append(out.DynamicState, d.DynamicStates.Get(i).String())
+		}
+	}
+	return out
+}
+
+// viewportAndScissor returns the first viewport and scissor used by a draw
+// with the pipeline: the dynamic ones if they are dynamic state of the
+// pipeline, otherwise the ones of the pipeline.
+func viewportAndScissor(pipe *GraphicsPipelineObject, dynamic *dynamicViewport) (*VkViewport, *VkRect2D) {
+	var viewport *VkViewport
+	var scissor *VkRect2D
+	if vs := pipe.ViewportState; vs != nil {
+		if v, ok := vs.Viewports.Lookup(0); ok {
+			viewport = &v
+		}
+		if sc, ok := vs.Scissors.Lookup(0); ok {
+			scissor = &sc
+		}
+	}
+	if d := pipe.DynamicState; d != nil {
+		for _, i := range d.DynamicStates.Keys() {
+			switch d.DynamicStates.Get(i) {
+			case VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT:
+				viewport = dynamic.viewport
+			case VkDynamicState_VK_DYNAMIC_STATE_SCISSOR:
+				scissor = dynamic.scissor
+			}
+		}
+	}
+	return viewport, scissor
+}
+
+// shaderStageType returns the api shader type of the shader stage.
+func shaderStageType(stage VkShaderStageFlagBits) api.ShaderType {
+	switch stage {
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_TESSELLATION_CONTROL_BIT:
+		return api.ShaderType_TessControl
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_TESSELLATION_EVALUATION_BIT:
+		return api.ShaderType_TessEvaluation
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_GEOMETRY_BIT:
+		return api.ShaderType_Geometry
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_FRAGMENT_BIT:
+		return api.ShaderType_Fragment
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_COMPUTE_BIT:
+		return api.ShaderType_Compute
+	default:
+		return api.ShaderType_Vertex
+	}
+}
+
+// vertexAttributes returns the vertex attributes of the pipeline, with the
+// vertex buffers bound for the draw if info is not nil.
+func vertexAttributes(pipe *GraphicsPipelineObject, info *DrawInfo) []*api.VertexAttribute {
+	out := []*api.VertexAttribute{}
+	vi := pipe.VertexInputState
+	for _, i := range vi.AttributeDescriptions.Keys() {
+		a := vi.AttributeDescriptions.Get(i)
+		attr := &api.VertexAttribute{
+			Location: a.Location,
+			Name:     fmt.Sprintf("binding=%v, location=%v", a.Binding, a.Location),
+			Enabled:  true,
+			Format:   a.Format.String(),
+			Binding:  a.Binding,
+			Offset:   uint64(a.Offset),
+		}
+		for _, j := range vi.BindingDescriptions.Keys() {
+			if b := vi.BindingDescriptions.Get(j); b.Binding == a.Binding {
+				attr.Stride = b.Stride
+				attr.PerInstance = b.InputRate == VkVertexInputRate_VK_VERTEX_INPUT_RATE_INSTANCE
+			}
+		}
+		if info == nil {
+			out = append(out, attr)
+			continue
+		}
+		if bound, ok := info.BoundVertexBuffers.Lookup(a.Binding); ok && bound.Buffer != nil {
+			attr.Buffer = fmt.Sprintf("Buffer<0x%x>", bound.Buffer.VulkanHandle)
+			attr.Offset += uint64(bound.Offset)
+		}
+		out = append(out, attr)
+	}
+	return out
+}
+
+// textureBindings returns the sampled images of the descriptor sets bound for
+// the draw, in set and binding order.
+func textureBindings(c *State, info *DrawInfo) []*api.TextureBinding {
+	out := []*api.TextureBinding{}
+	for _, set := range info.DescriptorSets.Keys() {
+		ds := info.DescriptorSets.Get(set)
+		if ds == nil {
+			continue
+		}
+		for _, binding := range ds.Bindings.Keys() {
+			b := ds.Bindings.Get(binding)
+			switch b.BindingType {
+			case VkDescriptorType_VK_DESCRIPTOR_TYPE_COMBINED_IMAGE_SAMPLER,
+				VkDescriptorType_VK_DESCRIPTOR_TYPE_SAMPLED_IMAGE:
+			default:
+				continue
+			}
+			for _, i := range b.ImageBinding.Keys() {
+				img := b.ImageBinding.Get(i)
+				if img == nil {
+					continue
+				}
+				view, ok := c.ImageViews.Lookup(img.ImageView)
+				if !ok {
+					continue
+				}
+				tb := &api.TextureBinding{
+					Set:    set,
+					Unit:   binding,
+					Target: view.Type.String(),
+					Format: view.Format.String(),
+				}
+				if view.Image != nil {
+					tb.Texture = view.Image.ResourceHandle()
+				}
+				if sampler, ok := c.Samplers.Lookup(img.Sampler); ok {
+					tb.Sampler = fmt.Sprintf("Sampler<0x%x>", sampler.VulkanHandle)
+					tb.Sampling = samplingParameters(sampler, view)
+				}
+				out = append(out, tb)
+			}
+		}
+	}
+	return out
+}
+
+// samplingParameters returns the sampling parameters of the sampler used with
+// the image view.
+func samplingParameters(s *SamplerObject, view *ImageViewObject) *api.SamplingParameters {
+	linear := func(f VkFilter) bool { return f == VkFilter_VK_FILTER_LINEAR }
+	minFilter := api.FilterMode_NearestMipmapNearest
+	switch mipLinear := s.MipMapMode == VkSamplerMipmapMode_VK_SAMPLER_MIPMAP_MODE_LINEAR; {
+	case linear(s.MinFilter) && mipLinear:
+		minFilter = api.FilterMode_LinearMipmapLinear
+	case linear(s.MinFilter):
+		minFilter = api.FilterMode_LinearMipmapNearest
+	case mipLinear:
+		minFilter = api.FilterMode_NearestMipmapLinear
+	}
+	magFilter := api.FilterMode_Nearest
+	if linear(s.MagFilter) {
+		magFilter = api.FilterMode_Linear
+	}
+	anisotropy := float32(1)
+	if s.AnisotropyEnable != 0 {
+		anisotropy = s.MaxAnisotropy
+	}
+	r := view.SubresourceRange
+	return &api.SamplingParameters{
+		MinFilter:     minFilter,
+		MagFilter:     magFilter,
+		WrapS:         wrapMode(s.AddressModeU),
+		WrapT:         wrapMode(s.AddressModeV),
+		WrapR:         wrapMode(s.AddressModeW),
+		MinLod:        s.MinLod,
+		MaxLod:        s.MaxLod,
+		BaseLevel:     int32(r.BaseMipLevel),
+		MaxLevel:      int32(r.BaseMipLevel+r.LevelCount) - 1,
+		MaxAnisotropy: anisotropy,
+	}
+}
+
+// wrapMode returns the api wrap mode of the sampler address mode.
+func wrapMode(m VkSamplerAddressMode) api.WrapMode {
+	switch m {
+	case VkSamplerAddressMode_VK_SAMPLER_ADDRESS_MODE_REPEAT:
+		return api.WrapMode_Repeat
+	case VkSamplerAddressMode_VK_SAMPLER_ADDRESS_MODE_MIRRORED_REPEAT:
+		return api.WrapMode_MirroredRepeat
+	case VkSamplerAddressMode_VK_SAMPLER_ADDRESS_MODE_CLAMP_TO_EDGE:
+		return api.WrapMode_ClampToEdge
+	case VkSamplerAddressMode_VK_SAMPLER_ADDRESS_MODE_CLAMP_TO_BORDER:
+		return api.WrapMode_ClampToBorder
+	default:
+		return api.WrapMode_UnknownWrap
+	}
+}
+
+// blendAttachments returns the blend state of each color attachment of the
+// pipeline.
+func blendAttachments(pipe *GraphicsPipelineObject) []*api.BlendAttachment {
+	out := []*api.BlendAttachment{}
+	if pipe.ColorBlendState == nil {
+		return out
+	}
+	atts := pipe.ColorBlendState.Attachments
+	for _, i := range atts.Keys() {
+		a := atts.Get(i)
+		mask := ""
+		for _, ch := range []struct {
+			bit  VkColorComponentFlagBits
+			name string
+		}{
+			{VkColorComponentFlagBits_VK_COLOR_COMPONENT_R_BIT, "R"},
+			{VkColorComponentFlagBits_VK_COLOR_COMPONENT_G_BIT, "G"},
+			{VkColorComponentFlagBits_VK_COLOR_COMPONENT_B_BIT, "B"},
+			{VkColorComponentFlagBits_VK_COLOR_COMPONENT_A_BIT, "A"},
+		} {
+			if VkColorComponentFlagBits(a.ColorWriteMask)&ch.bit != 0 {
+				mask += ch.name
+			}
+		}
+		out = append(out, &api.BlendAttachment{
+			Attachment: i,
+			Enabled:    a.BlendEnable != 0,
+			SrcColor:   a.SrcColorBlendFactor.String(),
+			DstColor:   a.DstColorBlendFactor.String(),
+			ColorOp:    a.ColorBlendOp.String(),
+			SrcAlpha:   a.SrcAlphaBlendFactor.String(),
+			DstAlpha:   a.DstAlphaBlendFactor.String(),
+			AlphaOp:    a.AlphaBlendOp.String(),
+			WriteMask:  mask,
+		})
+	}
+	return out
+}
+
+// depthStencilState returns the depth and stencil test state of the pipeline,
+// with the depth range of the viewport.
+func depthStencilState(pipe *GraphicsPipelineObject, viewport *VkViewport) *api.DepthStencilState {
+	d := pipe.DepthState
+	if d == nil {
+		return &api.DepthStencilState{}
+	}
+	face := func(s VkStencilOpState) *api.StencilFace {
+		return &api.StencilFace{
+			Func:        s.CompareOp.String(),
+			Reference:   s.Reference,
+			CompareMask: s.CompareMask,
+			WriteMask:   s.WriteMask,
+			FailOp:      s.FailOp.String(),
+			DepthFailOp: s.DepthFailOp.String(),
+			PassOp:      s.PassOp.String(),
+		}
+	}
+	out := &api.DepthStencilState{
+		DepthTest:   d.DepthTestEnable != 0,
+		DepthWrite:  d.DepthWriteEnable != 0,
+		DepthFunc:   d.DepthCompareOp.String(),
+		StencilTest: d.StencilTestEnable != 0,
+		Front:       face(d.Front),
+		Back:        face(d.Back),
+	}
+	if viewport != nil {
+		out.DepthNear, out.DepthFar = viewport.MinDepth, viewport.MaxDepth
+	}
+	return out
+}
+
+// rasterizerState returns the rasterization state of the pipeline, with the
+// viewport and scissor.
+func rasterizerState(pipe *GraphicsPipelineObject, viewport *VkViewport, scissor *VkRect2D) *api.RasterizerState {
+	r := pipe.RasterizationState
+	out := &api.RasterizerState{
+		Cull:                r.CullMode != 0,
+		CullFace:            fmt.Sprint(VkCullModeFlagBits(r.CullMode)),
+		FrontFace:           r.FrontFace.String(),
+		LineWidth:           r.LineWidth,
+		PolygonOffset:       r.DepthBiasEnable != 0,
+		PolygonOffsetFactor: r.DepthBiasSlopeFactor,
+		PolygonOffsetUnits:  r.DepthBiasConstantFactor,
+		RasterizerDiscard:   r.RasterizerDiscardEnable != 0,
+	}
+	if v := viewport; v != nil {
+		out.Viewport = &api.Viewport{X: v.X, Y: v.Y, Width: v.Width, Height: v.Height}
+	}
+	if sc := scissor; sc != nil {
+		out.Scissor = &api.Scissor{
+			Enabled: true,
+			X:       sc.Offset.X,
+			Y:       sc.Offset.Y,
+			Width:   sc.Extent.Width,
+			Height:  sc.Extent.Height,
+		}
+	}
+	return out
+}
+
+// attachmentBindings returns the attachments of the framebuffer used by the
+// subpass of the draw.
+func attachmentBindings(info *DrawInfo) []*api.AttachmentBinding {
+	out := []*api.AttachmentBinding{}
+	fb, rp := info.Framebuffer, info.RenderPass
+	if fb == nil || rp == nil {
+		return out
+	}
+	subpass, ok := rp.SubpassDescriptions.Lookup(info.LastSubpass)
+	if !ok {
+		return out
+	}
+	add := func(name string, ref VkAttachmentReference) {
+		view, ok := fb.ImageAttachments.Lookup(ref.Attachment)
+		if !ok || view == nil {
+			return
+		}
+		binding := &api.AttachmentBinding{
+			Attachment: name,
+			Width:      fb.Width,
+			Height:     fb.Height,
+			Format:     view.Format.String(),
+		}
+		if view.Image != nil {
+			binding.Resource = view.Image.ResourceHandle()
+		}
+		out = append(out, binding)
+	}
+	for _, i := range subpass.ColorAttachments.Keys() {
+		add(fmt.Sprintf("Color%d", i), subpass.ColorAttachments.Get(i))
+	}
+	if ds := subpass.DepthStencilAttachment; ds != nil {
+		add("DepthStencil", *ds)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+)
+
+func TestViewportAndScissor(t *testing.T) {
+	ctx := log.Testing(t)
+	static := &ViewportData{
+		ViewportCount: 1,
+		Viewports:     NewU32ːVkViewportᵐ().Add(0, VkViewport{Width: 64, Height: 32}),
+		ScissorCount:  1,
+		Scissors:      NewU32ːVkRect2Dᵐ().Add(0, VkRect2D{Extent: VkExtent2D{Width: 64, Height: 32}}),
+	}
+	dynamicStates := func(states ...VkDynamicState) *DynamicData {
+		d := &DynamicData{DynamicStates: NewU32ːVkDynamicStateᵐ()}
+		for i, s := range states {
+			d.DynamicStates.Add(uint32(i), s)
+		}
+		return d
+	}
+	viewport := VkViewport{Width: 16, Height: 8}
+	scissor := VkRect2D{Extent: VkExtent2D{Width: 16, Height: 8}}
+	set := &dynamicViewport{viewport: &viewport, scissor: &scissor}
+
+	for _, test := range []struct {
+		name     string
+		pipe     *GraphicsPipelineObject
+		dynamic  *dynamicViewport
+		viewport *VkViewport
+		scissor  *VkRect2D
+	}{
+		{"static", &GraphicsPipelineObject{ViewportState: static}, set,
+			&VkViewport{Width: 64, Height: 32}, &VkRect2D{Extent: VkExtent2D{Width: 64, Height: 32}}},
+		{"dynamic viewport", &GraphicsPipelineObject{
+			ViewportState: static,
+			DynamicState:  dynamicStates(VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT),
+		}, set, &viewport, &VkRect2D{Extent: VkExtent2D{Width: 64, Height: 32}}},
+		{"dynamic viewport and scissor", &GraphicsPipelineObject{
+			DynamicState: dynamicStates(VkDynamicState_VK_DYNAMIC_STATE_SCISSOR, VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT),
+		}, set, &viewport, &scissor},
+		{"dynamic but not set", &GraphicsPipelineObject{
+			ViewportState: static,
+			DynamicState:  dynamicStates(VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT, VkDynamicState_VK_DYNAMIC_STATE_SCISSOR),
+		}, &dynamicViewport{}, nil, nil},
+	} {
+		v, s := viewportAndScissor(test.pipe, test.dynamic)
+		assert.For(ctx, "%v viewport", test.name).That(v).DeepEquals(test.viewport)
+		assert.For(ctx, "%v scissor", test.name).That(s).DeepEquals(test.scissor)
+	}
+}
+
+func TestDrawCallStateWithoutQueue(t *testing.T) {
+	ctx := log.Testing(t)
+	assert.For(ctx, "state").That(drawCallState(&State{}, &dynamicViewport{})).IsNil()
+}
@@ -30,6 +30,7 @@ var emptyDefUseVars = []dependencygraph.DefUseVariable{}
 
 const vkWholeSize = uint64(0xFFFFFFFFFFFFFFFF)
 const vkAttachmentUnused = uint32(0xFFFFFFFF)
+const vkSubpassExternal = uint32(0xFFFFFFFF)
 const vkNullHandle = vkHandle(0x0)
 
 // Assume the value of a Vulkan handle is always unique
@@ -106,6 +107,9 @@ type vulkanMachine struct {
 	boundDescriptorSets           map[*boundDescriptorSet]struct{}
 	forwardPairedLabels           map[*forwardPairedLabel]struct{}
 	lastBoundFramebufferImageData map[api.CmdID][]dependencygraph.DefUseVariable
+	// sync is only recorded by the footprint builders made for the sync hazard
+	// detection, and is nil otherwise.
+	sync *footprintSyncInfo
 }
 
 func newVulkanMachine() *vulkanMachine {
@@ -398,6 +402,7 @@ type queueExecutionState struct {
 	currentCommand api.SubCmdIdx
 
 	framebuffer *FramebufferObject
+	renderPass  *RenderPassObject
 
 	lastSubmitID      api.CmdID
 	currentSubmitInfo *queueSubmitInfo
@@ -589,6 +594,7 @@ func (qei *queueExecutionState) beginRenderPass(ctx context.Context,
 	read(ctx, bh, vkHandle(rp.VulkanHandle))
 	read(ctx, bh, vkHandle(fb.VulkanHandle))
 	qei.framebuffer = fb
+	qei.renderPass = rp
 	qei.subpasses = []subpassInfo{}
 
 	// Record which subpass that loads or stores the attachments. A subpass loads
@@ -1205,6 +1211,76 @@ type FootprintBuilder struct {
 	swapchainImagePresented map[VkSwapchainKHR][]label
 }
 
+// footprintSyncInfo records the queues and the synchronization scopes of the
+// behaviors of the submitted commands, for the sync hazard detection.
+type footprintSyncInfo struct {
+	// The dependencies of the behaviors of pipeline barriers and event waits.
+	barriers map[*dependencygraph.Behavior]syncDependency
+	// The subpass dependencies from the commands before the render pass,
+	// applied before the behaviors beginning render passes.
+	dependenciesBefore map[*dependencygraph.Behavior][]syncDependency
+	// The subpass dependencies to the commands after the render pass, applied
+	// after the behaviors ending render passes.
+	dependenciesAfter map[*dependencygraph.Behavior][]syncDependency
+	// The semaphores waited on by the behaviors beginning queue submissions.
+	semaphoreWaits map[*dependencygraph.Behavior][]VkSemaphore
+	// The semaphores signalled by the behaviors ending queue submissions.
+	semaphoreSignals map[*dependencygraph.Behavior][]VkSemaphore
+	// The behaviors of the attachment loads, stores and clears of render
+	// passes.
+	renderPassOps map[*dependencygraph.Behavior]bool
+	// The data of the attachments drawn to by each draw behavior.
+	drawAttachments map[*dependencygraph.Behavior][]dependencygraph.DefUseVariable
+	// The render pass instance of the behaviors of render pass operations and
+	// draws, identified by the label of the beginning of the render pass.
+	renderPasses map[*dependencygraph.Behavior]*forwardPairedLabel
+	// The queue of each behavior of the submitted commands.
+	queues map[*dependencygraph.Behavior]VkQueue
+	// The access scope of each behavior of the submitted commands.
+	scopes map[*dependencygraph.Behavior]syncScope
+	// The access scope of each recorded command.
+	commandScopes map[*commandBufferCommand]syncScope
+	// The access scope of the command being recorded.
+	recording syncScope
+}
+
+func newFootprintSyncInfo() *footprintSyncInfo {
+	return &footprintSyncInfo{
+		barriers:           map[*dependencygraph.Behavior]syncDependency{},
+		dependenciesBefore: map[*dependencygraph.Behavior][]syncDependency{},
+		dependenciesAfter:  map[*dependencygraph.Behavior][]syncDependency{},
+		semaphoreWaits:     map[*dependencygraph.Behavior][]VkSemaphore{},
+		semaphoreSignals:   map[*dependencygraph.Behavior][]VkSemaphore{},
+		renderPassOps:      map[*dependencygraph.Behavior]bool{},
+		drawAttachments:    map[*dependencygraph.Behavior][]dependencygraph.DefUseVariable{},
+		renderPasses:       map[*dependencygraph.Behavior]*forwardPairedLabel{},
+		queues:             map[*dependencygraph.Behavior]VkQueue{},
+		scopes:             map[*dependencygraph.Behavior]syncScope{},
+		commandScopes:      map[*commandBufferCommand]syncScope{},
+	}
+}
+
+// markRenderPassOps records the behaviors added to ft from the index first as
+// attachment operations of the render pass instance rp, synchronized with the
+// commands outside of the render pass by the dependencies before and after.
+func (vb *FootprintBuilder) markRenderPassOps(ft *dependencygraph.Footprint, first int,
+	rp *forwardPairedLabel, before, after []syncDependency) {
+	sync := vb.machine.sync
+	if sync == nil {
+		return
+	}
+	for _, b := range ft.Behaviors[first:] {
+		sync.renderPassOps[b] = true
+		sync.renderPasses[b] = rp
+		if len(before) > 0 {
+			sync.dependenciesBefore[b] = before
+		}
+		if len(after) > 0 {
+			sync.dependenciesAfter[b] = after
+		}
+	}
+}
+
 // getImageData records a read operation of the Vulkan image handle, a read
 // operation of the image layout, a read operation of the image bindings, then
 // returns the underlying data.
@@ -1369,6 +1445,9 @@ func (vb *FootprintBuilder) newCommand(ctx context.Context,
 	read(ctx, bh, vb.commandBuffers[vkCb].begin)
 	write(ctx, bh, cbc)
 	vb.commands[vkCb] = append(vb.commands[vkCb], cbc)
+	if sync := vb.machine.sync; sync != nil {
+		sync.commandScopes[cbc] = sync.recording
+	}
 	return cbc
 }
 
@@ -1406,6 +1485,10 @@ func (vb *FootprintBuilder) rollOutExecuted(ctx context.Context,
 					modify(ctx, bh, vb.semaphoreSignals[sp])
 				}
 			}
+			if sync := vb.machine.sync; sync != nil {
+				sync.semaphoreWaits[bh] = submitinfo.waitSemaphores
+				sync.queues[bh] = submitinfo.queue
+			}
 			// write(ctx, bh, submitinfo.queued)
 			ft.AddBehavior(ctx, bh)
 			submitinfo.began = true
@@ -1415,7 +1498,14 @@ func (vb *FootprintBuilder) rollOutExecuted(ctx context.Context,
 			execInfo := vb.executionStates[submitinfo.queue]
 			execInfo.currentSubmitInfo = submitinfo
 			execInfo.updateCurrentCommand(ctx, executedFCI)
+			first := len(ft.Behaviors)
 			submittedCmd.runCommand(ctx, ft, vb.machine, execInfo)
+			if sync := vb.machine.sync; sync != nil {
+				for _, b := range ft.Behaviors[first:] {
+					sync.queues[b] = submitinfo.queue
+					sync.scopes[b] = sync.commandScopes[submittedCmd.cmd]
+				}
+			}
 		} else {
 			log.E(ctx, "FootprintBuilder: Execution order differs from submission order. "+
 				"Index of executed command: %v, Index of submitted command: %v",
@@ -1441,6 +1531,10 @@ func (vb *FootprintBuilder) rollOutExecuted(ctx context.Context,
 			if read(ctx, bh, vkHandle(submitinfo.signalFence)) {
 				write(ctx, bh, vb.fences[submitinfo.signalFence].signal)
 			}
+			if sync := vb.machine.sync; sync != nil {
+				sync.semaphoreSignals[bh] = submitinfo.signalSemaphores
+				sync.queues[bh] = submitinfo.queue
+			}
 			ft.AddBehavior(ctx, bh)
 		}
 	}
@@ -1485,6 +1579,10 @@ func (vb *FootprintBuilder) useBoundDescriptorSets(ctx context.Context,
 
 func (vb *FootprintBuilder) draw(ctx context.Context,
 	bh *dependencygraph.Behavior, execInfo *queueExecutionState) {
+	sync := vb.machine.sync
+	if sync != nil {
+		sync.renderPasses[bh] = execInfo.renderPassBegin
+	}
 	read(ctx, bh, execInfo.subpass)
 	read(ctx, bh, execInfo.currentCmdBufState.pipeline)
 	read(ctx, bh, execInfo.currentCmdBufState.dynamicState)
@@ -1505,10 +1603,16 @@ func (vb *FootprintBuilder) draw(ctx context.Context,
 	}
 	for _, color := range execInfo.subpasses[subpassI].colorAttachments {
 		modify(ctx, bh, color.data...)
+		if sync != nil {
+			sync.drawAttachments[bh] = append(sync.drawAttachments[bh], color.data...)
+		}
 	}
 	if execInfo.subpasses[subpassI].depthStencilAttachment != nil {
 		dsAtt := execInfo.subpasses[subpassI].depthStencilAttachment
 		modify(ctx, bh, dsAtt.data...)
+		if sync != nil {
+			sync.drawAttachments[bh] = append(sync.drawAttachments[bh], dsAtt.data...)
+		}
 	}
 }
 
@@ -1562,36 +1666,82 @@ func (vb *FootprintBuilder) recordBarriers(ctx context.Context,
 	imageBarrierCount uint32, pImageBarriers VkImageMemoryBarrierᶜᵖ,
 	attachedReads []dependencygraph.DefUseVariable) {
 	l := s.MemoryLayout
+	var srcStages, dstStages VkPipelineStageFlags
+	var memoryBarriers []VkMemoryBarrier
+	switch cmd := cmd.(type) {
+	case *VkCmdPipelineBarrier:
+		srcStages, dstStages = cmd.SrcStageMask, cmd.DstStageMask
+		memoryBarriers = cmd.PMemoryBarriers.Slice(0,
+			uint64(memoryBarrierCount), l).MustRead(ctx, cmd, s, nil)
+	case *VkCmdWaitEvents:
+		srcStages, dstStages = cmd.SrcStageMask, cmd.DstStageMask
+		memoryBarriers = cmd.PMemoryBarriers.Slice(0,
+			uint64(memoryBarrierCount), l).MustRead(ctx, cmd, s, nil)
+	}
+	bufferBarriers := pBufferBarriers.Slice(0,
+		uint64(bufferBarrierCount), l).MustRead(ctx, cmd, s, nil)
+	imageBarriers := pImageBarriers.Slice(0,
+		uint64(imageBarrierCount), l).MustRead(ctx, cmd, s, nil)
+	dependency := func(srcAccess, dstAccess VkAccessFlags) syncDependency {
+		return syncDependency{
+			src: syncScope{srcStages, srcAccess},
+			dst: syncScope{dstStages, dstAccess},
+		}
+	}
+
 	touchedData := []dependencygraph.DefUseVariable{}
+	dependencies := []syncDependency{}
+	touch := func(dep syncDependency, data ...dependencygraph.DefUseVariable) {
+		touchedData = append(touchedData, data...)
+		for range data {
+			dependencies = append(dependencies, dep)
+		}
+	}
 	if memoryBarrierCount > 0 {
 		// touch all buffer and image backing data
+		all := dependency(0, 0)
+		for _, barrier := range memoryBarriers {
+			all.src.access |= barrier.SrcAccessMask
+			all.dst.access |= barrier.DstAccessMask
+		}
+		for _, barrier := range bufferBarriers {
+			all.src.access |= barrier.SrcAccessMask
+			all.dst.access |= barrier.DstAccessMask
+		}
+		for _, barrier := range imageBarriers {
+			all.src.access |= barrier.SrcAccessMask
+			all.dst.access |= barrier.DstAccessMask
+		}
 		for i := range vb.images {
-			touchedData = append(touchedData, vb.getImageData(ctx, bh, i)...)
+			touch(all, vb.getImageData(ctx, bh, i)...)
 		}
 		for b := range vb.buffers {
-			touchedData = append(touchedData, vb.getBufferData(ctx, bh, b, 0, vkWholeSize)...)
+			touch(all, vb.getBufferData(ctx, bh, b, 0, vkWholeSize)...)
 		}
 	} else {
-		for _, barrier := range pBufferBarriers.Slice(0,
-			uint64(bufferBarrierCount), l).MustRead(ctx, cmd, s, nil) {
-			touchedData = append(touchedData, vb.getBufferData(ctx, bh, barrier.Buffer,
-				uint64(barrier.Offset), uint64(barrier.Size))...)
+		for _, barrier := range bufferBarriers {
+			touch(dependency(barrier.SrcAccessMask, barrier.DstAccessMask),
+				vb.getBufferData(ctx, bh, barrier.Buffer,
+					uint64(barrier.Offset), uint64(barrier.Size))...)
 		}
-		for _, barrier := range pImageBarriers.Slice(0,
-			uint64(imageBarrierCount), l).MustRead(ctx, cmd, s, nil) {
+		for _, barrier := range imageBarriers {
 			imgLayout, imgData := vb.getImageLayoutAndData(ctx, bh, barrier.Image)
-			touchedData = append(touchedData, imgLayout)
-			touchedData = append(touchedData, imgData...)
+			dep := dependency(barrier.SrcAccessMask, barrier.DstAccessMask)
+			touch(dep, imgLayout)
+			touch(dep, imgData...)
 		}
 	}
 	cbc := vb.newCommand(ctx, bh, vkCb)
 	cbc.behave = func(sc submittedCommand,
 		execInfo *queueExecutionState) {
-		for _, d := range touchedData {
+		for i, d := range touchedData {
 			cbh := sc.cmd.newBehavior(ctx, sc, vb.machine, execInfo)
 			read(ctx, cbh, attachedReads...)
 			modify(ctx, cbh, d)
 			ft.AddBehavior(ctx, cbh)
+			if sync := vb.machine.sync; sync != nil {
+				sync.barriers[cbh] = dependencies[i]
+			}
 		}
 	}
 }
@@ -1602,6 +1752,9 @@ func (vb *FootprintBuilder) BuildFootprint(ctx context.Context,
 	s *api.GlobalState, ft *dependencygraph.Footprint, id api.CmdID, cmd api.Cmd) {
 
 	l := s.MemoryLayout
+	if sync := vb.machine.sync; sync != nil {
+		sync.recording = accessScope(cmd)
+	}
 
 	// Records the mapping from queue submit to command ID, so the
 	// HandleSubcommand callback can use it.
@@ -2249,10 +2402,12 @@ func (vb *FootprintBuilder) BuildFootprint(ctx context.Context,
 		cbc := vb.newCommand(ctx, bh, cmd.CommandBuffer)
 		cbc.behave = func(sc submittedCommand,
 			execInfo *queueExecutionState) {
+			first := len(ft.Behaviors)
 			cbh := sc.cmd.newBehavior(ctx, sc, vb.machine, execInfo)
 			execInfo.beginRenderPass(ctx, vb, cbh, rp, fb)
 			execInfo.renderPassBegin = newForwardPairedLabel(ctx, cbh)
 			ft.AddBehavior(ctx, cbh)
+			vb.markRenderPassOps(ft, first, execInfo.renderPassBegin, externalDependencies(rp, true), nil)
 			cbh.Alive = true // TODO(awoloszyn)(BUG:1158): Investigate why this is needed.
 			// Without this, we drop some needed commands.
 		}
@@ -2261,9 +2416,11 @@ func (vb *FootprintBuilder) BuildFootprint(ctx context.Context,
 		cbc := vb.newCommand(ctx, bh, cmd.CommandBuffer)
 		cbc.behave = func(sc submittedCommand,
 			execInfo *queueExecutionState) {
+			first := len(ft.Behaviors)
 			cbh := sc.cmd.newBehavior(ctx, sc, vb.machine, execInfo)
 			execInfo.nextSubpass(ctx, ft, cbh, sc, vb.machine)
 			ft.AddBehavior(ctx, cbh)
+			vb.markRenderPassOps(ft, first, execInfo.renderPassBegin, nil, nil)
 			cbh.Alive = true // TODO(awoloszyn)(BUG:1158): Investigate why this is needed.
 			// Without this, we drop some needed commands.
 		}
@@ -2273,10 +2430,13 @@ func (vb *FootprintBuilder) BuildFootprint(ctx context.Context,
 		cbc := vb.newCommand(ctx, bh, cmd.CommandBuffer)
 		cbc.behave = func(sc submittedCommand,
 			execInfo *queueExecutionState) {
+			first := len(ft.Behaviors)
 			cbh := sc.cmd.newBehavior(ctx, sc, vb.machine, execInfo)
 			execInfo.endRenderPass(ctx, ft, cbh, sc, vb.machine)
 			read(ctx, cbh, execInfo.renderPassBegin)
 			ft.AddBehavior(ctx, cbh)
+			vb.markRenderPassOps(ft, first, execInfo.renderPassBegin,
+				nil, externalDependencies(execInfo.renderPass, false))
 			cbh.Alive = true // TODO(awoloszyn)(BUG:1158): Investigate why this is needed.
 			// Without this, we drop some needed commands.
 		}
@@ -2485,11 +2645,13 @@ func (vb *FootprintBuilder) BuildFootprint(ctx context.Context,
 		cbc := vb.newCommand(ctx, bh, cmd.CommandBuffer)
 		cbc.behave = func(sc submittedCommand,
 			execInfo *queueExecutionState) {
+			first := len(ft.Behaviors)
 			cbh := sc.cmd.newBehavior(ctx, sc, vb.machine, execInfo)
 			for _, a := range atts {
 				clearAttachmentData(ctx, cbh, execInfo, a, rects)
 			}
 			ft.AddBehavior(ctx, cbh)
+			vb.markRenderPassOps(ft, first, execInfo.renderPassBegin, nil, nil)
 		}
 
 	// query pool commands
@@ -2951,7 +3113,7 @@ func (vb *FootprintBuilder) writeCoherentMemoryData(ctx context.Context,
 
 // helper functions
 func debug(ctx context.Context, fmt string, args ...interface{}) {
-	if config.DebugDeadCodeElimination {
+	if config.Get(ctx).DebugDeadCodeElimination {
 		log.D(ctx, fmt, args...)
 	}
 }
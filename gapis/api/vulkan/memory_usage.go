@@ -0,0 +1,169 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/core/math/interval"
+	"github.com/google/gapid/gapis/api"
+)
+
+// EstimateMemoryUsage returns the images and buffers bound to device memory,
+// including their sparse bindings, and for each device memory allocation, the
+// bytes of the allocation that are not bound to any of them.
+func (API) EstimateMemoryUsage(ctx context.Context, s *api.GlobalState) []api.MemoryAllocation {
+	out := []api.MemoryAllocation{}
+	st := GetState(s)
+	u := newMemoryUsage(st.DeviceMemories.Get)
+	for _, h := range st.Images.Keys() {
+		i := st.Images.Get(h)
+		if size := u.image(i); size > 0 {
+			out = append(out, api.MemoryAllocation{
+				Category: "Image",
+				Name:     i.ResourceHandle(),
+				Size:     size,
+			})
+		}
+	}
+	for _, h := range st.Buffers.Keys() {
+		b := st.Buffers.Get(h)
+		if size := u.buffer(b); size > 0 {
+			out = append(out, api.MemoryAllocation{
+				Category: "Buffer",
+				Name:     fmt.Sprintf("Buffer<%d>", b.VulkanHandle),
+				Size:     size,
+			})
+		}
+	}
+	for _, h := range st.DeviceMemories.Keys() {
+		m := st.DeviceMemories.Get(h)
+		if unbound := uint64(m.AllocationSize) - u.used(m); unbound > 0 {
+			out = append(out, api.MemoryAllocation{
+				Category: "Unbound device memory",
+				Name:     fmt.Sprintf("DeviceMemory<%d>", h),
+				Size:     unbound,
+			})
+		}
+	}
+	return out
+}
+
+// memoryUsage counts the bytes of device memory bound to images and buffers.
+// Memory is counted by device memory range, so memory aliased by several
+// images or buffers is only counted once, for the first image or buffer bound
+// to it.
+type memoryUsage struct {
+	memory func(VkDeviceMemory) *DeviceMemoryObject
+	bound  map[*DeviceMemoryObject]*interval.U64SpanList
+}
+
+func newMemoryUsage(memory func(VkDeviceMemory) *DeviceMemoryObject) *memoryUsage {
+	return &memoryUsage{
+		memory: memory,
+		bound:  map[*DeviceMemoryObject]*interval.U64SpanList{},
+	}
+}
+
+// bind marks the range of m as bound, and returns the number of bytes of the
+// range that were not already bound.
+func (u *memoryUsage) bind(m *DeviceMemoryObject, offset, size uint64) uint64 {
+	if m == nil {
+		return 0
+	}
+	span := interval.U64Span{Start: offset, End: offset + size}
+	if end := uint64(m.AllocationSize); span.End > end {
+		span.End = end
+	}
+	if span.End <= span.Start {
+		return 0
+	}
+	l, ok := u.bound[m]
+	if !ok {
+		l = &interval.U64SpanList{}
+		u.bound[m] = l
+	}
+	count := span.End - span.Start
+	first, n := interval.Intersect(l, span)
+	for _, o := range (*l)[first : first+n] {
+		start, end := o.Start, o.End
+		if start < span.Start {
+			start = span.Start
+		}
+		if end > span.End {
+			end = span.End
+		}
+		count -= end - start
+	}
+	interval.Merge(l, span, true)
+	return count
+}
+
+// bindSparse marks the memory ranges of the sparse bindings as bound, and
+// returns the number of bytes of the ranges that were not already bound.
+func (u *memoryUsage) bindSparse(binds U64ːVkSparseMemoryBindᵐ) uint64 {
+	count := uint64(0)
+	for _, k := range binds.Keys() {
+		b := binds.Get(k)
+		count += u.bind(u.memory(b.Memory), uint64(b.MemoryOffset), uint64(b.Size))
+	}
+	return count
+}
+
+// image marks the memory bound to i as bound, and returns the number of bytes
+// that were not already bound.
+func (u *memoryUsage) image(i *ImageObject) uint64 {
+	count := uint64(0)
+	if i.BoundMemory != nil {
+		count += u.bind(i.BoundMemory, uint64(i.BoundMemoryOffset), uint64(i.MemoryRequirements.Size))
+	}
+	count += u.bindSparse(i.OpaqueSparseMemoryBindings)
+	for _, aspect := range i.SparseImageMemoryBindings.Keys() {
+		layers := i.SparseImageMemoryBindings.Get(aspect).Layers
+		for _, layer := range layers.Keys() {
+			levels := layers.Get(layer).Levels
+			for _, level := range levels.Keys() {
+				blocks := levels.Get(level).Blocks
+				for _, k := range blocks.Keys() {
+					b := blocks.Get(k)
+					count += u.bind(u.memory(b.Memory), uint64(b.MemoryOffset), uint64(b.Size))
+				}
+			}
+		}
+	}
+	return count
+}
+
+// buffer marks the memory bound to b as bound, and returns the number of bytes
+// that were not already bound.
+func (u *memoryUsage) buffer(b *BufferObject) uint64 {
+	count := uint64(0)
+	if b.Memory != nil {
+		count += u.bind(b.Memory, uint64(b.MemoryOffset), uint64(b.MemoryRequirements.Size))
+	}
+	return count + u.bindSparse(b.SparseMemoryBindings)
+}
+
+// used returns the number of bytes of m that are bound.
+func (u *memoryUsage) used(m *DeviceMemoryObject) uint64 {
+	count := uint64(0)
+	if l, ok := u.bound[m]; ok {
+		for _, span := range *l {
+			count += span.End - span.Start
+		}
+	}
+	return count
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	ctx := log.Testing(t)
+	memories := map[VkDeviceMemory]*DeviceMemoryObject{
+		1: {VulkanHandle: 1, AllocationSize: 1024},
+		2: {VulkanHandle: 2, AllocationSize: 512},
+	}
+	u := newMemoryUsage(func(h VkDeviceMemory) *DeviceMemoryObject { return memories[h] })
+	sparse := func(binds ...VkSparseMemoryBind) U64ːVkSparseMemoryBindᵐ {
+		m := NewU64ːVkSparseMemoryBindᵐ()
+		for _, b := range binds {
+			m.Add(uint64(b.ResourceOffset), b)
+		}
+		return m
+	}
+	blocks := func(blocks ...*SparseBoundImageBlockInfo) U32ːSparseBoundImageAspectInfoʳᵐ {
+		level := &SparseBoundImageLevelInfo{Blocks: NewU64ːSparseBoundImageBlockInfoʳᵐ()}
+		for _, b := range blocks {
+			level.Blocks.Add(uint64(b.MemoryOffset), b)
+		}
+		layer := &SparseBoundImageLayerInfo{Levels: NewU32ːSparseBoundImageLevelInfoʳᵐ().Add(0, level)}
+		aspect := &SparseBoundImageAspectInfo{Layers: NewU32ːSparseBoundImageLayerInfoʳᵐ().Add(0, layer)}
+		return NewU32ːSparseBoundImageAspectInfoʳᵐ().Add(uint32(VkImageAspectFlagBits_VK_IMAGE_ASPECT_COLOR_BIT), aspect)
+	}
+
+	for _, test := range []struct {
+		name     string
+		size     func() uint64
+		expected uint64
+	}{
+		{"bound buffer", func() uint64 {
+			return u.buffer(&BufferObject{
+				Memory:             memories[1],
+				MemoryRequirements: VkMemoryRequirements{Size: 256},
+			})
+		}, 256},
+		{"image with opaque sparse bindings", func() uint64 {
+			return u.image(&ImageObject{
+				OpaqueSparseMemoryBindings: sparse(
+					VkSparseMemoryBind{ResourceOffset: 0, Size: 256, Memory: 1, MemoryOffset: 128},
+					VkSparseMemoryBind{ResourceOffset: 256, Size: 128, Memory: 2},
+				),
+			})
+		}, 256},
+		{"image with sparse image bindings", func() uint64 {
+			return u.image(&ImageObject{
+				SparseImageMemoryBindings: blocks(
+					&SparseBoundImageBlockInfo{Memory: 2, MemoryOffset: 128, Size: 64},
+					&SparseBoundImageBlockInfo{Memory: 2, MemoryOffset: 192, Size: 64},
+				),
+			})
+		}, 128},
+		{"buffer with sparse bindings past the allocation", func() uint64 {
+			return u.buffer(&BufferObject{
+				SparseMemoryBindings: sparse(VkSparseMemoryBind{Size: 1024, Memory: 2}),
+			})
+		}, 256},
+		{"sparse bindings to unknown memory", func() uint64 {
+			return u.buffer(&BufferObject{
+				SparseMemoryBindings: sparse(VkSparseMemoryBind{Size: 1024, Memory: 3}),
+			})
+		}, 0},
+		{"unbound image", func() uint64 { return u.image(&ImageObject{}) }, 0},
+	} {
+		assert.For(ctx, "%v", test.name).That(test.size()).Equals(test.expected)
+	}
+	assert.For(ctx, "used memory 1").That(u.used(memories[1])).Equals(uint64(384))
+	assert.For(ctx, "used memory 2").That(u.used(memories[2])).Equals(uint64(512))
+}
@@ -0,0 +1,381 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+)
+
+// objectList accumulates the Vulkan objects created or destroyed by a
+// command. Objects are identified by their handle, as some objects, such as
+// descriptor pools, are replaced in the state when they are reset.
+type objectList []api.Object
+
+func (l *objectList) add(kind string, handle interface{}, value interface{}) {
+	*l = append(*l, api.Object{
+		Key:    handle,
+		Value:  value,
+		Kind:   kind,
+		Handle: fmt.Sprintf("%v<0x%x>", kind, handle),
+	})
+}
+
+// CreatedObjects implements the api.ObjectLifetimeTracker interface.
+func (API) CreatedObjects(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) []api.Object {
+	st := GetState(s)
+	l := s.MemoryLayout
+	out := objectList{}
+	switch cmd := cmd.(type) {
+	case *VkCreateInstance:
+		h := cmd.PInstance.MustRead(ctx, cmd, s, nil)
+		if o := st.Instances.Get(h); o != nil {
+			out.add("VkInstance", h, o)
+		}
+	case *VkCreateDevice:
+		h := cmd.PDevice.MustRead(ctx, cmd, s, nil)
+		if o := st.Devices.Get(h); o != nil {
+			out.add("VkDevice", h, o)
+		}
+	case *VkAllocateMemory:
+		h := cmd.PMemory.MustRead(ctx, cmd, s, nil)
+		if o := st.DeviceMemories.Get(h); o != nil {
+			out.add("VkDeviceMemory", h, o)
+		}
+	case *VkCreateBuffer:
+		h := cmd.PBuffer.MustRead(ctx, cmd, s, nil)
+		if o := st.Buffers.Get(h); o != nil {
+			out.add("VkBuffer", h, o)
+		}
+	case *VkCreateBufferView:
+		h := cmd.PView.MustRead(ctx, cmd, s, nil)
+		if o := st.BufferViews.Get(h); o != nil {
+			out.add("VkBufferView", h, o)
+		}
+	case *VkCreateImage:
+		h := cmd.PImage.MustRead(ctx, cmd, s, nil)
+		if o := st.Images.Get(h); o != nil {
+			out.add("VkImage", h, o)
+		}
+	case *VkGetSwapchainImagesKHR:
+		if (cmd.PSwapchainImages != VkImageᵖ{}) {
+			count := uint64(cmd.PSwapchainImageCount.MustRead(ctx, cmd, s, nil))
+			for _, h := range cmd.PSwapchainImages.Slice(0, count, l).MustRead(ctx, cmd, s, nil) {
+				if o := st.Images.Get(h); o != nil {
+					out.add("VkImage", h, o)
+				}
+			}
+		}
+	case *VkCreateImageView:
+		h := cmd.PView.MustRead(ctx, cmd, s, nil)
+		if o := st.ImageViews.Get(h); o != nil {
+			out.add("VkImageView", h, o)
+		}
+	case *VkCreateShaderModule:
+		h := cmd.PShaderModule.MustRead(ctx, cmd, s, nil)
+		if o := st.ShaderModules.Get(h); o != nil {
+			out.add("VkShaderModule", h, o)
+		}
+	case *VkCreateGraphicsPipelines:
+		for _, h := range cmd.PPipelines.Slice(0, uint64(cmd.CreateInfoCount), l).MustRead(ctx, cmd, s, nil) {
+			if o := st.GraphicsPipelines.Get(h); o != nil {
+				out.add("VkPipeline", h, o)
+			}
+		}
+	case *VkCreateComputePipelines:
+		for _, h := range cmd.PPipelines.Slice(0, uint64(cmd.CreateInfoCount), l).MustRead(ctx, cmd, s, nil) {
+			if o := st.ComputePipelines.Get(h); o != nil {
+				out.add("VkPipeline", h, o)
+			}
+		}
+	case *VkCreatePipelineLayout:
+		h := cmd.PPipelineLayout.MustRead(ctx, cmd, s, nil)
+		if o := st.PipelineLayouts.Get(h); o != nil {
+			out.add("VkPipelineLayout", h, o)
+		}
+	case *VkCreatePipelineCache:
+		h := cmd.PPipelineCache.MustRead(ctx, cmd, s, nil)
+		if o := st.PipelineCaches.Get(h); o != nil {
+			out.add("VkPipelineCache", h, o)
+		}
+	case *VkCreateSampler:
+		h := cmd.PSampler.MustRead(ctx, cmd, s, nil)
+		if o := st.Samplers.Get(h); o != nil {
+			out.add("VkSampler", h, o)
+		}
+	case *VkCreateDescriptorSetLayout:
+		h := cmd.PSetLayout.MustRead(ctx, cmd, s, nil)
+		if o := st.DescriptorSetLayouts.Get(h); o != nil {
+			out.add("VkDescriptorSetLayout", h, o)
+		}
+	case *VkCreateDescriptorPool:
+		h := cmd.PDescriptorPool.MustRead(ctx, cmd, s, nil)
+		if o := st.DescriptorPools.Get(h); o != nil {
+			out.add("VkDescriptorPool", h, o)
+		}
+	case *VkAllocateDescriptorSets:
+		count := uint64(cmd.PAllocateInfo.MustRead(ctx, cmd, s, nil).DescriptorSetCount)
+		for _, h := range cmd.PDescriptorSets.Slice(0, count, l).MustRead(ctx, cmd, s, nil) {
+			if o := st.DescriptorSets.Get(h); o != nil {
+				out.add("VkDescriptorSet", h, o)
+			}
+		}
+	case *VkCreateFence:
+		h := cmd.PFence.MustRead(ctx, cmd, s, nil)
+		if o := st.Fences.Get(h); o != nil {
+			out.add("VkFence", h, o)
+		}
+	case *VkCreateSemaphore:
+		h := cmd.PSemaphore.MustRead(ctx, cmd, s, nil)
+		if o := st.Semaphores.Get(h); o != nil {
+			out.add("VkSemaphore", h, o)
+		}
+	case *VkCreateEvent:
+		h := cmd.PEvent.MustRead(ctx, cmd, s, nil)
+		if o := st.Events.Get(h); o != nil {
+			out.add("VkEvent", h, o)
+		}
+	case *VkCreateQueryPool:
+		h := cmd.PQueryPool.MustRead(ctx, cmd, s, nil)
+		if o := st.QueryPools.Get(h); o != nil {
+			out.add("VkQueryPool", h, o)
+		}
+	case *VkCreateFramebuffer:
+		h := cmd.PFramebuffer.MustRead(ctx, cmd, s, nil)
+		if o := st.Framebuffers.Get(h); o != nil {
+			out.add("VkFramebuffer", h, o)
+		}
+	case *VkCreateRenderPass:
+		h := cmd.PRenderPass.MustRead(ctx, cmd, s, nil)
+		if o := st.RenderPasses.Get(h); o != nil {
+			out.add("VkRenderPass", h, o)
+		}
+	case *VkCreateCommandPool:
+		h := cmd.PCommandPool.MustRead(ctx, cmd, s, nil)
+		if o := st.CommandPools.Get(h); o != nil {
+			out.add("VkCommandPool", h, o)
+		}
+	case *VkAllocateCommandBuffers:
+		count := uint64(cmd.PAllocateInfo.MustRead(ctx, cmd, s, nil).CommandBufferCount)
+		for _, h := range cmd.PCommandBuffers.Slice(0, count, l).MustRead(ctx, cmd, s, nil) {
+			if o := st.CommandBuffers.Get(h); o != nil {
+				out.add("VkCommandBuffer", h, o)
+			}
+		}
+	case *VkCreateSwapchainKHR:
+		h := cmd.PSwapchain.MustRead(ctx, cmd, s, nil)
+		if o := st.Swapchains.Get(h); o != nil {
+			out.add("VkSwapchainKHR", h, o)
+		}
+	}
+	return out
+}
+
+// DestroyedObjects implements the api.ObjectLifetimeTracker interface.
+// Destroying a pool also destroys the objects allocated from it, and
+// destroying a swapchain destroys its images.
+func (API) DestroyedObjects(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) []api.Object {
+	st := GetState(s)
+	l := s.MemoryLayout
+	out := objectList{}
+	switch cmd := cmd.(type) {
+	case *VkDestroyInstance:
+		if o := st.Instances.Get(cmd.Instance); o != nil {
+			out.add("VkInstance", cmd.Instance, o)
+		}
+	case *VkDestroyDevice:
+		if o := st.Devices.Get(cmd.Device); o != nil {
+			out.add("VkDevice", cmd.Device, o)
+		}
+	case *VkFreeMemory:
+		if o := st.DeviceMemories.Get(cmd.Memory); o != nil {
+			out.add("VkDeviceMemory", cmd.Memory, o)
+		}
+	case *VkDestroyBuffer:
+		if o := st.Buffers.Get(cmd.Buffer); o != nil {
+			out.add("VkBuffer", cmd.Buffer, o)
+		}
+	case *VkDestroyBufferView:
+		if o := st.BufferViews.Get(cmd.BufferView); o != nil {
+			out.add("VkBufferView", cmd.BufferView, o)
+		}
+	case *VkDestroyImage:
+		if o := st.Images.Get(cmd.Image); o != nil {
+			out.add("VkImage", cmd.Image, o)
+		}
+	case *VkDestroyImageView:
+		if o := st.ImageViews.Get(cmd.ImageView); o != nil {
+			out.add("VkImageView", cmd.ImageView, o)
+		}
+	case *VkDestroyShaderModule:
+		if o := st.ShaderModules.Get(cmd.ShaderModule); o != nil {
+			out.add("VkShaderModule", cmd.ShaderModule, o)
+		}
+	case *VkDestroyPipeline:
+		if o := st.GraphicsPipelines.Get(cmd.Pipeline); o != nil {
+			out.add("VkPipeline", cmd.Pipeline, o)
+		} else if o := st.ComputePipelines.Get(cmd.Pipeline); o != nil {
+			out.add("VkPipeline", cmd.Pipeline, o)
+		}
+	case *VkDestroyPipelineLayout:
+		if o := st.PipelineLayouts.Get(cmd.PipelineLayout); o != nil {
+			out.add("VkPipelineLayout", cmd.PipelineLayout, o)
+		}
+	case *VkDestroyPipelineCache:
+		if o := st.PipelineCaches.Get(cmd.PipelineCache); o != nil {
+			out.add("VkPipelineCache", cmd.PipelineCache, o)
+		}
+	case *VkDestroySampler:
+		if o := st.Samplers.Get(cmd.Sampler); o != nil {
+			out.add("VkSampler", cmd.Sampler, o)
+		}
+	case *VkDestroyDescriptorSetLayout:
+		if o := st.DescriptorSetLayouts.Get(cmd.DescriptorSetLayout); o != nil {
+			out.add("VkDescriptorSetLayout", cmd.DescriptorSetLayout, o)
+		}
+	case *VkDestroyDescriptorPool:
+		if o := st.DescriptorPools.Get(cmd.DescriptorPool); o != nil {
+			out.addDescriptorSets(o)
+			out.add("VkDescriptorPool", cmd.DescriptorPool, o)
+		}
+	case *VkResetDescriptorPool:
+		if o := st.DescriptorPools.Get(cmd.DescriptorPool); o != nil {
+			out.addDescriptorSets(o)
+		}
+	case *VkFreeDescriptorSets:
+		for _, h := range cmd.PDescriptorSets.Slice(0, uint64(cmd.DescriptorSetCount), l).MustRead(ctx, cmd, s, nil) {
+			if o := st.DescriptorSets.Get(h); o != nil {
+				out.add("VkDescriptorSet", h, o)
+			}
+		}
+	case *VkDestroyFence:
+		if o := st.Fences.Get(cmd.Fence); o != nil {
+			out.add("VkFence", cmd.Fence, o)
+		}
+	case *VkDestroySemaphore:
+		if o := st.Semaphores.Get(cmd.Semaphore); o != nil {
+			out.add("VkSemaphore", cmd.Semaphore, o)
+		}
+	case *VkDestroyEvent:
+		if o := st.Events.Get(cmd.Event); o != nil {
+			out.add("VkEvent", cmd.Event, o)
+		}
+	case *VkDestroyQueryPool:
+		if o := st.QueryPools.Get(cmd.QueryPool); o != nil {
+			out.add("VkQueryPool", cmd.QueryPool, o)
+		}
+	case *VkDestroyFramebuffer:
+		if o := st.Framebuffers.Get(cmd.Framebuffer); o != nil {
+			out.add("VkFramebuffer", cmd.Framebuffer, o)
+		}
+	case *VkDestroyRenderPass:
+		if o := st.RenderPasses.Get(cmd.RenderPass); o != nil {
+			out.add("VkRenderPass", cmd.RenderPass, o)
+		}
+	case *VkDestroyCommandPool:
+		if o := st.CommandPools.Get(cmd.CommandPool); o != nil {
+			for _, h := range o.CommandBuffers.Keys() {
+				out.add("VkCommandBuffer", h, o.CommandBuffers.Get(h))
+			}
+			out.add("VkCommandPool", cmd.CommandPool, o)
+		}
+	case *VkFreeCommandBuffers:
+		for _, h := range cmd.PCommandBuffers.Slice(0, uint64(cmd.CommandBufferCount), l).MustRead(ctx, cmd, s, nil) {
+			if o := st.CommandBuffers.Get(h); o != nil {
+				out.add("VkCommandBuffer", h, o)
+			}
+		}
+	case *VkDestroySwapchainKHR:
+		if o := st.Swapchains.Get(cmd.Swapchain); o != nil {
+			for _, i := range o.SwapchainImages.Keys() {
+				img := o.SwapchainImages.Get(i)
+				out.add("VkImage", img.VulkanHandle, img)
+			}
+			out.add("VkSwapchainKHR", cmd.Swapchain, o)
+		}
+	}
+	return out
+}
+
+// addDescriptorSets adds the descriptor sets allocated from the pool p.
+func (l *objectList) addDescriptorSets(p *DescriptorPoolObject) {
+	for _, h := range p.DescriptorSets.Keys() {
+		l.add("VkDescriptorSet", h, p.DescriptorSets.Get(h))
+	}
+}
+
+// TrackDrawnResources implements the api.DrawnResourceTracker interface.
+// The resources used by a draw are its graphics pipeline and the shader
+// modules of the pipeline, the images of its framebuffer, and the images of
+// the descriptor sets bound for it.
+func (API) TrackDrawnResources(ctx context.Context, s *api.GlobalState, cb func(api.Resource)) {
+	st := GetState(s)
+	next := st.PostSubcommand
+	st.PostSubcommand = func(a interface{}) {
+		if next != nil {
+			next(a)
+		}
+		switch a.(*CommandReference).Type {
+		case CommandType_cmd_vkCmdDraw,
+			CommandType_cmd_vkCmdDrawIndexed,
+			CommandType_cmd_vkCmdDrawIndirect,
+			CommandType_cmd_vkCmdDrawIndexedIndirect:
+		default:
+			return
+		}
+		if st.LastBoundQueue == nil {
+			return
+		}
+		info, ok := st.LastDrawInfos.Lookup(st.LastBoundQueue.VulkanHandle)
+		if !ok {
+			return
+		}
+		if p := info.GraphicsPipeline; p != nil {
+			cb(p)
+			for _, i := range p.Stages.Keys() {
+				if m := p.Stages.Get(i).Module; m != nil {
+					cb(m)
+				}
+			}
+		}
+		if fb := info.Framebuffer; fb != nil {
+			for _, i := range fb.ImageAttachments.Keys() {
+				if view := fb.ImageAttachments.Get(i); view != nil && view.Image != nil {
+					cb(view.Image)
+				}
+			}
+		}
+		for _, i := range info.DescriptorSets.Keys() {
+			set := info.DescriptorSets.Get(i)
+			if set == nil {
+				continue
+			}
+			for _, b := range set.Bindings.Keys() {
+				images := set.Bindings.Get(b).ImageBinding
+				for _, j := range images.Keys() {
+					img := images.Get(j)
+					if img == nil {
+						continue
+					}
+					if view, ok := st.ImageViews.Lookup(img.ImageView); ok && view.Image != nil {
+						cb(view.Image)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// RenderPassInstance is an execution of a render pass by a submitted command
+// buffer.
+type RenderPassInstance struct {
+	// Command is the path to the vkCmdBeginRenderPass of the instance.
+	Command     *path.Command
+	RenderPass  VkRenderPass
+	Framebuffer VkFramebuffer
+	Attachments []*RenderPassAttachment
+}
+
+// RenderPassAttachment is an attachment of a render pass instance, with its
+// load and store operations.
+type RenderPassAttachment struct {
+	Index          uint32
+	Image          VkImage
+	Format         VkFormat
+	Samples        VkSampleCountFlagBits
+	Width          uint32
+	Height         uint32
+	LoadOp         VkAttachmentLoadOp
+	StoreOp        VkAttachmentStoreOp
+	StencilLoadOp  VkAttachmentLoadOp
+	StencilStoreOp VkAttachmentStoreOp
+	// Waste lists the reasons for which the load and store operations look
+	// wasteful given the other accesses to the image.
+	Waste []api.RenderPassWaste
+}
+
+// load returns true if the attachment loads its contents.
+func (a *RenderPassAttachment) load() bool {
+	return a.LoadOp.isLoad() || (hasStencil(a.Format) && a.StencilLoadOp.isLoad())
+}
+
+// store returns true if the attachment stores its contents.
+func (a *RenderPassAttachment) store() bool {
+	return a.StoreOp.isStore() || (hasStencil(a.Format) && a.StencilStoreOp.isStore())
+}
+
+func hasStencil(f VkFormat) bool {
+	switch f {
+	case VkFormat_VK_FORMAT_S8_UINT,
+		VkFormat_VK_FORMAT_D16_UNORM_S8_UINT,
+		VkFormat_VK_FORMAT_D24_UNORM_S8_UINT,
+		VkFormat_VK_FORMAT_D32_SFLOAT_S8_UINT:
+		return true
+	}
+	return false
+}
+
+// RenderPassInstances implements the api.RenderPassInstancesProvider
+// interface.
+func (API) RenderPassInstances(ctx context.Context, p *path.Capture) (*api.RenderPassInstances, error) {
+	obj, err := database.Build(ctx, &RenderPassInstancesResolvable{Capture: p})
+	if err != nil {
+		return nil, err
+	}
+	out := &api.RenderPassInstances{}
+	for _, i := range obj.([]*RenderPassInstance) {
+		instance := &api.RenderPassInstance{
+			Command:     i.Command,
+			RenderPass:  fmt.Sprintf("RenderPass<0x%x>", i.RenderPass),
+			Framebuffer: fmt.Sprintf("Framebuffer<0x%x>", i.Framebuffer),
+		}
+		for _, a := range i.Attachments {
+			attachment := &api.RenderPassAttachment{
+				Index:          a.Index,
+				Format:         a.Format.String(),
+				Samples:        a.Samples.String(),
+				Width:          a.Width,
+				Height:         a.Height,
+				LoadOp:         a.LoadOp.String(),
+				StoreOp:        a.StoreOp.String(),
+				StencilLoadOp:  a.StencilLoadOp.String(),
+				StencilStoreOp: a.StencilStoreOp.String(),
+				Waste:          a.Waste,
+			}
+			if a.Image != 0 {
+				attachment.Image = fmt.Sprintf("Image<0x%x>", a.Image)
+			}
+			instance.Attachments = append(instance.Attachments, attachment)
+		}
+		out.Instances = append(out.Instances, instance)
+	}
+	return out, nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The accesses to the attachment images are tracked through the execution of
+// the submitted commands. Sampling an image, copying from it, loading it in a
+// render pass or presenting it reads its contents, while clearing it, copying,
+// blitting or resolving to it, or rendering to it without loading it
+// overwrites them.
+func (r *RenderPassInstancesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &renderPassTracker{
+		pending:  map[VkImage]*RenderPassAttachment{},
+		cleared:  map[VkImage]bool{},
+		bindings: descriptorSetBindings{},
+	}
+	s := c.NewState(ctx)
+	st := GetState(s)
+	err = mutateSubcommands(ctx, s, c.Commands, func(id api.CmdID, cmd api.Cmd) {
+		present, ok := cmd.(*VkQueuePresentKHR)
+		if !ok {
+			return
+		}
+		l := s.MemoryLayout
+		info := present.PPresentInfo.MustRead(ctx, present, s, nil)
+		count := uint64(info.SwapchainCount)
+		indices := info.PImageIndices.Slice(0, count, l).MustRead(ctx, present, s, nil)
+		for i, sw := range info.PSwapchains.Slice(0, count, l).MustRead(ctx, present, s, nil) {
+			if o, ok := st.Swapchains.Lookup(sw); ok {
+				if img := o.SwapchainImages.Get(indices[i]); img != nil {
+					t.read(img.VulkanHandle)
+				}
+			}
+		}
+	}, func(idx api.SubCmdIdx, ref *CommandReference) {
+		t.subcommand(st, ref, GetCommandArgs(ctx, ref, st), r.Capture.Command(idx[0], idx[1:]...))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range t.pending {
+		a.Waste = append(a.Waste, api.RenderPassWaste_StoreNeverRead)
+	}
+	return t.instances, nil
+}
+
+// renderPassTracker tracks the accesses to the images used as attachments.
+type renderPassTracker struct {
+	instances []*RenderPassInstance
+	// current is the render pass instance being executed, and subpass its
+	// current subpass.
+	current *RenderPassInstance
+	subpass uint32
+	// drawn is true if the current instance has drawn.
+	drawn bool
+	// pending holds the attachment that stored the contents of each image,
+	// while they are not read.
+	pending map[VkImage]*RenderPassAttachment
+	// cleared holds the images whose last access was a clear command.
+	cleared map[VkImage]bool
+	// bindings holds the descriptor sets bound by the command buffers.
+	bindings descriptorSetBindings
+}
+
+func (t *renderPassTracker) read(img VkImage) {
+	delete(t.pending, img)
+	delete(t.cleared, img)
+}
+
+func (t *renderPassTracker) overwrite(img VkImage, clear bool) {
+	if a, ok := t.pending[img]; ok {
+		a.Waste = append(a.Waste, api.RenderPassWaste_StoreOverwritten)
+		delete(t.pending, img)
+	}
+	if clear {
+		t.cleared[img] = true
+	} else {
+		delete(t.cleared, img)
+	}
+}
+
+// subcommand processes the command buffer command ref with the arguments args
+// once it has been executed.
+func (t *renderPassTracker) subcommand(s *State, ref *CommandReference, args interface{}, p *path.Command) {
+	switch args := args.(type) {
+	case *VkCmdBeginRenderPassArgs:
+		t.beginRenderPass(s, args, p)
+	case *VkCmdNextSubpassArgs:
+		t.subpass++
+	case *VkCmdEndRenderPassArgs:
+		t.current = nil
+	case *VkCmdBindDescriptorSetsArgs:
+		t.bindings.bindArgs(s, ref.Buffer, args)
+	case *VkCmdClearAttachmentsArgs:
+		t.clearAttachments(s, args)
+	case *VkCmdClearColorImageArgs:
+		t.overwrite(args.Image, true)
+	case *VkCmdClearDepthStencilImageArgs:
+		t.overwrite(args.Image, true)
+	case *VkCmdCopyImageArgs:
+		t.read(args.SrcImage)
+		t.overwrite(args.DstImage, false)
+	case *VkCmdBlitImageArgs:
+		t.read(args.SrcImage)
+		t.overwrite(args.DstImage, false)
+	case *VkCmdResolveImageArgs:
+		t.read(args.SrcImage)
+		t.overwrite(args.DstImage, false)
+	case *VkCmdCopyBufferToImageArgs:
+		t.overwrite(args.DstImage, false)
+	case *VkCmdCopyImageToBufferArgs:
+		t.read(args.SrcImage)
+	}
+
+	switch ref.Type {
+	case CommandType_cmd_vkCmdDraw,
+		CommandType_cmd_vkCmdDrawIndexed,
+		CommandType_cmd_vkCmdDrawIndirect,
+		CommandType_cmd_vkCmdDrawIndexedIndirect,
+		CommandType_cmd_vkCmdDispatch,
+		CommandType_cmd_vkCmdDispatchIndirect:
+		if t.current != nil {
+			t.drawn = true
+		}
+		// Conservatively consider every image bound to a descriptor as read.
+		for _, bound := range t.bindings.sets(ref) {
+			ds, ok := s.DescriptorSets.Lookup(bound.set)
+			if !ok {
+				continue
+			}
+			for _, binding := range ds.Bindings.Keys() {
+				images := ds.Bindings.Get(binding).ImageBinding
+				for _, i := range images.Keys() {
+					img := images.Get(i)
+					if img == nil {
+						continue
+					}
+					if view, ok := s.ImageViews.Lookup(img.ImageView); ok && view.Image != nil {
+						t.read(view.Image.VulkanHandle)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (t *renderPassTracker) beginRenderPass(s *State, args *VkCmdBeginRenderPassArgs, p *path.Command) {
+	rp, ok := s.RenderPasses.Lookup(args.RenderPass)
+	if !ok {
+		return
+	}
+	fb, ok := s.Framebuffers.Lookup(args.Framebuffer)
+	if !ok {
+		return
+	}
+	instance := &RenderPassInstance{
+		Command:     p,
+		RenderPass:  args.RenderPass,
+		Framebuffer: args.Framebuffer,
+	}
+	t.instances = append(t.instances, instance)
+	t.current, t.subpass, t.drawn = instance, 0, false
+
+	colors, resolved := map[uint32]bool{}, map[uint32]bool{}
+	for _, i := range rp.SubpassDescriptions.Keys() {
+		subpass := rp.SubpassDescriptions.Get(i)
+		for _, j := range subpass.ColorAttachments.Keys() {
+			colors[subpass.ColorAttachments.Get(j).Attachment] = true
+		}
+		for _, j := range subpass.ResolveAttachments.Keys() {
+			if subpass.ResolveAttachments.Get(j).Attachment != vkAttachmentUnused {
+				resolved[subpass.ColorAttachments.Get(j).Attachment] = true
+			}
+		}
+	}
+
+	for _, i := range rp.AttachmentDescriptions.Keys() {
+		desc := rp.AttachmentDescriptions.Get(i)
+		a := &RenderPassAttachment{
+			Index:          i,
+			Format:         desc.Format,
+			Samples:        desc.Samples,
+			Width:          fb.Width,
+			Height:         fb.Height,
+			LoadOp:         desc.LoadOp,
+			StoreOp:        desc.StoreOp,
+			StencilLoadOp:  desc.StencilLoadOp,
+			StencilStoreOp: desc.StencilStoreOp,
+		}
+		instance.Attachments = append(instance.Attachments, a)
+		view, ok := fb.ImageAttachments.Lookup(i)
+		if !ok || view == nil || view.Image == nil {
+			continue
+		}
+		a.Image = view.Image.VulkanHandle
+		t.beginAttachment(a, desc.InitialLayout, colors[i], resolved[i])
+	}
+}
+
+// beginAttachment processes the loading and storing of the attachment a of a
+// beginning render pass instance. color is true if a subpass uses the
+// attachment as a color attachment, and resolved if a subpass resolves it.
+// Only the color attachments can be resolved.
+func (t *renderPassTracker) beginAttachment(a *RenderPassAttachment, initialLayout VkImageLayout, color, resolved bool) {
+	if a.Samples != VkSampleCountFlagBits_VK_SAMPLE_COUNT_1_BIT && color && !resolved {
+		a.Waste = append(a.Waste, api.RenderPassWaste_MultisampleUnresolved)
+	}
+	if a.load() {
+		if initialLayout == VkImageLayout_VK_IMAGE_LAYOUT_UNDEFINED {
+			a.Waste = append(a.Waste, api.RenderPassWaste_LoadUndefined)
+		} else if t.cleared[a.Image] {
+			a.Waste = append(a.Waste, api.RenderPassWaste_LoadCleared)
+		}
+		t.read(a.Image)
+	}
+	t.overwrite(a.Image, false)
+	if a.store() {
+		t.pending[a.Image] = a
+	}
+}
+
+// clearAttachments flags the loaded attachments of the current instance
+// cleared over the whole render area before any draw.
+func (t *renderPassTracker) clearAttachments(s *State, args *VkCmdClearAttachmentsArgs) {
+	if t.current == nil || t.drawn {
+		return
+	}
+	rp, ok := s.RenderPasses.Lookup(t.current.RenderPass)
+	if !ok {
+		return
+	}
+	subpass, ok := rp.SubpassDescriptions.Lookup(t.subpass)
+	if !ok {
+		return
+	}
+	full := false
+	for _, i := range args.Rects.Keys() {
+		rect := args.Rects.Get(i).Rect
+		a := t.current.Attachments
+		if len(a) > 0 && rect.Offset.X <= 0 && rect.Offset.Y <= 0 &&
+			int64(rect.Offset.X)+int64(rect.Extent.Width) >= int64(a[0].Width) &&
+			int64(rect.Offset.Y)+int64(rect.Extent.Height) >= int64(a[0].Height) {
+			full = true
+		}
+	}
+	if !full {
+		return
+	}
+	for _, i := range args.Attachments.Keys() {
+		ca := args.Attachments.Get(i)
+		index := vkAttachmentUnused
+		if VkImageAspectFlagBits(ca.AspectMask)&VkImageAspectFlagBits_VK_IMAGE_ASPECT_COLOR_BIT != 0 {
+			if ref, ok := subpass.ColorAttachments.Lookup(ca.ColorAttachment); ok {
+				index = ref.Attachment
+			}
+		} else if ref := subpass.DepthStencilAttachment; ref != nil {
+			index = ref.Attachment
+		}
+		for _, a := range t.current.Attachments {
+			if a.Index == index && a.load() {
+				a.Waste = append(a.Waste, api.RenderPassWaste_LoadFullyCleared)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+)
+
+func newRenderPassTracker() *renderPassTracker {
+	return &renderPassTracker{
+		pending:  map[VkImage]*RenderPassAttachment{},
+		cleared:  map[VkImage]bool{},
+		bindings: descriptorSetBindings{},
+	}
+}
+
+func newAttachment(img VkImage, samples VkSampleCountFlagBits, load VkAttachmentLoadOp, store VkAttachmentStoreOp) *RenderPassAttachment {
+	return &RenderPassAttachment{
+		Image:          img,
+		Format:         VkFormat_VK_FORMAT_R8G8B8A8_UNORM,
+		Samples:        samples,
+		LoadOp:         load,
+		StoreOp:        store,
+		StencilLoadOp:  VkAttachmentLoadOp_VK_ATTACHMENT_LOAD_OP_DONT_CARE,
+		StencilStoreOp: VkAttachmentStoreOp_VK_ATTACHMENT_STORE_OP_DONT_CARE,
+	}
+}
+
+func TestRenderPassWaste(t *testing.T) {
+	ctx := log.Testing(t)
+	const (
+		image     = VkImage(1)
+		other     = VkImage(2)
+		single    = VkSampleCountFlagBits_VK_SAMPLE_COUNT_1_BIT
+		multi     = VkSampleCountFlagBits_VK_SAMPLE_COUNT_4_BIT
+		opLoad    = VkAttachmentLoadOp_VK_ATTACHMENT_LOAD_OP_LOAD
+		opClear   = VkAttachmentLoadOp_VK_ATTACHMENT_LOAD_OP_CLEAR
+		opStore   = VkAttachmentStoreOp_VK_ATTACHMENT_STORE_OP_STORE
+		opDiscard = VkAttachmentStoreOp_VK_ATTACHMENT_STORE_OP_DONT_CARE
+		general   = VkImageLayout_VK_IMAGE_LAYOUT_GENERAL
+	)
+	copyImage := &CommandReference{Type: CommandType_cmd_vkCmdCopyImage}
+	blitImage := &CommandReference{Type: CommandType_cmd_vkCmdBlitImage}
+
+	for _, test := range []struct {
+		name     string
+		run      func(tr *renderPassTracker, a *RenderPassAttachment)
+		expected []api.RenderPassWaste
+	}{
+		{"stored then read", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			tr.beginAttachment(a, general, true, false)
+			tr.subcommand(nil, copyImage, &VkCmdCopyImageArgs{SrcImage: image, DstImage: other}, nil)
+		}, nil},
+		{"stored then copied to", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			tr.beginAttachment(a, general, true, false)
+			tr.subcommand(nil, copyImage, &VkCmdCopyImageArgs{SrcImage: other, DstImage: image}, nil)
+		}, []api.RenderPassWaste{api.RenderPassWaste_StoreOverwritten}},
+		{"stored then blitted to", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			tr.beginAttachment(a, general, true, false)
+			tr.subcommand(nil, blitImage, &VkCmdBlitImageArgs{SrcImage: other, DstImage: image}, nil)
+		}, []api.RenderPassWaste{api.RenderPassWaste_StoreOverwritten}},
+		{"loaded after a copy", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			tr.subcommand(nil, copyImage, &VkCmdCopyImageArgs{SrcImage: other, DstImage: image}, nil)
+			a.LoadOp = opLoad
+			tr.beginAttachment(a, general, true, false)
+			tr.read(image)
+		}, nil},
+		{"loaded from undefined", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			a.LoadOp = opLoad
+			tr.beginAttachment(a, VkImageLayout_VK_IMAGE_LAYOUT_UNDEFINED, true, false)
+			tr.read(image)
+		}, []api.RenderPassWaste{api.RenderPassWaste_LoadUndefined}},
+		{"multisampled and resolved", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			a.Samples, a.StoreOp = multi, opDiscard
+			tr.beginAttachment(a, general, true, true)
+		}, nil},
+		{"multisampled, discarded and not resolved", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			a.Samples, a.StoreOp = multi, opDiscard
+			tr.beginAttachment(a, general, true, false)
+		}, []api.RenderPassWaste{api.RenderPassWaste_MultisampleUnresolved}},
+		{"multisampled depth not resolved", func(tr *renderPassTracker, a *RenderPassAttachment) {
+			a.Format, a.Samples, a.StoreOp = VkFormat_VK_FORMAT_D24_UNORM_S8_UINT, multi, opDiscard
+			tr.beginAttachment(a, general, false, false)
+		}, nil},
+	} {
+		tracker := newRenderPassTracker()
+		a := newAttachment(image, single, opClear, opStore)
+		test.run(tracker, a)
+		assert.For(ctx, "%v", test.name).ThatSlice(a.Waste).Equals(test.expected)
+	}
+}
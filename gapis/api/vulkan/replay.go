@@ -476,7 +476,7 @@ func (a API) Replay(
 		return log.Errf(ctx, nil, "Cannot replay Vulkan commands on device '%v'", device.Name)
 	}
 
-	optimize := !config.DisableDeadCodeElimination
+	optimize := !config.Get(ctx).DisableDeadCodeElimination
 
 	cmds := capture.Commands
 
@@ -615,14 +615,14 @@ func (a API) Replay(
 	transforms.Add(readFramebuffer, injector)
 	transforms.Add(&destroyResourcesAtEOS{})
 
-	if config.DebugReplay {
+	if config.Get(ctx).DebugReplay {
 		log.I(ctx, "Replaying %d commands using transform chain:", len(cmds))
 		for i, t := range transforms {
 			log.I(ctx, "(%d) %#v", i, t)
 		}
 	}
 
-	if config.LogTransformsToFile {
+	if config.Get(ctx).LogTransformsToFile {
 		newTransforms := transform.Transforms{}
 		newTransforms.Add(transform.NewFileLog(ctx, "0_original_cmds"))
 		for i, t := range transforms {
@@ -30,6 +30,7 @@ import (
 	"github.com/google/gapid/gapis/service"
 	"github.com/google/gapid/gapis/service/path"
 	"github.com/google/gapid/gapis/shadertools"
+	"github.com/google/gapid/gapis/shadertools/spirv"
 )
 
 func (t *ImageObject) IsResource() bool {
@@ -720,8 +721,14 @@ func (s *ShaderModuleObject) ResourceType(ctx context.Context) api.ResourceType
 func (s *ShaderModuleObject) ResourceData(ctx context.Context, t *api.GlobalState) (*api.ResourceData, error) {
 	ctx = log.Enter(ctx, "ShaderModuleObject.ResourceData()")
 	words := s.Words.MustRead(ctx, nil, t, nil)
-	source := shadertools.DisassembleSpirvBinary(words)
-	return api.NewResourceData(&api.Shader{Type: api.ShaderType_Spirv, Source: source}), nil
+	shader := &api.Shader{
+		Type:   api.ShaderType_Spirv,
+		Source: shadertools.DisassembleSpirvBinary(words),
+	}
+	if eps, err := entryPoints(ctx, words); err == nil {
+		shader.EntryPoints = eps
+	}
+	return api.NewResourceData(shader), nil
 }
 
 func (shader *ShaderModuleObject) SetResourceData(
@@ -806,3 +813,243 @@ func (cmd *VkCreateShaderModule) Replace(ctx context.Context, c *capture.Capture
 	}
 	return newCmd
 }
+
+// IsResource returns true if this instance should be considered as a resource.
+func (p *GraphicsPipelineObject) IsResource() bool {
+	return true
+}
+
+// ResourceHandle returns the UI identity for the resource.
+func (p *GraphicsPipelineObject) ResourceHandle() string {
+	return fmt.Sprintf("Pipeline<0x%x>", p.VulkanHandle)
+}
+
+// ResourceLabel returns an optional debug label for the resource.
+func (p *GraphicsPipelineObject) ResourceLabel() string {
+	if p.DebugInfo != nil {
+		if p.DebugInfo.ObjectName != "" {
+			return p.DebugInfo.ObjectName
+		}
+		return fmt.Sprintf("<%d:%v>", p.DebugInfo.TagName, p.DebugInfo.Tag)
+	}
+	return ""
+}
+
+// Order returns an integer used to sort the resources for presentation.
+func (p *GraphicsPipelineObject) Order() uint64 {
+	return uint64(p.VulkanHandle)
+}
+
+// ResourceType returns the type of this resource.
+func (p *GraphicsPipelineObject) ResourceType(ctx context.Context) api.ResourceType {
+	return api.ResourceType_PipelineResource
+}
+
+// ResourceData returns the resource data given the current state.
+func (p *GraphicsPipelineObject) ResourceData(ctx context.Context, t *api.GlobalState) (*api.ResourceData, error) {
+	ctx = log.Enter(ctx, "GraphicsPipelineObject.ResourceData()")
+	viewport, scissor := viewportAndScissor(p, &dynamicViewport{})
+	out := &api.Pipeline{
+		Topology:           p.InputAssemblyState.Topology.String(),
+		VertexAttributes:   vertexAttributes(p, nil),
+		Blend:              blendAttachments(p),
+		DepthStencil:       depthStencilState(p, viewport),
+		Rasterizer:         rasterizerState(p, viewport, scissor),
+		PushConstantRanges: pushConstantRanges(p.Layout),
+	}
+	for _, i := range p.Stages.Keys() {
+		out.Stages = append(out.Stages, pipelineStage(ctx, p.Stages.Get(i), t))
+	}
+	if d := p.DynamicState; d != nil {
+		for _, i := range d.DynamicStates.Keys() {
+			out.DynamicState = append(out.DynamicState, d.DynamicStates.Get(i).String())
+		}
+	}
+	return api.NewResourceData(out), nil
+}
+
+func (p *GraphicsPipelineObject) SetResourceData(ctx context.Context, at *path.Command,
+	data *api.ResourceData, resources api.ResourceMap, edits api.ReplaceCallback) error {
+	return fmt.Errorf("SetResourceData is not supported for GraphicsPipelineObject")
+}
+
+// IsResource returns true if this instance should be considered as a resource.
+func (p *ComputePipelineObject) IsResource() bool {
+	return true
+}
+
+// ResourceHandle returns the UI identity for the resource.
+func (p *ComputePipelineObject) ResourceHandle() string {
+	return fmt.Sprintf("Pipeline<0x%x>", p.VulkanHandle)
+}
+
+// ResourceLabel returns an optional debug label for the resource.
+func (p *ComputePipelineObject) ResourceLabel() string {
+	if p.DebugInfo != nil {
+		if p.DebugInfo.ObjectName != "" {
+			return p.DebugInfo.ObjectName
+		}
+		return fmt.Sprintf("<%d:%v>", p.DebugInfo.TagName, p.DebugInfo.Tag)
+	}
+	return ""
+}
+
+// Order returns an integer used to sort the resources for presentation.
+func (p *ComputePipelineObject) Order() uint64 {
+	return uint64(p.VulkanHandle)
+}
+
+// ResourceType returns the type of this resource.
+func (p *ComputePipelineObject) ResourceType(ctx context.Context) api.ResourceType {
+	return api.ResourceType_PipelineResource
+}
+
+// ResourceData returns the resource data given the current state.
+func (p *ComputePipelineObject) ResourceData(ctx context.Context, t *api.GlobalState) (*api.ResourceData, error) {
+	ctx = log.Enter(ctx, "ComputePipelineObject.ResourceData()")
+	return api.NewResourceData(&api.Pipeline{
+		Compute:            true,
+		Stages:             []*api.PipelineStage{pipelineStage(ctx, p.Stage, t)},
+		PushConstantRanges: pushConstantRanges(p.PipelineLayout),
+	}), nil
+}
+
+func (p *ComputePipelineObject) SetResourceData(ctx context.Context, at *path.Command,
+	data *api.ResourceData, resources api.ResourceMap, edits api.ReplaceCallback) error {
+	return fmt.Errorf("SetResourceData is not supported for ComputePipelineObject")
+}
+
+// pipelineStage returns the shader stage of a pipeline, with the interface of
+// its entry point reflected from the words of the shader module.
+func pipelineStage(ctx context.Context, stage StageData, t *api.GlobalState) *api.PipelineStage {
+	out := &api.PipelineStage{
+		Type:       shaderStageType(stage.Stage),
+		EntryPoint: stage.EntryPoint,
+	}
+	specNames := map[uint32]string{}
+	if stage.Module != nil {
+		out.Shader = stage.Module.ResourceHandle()
+		words := stage.Module.Words.MustRead(ctx, nil, t, nil)
+		if r, err := reflectShader(words, stage.EntryPoint, stage.Stage); err != nil {
+			log.W(ctx, "Couldn't reflect %v: %v", out.Shader, err)
+		} else {
+			out.Reflection = shaderReflection(r, stage.Stage)
+			for _, c := range r.SpecConstants {
+				specNames[c.SpecID] = c.Name
+			}
+		}
+	}
+	if spec := stage.Specialization; spec != nil {
+		data := spec.Data.MustRead(ctx, nil, t, nil)
+		for _, i := range spec.Specializations.Keys() {
+			e := spec.Specializations.Get(i)
+			c := &api.SpecializationConstant{
+				Id:   e.ConstantID,
+				Name: specNames[e.ConstantID],
+			}
+			if end := uint64(e.Offset) + uint64(e.Size); end <= uint64(len(data)) {
+				c.Value = data[e.Offset:end]
+			}
+			out.SpecializationConstants = append(out.SpecializationConstants, c)
+		}
+	}
+	return out
+}
+
+// pushConstantRanges returns the push constant ranges of the pipeline layout.
+func pushConstantRanges(l *PipelineLayoutObject) []*api.PushConstantRange {
+	out := []*api.PushConstantRange{}
+	if l == nil {
+		return out
+	}
+	for _, i := range l.PushConstantRanges.Keys() {
+		r := l.PushConstantRanges.Get(i)
+		out = append(out, &api.PushConstantRange{
+			Stages: fmt.Sprint(VkShaderStageFlagBits(r.StageFlags)),
+			Offset: r.Offset,
+			Size:   r.Size,
+		})
+	}
+	return out
+}
+
+// reflectShader returns the reflection of the entry point of the SPIR-V module
+// words for the stage.
+func reflectShader(words []uint32, entryPoint string, stage VkShaderStageFlagBits) (*spirv.Reflection, error) {
+	m, err := spirv.Decode(words)
+	if err != nil {
+		return nil, err
+	}
+	return m.Reflect(entryPoint, executionModel(stage))
+}
+
+// executionModel returns the SPIR-V execution model of the shader stage.
+func executionModel(stage VkShaderStageFlagBits) spirv.ExecutionModel {
+	switch stage {
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_TESSELLATION_CONTROL_BIT:
+		return spirv.ExecutionModelTessellationControl
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_TESSELLATION_EVALUATION_BIT:
+		return spirv.ExecutionModelTessellationEvaluation
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_GEOMETRY_BIT:
+		return spirv.ExecutionModelGeometry
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_FRAGMENT_BIT:
+		return spirv.ExecutionModelFragment
+	case VkShaderStageFlagBits_VK_SHADER_STAGE_COMPUTE_BIT:
+		return spirv.ExecutionModelGLCompute
+	default:
+		return spirv.ExecutionModelVertex
+	}
+}
+
+var descriptorTypes = map[spirv.DescriptorType]VkDescriptorType{
+	spirv.DescriptorSampler:              VkDescriptorType_VK_DESCRIPTOR_TYPE_SAMPLER,
+	spirv.DescriptorCombinedImageSampler: VkDescriptorType_VK_DESCRIPTOR_TYPE_COMBINED_IMAGE_SAMPLER,
+	spirv.DescriptorSampledImage:         VkDescriptorType_VK_DESCRIPTOR_TYPE_SAMPLED_IMAGE,
+	spirv.DescriptorStorageImage:         VkDescriptorType_VK_DESCRIPTOR_TYPE_STORAGE_IMAGE,
+	spirv.DescriptorUniformTexelBuffer:   VkDescriptorType_VK_DESCRIPTOR_TYPE_UNIFORM_TEXEL_BUFFER,
+	spirv.DescriptorStorageTexelBuffer:   VkDescriptorType_VK_DESCRIPTOR_TYPE_STORAGE_TEXEL_BUFFER,
+	spirv.DescriptorUniformBuffer:        VkDescriptorType_VK_DESCRIPTOR_TYPE_UNIFORM_BUFFER,
+	spirv.DescriptorStorageBuffer:        VkDescriptorType_VK_DESCRIPTOR_TYPE_STORAGE_BUFFER,
+	spirv.DescriptorInputAttachment:      VkDescriptorType_VK_DESCRIPTOR_TYPE_INPUT_ATTACHMENT,
+}
+
+// shaderReflection converts the SPIR-V reflection of the shader stage to its
+// api representation.
+func shaderReflection(r *spirv.Reflection, stage VkShaderStageFlagBits) *api.ShaderReflection {
+	out := &api.ShaderReflection{}
+	variables := func(vars []spirv.InterfaceVariable) []*api.ShaderVariable {
+		out := make([]*api.ShaderVariable, len(vars))
+		for i, v := range vars {
+			out[i] = &api.ShaderVariable{
+				Name:     v.Name,
+				Type:     v.Type.String(),
+				Location: v.Location,
+			}
+			if v.IsBuiltIn {
+				out[i].BuiltIn = v.BuiltIn.String()
+			}
+		}
+		return out
+	}
+	out.Inputs = variables(r.Inputs)
+	out.Outputs = variables(r.Outputs)
+	for _, b := range r.DescriptorBindings {
+		out.DescriptorBindings = append(out.DescriptorBindings, &api.ShaderDescriptorBinding{
+			Set:            b.Set,
+			Binding:        b.Binding,
+			Name:           b.Name,
+			DescriptorType: descriptorTypes[b.DescriptorType].String(),
+			Type:           b.Type.String(),
+			Count:          b.Count,
+		})
+	}
+	for _, p := range r.PushConstants {
+		out.PushConstants = append(out.PushConstants, &api.PushConstantRange{
+			Stages: stage.String(),
+			Name:   p.Name,
+			Offset: p.Offset,
+			Size:   p.Size,
+		})
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/api"
+)
+
+func TestGraphicsPipelineResourceData(t *testing.T) {
+	ctx := log.Testing(t)
+	s := api.NewStateWithEmptyAllocator(device.Little32)
+	vertex := VkShaderStageFlagBits_VK_SHADER_STAGE_VERTEX_BIT
+	fragment := VkShaderStageFlagBits_VK_SHADER_STAGE_FRAGMENT_BIT
+	p := &GraphicsPipelineObject{
+		VulkanHandle: 1,
+		Stages: NewU32ːStageDataᵐ().
+			Add(0, StageData{Stage: vertex, EntryPoint: "main"}).
+			Add(1, StageData{Stage: fragment, EntryPoint: "frag"}),
+		VertexInputState: VertexData{
+			BindingDescriptions: NewU32ːVkVertexInputBindingDescriptionᵐ(),
+			AttributeDescriptions: NewU32ːVkVertexInputAttributeDescriptionᵐ().
+				Add(0, VkVertexInputAttributeDescription{
+					Location: 2,
+					Format:   VkFormat_VK_FORMAT_R32G32B32_SFLOAT,
+				}),
+		},
+		InputAssemblyState: InputAssemblyData{
+			Topology: VkPrimitiveTopology_VK_PRIMITIVE_TOPOLOGY_TRIANGLE_LIST,
+		},
+		RasterizationState: RasterizationData{
+			CullMode:  VkCullModeFlags(VkCullModeFlagBits_VK_CULL_MODE_BACK_BIT),
+			LineWidth: 1,
+		},
+		DepthState: &DepthData{
+			DepthTestEnable: 1,
+			DepthCompareOp:  VkCompareOp_VK_COMPARE_OP_LESS,
+		},
+		ColorBlendState: &ColorBlendData{
+			Attachments: NewU32ːVkPipelineColorBlendAttachmentStateᵐ().
+				Add(0, VkPipelineColorBlendAttachmentState{
+					BlendEnable: 1,
+					ColorWriteMask: VkColorComponentFlags(VkColorComponentFlagBits_VK_COLOR_COMPONENT_R_BIT |
+						VkColorComponentFlagBits_VK_COLOR_COMPONENT_A_BIT),
+				}),
+		},
+		DynamicState: &DynamicData{
+			DynamicStates: NewU32ːVkDynamicStateᵐ().Add(0, VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT),
+		},
+		Layout: &PipelineLayoutObject{
+			PushConstantRanges: NewU32ːVkPushConstantRangeᵐ().
+				Add(0, VkPushConstantRange{StageFlags: VkShaderStageFlags(vertex), Offset: 0, Size: 16}),
+		},
+	}
+
+	data, err := p.ResourceData(ctx, s)
+	assert.For(ctx, "err").ThatError(err).Succeeded()
+	out := data.GetPipeline()
+	assert.For(ctx, "compute").That(out.Compute).Equals(false)
+	assert.For(ctx, "topology").That(out.Topology).Equals(
+		VkPrimitiveTopology_VK_PRIMITIVE_TOPOLOGY_TRIANGLE_LIST.String())
+	assert.For(ctx, "stages").That(out.Stages).DeepEquals([]*api.PipelineStage{
+		{Type: api.ShaderType_Vertex, EntryPoint: "main"},
+		{Type: api.ShaderType_Fragment, EntryPoint: "frag"},
+	})
+	assert.For(ctx, "attributes").ThatSlice(out.VertexAttributes).IsLength(1)
+	assert.For(ctx, "location").That(out.VertexAttributes[0].Location).Equals(uint32(2))
+	assert.For(ctx, "blend").ThatSlice(out.Blend).IsLength(1)
+	assert.For(ctx, "blend enabled").That(out.Blend[0].Enabled).Equals(true)
+	assert.For(ctx, "write mask").That(out.Blend[0].WriteMask).Equals("RA")
+	assert.For(ctx, "depth test").That(out.DepthStencil.DepthTest).Equals(true)
+	assert.For(ctx, "depth func").That(out.DepthStencil.DepthFunc).Equals(VkCompareOp_VK_COMPARE_OP_LESS.String())
+	assert.For(ctx, "cull").That(out.Rasterizer.Cull).Equals(true)
+	assert.For(ctx, "dynamic state").That(out.DynamicState).DeepEquals([]string{
+		VkDynamicState_VK_DYNAMIC_STATE_VIEWPORT.String(),
+	})
+	assert.For(ctx, "push constants").That(out.PushConstantRanges).DeepEquals([]*api.PushConstantRange{
+		{Stages: vertex.String(), Offset: 0, Size: 16},
+	})
+}
+
+func TestComputePipelineResourceData(t *testing.T) {
+	ctx := log.Testing(t)
+	s := api.NewStateWithEmptyAllocator(device.Little32)
+	compute := VkShaderStageFlagBits_VK_SHADER_STAGE_COMPUTE_BIT
+	for _, test := range []struct {
+		name     string
+		layout   *PipelineLayoutObject
+		expected []*api.PushConstantRange
+	}{
+		{"no layout", nil, []*api.PushConstantRange{}},
+		{"push constants", &PipelineLayoutObject{
+			PushConstantRanges: NewU32ːVkPushConstantRangeᵐ().
+				Add(0, VkPushConstantRange{StageFlags: VkShaderStageFlags(compute), Offset: 4, Size: 8}),
+		}, []*api.PushConstantRange{{Stages: compute.String(), Offset: 4, Size: 8}}},
+	} {
+		p := &ComputePipelineObject{
+			VulkanHandle:   2,
+			Stage:          StageData{Stage: compute, EntryPoint: "main"},
+			PipelineLayout: test.layout,
+		}
+		data, err := p.ResourceData(ctx, s)
+		assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded()
+		assert.For(ctx, "%v pipeline", test.name).That(data.GetPipeline()).DeepEquals(&api.Pipeline{
+			Compute:            true,
+			Stages:             []*api.PipelineStage{{Type: api.ShaderType_Compute, EntryPoint: "main"}},
+			PushConstantRanges: test.expected,
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/shadertools"
+	"github.com/google/gapid/gapis/shadertools/spirv"
+)
+
+// CheckShader implements the api.ShaderChecker interface.
+//
+// The SPIR-V module created by vkCreateShaderModule is validated by
+// SPIRV-Tools for the Vulkan version requested by the instance of the device,
+// capped by the version supported by the replay device.
+func (API) CheckShader(ctx context.Context, cmd api.Cmd, s *api.GlobalState, d *device.Instance) *api.ShaderCheckResult {
+	create, ok := cmd.(*VkCreateShaderModule)
+	if !ok {
+		return nil
+	}
+	m := GetState(s).ShaderModules.Get(create.PShaderModule.MustRead(ctx, cmd, s, nil))
+	if m == nil {
+		return nil
+	}
+	out := &api.ShaderCheckResult{
+		Handle: m.ResourceHandle(),
+		Type:   api.ShaderType_Spirv,
+	}
+	words := m.Words.MustRead(ctx, nil, s, nil)
+	module, err := spirv.Decode(words)
+	if err != nil {
+		out.Errors = append(out.Errors, err.Error())
+		return out
+	}
+
+	major, minor := apiVersion(s, m.Device)
+	if d != nil {
+		if dMajor, dMinor := deviceAPIVersion(d); dMajor < major || (dMajor == major && dMinor < minor) {
+			major, minor = dMajor, dMinor
+		}
+	}
+	if max := maxSpirvVersion(major, minor); module.Version > max {
+		out.Errors = append(out.Errors, fmt.Sprintf("SPIR-V %d.%d is newer than SPIR-V %d.%d, the latest that can be validated for Vulkan %d.%d.",
+			(module.Version>>16)&0xff, (module.Version>>8)&0xff, (max>>16)&0xff, (max>>8)&0xff, major, minor))
+		return out
+	}
+	if msg := shadertools.ValidateSpirvBinary(words, major, minor); msg != "" {
+		out.Errors = append(out.Errors, msg)
+	}
+	if len(module.EntryPoints) == 0 {
+		out.Warnings = append(out.Warnings, "The module has no entry point.")
+	}
+	return out
+}
+
+// apiVersion returns the Vulkan version requested by the instance of the
+// device, 1.0 if unknown.
+func apiVersion(s *api.GlobalState, device VkDevice) (major, minor uint32) {
+	st := GetState(s)
+	d := st.Devices.Get(device)
+	if d == nil {
+		return 1, 0
+	}
+	p := st.PhysicalDevices.Get(d.PhysicalDevice)
+	if p == nil {
+		return 1, 0
+	}
+	i := st.Instances.Get(p.Instance)
+	if i == nil || i.ApiVersion == 0 {
+		return 1, 0
+	}
+	return i.ApiVersion >> 22, (i.ApiVersion >> 12) & 0x3ff
+}
+
+// deviceAPIVersion returns the highest Vulkan version supported by the
+// physical devices of the replay device d, 1.0 if unknown.
+func deviceAPIVersion(d *device.Instance) (major, minor uint32) {
+	version := uint32(0)
+	for _, p := range d.GetConfiguration().GetDrivers().GetVulkan().GetPhysicalDevices() {
+		if p.ApiVersion > version {
+			version = p.ApiVersion
+		}
+	}
+	if version == 0 {
+		return 1, 0
+	}
+	return version >> 22, (version >> 12) & 0x3ff
+}
+
+// maxSpirvVersion returns the latest SPIR-V version supported by the Vulkan
+// version that can be validated by the bundled SPIRV-Tools, which knows Vulkan
+// environments up to 1.1, and thus SPIR-V up to 1.3.
+func maxSpirvVersion(major, minor uint32) uint32 {
+	if major == 1 && minor == 0 {
+		return 0x00010000
+	}
+	return 0x00010300
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+)
+
+func TestDeviceAPIVersion(t *testing.T) {
+	ctx := log.Testing(t)
+	vulkanDevice := func(versions ...uint32) *device.Instance {
+		driver := &device.VulkanDriver{}
+		for _, v := range versions {
+			driver.PhysicalDevices = append(driver.PhysicalDevices, &device.VulkanPhysicalDevice{ApiVersion: v})
+		}
+		return &device.Instance{
+			Configuration: &device.Configuration{
+				Drivers: &device.Drivers{Vulkan: driver},
+			},
+		}
+	}
+	for _, test := range []struct {
+		name         string
+		device       *device.Instance
+		major, minor uint32
+	}{
+		{"no driver", &device.Instance{}, 1, 0},
+		{"no physical device", vulkanDevice(), 1, 0},
+		{"1.0.61", vulkanDevice(1<<22 | 61), 1, 0},
+		{"highest", vulkanDevice(1<<22|61, 1<<22|1<<12|70), 1, 1},
+	} {
+		major, minor := deviceAPIVersion(test.device)
+		assert.For(ctx, "%v major", test.name).That(major).Equals(test.major)
+		assert.For(ctx, "%v minor", test.name).That(minor).Equals(test.minor)
+	}
+}
+
+func TestMaxSpirvVersion(t *testing.T) {
+	ctx := log.Testing(t)
+	assert.For(ctx, "1.0").That(maxSpirvVersion(1, 0)).Equals(uint32(0x00010000))
+	assert.For(ctx, "1.1").That(maxSpirvVersion(1, 1)).Equals(uint32(0x00010300))
+	assert.For(ctx, "1.2").That(maxSpirvVersion(1, 2)).Equals(uint32(0x00010300))
+	assert.For(ctx, "2.0").That(maxSpirvVersion(2, 0)).Equals(uint32(0x00010300))
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+	"github.com/google/gapid/gapis/shadertools/spirv"
+)
+
+// ShaderComplexities implements the api.ShaderComplexityProvider interface.
+//
+// The complexity of each of the entry points of the SPIR-V modules of the
+// initial state and created by vkCreateShaderModule is measured. A draw call
+// uses the entry points of the stages of the graphics pipeline bound for it,
+// as for the draw call state.
+func (API) ShaderComplexities(ctx context.Context, p *path.Capture) ([]*api.ShaderComplexityEntry, error) {
+	ctx = capture.Put(ctx, p)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type entryPoint struct {
+		module *ShaderModuleObject
+		name   string
+		ty     api.ShaderType
+	}
+	out := []*api.ShaderComplexityEntry{}
+	entries := map[entryPoint]*api.ShaderComplexityEntry{}
+
+	s := c.NewState(ctx)
+	st := GetState(s)
+	add := func(m *ShaderModuleObject, built *path.Command) {
+		eps, err := entryPoints(ctx, m.Words.MustRead(ctx, nil, s, nil))
+		if err != nil {
+			log.W(ctx, "Couldn't measure the complexity of %v: %v", m.ResourceHandle(), err)
+			return
+		}
+		for _, e := range eps {
+			entry := &api.ShaderComplexityEntry{
+				Handle:     m.ResourceHandle(),
+				Type:       e.Type,
+				EntryPoint: e.Name,
+				Built:      built,
+				Complexity: e.Complexity,
+			}
+			entries[entryPoint{m, e.Name, e.Type}] = entry
+			out = append(out, entry)
+		}
+	}
+	for _, h := range st.ShaderModules.Keys() {
+		add(st.ShaderModules.Get(h), nil)
+	}
+
+	draw := func(a interface{}) {
+		switch a.(*CommandReference).Type {
+		case CommandType_cmd_vkCmdDraw,
+			CommandType_cmd_vkCmdDrawIndexed,
+			CommandType_cmd_vkCmdDrawIndirect,
+			CommandType_cmd_vkCmdDrawIndexedIndirect:
+		default:
+			return
+		}
+		if st.LastBoundQueue == nil {
+			return
+		}
+		info, ok := st.LastDrawInfos.Lookup(st.LastBoundQueue.VulkanHandle)
+		if !ok || info.GraphicsPipeline == nil {
+			return
+		}
+		stages := info.GraphicsPipeline.Stages
+		for _, i := range stages.Keys() {
+			stage := stages.Get(i)
+			if e, ok := entries[entryPoint{stage.Module, stage.EntryPoint, shaderStageType(stage.Stage)}]; ok {
+				e.DrawCalls++
+			}
+		}
+	}
+
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		if _, ok := cmd.(*VkQueueSubmit); ok {
+			st.PostSubcommand = draw
+		}
+		err := cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+		st.PostSubcommand = nil
+		create, ok := cmd.(*VkCreateShaderModule)
+		if err != nil || !ok {
+			return nil
+		}
+		if m := st.ShaderModules.Get(create.PShaderModule.MustRead(ctx, cmd, s, nil)); m != nil {
+			add(m, p.Command(uint64(id)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// entryPoints returns each of the entry points of the SPIR-V module words with
+// their complexity, measured once for each distinct module.
+func entryPoints(ctx context.Context, words []uint32) ([]*api.ShaderEntryPoint, error) {
+	obj, err := database.Build(ctx, &ShaderComplexityResolvable{Words: words})
+	if err != nil {
+		return nil, err
+	}
+	return obj.([]*api.ShaderEntryPoint), nil
+}
+
+// Resolve implements the database.Resolver interface.
+// The entry points whose complexity can't be measured are skipped.
+func (r *ShaderComplexityResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	m, err := spirv.Decode(r.Words)
+	if err != nil {
+		return nil, err
+	}
+	out := []*api.ShaderEntryPoint{}
+	for _, e := range m.EntryPoints {
+		c, err := m.Complexity(e.Name, e.Model)
+		if err != nil {
+			log.W(ctx, "Couldn't measure the complexity of %v: %v", e.Name, err)
+			continue
+		}
+		out = append(out, &api.ShaderEntryPoint{
+			Name:       e.Name,
+			Type:       modelShaderType(e.Model),
+			Complexity: api.NewShaderComplexity(c),
+		})
+	}
+	return out, nil
+}
+
+// modelShaderType returns the api shader type of the SPIR-V execution model.
+func modelShaderType(model spirv.ExecutionModel) api.ShaderType {
+	switch model {
+	case spirv.ExecutionModelTessellationControl:
+		return api.ShaderType_TessControl
+	case spirv.ExecutionModelTessellationEvaluation:
+		return api.ShaderType_TessEvaluation
+	case spirv.ExecutionModelGeometry:
+		return api.ShaderType_Geometry
+	case spirv.ExecutionModelFragment:
+		return api.ShaderType_Fragment
+	case spirv.ExecutionModelGLCompute:
+		return api.ShaderType_Compute
+	default:
+		return api.ShaderType_Vertex
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/api"
+)
+
+// mutateSubcommands mutates the commands cmds on the state s, calling before,
+// if not nil, before mutating each command, and subcommand after each
+// subcommand executed by the queue submissions with the index of the
+// subcommand, starting with the index of its submission.
+func mutateSubcommands(ctx context.Context, s *api.GlobalState, cmds []api.Cmd,
+	before func(id api.CmdID, cmd api.Cmd), subcommand func(idx api.SubCmdIdx, ref *CommandReference)) error {
+
+	st := GetState(s)
+	defer func() { st.PostSubcommand = nil }()
+	return api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		if before != nil {
+			before(id, cmd)
+		}
+		if _, ok := cmd.(*VkQueueSubmit); ok {
+			st.PostSubcommand = func(a interface{}) {
+				subcommand(append(api.SubCmdIdx{uint64(id)}, st.SubCmdIdx...), a.(*CommandReference))
+			}
+		} else {
+			st.PostSubcommand = nil
+		}
+		cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+		return nil
+	})
+}
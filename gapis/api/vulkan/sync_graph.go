@@ -0,0 +1,308 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/sync"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/resolve"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// SyncGraph implements the sync.SyncGraphProvider interface.
+//
+// The submissions and presentations are nodes on their queue, and the host
+// commands signaling or waiting for the queues are nodes on the host queue.
+// The events set by the host or by the executed command buffers are linked to
+// the submissions waiting for them, and the commands of d unblocking the
+// execution of a submission are linked to it.
+func (API) SyncGraph(ctx context.Context, p *path.Capture, d *sync.Data) (*api.SyncGraph, error) {
+	ctx = capture.Put(ctx, p)
+	st, err := capture.NewState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := resolve.Cmds(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newSyncGraphBuilder(p)
+	// Blocked command buffer commands are executed by later commands, the
+	// submission of a command is the one of the state.
+	s := GetState(st)
+	s.PostSubcommand = func(a interface{}) {
+		if n, ok := b.submissions[s.CurrentSubmission]; ok {
+			b.subcommand(ctx, a.(*CommandReference), s, n)
+		}
+	}
+	err = api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		b.add(ctx, id, cmd, st)
+		cmd.Mutate(ctx, id, st, nil /* no builder, just mutate */)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.addUnblocks(d)
+	sort.SliceStable(b.graph.Edges, func(i, j int) bool {
+		return b.graph.Edges[i].To < b.graph.Edges[j].To
+	})
+	return b.graph, nil
+}
+
+// syncGraphBuilder builds a sync graph from the commands of a capture.
+type syncGraphBuilder struct {
+	graph   *api.SyncGraph
+	capture *path.Capture
+	queues  map[VkQueue]uint32
+	nodes   map[api.CmdID]uint32
+	// lastOnQueue is the last node of each queue.
+	lastOnQueue map[uint32]uint32
+	// semaphores and fences are the last nodes signaling each semaphore and
+	// fence.
+	semaphores map[VkSemaphore]uint32
+	fences     map[VkFence]uint32
+	// submissions are the nodes of the vkQueueSubmit commands.
+	submissions map[api.Cmd]uint32
+	// events are the last nodes setting each event, and eventWaits the nodes
+	// waiting for each unset event.
+	events     map[VkEvent]uint32
+	eventWaits map[VkEvent][]uint32
+	eventEdges map[eventEdge]bool
+}
+
+// eventEdge is an edge between two nodes for an event.
+type eventEdge struct {
+	event    VkEvent
+	from, to uint32
+}
+
+func newSyncGraphBuilder(p *path.Capture) *syncGraphBuilder {
+	return &syncGraphBuilder{
+		graph:       &api.SyncGraph{Queues: []*api.SyncQueue{{Name: "Host"}}},
+		capture:     p,
+		queues:      map[VkQueue]uint32{},
+		nodes:       map[api.CmdID]uint32{},
+		lastOnQueue: map[uint32]uint32{},
+		semaphores:  map[VkSemaphore]uint32{},
+		fences:      map[VkFence]uint32{},
+		submissions: map[api.Cmd]uint32{},
+		events:      map[VkEvent]uint32{},
+		eventWaits:  map[VkEvent][]uint32{},
+		eventEdges:  map[eventEdge]bool{},
+	}
+}
+
+// queue returns the index of the queue q in the graph, adding it if needed.
+func (b *syncGraphBuilder) queue(q VkQueue, s *api.GlobalState) uint32 {
+	if i, ok := b.queues[q]; ok {
+		return i
+	}
+	name := fmt.Sprintf("Queue<0x%x>", q)
+	if o, ok := GetState(s).Queues.Lookup(q); ok {
+		name = fmt.Sprintf("%v (family %d, index %d)", name, o.Family, o.Index)
+	}
+	i := uint32(len(b.graph.Queues))
+	b.graph.Queues = append(b.graph.Queues, &api.SyncQueue{Name: name})
+	b.queues[q] = i
+	return i
+}
+
+func (b *syncGraphBuilder) addNode(kind api.SyncNodeKind, queue uint32, id api.CmdID, cmd api.Cmd) uint32 {
+	i := uint32(len(b.graph.Nodes))
+	b.graph.Nodes = append(b.graph.Nodes, &api.SyncNode{
+		Kind:      kind,
+		Name:      cmd.CmdName(),
+		Queue:     queue,
+		Command:   b.capture.Command(uint64(id)),
+		Completed: b.capture.Command(uint64(id)),
+	})
+	b.nodes[id] = i
+	if queue > 0 {
+		b.lastOnQueue[queue] = i
+	}
+	return i
+}
+
+func (b *syncGraphBuilder) addEdge(kind api.SyncEdgeKind, name string, from, to uint32) {
+	b.graph.Edges = append(b.graph.Edges, &api.SyncEdge{
+		Kind: kind,
+		Name: name,
+		From: from,
+		To:   to,
+	})
+}
+
+// wait adds the edge from the last node signaling the semaphore sem to the
+// node n, consuming the signal.
+func (b *syncGraphBuilder) wait(sem VkSemaphore, n uint32) {
+	if from, ok := b.semaphores[sem]; ok {
+		b.addEdge(api.SyncEdgeKind_SemaphoreEdge, fmt.Sprintf("Semaphore<0x%x>", sem), from, n)
+		delete(b.semaphores, sem)
+	}
+}
+
+// setEvent records the node n as setting the event, linking it to the nodes
+// waiting for the event.
+func (b *syncGraphBuilder) setEvent(event VkEvent, n uint32) {
+	b.events[event] = n
+	for _, to := range b.eventWaits[event] {
+		b.addEventEdge(event, n, to)
+	}
+	delete(b.eventWaits, event)
+}
+
+// resetEvent records the event as unset.
+func (b *syncGraphBuilder) resetEvent(event VkEvent) {
+	delete(b.events, event)
+}
+
+// waitEvent links the node setting the event to the node n, or records n as
+// waiting for the event if it is unset.
+func (b *syncGraphBuilder) waitEvent(event VkEvent, n uint32) {
+	if from, ok := b.events[event]; ok {
+		b.addEventEdge(event, from, n)
+	} else {
+		b.eventWaits[event] = append(b.eventWaits[event], n)
+	}
+}
+
+// addEventEdge adds the edge for the event between the nodes, once.
+func (b *syncGraphBuilder) addEventEdge(event VkEvent, from, to uint32) {
+	e := eventEdge{event, from, to}
+	if from == to || b.eventEdges[e] {
+		return
+	}
+	b.eventEdges[e] = true
+	b.addEdge(api.SyncEdgeKind_EventEdge, fmt.Sprintf("Event<0x%x>", event), from, to)
+}
+
+// subcommand processes the command buffer command ref executed by the
+// submission of the node n.
+func (b *syncGraphBuilder) subcommand(ctx context.Context, ref *CommandReference, s *State, n uint32) {
+	switch args := GetCommandArgs(ctx, ref, s).(type) {
+	case *VkCmdSetEventArgs:
+		b.setEvent(args.Event, n)
+	case *VkCmdResetEventArgs:
+		b.resetEvent(args.Event)
+	case *VkCmdWaitEventsArgs:
+		for _, i := range args.Events.Keys() {
+			b.waitEvent(args.Events.Get(i), n)
+		}
+	}
+}
+
+// add adds the node of the command cmd, if it submits work to a queue or
+// synchronizes the host with the queues.
+func (b *syncGraphBuilder) add(ctx context.Context, id api.CmdID, cmd api.Cmd, s *api.GlobalState) {
+	l := s.MemoryLayout
+	switch cmd := cmd.(type) {
+	case *VkQueueSubmit:
+		n := b.addNode(api.SyncNodeKind_Submission, b.queue(cmd.Queue, s), id, cmd)
+		b.submissions[cmd] = n
+		for _, submit := range cmd.PSubmits.Slice(0, uint64(cmd.SubmitCount), l).MustRead(ctx, cmd, s, nil) {
+			for _, sem := range submit.PWaitSemaphores.Slice(0, uint64(submit.WaitSemaphoreCount), l).MustRead(ctx, cmd, s, nil) {
+				b.wait(sem, n)
+			}
+			for _, sem := range submit.PSignalSemaphores.Slice(0, uint64(submit.SignalSemaphoreCount), l).MustRead(ctx, cmd, s, nil) {
+				b.semaphores[sem] = n
+			}
+		}
+		if cmd.Fence != VkFence(0) {
+			b.fences[cmd.Fence] = n
+		}
+
+	case *VkQueuePresentKHR:
+		n := b.addNode(api.SyncNodeKind_Presentation, b.queue(cmd.Queue, s), id, cmd)
+		info := cmd.PPresentInfo.MustRead(ctx, cmd, s, nil)
+		for _, sem := range info.PWaitSemaphores.Slice(0, uint64(info.WaitSemaphoreCount), l).MustRead(ctx, cmd, s, nil) {
+			b.wait(sem, n)
+		}
+
+	case *VkAcquireNextImageKHR:
+		n := b.addNode(api.SyncNodeKind_ImageAcquisition, 0, id, cmd)
+		if cmd.Semaphore != VkSemaphore(0) {
+			b.semaphores[cmd.Semaphore] = n
+		}
+		if cmd.Fence != VkFence(0) {
+			b.fences[cmd.Fence] = n
+		}
+
+	case *VkWaitForFences:
+		n := b.addNode(api.SyncNodeKind_FenceWait, 0, id, cmd)
+		for _, fence := range cmd.PFences.Slice(0, uint64(cmd.FenceCount), l).MustRead(ctx, cmd, s, nil) {
+			if from, ok := b.fences[fence]; ok {
+				b.addEdge(api.SyncEdgeKind_FenceEdge, fmt.Sprintf("Fence<0x%x>", fence), from, n)
+			}
+		}
+
+	case *VkQueueWaitIdle:
+		q := b.queue(cmd.Queue, s)
+		last, ok := b.lastOnQueue[q]
+		n := b.addNode(api.SyncNodeKind_QueueWaitIdle, 0, id, cmd)
+		if ok {
+			b.addEdge(api.SyncEdgeKind_IdleEdge, "", last, n)
+		}
+
+	case *VkDeviceWaitIdle:
+		n := b.addNode(api.SyncNodeKind_DeviceWaitIdle, 0, id, cmd)
+		for q := uint32(1); q < uint32(len(b.graph.Queues)); q++ {
+			if last, ok := b.lastOnQueue[q]; ok {
+				b.addEdge(api.SyncEdgeKind_IdleEdge, "", last, n)
+			}
+		}
+
+	case *VkSetEvent:
+		b.setEvent(cmd.Event, b.addNode(api.SyncNodeKind_EventSignal, 0, id, cmd))
+
+	case *VkResetEvent:
+		b.resetEvent(cmd.Event)
+	}
+}
+
+// addUnblocks sets the number of executed commands and the completion of the
+// submissions from d, and links the commands unblocking their execution.
+func (b *syncGraphBuilder) addUnblocks(d *sync.Data) {
+	for _, node := range b.graph.Nodes {
+		if node.Kind != api.SyncNodeKind_Submission {
+			continue
+		}
+		id := api.CmdID(node.Command.Indices[0])
+		node.Subcommands = uint32(len(d.SubcommandReferences[id]))
+		rng, ok := d.CommandRanges[id]
+		if !ok {
+			continue
+		}
+		unblocks := make([]api.CmdID, 0, len(rng.Ranges))
+		for k := range rng.Ranges {
+			unblocks = append(unblocks, k)
+		}
+		sort.Slice(unblocks, func(i, j int) bool { return unblocks[i] < unblocks[j] })
+		if len(unblocks) > 0 {
+			node.Completed = b.capture.Command(uint64(unblocks[len(unblocks)-1]))
+		}
+		for _, k := range unblocks {
+			if from, ok := b.nodes[k]; ok && k != id {
+				b.addEdge(api.SyncEdgeKind_UnblockEdge, "", from, b.nodes[id])
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+func TestSyncGraphEvents(t *testing.T) {
+	ctx := log.Testing(t)
+	const (
+		eventA = VkEvent(1)
+		eventB = VkEvent(2)
+	)
+	b := newSyncGraphBuilder(path.NewCapture(id.ID{}))
+
+	// Node 0 waits for A before it is set by node 1, node 2 sets and waits
+	// for B and node 3 waits for A and B twice. Node 4 waits for A once reset.
+	b.waitEvent(eventA, 0)
+	b.setEvent(eventA, 1)
+	b.setEvent(eventB, 2)
+	b.waitEvent(eventB, 2)
+	b.waitEvent(eventA, 3)
+	b.waitEvent(eventB, 3)
+	b.waitEvent(eventB, 3)
+	b.resetEvent(eventA)
+	b.waitEvent(eventA, 4)
+
+	type edge struct {
+		name     string
+		from, to uint32
+	}
+	edges := []edge{}
+	for _, e := range b.graph.Edges {
+		assert.For(ctx, "kind").That(e.Kind).Equals(api.SyncEdgeKind_EventEdge)
+		edges = append(edges, edge{e.Name, e.From, e.To})
+	}
+	assert.For(ctx, "edges").ThatSlice(edges).Equals([]edge{
+		{"Event<0x1>", 1, 0},
+		{"Event<0x1>", 1, 3},
+		{"Event<0x2>", 2, 3},
+	})
+	assert.For(ctx, "waiting").ThatSlice(b.eventWaits[eventA]).Equals([]uint32{4})
+}
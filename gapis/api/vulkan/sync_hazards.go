@@ -0,0 +1,433 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/core/math/interval"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// FindSyncHazards implements the api.SyncHazardFinder interface.
+func (API) FindSyncHazards(ctx context.Context, p *path.Capture) ([]api.SyncHazard, error) {
+	obj, err := database.Build(ctx, &SyncHazardsResolvable{Capture: p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.([]api.SyncHazard), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The memory accesses of the submitted commands are replayed in execution
+// order from the footprint of the capture. A write is pending until another
+// write replaces it, and is made visible to the later accesses of its queue
+// by the barriers and the render pass dependencies with it in their first
+// scope, to the accesses of another queue by a wait on a semaphore signalled
+// after it, and to all accesses by a host wait for the device. Accesses to
+// memory with a pending write from another command that is not visible to
+// them are hazards.
+// Attachment accesses within a render pass instance are ordered by the
+// subpass dependencies, and are not checked against each other.
+func (r *SyncHazardsResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Capture)
+	// The footprint is built here rather than shared with the dead code
+	// elimination, as only this builder records the synchronization info.
+	vb := newFootprintBuilder()
+	vb.machine.sync = newFootprintSyncInfo()
+	ft, err := dependencygraph.BuildFootprint(ctx, r.Capture,
+		func(a api.API) dependencygraph.FootprintBuilder {
+			if _, ok := a.(API); ok {
+				return vb
+			}
+			if bp, ok := a.(dependencygraph.FootprintBuilderProvider); ok {
+				return bp.FootprintBuilder(ctx)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	t := newSyncHazardTracker(vb.machine.sync)
+	for _, bh := range ft.Behaviors {
+		if bh.Owner[0] < uint64(ft.NumInitialCommands) {
+			continue
+		}
+		owner := append(api.SubCmdIdx{bh.Owner[0] - uint64(ft.NumInitialCommands)}, bh.Owner[1:]...)
+		if len(owner) == 1 {
+			switch ft.Commands[bh.Owner[0]].(type) {
+			case *VkWaitForFences, *VkQueueWaitIdle, *VkDeviceWaitIdle:
+				t.pending = map[VkDeviceMemory][]*pendingWrite{}
+				continue
+			}
+		}
+		t.process(bh, owner)
+	}
+	return t.hazards, nil
+}
+
+// syncScope is a set of pipeline stages and memory accesses, either of the
+// accesses of a command, or of one of the scopes of a dependency.
+type syncScope struct {
+	stages VkPipelineStageFlags
+	access VkAccessFlags
+}
+
+// syncDependency is a dependency between the accesses in its first scope src
+// and the accesses in its second scope dst.
+type syncDependency struct {
+	src, dst syncScope
+}
+
+const (
+	graphicsStages = VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_DRAW_INDIRECT_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_VERTEX_INPUT_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_VERTEX_SHADER_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TESSELLATION_CONTROL_SHADER_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TESSELLATION_EVALUATION_SHADER_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_GEOMETRY_SHADER_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_FRAGMENT_SHADER_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_EARLY_FRAGMENT_TESTS_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_LATE_FRAGMENT_TESTS_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COLOR_ATTACHMENT_OUTPUT_BIT)
+	attachmentStages = VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_EARLY_FRAGMENT_TESTS_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_LATE_FRAGMENT_TESTS_BIT |
+		VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COLOR_ATTACHMENT_OUTPUT_BIT)
+	allStages = graphicsStages |
+		VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COMPUTE_SHADER_BIT|
+			VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TRANSFER_BIT|
+			VkPipelineStageFlagBits_VK_PIPELINE_STAGE_HOST_BIT)
+
+	readAccesses = VkAccessFlags(VkAccessFlagBits_VK_ACCESS_INDIRECT_COMMAND_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_INDEX_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_VERTEX_ATTRIBUTE_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_UNIFORM_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_INPUT_ATTACHMENT_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_SHADER_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_COLOR_ATTACHMENT_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_DEPTH_STENCIL_ATTACHMENT_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_TRANSFER_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_HOST_READ_BIT)
+	writeAccesses = VkAccessFlags(VkAccessFlagBits_VK_ACCESS_SHADER_WRITE_BIT |
+		VkAccessFlagBits_VK_ACCESS_COLOR_ATTACHMENT_WRITE_BIT |
+		VkAccessFlagBits_VK_ACCESS_DEPTH_STENCIL_ATTACHMENT_WRITE_BIT |
+		VkAccessFlagBits_VK_ACCESS_TRANSFER_WRITE_BIT |
+		VkAccessFlagBits_VK_ACCESS_HOST_WRITE_BIT)
+	attachmentAccesses = VkAccessFlags(VkAccessFlagBits_VK_ACCESS_COLOR_ATTACHMENT_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_COLOR_ATTACHMENT_WRITE_BIT |
+		VkAccessFlagBits_VK_ACCESS_DEPTH_STENCIL_ATTACHMENT_READ_BIT |
+		VkAccessFlagBits_VK_ACCESS_DEPTH_STENCIL_ATTACHMENT_WRITE_BIT)
+)
+
+var (
+	fullScope       = syncScope{allStages, readAccesses | writeAccesses}
+	attachmentScope = syncScope{attachmentStages, attachmentAccesses}
+	transferScope   = syncScope{
+		VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TRANSFER_BIT),
+		VkAccessFlags(VkAccessFlagBits_VK_ACCESS_TRANSFER_READ_BIT | VkAccessFlagBits_VK_ACCESS_TRANSFER_WRITE_BIT),
+	}
+	drawScope = syncScope{
+		graphicsStages,
+		VkAccessFlags(VkAccessFlagBits_VK_ACCESS_INDIRECT_COMMAND_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_INDEX_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_VERTEX_ATTRIBUTE_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_UNIFORM_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_INPUT_ATTACHMENT_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_SHADER_READ_BIT|
+			VkAccessFlagBits_VK_ACCESS_SHADER_WRITE_BIT) | attachmentAccesses,
+	}
+	dispatchScope = syncScope{
+		VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_DRAW_INDIRECT_BIT |
+			VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COMPUTE_SHADER_BIT),
+		VkAccessFlags(VkAccessFlagBits_VK_ACCESS_INDIRECT_COMMAND_READ_BIT |
+			VkAccessFlagBits_VK_ACCESS_UNIFORM_READ_BIT |
+			VkAccessFlagBits_VK_ACCESS_SHADER_READ_BIT |
+			VkAccessFlagBits_VK_ACCESS_SHADER_WRITE_BIT),
+	}
+)
+
+// accessScope returns the pipeline stages and the memory accesses of the
+// recorded command cmd.
+func accessScope(cmd api.Cmd) syncScope {
+	switch cmd.(type) {
+	case *VkCmdCopyBuffer, *VkCmdCopyImage, *VkCmdBlitImage,
+		*VkCmdCopyBufferToImage, *VkCmdCopyImageToBuffer, *VkCmdUpdateBuffer,
+		*VkCmdFillBuffer, *VkCmdClearColorImage, *VkCmdClearDepthStencilImage,
+		*VkCmdResolveImage, *VkCmdCopyQueryPoolResults:
+		return transferScope
+	case *VkCmdDraw, *VkCmdDrawIndexed, *VkCmdDrawIndirect, *VkCmdDrawIndexedIndirect:
+		return drawScope
+	case *VkCmdDispatch, *VkCmdDispatchIndirect:
+		return dispatchScope
+	case *VkCmdBeginRenderPass, *VkCmdNextSubpass, *VkCmdEndRenderPass, *VkCmdClearAttachments:
+		return attachmentScope
+	}
+	return fullScope
+}
+
+// expand returns the scope s of a dependency with the stages and accesses
+// implied by its ALL_COMMANDS, ALL_GRAPHICS, MEMORY_READ and MEMORY_WRITE
+// bits, and by its BOTTOM_OF_PIPE bit if s is the first scope.
+func (s syncScope) expand(first bool) syncScope {
+	if s.stages&VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_ALL_COMMANDS_BIT) != 0 {
+		s.stages |= allStages
+	}
+	if s.stages&VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_ALL_GRAPHICS_BIT) != 0 {
+		s.stages |= graphicsStages
+	}
+	if first && s.stages&VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_BOTTOM_OF_PIPE_BIT) != 0 {
+		s.stages |= allStages
+	}
+	if s.access&VkAccessFlags(VkAccessFlagBits_VK_ACCESS_MEMORY_READ_BIT) != 0 {
+		s.access |= readAccesses
+	}
+	if s.access&VkAccessFlags(VkAccessFlagBits_VK_ACCESS_MEMORY_WRITE_BIT) != 0 {
+		s.access |= writeAccesses
+	}
+	return s
+}
+
+// externalDependencies returns the subpass dependencies of the render pass rp
+// from the commands before it if before is true, or to the commands after it
+// otherwise.
+func externalDependencies(rp *RenderPassObject, before bool) []syncDependency {
+	out := []syncDependency{}
+	if rp == nil {
+		return out
+	}
+	for _, i := range rp.SubpassDependencies.Keys() {
+		d := rp.SubpassDependencies.Get(i)
+		if (before && d.SrcSubpass == vkSubpassExternal) || (!before && d.DstSubpass == vkSubpassExternal) {
+			out = append(out, syncDependency{
+				src: syncScope{d.SrcStageMask, d.SrcAccessMask},
+				dst: syncScope{d.DstStageMask, d.DstAccessMask},
+			})
+		}
+	}
+	return out
+}
+
+// pendingWrite is a write to device memory that is not yet synchronized with
+// all the following accesses.
+type pendingWrite struct {
+	span  interval.U64Span
+	owner api.SubCmdIdx
+	queue VkQueue
+	// scope holds the stages and the write accesses of the write.
+	scope syncScope
+	// renderPass is the render pass instance of the writes to attachments.
+	renderPass *forwardPairedLabel
+	// visible holds the scopes of the accesses the write is visible to.
+	visible []visibility
+}
+
+// visibility is the scope of the accesses of a queue that a write is visible
+// to.
+type visibility struct {
+	queue VkQueue
+	scope syncScope
+}
+
+// synchronized returns true if w is visible to an access of queue in the
+// scope a. Writes only need to be ordered after w, while reads also need the
+// written memory to be visible to their accesses.
+func (w *pendingWrite) synchronized(queue VkQueue, a syncScope, isWrite bool) bool {
+	for _, v := range w.visible {
+		if v.queue != queue || v.scope.stages&a.stages == 0 {
+			continue
+		}
+		if isWrite || v.scope.access&a.access&readAccesses != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// syncHazardTracker tracks the pending writes to each device memory.
+type syncHazardTracker struct {
+	sync      *footprintSyncInfo
+	pending   map[VkDeviceMemory][]*pendingWrite
+	signalled map[VkSemaphore][]*pendingWrite
+	hazards   []api.SyncHazard
+}
+
+func newSyncHazardTracker(sync *footprintSyncInfo) *syncHazardTracker {
+	return &syncHazardTracker{
+		sync:      sync,
+		pending:   map[VkDeviceMemory][]*pendingWrite{},
+		signalled: map[VkSemaphore][]*pendingWrite{},
+		hazards:   []api.SyncHazard{},
+	}
+}
+
+// process checks the accesses of the behavior bh of a submitted command,
+// owned by the command owner in the capture, and updates the pending writes.
+func (t *syncHazardTracker) process(bh *dependencygraph.Behavior, owner api.SubCmdIdx) {
+	queue, ok := t.sync.queues[bh]
+	if !ok {
+		return
+	}
+	if semaphores, ok := t.sync.semaphoreWaits[bh]; ok {
+		for _, s := range semaphores {
+			for _, w := range t.signalled[s] {
+				w.visible = append(w.visible, visibility{queue, fullScope})
+			}
+			delete(t.signalled, s)
+		}
+		return
+	}
+	if semaphores, ok := t.sync.semaphoreSignals[bh]; ok {
+		writes := []*pendingWrite{}
+		for _, l := range t.pending {
+			for _, w := range l {
+				if w.queue == queue {
+					writes = append(writes, w)
+				}
+			}
+		}
+		for _, s := range semaphores {
+			t.signalled[s] = writes
+		}
+		return
+	}
+	if dep, ok := t.sync.barriers[bh]; ok {
+		for _, d := range bh.Writes {
+			if ms, ok := d.(memorySpan); ok {
+				for _, w := range t.pending[ms.memory] {
+					if overlaps(w.span, ms.span) {
+						w.synchronize(queue, dep)
+					}
+				}
+			}
+		}
+		return
+	}
+
+	for _, dep := range t.sync.dependenciesBefore[bh] {
+		t.synchronizeAll(queue, dep)
+	}
+	t.access(bh, owner, queue)
+	for _, dep := range t.sync.dependenciesAfter[bh] {
+		t.synchronizeAll(queue, dep)
+	}
+}
+
+// synchronize makes w visible to the second scope of the dependency dep of
+// queue, if w is in its first scope.
+func (w *pendingWrite) synchronize(queue VkQueue, dep syncDependency) {
+	src := dep.src.expand(true)
+	if w.queue == queue && w.scope.stages&src.stages != 0 && w.scope.access&src.access != 0 {
+		w.visible = append(w.visible, visibility{queue, dep.dst.expand(false)})
+	}
+}
+
+// synchronizeAll applies the dependency dep of queue to all the pending writes.
+func (t *syncHazardTracker) synchronizeAll(queue VkQueue, dep syncDependency) {
+	for _, l := range t.pending {
+		for _, w := range l {
+			w.synchronize(queue, dep)
+		}
+	}
+}
+
+// access checks the reads and writes of bh against the pending writes, and
+// records the writes of bh as pending.
+func (t *syncHazardTracker) access(bh *dependencygraph.Behavior, owner api.SubCmdIdx, queue VkQueue) {
+	scope, ok := t.sync.scopes[bh]
+	if !ok {
+		scope = fullScope
+	}
+	renderPass := t.sync.renderPasses[bh]
+	attachments := map[memorySpan]bool{}
+	for _, d := range t.sync.drawAttachments[bh] {
+		if ms, ok := d.(memorySpan); ok {
+			attachments[ms] = true
+		}
+	}
+	isAttachment := func(ms memorySpan) bool {
+		return t.sync.renderPassOps[bh] || attachments[ms]
+	}
+
+	reported := map[string]bool{}
+	check := func(ms memorySpan, isWrite bool) {
+		a := scope
+		if isAttachment(ms) {
+			a = attachmentScope
+		}
+		for _, w := range t.pending[ms.memory] {
+			if !overlaps(w.span, ms.span) || w.owner.Equals(owner) {
+				continue
+			}
+			if w.renderPass != nil && w.renderPass == renderPass && isAttachment(ms) {
+				continue
+			}
+			if w.synchronized(queue, a, isWrite) {
+				continue
+			}
+			h := api.SyncHazard{Write: w.owner, Access: owner, IsWrite: isWrite}
+			if key := fmt.Sprint(h.Write, h.Access); !reported[key] {
+				reported[key] = true
+				t.hazards = append(t.hazards, h)
+			}
+		}
+	}
+
+	for _, r := range bh.Reads {
+		if ms, ok := r.(memorySpan); ok {
+			check(ms, false)
+		}
+	}
+	for _, d := range bh.Writes {
+		ms, ok := d.(memorySpan)
+		if !ok {
+			continue
+		}
+		check(ms, true)
+		w := &pendingWrite{
+			span:  ms.span,
+			owner: owner,
+			queue: queue,
+			scope: syncScope{scope.stages, scope.access & writeAccesses},
+		}
+		if isAttachment(ms) {
+			w.scope = syncScope{attachmentStages, attachmentAccesses & writeAccesses}
+			w.renderPass = renderPass
+		}
+		t.pending[ms.memory] = append(t.remove(ms), w)
+	}
+}
+
+// remove returns the pending writes to the memory of ms that do not overlap
+// with ms.
+func (t *syncHazardTracker) remove(ms memorySpan) []*pendingWrite {
+	out := []*pendingWrite{}
+	for _, w := range t.pending[ms.memory] {
+		if !overlaps(w.span, ms.span) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func overlaps(a, b interval.U64Span) bool {
+	return a.Start < b.End && b.Start < a.End
+}
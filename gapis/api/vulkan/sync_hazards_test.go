@@ -0,0 +1,225 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vulkan
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/math/interval"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+)
+
+const (
+	queueA = VkQueue(1)
+	queueB = VkQueue(2)
+)
+
+// syncTest builds the behaviors of the submitted commands of a sync hazard
+// test, in execution order.
+type syncTest struct {
+	sync      *footprintSyncInfo
+	behaviors []*dependencygraph.Behavior
+}
+
+func newSyncTest() *syncTest {
+	return &syncTest{sync: newFootprintSyncInfo()}
+}
+
+func memoryBytes(start, end uint64) memorySpan {
+	return memorySpan{span: interval.U64Span{Start: start, End: end}, memory: VkDeviceMemory(1)}
+}
+
+func (s *syncTest) add(owner api.SubCmdIdx, queue VkQueue, reads, writes []memorySpan) *dependencygraph.Behavior {
+	bh := dependencygraph.NewBehavior(owner, nil)
+	for _, r := range reads {
+		bh.Read(r)
+	}
+	for _, w := range writes {
+		bh.Write(w)
+	}
+	s.sync.queues[bh] = queue
+	s.behaviors = append(s.behaviors, bh)
+	return bh
+}
+
+// cmd adds a command accessing memory with the given scope.
+func (s *syncTest) cmd(owner api.SubCmdIdx, queue VkQueue, scope syncScope, reads, writes []memorySpan) *dependencygraph.Behavior {
+	bh := s.add(owner, queue, reads, writes)
+	s.sync.scopes[bh] = scope
+	return bh
+}
+
+// barrier adds a barrier with the dependency dep for the memory ms.
+func (s *syncTest) barrier(owner api.SubCmdIdx, queue VkQueue, dep syncDependency, ms memorySpan) {
+	bh := s.add(owner, queue, []memorySpan{ms}, []memorySpan{ms})
+	s.sync.barriers[bh] = dep
+}
+
+func (s *syncTest) signal(submit uint64, queue VkQueue, sem VkSemaphore) {
+	s.sync.semaphoreSignals[s.add(api.SubCmdIdx{submit}, queue, nil, nil)] = []VkSemaphore{sem}
+}
+
+func (s *syncTest) wait(submit uint64, queue VkQueue, sem VkSemaphore) {
+	s.sync.semaphoreWaits[s.add(api.SubCmdIdx{submit}, queue, nil, nil)] = []VkSemaphore{sem}
+}
+
+func (s *syncTest) hazards() []api.SyncHazard {
+	t := newSyncHazardTracker(s.sync)
+	for _, bh := range s.behaviors {
+		t.process(bh, bh.Owner)
+	}
+	return t.hazards
+}
+
+func TestSyncHazards(t *testing.T) {
+	ctx := log.Testing(t)
+	data := memoryBytes(0, 256)
+	copyCmd := api.SubCmdIdx{1, 0, 0, 0}
+	drawCmd := api.SubCmdIdx{1, 0, 0, 2}
+	otherQueueCmd := api.SubCmdIdx{2, 0, 0, 0}
+	fragmentRead := syncScope{
+		VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_FRAGMENT_SHADER_BIT),
+		VkAccessFlags(VkAccessFlagBits_VK_ACCESS_SHADER_READ_BIT),
+	}
+	transferWrite := syncScope{
+		VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TRANSFER_BIT),
+		VkAccessFlags(VkAccessFlagBits_VK_ACCESS_TRANSFER_WRITE_BIT),
+	}
+	rawHazard := func(write, access api.SubCmdIdx) []api.SyncHazard {
+		return []api.SyncHazard{{Write: write, Access: access}}
+	}
+
+	for _, test := range []struct {
+		name     string
+		build    func(s *syncTest)
+		expected []api.SyncHazard
+	}{
+		{"no barrier", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, drawCmd)},
+		{"barrier", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{transferWrite, fragmentRead}, data)
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, []api.SyncHazard{}},
+		{"barrier for other memory", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{transferWrite, fragmentRead}, memoryBytes(256, 512))
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, drawCmd)},
+		{"barrier without the write stage", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{
+				syncScope{VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COMPUTE_SHADER_BIT), transferWrite.access},
+				fragmentRead,
+			}, data)
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, drawCmd)},
+		{"barrier without the write access", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{
+				syncScope{transferWrite.stages, 0},
+				fragmentRead,
+			}, data)
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, drawCmd)},
+		{"barrier without the read stage", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{transferWrite, transferScope}, data)
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, drawCmd)},
+		{"all commands barrier", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			all := syncScope{
+				VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_ALL_COMMANDS_BIT),
+				VkAccessFlags(VkAccessFlagBits_VK_ACCESS_MEMORY_READ_BIT | VkAccessFlagBits_VK_ACCESS_MEMORY_WRITE_BIT),
+			}
+			s.barrier(api.SubCmdIdx{1, 0, 0, 1}, queueA, syncDependency{all, all}, data)
+			s.cmd(drawCmd, queueA, drawScope, []memorySpan{data}, nil)
+		}, []api.SyncHazard{}},
+		{"write after write", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.cmd(drawCmd, queueA, drawScope, nil, []memorySpan{memoryBytes(128, 384)})
+		}, []api.SyncHazard{{Write: copyCmd, Access: drawCmd, IsWrite: true}}},
+		{"barrier on other queue", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.barrier(api.SubCmdIdx{2, 0, 0, 0}, queueB, syncDependency{transferWrite, fragmentRead}, data)
+			s.cmd(api.SubCmdIdx{2, 0, 0, 1}, queueB, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, api.SubCmdIdx{2, 0, 0, 1})},
+		{"semaphore", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.signal(1, queueA, VkSemaphore(1))
+			s.wait(2, queueB, VkSemaphore(1))
+			s.cmd(otherQueueCmd, queueB, drawScope, []memorySpan{data}, nil)
+		}, []api.SyncHazard{}},
+		{"semaphore signalled before the write", func(s *syncTest) {
+			s.signal(0, queueA, VkSemaphore(1))
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.wait(2, queueB, VkSemaphore(1))
+			s.cmd(otherQueueCmd, queueB, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, otherQueueCmd)},
+		{"semaphore of other queue", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.signal(3, queueB, VkSemaphore(1))
+			s.wait(2, queueB, VkSemaphore(1))
+			s.cmd(otherQueueCmd, queueB, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, otherQueueCmd)},
+		{"semaphore wait is not a barrier on the same queue", func(s *syncTest) {
+			s.cmd(copyCmd, queueA, transferScope, nil, []memorySpan{data})
+			s.signal(1, queueA, VkSemaphore(1))
+			s.wait(2, queueB, VkSemaphore(1))
+			s.cmd(api.SubCmdIdx{3, 0, 0, 0}, queueA, drawScope, []memorySpan{data}, nil)
+		}, rawHazard(copyCmd, api.SubCmdIdx{3, 0, 0, 0})},
+		{"attachment writes of a render pass", func(s *syncTest) {
+			rp := &forwardPairedLabel{}
+			draw := s.cmd(drawCmd, queueA, drawScope, nil, []memorySpan{data})
+			s.sync.drawAttachments[draw] = []dependencygraph.DefUseVariable{data}
+			s.sync.renderPasses[draw] = rp
+			store := s.cmd(api.SubCmdIdx{1, 0, 0, 3}, queueA, attachmentScope, nil, []memorySpan{data})
+			s.sync.renderPassOps[store] = true
+			s.sync.renderPasses[store] = rp
+		}, []api.SyncHazard{}},
+		{"store without dependency", func(s *syncTest) {
+			store := s.cmd(api.SubCmdIdx{1, 0, 0, 3}, queueA, attachmentScope, nil, []memorySpan{data})
+			s.sync.renderPassOps[store] = true
+			s.sync.renderPasses[store] = &forwardPairedLabel{}
+			s.cmd(api.SubCmdIdx{1, 0, 0, 4}, queueA, transferScope, []memorySpan{data}, nil)
+		}, rawHazard(api.SubCmdIdx{1, 0, 0, 3}, api.SubCmdIdx{1, 0, 0, 4})},
+		{"store with external dependency", func(s *syncTest) {
+			store := s.cmd(api.SubCmdIdx{1, 0, 0, 3}, queueA, attachmentScope, nil, []memorySpan{data})
+			s.sync.renderPassOps[store] = true
+			s.sync.renderPasses[store] = &forwardPairedLabel{}
+			s.sync.dependenciesAfter[store] = []syncDependency{{
+				syncScope{
+					VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_COLOR_ATTACHMENT_OUTPUT_BIT),
+					VkAccessFlags(VkAccessFlagBits_VK_ACCESS_COLOR_ATTACHMENT_WRITE_BIT),
+				},
+				syncScope{
+					VkPipelineStageFlags(VkPipelineStageFlagBits_VK_PIPELINE_STAGE_TRANSFER_BIT),
+					VkAccessFlags(VkAccessFlagBits_VK_ACCESS_TRANSFER_READ_BIT),
+				},
+			}}
+			s.cmd(api.SubCmdIdx{1, 0, 0, 4}, queueA, transferScope, []memorySpan{data}, nil)
+		}, []api.SyncHazard{}},
+	} {
+		s := newSyncTest()
+		test.build(s)
+		assert.For(ctx, "%v", test.name).ThatSlice(s.hazards()).DeepEquals(test.expected)
+	}
+}
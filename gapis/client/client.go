@@ -162,6 +162,30 @@ func (c *client) GetProfile(ctx context.Context, name string, debug int32) ([]by
 	return res.GetData(), nil
 }
 
+func (c *client) SetDebugConfig(ctx context.Context, cfg *service.DebugConfig) error {
+	res, err := c.client.SetDebugConfig(ctx, &service.SetDebugConfigRequest{
+		Config: cfg,
+	})
+	if err != nil {
+		return err
+	}
+	if err := res.GetError(); err != nil {
+		return err.Get()
+	}
+	return nil
+}
+
+func (c *client) GetDebugConfig(ctx context.Context) (*service.DebugConfig, error) {
+	res, err := c.client.GetDebugConfig(ctx, &service.GetDebugConfigRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.GetError(); err != nil {
+		return nil, err.Get()
+	}
+	return res.GetConfig(), nil
+}
+
 func (c *client) GetAvailableStringTables(ctx context.Context) ([]*stringtable.Info, error) {
 	res, err := c.client.GetAvailableStringTables(ctx, &service.GetAvailableStringTablesRequest{})
 	if err != nil {
@@ -275,6 +299,33 @@ func (c *client) GetFramebufferAttachment(
 	return res.GetImage(), nil
 }
 
+func (c *client) CheckReplayDeterminism(
+	ctx context.Context,
+	repS *service.ReplaySettings,
+	capture *path.Capture,
+	cmds []*path.Command,
+	drawCalls bool,
+	replays uint32,
+	tolerance float32,
+) (*service.DeterminismReport, error) {
+
+	res, err := c.client.CheckReplayDeterminism(ctx, &service.CheckReplayDeterminismRequest{
+		ReplaySettings: repS,
+		Capture:        capture,
+		Commands:       cmds,
+		DrawCalls:      drawCalls,
+		Replays:        replays,
+		Tolerance:      tolerance,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.GetError(); err != nil {
+		return nil, err.Get()
+	}
+	return res.GetReport(), nil
+}
+
 func (c *client) GetLogStream(ctx context.Context, handler log.Handler) error {
 	stream, err := c.client.GetLogStream(ctx, &service.GetLogStreamRequest{})
 	if err != nil {
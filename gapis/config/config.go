@@ -12,16 +12,66 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package config contains a list of build configuration flags.
+// Package config holds the runtime debugging configuration of gapis.
 package config
 
-const (
-	DebugReplay                = false
-	DebugReplayBuilder         = false
-	DisableDeadCodeElimination = false
-	DebugDeadCodeElimination   = false
-	LogExtrasInTransforms      = false // Logs all atoms' extras together with transforms
-	LogMemoryInExtras          = false // Logs all atoms' read/write memory observation together with extras
-	LogTransformsToFile        = false
-	SeparateMutateStates       = false
+import (
+	"context"
+	"sync"
+
+	"github.com/google/gapid/core/context/keys"
 )
+
+// Debug is the set of debugging options of gapis.
+// Debug is comparable, and so can be used as part of map keys.
+type Debug struct {
+	DebugReplay                bool
+	DebugReplayBuilder         bool
+	DisableDeadCodeElimination bool
+	DebugDeadCodeElimination   bool
+	LogExtrasInTransforms      bool   // Logs all atoms' extras together with transforms
+	LogMemoryInExtras          bool   // Logs all atoms' read/write memory observation together with extras
+	LogTransformsToFile        bool   // Logs the commands output by each transform to a file
+	SeparateMutateStates       bool   // Mutates a separate state for each transform
+	ProfileTransforms          bool   // Records the time, commands and allocations of each transform
+	TransformTraceFile         string // Path of the Chrome trace JSON file for ProfileTransforms
+}
+
+var (
+	global      Debug
+	globalMutex sync.RWMutex
+)
+
+// Global returns the server-wide debug configuration.
+func Global() Debug {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+	return global
+}
+
+// SetGlobal replaces the server-wide debug configuration with d.
+// Requests that have already started are not affected.
+func SetGlobal(d Debug) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+	global = d
+}
+
+type contextKeyTy string
+
+const contextKey = contextKeyTy("debugConfig")
+
+// Put attaches the debug configuration d to the returned context, overriding
+// the global configuration for everything using that context.
+func Put(ctx context.Context, d Debug) context.Context {
+	return keys.WithValue(ctx, contextKey, d)
+}
+
+// Get returns the debug configuration attached to the context, or the global
+// configuration if there is none.
+func Get(ctx context.Context) Debug {
+	if d, ok := ctx.Value(contextKey).(Debug); ok {
+		return d
+	}
+	return Global()
+}
@@ -54,6 +54,7 @@ func findABI(ml *device.MemoryLayout, abis []*device.ABI) *device.ABI {
 
 func (m *Manager) batch(ctx context.Context, e []scheduler.Executable, b scheduler.Batch) {
 	batch := b.Key.(batchKey)
+	ctx = config.Put(ctx, batch.debug)
 
 	d := bind.GetRegistry(ctx).Device(batch.device)
 
@@ -67,7 +68,8 @@ func (m *Manager) batch(ctx context.Context, e []scheduler.Executable, b schedul
 
 	err := func() error {
 		if d == nil {
-			return log.Errf(ctx, nil, "Unknown device %v", batch.device)
+			// The device was disconnected since the batch was scheduled.
+			return deviceFailure{log.Errf(ctx, nil, "Unknown device %v", batch.device)}
 		}
 
 		defer analytics.SendTiming("replay", "batch")(
@@ -84,9 +86,11 @@ func (m *Manager) batch(ctx context.Context, e []scheduler.Executable, b schedul
 	}()
 
 	if err != nil {
-		analytics.SendEvent("replay", "batch", "failure",
-			analytics.TargetDevice(d.Instance().GetConfiguration()),
-		)
+		if d != nil {
+			analytics.SendEvent("replay", "batch", "failure",
+				analytics.TargetDevice(d.Instance().GetConfiguration()),
+			)
+		}
 		for _, e := range requests {
 			e.Result(nil, err)
 		}
@@ -119,7 +123,7 @@ func (m *Manager) execute(
 		"device":  d.Instance().GetName(),
 	}.Bind(ctx)
 
-	intent := Intent{path.NewDevice(deviceID), capturePath}
+	intent := Intent{Device: path.NewDevice(deviceID), Capture: capturePath}
 
 	cml := c.Header.Abi.MemoryLayout
 	ctx = log.V{"capture memory layout": cml}.Bind(ctx)
@@ -159,7 +163,7 @@ func (m *Manager) execute(
 		return log.Err(ctx, err, "Replay returned error")
 	}
 
-	if config.DebugReplay {
+	if config.Get(ctx).DebugReplay {
 		log.I(ctx, "Building payload...")
 	}
 
@@ -172,11 +176,11 @@ func (m *Manager) execute(
 
 	connection, err := m.gapir.Connect(ctx, d, replayABI)
 	if err != nil {
-		return log.Err(ctx, err, "Failed to connect to device")
+		return deviceFailure{log.Err(ctx, err, "Failed to connect to device")}
 	}
 	defer connection.Close()
 
-	if config.DebugReplay {
+	if config.Get(ctx).DebugReplay {
 		log.I(ctx, "Sending payload")
 	}
 
@@ -194,6 +198,9 @@ func (m *Manager) execute(
 			d.Instance().GetConfiguration().GetOS(),
 		)
 	})
+	if isConnectionError(err) {
+		return deviceFailure{err}
+	}
 	return err
 }
 
@@ -561,7 +561,7 @@ func (b *Builder) Write(rng memory.Range, resourceID id.ID) {
 // the responses.
 func (b *Builder) Build(ctx context.Context) (protocol.Payload, ResponseDecoder, error) {
 	ctx = log.Enter(ctx, "Build")
-	if config.DebugReplayBuilder {
+	if config.Get(ctx).DebugReplayBuilder {
 		log.I(ctx, "Instruction count: %d", len(b.instructions))
 		b.assertResourceSizesAreAsExpected(ctx)
 	}
@@ -592,7 +592,7 @@ func (b *Builder) Build(ctx context.Context) (protocol.Payload, ResponseDecoder,
 		Opcodes:            opcodes.Bytes(),
 	}
 
-	if config.DebugReplayBuilder {
+	if config.Get(ctx).DebugReplayBuilder {
 		log.I(ctx, "Stack size:           0x%x", payload.StackSize)
 		log.I(ctx, "Volatile memory size: 0x%x", payload.VolatileMemorySize)
 		log.I(ctx, "Constant memory size: 0x%x", len(payload.Constants))
@@ -701,7 +701,7 @@ func (b *Builder) layoutVolatileMemory(ctx context.Context, w binary.Writer) *vo
 		memoryLayout:         b.memoryLayout,
 	}
 
-	if config.DebugReplayBuilder {
+	if config.Get(ctx).DebugReplayBuilder {
 		log.I(ctx, "Volatile memory layout: [0x%x, 0x%x]", 0, size-1)
 		log.I(ctx, "  Heap:      [0x%x, 0x%x]", heapStart, heapEnd)
 		log.I(ctx, "  Temporary: [0x%x, 0x%x]", tempStart, tempEnd)
@@ -150,15 +150,15 @@ func (e executor) handleReplayCommunication(ctx context.Context, connection io.R
 		switch protocol.MessageType(msg) {
 		case protocol.MessageType_Get:
 			if err := e.handleGetData(ctx, r, w); err != nil {
-				return fmt.Errorf("Failed to read replay postback data: %v", err)
+				return log.Err(ctx, err, "Failed to read replay postback data")
 			}
 		case protocol.MessageType_Post:
 			if err := e.handleDataResponse(ctx, r, postbacks); err != nil {
-				return fmt.Errorf("Failed to send replay resource data: %v", err)
+				return log.Err(ctx, err, "Failed to send replay resource data")
 			}
 		case protocol.MessageType_Crash:
 			if err := e.handleCrash(ctx, r); err != nil {
-				return fmt.Errorf("Failed to handle crash sent by gapir: %v", err)
+				return log.Err(ctx, err, "Failed to handle crash sent by gapir")
 			}
 			// replay crashed, will we get an EOF next, or should we return here
 		default:
@@ -16,6 +16,8 @@ package replay
 
 import (
 	"context"
+	"io"
+	"net"
 	"sync"
 
 	"time"
@@ -24,6 +26,8 @@ import (
 	"github.com/google/gapid/core/log"
 	"github.com/google/gapid/core/os/device/bind"
 	gapir "github.com/google/gapid/gapir/client"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/config"
 	"github.com/google/gapid/gapis/replay/scheduler"
 	"github.com/google/gapid/gapis/service"
 )
@@ -53,6 +57,7 @@ type batchKey struct {
 	device    id.ID
 	config    Config
 	generator Generator
+	debug     config.Debug
 }
 
 // New returns a new Manager instance using the database db.
@@ -65,9 +70,46 @@ func New(ctx context.Context) *Manager {
 	return out
 }
 
+// deviceFailure wraps errors raised communicating with the replay device, such
+// as a lost connection to gapir. Replays failing with a deviceFailure may
+// succeed on another device, unlike replays failing deterministically.
+type deviceFailure struct{ error }
+
+// isConnectionError returns true if err, or any of its causes, is raised by
+// the loss of a connection.
+func isConnectionError(err error) bool {
+	for err != nil {
+		switch err {
+		case io.ErrUnexpectedEOF, io.ErrClosedPipe:
+			return true
+		}
+		if _, ok := err.(net.Error); ok {
+			return true
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+// unwrapDeviceFailure returns the error wrapped by err if it is a
+// deviceFailure, otherwise err.
+func unwrapDeviceFailure(err error) error {
+	if f, ok := err.(deviceFailure); ok {
+		return f.error
+	}
+	return err
+}
+
 // Replay requests that req is to be performed on the device described by intent,
 // using the capture described by intent. Replay requests made with configs that
 // have equality (==) will likely be batched into the same replay pass.
+// If intent.DevicePool is true then the request is scheduled on the least
+// busy device compatible with intent.Device, and is retried on the other
+// compatible devices should the replay device fail.
 func (m *Manager) Replay(
 	ctx context.Context,
 	intent Intent,
@@ -77,7 +119,46 @@ func (m *Manager) Replay(
 	hints *service.UsageHints) (val interface{}, err error) {
 
 	log.D(ctx, "Replay request")
-	s, err := m.scheduler(ctx, intent.Device.Id.ID())
+	if !intent.DevicePool {
+		val, err := m.schedule(ctx, intent.Device.Id.ID(), intent, cfg, req, generator, hints)
+		return val, unwrapDeviceFailure(err)
+	}
+
+	pool, err := m.devicePool(ctx, intent, generator)
+	if err != nil {
+		return nil, err
+	}
+	failed := map[id.ID]bool{}
+	var lastFailure error
+	for {
+		deviceID, err := pool.next(ctx, m, failed)
+		if err != nil {
+			if lastFailure != nil {
+				return nil, unwrapDeviceFailure(lastFailure)
+			}
+			return nil, err
+		}
+		val, err := m.schedule(ctx, deviceID, intent, cfg, req, generator, hints)
+		if _, ok := err.(deviceFailure); !ok {
+			return val, err
+		}
+		log.W(ctx, "Replay device %v failed, retrying on another device: %v", deviceID, err)
+		failed[deviceID] = true
+		lastFailure = err
+	}
+}
+
+// schedule schedules the request on the scheduler for the device deviceID.
+func (m *Manager) schedule(
+	ctx context.Context,
+	deviceID id.ID,
+	intent Intent,
+	cfg Config,
+	req Request,
+	generator Generator,
+	hints *service.UsageHints) (val interface{}, err error) {
+
+	s, err := m.scheduler(ctx, deviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -85,9 +166,10 @@ func (m *Manager) Replay(
 	b := scheduler.Batch{
 		Key: batchKey{
 			capture:   intent.Capture.Id.ID(),
-			device:    intent.Device.Id.ID(),
+			device:    deviceID,
 			config:    cfg,
 			generator: generator,
+			debug:     config.Get(ctx),
 		},
 		Priority:     defaultPriority,
 		Precondition: defaultBatchDelay,
@@ -108,6 +190,79 @@ func (m *Manager) Replay(
 	return s.Schedule(ctx, req, b)
 }
 
+// devicePool is the set of the devices that can replay a request in place of
+// the requested device.
+type devicePool struct {
+	requested id.ID
+	// support is nil if the generator cannot tell the replay priority of the
+	// devices, in which case only the requested device is in the pool.
+	support  Support
+	header   *capture.Header
+	priority uint32
+}
+
+// devicePool returns the pool of the devices that have the same, non-zero,
+// replay priority as intent.Device. The priority is resolved once, so the
+// pool outlives the disconnection of the requested device.
+func (m *Manager) devicePool(ctx context.Context, intent Intent, generator Generator) (*devicePool, error) {
+	pool := &devicePool{requested: intent.Device.Id.ID()}
+	support, ok := generator.(Support)
+	if !ok {
+		return pool, nil
+	}
+
+	c, err := capture.ResolveFromPath(ctx, intent.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	d := bind.GetRegistry(ctx).Device(pool.requested)
+	if d == nil {
+		return nil, log.Errf(ctx, nil, "Unknown device %v", pool.requested)
+	}
+	pool.support, pool.header = support, c.Header
+	pool.priority = support.GetReplayPriority(ctx, d.Instance(), c.Header)
+	if pool.priority == 0 {
+		return nil, log.Errf(ctx, nil, "Device %v cannot replay the capture", pool.requested)
+	}
+	return pool, nil
+}
+
+// next returns the identifier of the device of the pool with the fewest
+// queued tasks. Devices in exclude are not considered.
+func (p *devicePool) next(ctx context.Context, m *Manager, exclude map[id.ID]bool) (id.ID, error) {
+	if p.support == nil {
+		if exclude[p.requested] {
+			return id.ID{}, log.Err(ctx, nil, "Replay device failed")
+		}
+		return p.requested, nil
+	}
+
+	registry := bind.GetRegistry(ctx)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	best, bestQueued := id.ID{}, -1
+	for deviceID, s := range m.schedulers {
+		if exclude[deviceID] {
+			continue
+		}
+		d := registry.Device(deviceID)
+		if d == nil || p.support.GetReplayPriority(ctx, d.Instance(), p.header) != p.priority {
+			continue
+		}
+		queued := s.NumTasksQueued()
+		if bestQueued < 0 || queued < bestQueued || (queued == bestQueued && deviceID == p.requested) {
+			best, bestQueued = deviceID, queued
+		}
+	}
+	if bestQueued < 0 {
+		return id.ID{}, log.Err(ctx, nil, "No compatible replay devices remaining")
+	}
+	return best, nil
+}
+
 func (m *Manager) scheduler(ctx context.Context, deviceID id.ID) (*scheduler.Scheduler, error) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -0,0 +1,107 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/replay/scheduler"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	ctx := log.Testing(t)
+	other := errors.New("invalid payload")
+	for _, test := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"closed pipe", io.ErrClosedPipe, true},
+		{"network error", &net.OpError{Op: "read", Err: other}, true},
+		{"wrapped unexpected EOF", log.Err(ctx, io.ErrUnexpectedEOF, "Failed to read"), true},
+		{"other error", other, false},
+		{"wrapped other error", log.Err(ctx, other, "Failed to replay"), false},
+	} {
+		assert.For(ctx, "%v", test.name).That(isConnectionError(test.err)).Equals(test.expected)
+	}
+}
+
+func TestUnwrapDeviceFailure(t *testing.T) {
+	ctx := log.Testing(t)
+	err := errors.New("lost device")
+	assert.For(ctx, "device failure").That(unwrapDeviceFailure(deviceFailure{err})).Equals(err)
+	assert.For(ctx, "other error").That(unwrapDeviceFailure(err)).Equals(err)
+	assert.For(ctx, "nil").ThatError(unwrapDeviceFailure(nil)).Succeeded()
+}
+
+// testSupport returns the replay priority of the devices by name.
+type testSupport map[string]uint32
+
+func (s testSupport) GetReplayPriority(ctx context.Context, i *device.Instance, h *capture.Header) uint32 {
+	return s[i.Name]
+}
+
+func TestDevicePoolNext(t *testing.T) {
+	ctx := log.Testing(t)
+	r := bind.NewRegistry()
+	ctx = bind.PutRegistry(ctx, r)
+	m := &Manager{schedulers: map[id.ID]*scheduler.Scheduler{}}
+	ids := map[string]id.ID{}
+	for i, name := range []string{"requested", "compatible", "other"} {
+		instance := &device.Instance{Id: device.NewID(id.ID{byte(i + 1)}), Name: name}
+		ids[name] = instance.Id.ID()
+		r.AddDevice(ctx, &bind.Simple{To: instance})
+		m.schedulers[ids[name]] = scheduler.New(ctx, func(context.Context, []scheduler.Executable, scheduler.Batch) {})
+	}
+	support := testSupport{"requested": 1, "compatible": 1, "other": 2}
+
+	for _, test := range []struct {
+		name     string
+		pool     *devicePool
+		exclude  []string
+		expected string
+	}{
+		{"requested device", &devicePool{requested: ids["requested"], support: support, priority: 1}, nil, "requested"},
+		{"compatible device", &devicePool{requested: ids["requested"], support: support, priority: 1}, []string{"requested"}, "compatible"},
+		{"no compatible device", &devicePool{requested: ids["requested"], support: support, priority: 1}, []string{"requested", "compatible"}, ""},
+		{"no support", &devicePool{requested: ids["requested"]}, nil, "requested"},
+		{"no support, failed", &devicePool{requested: ids["requested"]}, []string{"requested"}, ""},
+	} {
+		exclude := map[id.ID]bool{}
+		for _, name := range test.exclude {
+			exclude[ids[name]] = true
+		}
+		got, err := test.pool.next(ctx, m, exclude)
+		if test.expected == "" {
+			assert.For(ctx, "%v", test.name).ThatError(err).Failed()
+			continue
+		}
+		if assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded() {
+			assert.For(ctx, "%v", test.name).That(got).Equals(ids[test.expected])
+		}
+	}
+}
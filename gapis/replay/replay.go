@@ -42,8 +42,9 @@ type Generator interface {
 // Intent describes the source capture and replay target information used for
 // issuing a replay request.
 type Intent struct {
-	Device  *path.Device  // The path to the device being used for replay.
-	Capture *path.Capture // The path to the capture that is being replayed.
+	Device     *path.Device  // The path to the device being used for replay.
+	Capture    *path.Capture // The path to the capture that is being replayed.
+	DevicePool bool          // If true, any device compatible with Device may perform the replay.
 }
 
 // Config is a user-defined type used to describe the type of replay being
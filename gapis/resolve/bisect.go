@@ -0,0 +1,243 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/core/image"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// Bisect resolves and returns the result of bisecting the draw calls of the
+// frame ending at p.Command.
+func Bisect(ctx context.Context, p *path.Bisect) (*service.BisectResult, error) {
+	obj, err := database.Build(ctx, &BisectResolvable{
+		Path:        p,
+		DebugConfig: debugConfig(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.BisectResult), nil
+}
+
+// bisector holds the images shared by each step of a bisect.
+type bisector struct {
+	path          *path.Bisect
+	width, height uint32
+	expected      *image.Data // The framebuffer observation. Nil if comparing devices.
+	final         *image.Data // The replay at the end of the frame. Nil if comparing devices.
+}
+
+// Resolve implements the database.Resolver interface.
+func (r *BisectResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	end := r.Path.Command
+	ctx = capture.Put(ctx, path.FindCapture(end))
+	ctx = r.DebugConfig.Put(ctx)
+
+	candidates, err := bisectCandidates(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bisector{path: r.Path}
+	if r.Path.ReferenceDevice == nil {
+		observation, err := FramebufferObservation(ctx, end.FramebufferObservation())
+		if err != nil {
+			return nil, err
+		}
+		b.width, b.height = observation.Width, observation.Height
+		if b.expected, err = imageData(ctx, observation, b.width, b.height); err != nil {
+			return nil, err
+		}
+		if b.final, err = b.replay(ctx, r.Path.Device, end); err != nil {
+			return nil, err
+		}
+	} else {
+		changes, err := FramebufferChanges(ctx, path.FindCapture(end))
+		if err != nil {
+			return nil, err
+		}
+		info, err := changes.Get(ctx, end, api.FramebufferAttachment_Color0)
+		if err != nil {
+			return nil, err
+		}
+		b.width, b.height = info.Width, info.Height
+	}
+
+	out := &service.BisectResult{}
+	step := func(cmd *path.Command) (*service.BisectStep, error) {
+		diff, err := b.difference(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		s := &service.BisectStep{
+			Command:    cmd,
+			Difference: diff,
+			Diverged:   diff > r.Path.Threshold,
+		}
+		log.D(ctx, "Bisect %v: difference %v", cmd, diff)
+		out.Steps = append(out.Steps, s)
+		return s, nil
+	}
+
+	// The end of the frame is probed first, as there is nothing to search for
+	// if the replay of the whole frame matches the reference.
+	last, err := step(end)
+	if err != nil {
+		return nil, err
+	}
+	out.FrameDifference = last.Difference
+	if !last.Diverged {
+		return out, nil
+	}
+
+	// Invariant: candidates[hi] has diverged, all candidates before lo have not.
+	lo, hi, found := 0, len(candidates)-1, last
+	for lo < hi {
+		mid := (lo + hi) / 2
+		s, err := step(candidates[mid])
+		if err != nil {
+			return nil, err
+		}
+		if s.Diverged {
+			hi, found = mid, s
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	out.FirstBadCommand = found.Command
+	out.Difference = found.Difference
+	return out, nil
+}
+
+// bisectCandidates returns the draw calls of the frame ending at end, followed
+// by end itself.
+func bisectCandidates(ctx context.Context, end *path.Command) ([]*path.Command, error) {
+	if len(end.Indices) != 1 {
+		return nil, fmt.Errorf("Bisecting from sub-command %v is not supported", end)
+	}
+	events, err := Events(ctx, &path.Events{
+		Capture:      end.Capture,
+		DrawCalls:    true,
+		FirstInFrame: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endIdx := end.Indices[0]
+	out := []*path.Command{}
+	for _, e := range events.List {
+		idx := e.Command.Indices[0]
+		if idx > endIdx {
+			break
+		}
+		switch e.Kind {
+		case service.EventKind_FirstInFrame:
+			out = out[:0]
+		case service.EventKind_DrawCall:
+			if idx < endIdx {
+				out = append(out, e.Command)
+			}
+		}
+	}
+	return append(out, end), nil
+}
+
+// difference returns the difference between the replay after cmd and the
+// reference image for cmd.
+func (b *bisector) difference(ctx context.Context, cmd *path.Command) (float32, error) {
+	got, err := b.replay(ctx, b.path.Device, cmd)
+	if err != nil {
+		return 0, err
+	}
+	if b.path.ReferenceDevice != nil {
+		ref, err := b.replay(ctx, b.path.ReferenceDevice, cmd)
+		if err != nil {
+			return 0, err
+		}
+		return image.Difference(got, ref)
+	}
+	return settledDifference(b.expected, b.final, got), nil
+}
+
+// replay returns the color attachment after cmd replayed on device d.
+func (b *bisector) replay(ctx context.Context, d *path.Device, cmd *path.Command) (*image.Data, error) {
+	settings := &service.RenderSettings{MaxWidth: b.width, MaxHeight: b.height}
+	iip, err := FramebufferAttachment(ctx, &service.ReplaySettings{Device: d}, cmd, api.FramebufferAttachment_Color0, settings, nil)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ImageInfo(ctx, iip)
+	if err != nil {
+		return nil, err
+	}
+	return imageData(ctx, info, b.width, b.height)
+}
+
+// imageData returns the image described by info as RGBA_U8_NORM data with the
+// dimensions width x height.
+func imageData(ctx context.Context, info *image.Info, width, height uint32) (*image.Data, error) {
+	info, err := info.Convert(ctx, image.RGBA_U8_NORM)
+	if err != nil {
+		return nil, err
+	}
+	if info.Width != width || info.Height != height {
+		if info, err = info.Resize(ctx, width, height, 1); err != nil {
+			return nil, err
+		}
+	}
+	data, err := database.Resolve(ctx, info.Bytes.ID())
+	if err != nil {
+		return nil, err
+	}
+	return &image.Data{
+		Bytes:  data.([]byte),
+		Width:  width,
+		Height: height,
+		Depth:  1,
+		Format: image.RGBA_U8_NORM,
+	}, nil
+}
+
+// settledDifference returns the normalized square error between got and
+// expected, only counting the pixels of got that already hold the value they
+// have at the end of the frame (final). Pixels that are overwritten by later
+// commands do not contribute, so the difference grows as the commands
+// responsible for the divergent pixels of the final image are replayed.
+// All images must be RGBA_U8_NORM with identical dimensions.
+func settledDifference(expected, final, got *image.Data) float32 {
+	sqrErr := float32(0)
+	for i := 0; i+4 <= len(got.Bytes); i += 4 {
+		if !bytes.Equal(got.Bytes[i:i+4], final.Bytes[i:i+4]) {
+			continue
+		}
+		for c := 0; c < 4; c++ {
+			err := (float32(got.Bytes[i+c]) - float32(expected.Bytes[i+c])) / 255
+			sqrErr += err * err
+		}
+	}
+	return sqrErr / float32(len(got.Bytes))
+}
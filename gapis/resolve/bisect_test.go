@@ -0,0 +1,70 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/image"
+	"github.com/google/gapid/core/log"
+)
+
+func TestSettledDifference(t *testing.T) {
+	ctx := log.Testing(t)
+	rgba := func(bytes ...byte) *image.Data {
+		return &image.Data{
+			Bytes:  bytes,
+			Width:  uint32(len(bytes) / 4),
+			Height: 1,
+			Depth:  1,
+			Format: image.RGBA_U8_NORM,
+		}
+	}
+	for _, test := range []struct {
+		name                 string
+		expected, final, got *image.Data
+		diff                 float64
+	}{
+		{"identical",
+			rgba(10, 20, 30, 255),
+			rgba(10, 20, 30, 255),
+			rgba(10, 20, 30, 255),
+			0},
+		{"settled and divergent",
+			rgba(255, 0, 0, 255),
+			rgba(0, 0, 0, 255),
+			rgba(0, 0, 0, 255),
+			0.25},
+		{"not settled yet",
+			rgba(255, 0, 0, 255),
+			rgba(0, 0, 0, 255),
+			rgba(0, 0, 255, 255),
+			0},
+		{"only settled pixels count",
+			rgba(255, 255, 0, 255, 255, 255, 255, 255),
+			rgba(0, 0, 0, 255, 0, 0, 0, 255),
+			rgba(0, 0, 0, 255, 0, 0, 0, 0),
+			0.25},
+		{"partial difference",
+			rgba(51, 0, 0, 255),
+			rgba(0, 0, 0, 255),
+			rgba(0, 0, 0, 255),
+			0.01},
+	} {
+		got := settledDifference(test.expected, test.final, test.got)
+		assert.For(ctx, "%v", test.name).ThatFloat(float64(got)).Equals(test.diff, 1e-6)
+	}
+}
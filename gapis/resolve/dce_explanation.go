@@ -0,0 +1,133 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/transform"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DCEExplanation resolves and returns the explanation of why each command is
+// kept alive by the dead code elimination when replaying up to p.Command.
+func DCEExplanation(ctx context.Context, p *path.DCEExplanation) (*service.DCEExplanation, error) {
+	obj, err := database.Build(ctx, &DCEExplanationResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.DCEExplanation), nil
+}
+
+// Resolve implements the database.Resolver interface.
+func (r *DCEExplanationResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	c := path.FindCapture(r.Path.Command)
+	ctx = capture.Put(ctx, c)
+
+	cmd, err := Cmd(ctx, r.Path.Command)
+	if err != nil {
+		return nil, err
+	}
+	if r.Path.Target != nil {
+		if _, err := Cmd(ctx, r.Path.Target); err != nil {
+			return nil, err
+		}
+	}
+
+	// Use the same elimination as the replay of the requested command's API.
+	var cmds []api.Cmd
+	var numInitialCmds int
+	var live []transform.LiveCommand
+	requested := r.Path.Command.Indices[0]
+	if _, ok := cmd.API().(dependencygraph.FootprintBuilderProvider); ok {
+		ft, err := dependencygraph.GetFootprint(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cmds, numInitialCmds = ft.Commands, ft.NumInitialCommands
+		dce := transform.NewDCE(ctx, ft)
+		dce.Request(ctx, api.SubCmdIdx{requested + uint64(numInitialCmds)})
+		live = dce.Explain(ctx)
+	} else {
+		g, err := dependencygraph.GetDependencyGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cmds, numInitialCmds = g.Commands, g.NumInitialCommands
+		dce := transform.NewDeadCodeElimination(ctx, g)
+		dce.Request(api.CmdID(requested))
+		live = dce.Explain(ctx)
+	}
+
+	commandPath := func(idx api.SubCmdIdx) *path.Command {
+		if len(idx) == 0 || idx[0] < uint64(numInitialCmds) {
+			return nil
+		}
+		return c.Command(idx[0]-uint64(numInitialCmds), idx[1:]...)
+	}
+
+	out := &service.DCEExplanation{}
+	for _, l := range live {
+		out.Commands = append(out.Commands, &service.LiveCommand{
+			Command:     commandPath(l.Command),
+			Name:        cmds[l.Command[0]].CmdName(),
+			Requested:   l.Requested,
+			KeptAliveBy: commandPath(l.KeptAliveBy),
+			State:       l.State,
+			Behavior:    l.Behavior,
+		})
+	}
+
+	if r.Path.Target != nil {
+		out.Commands = dceChain(out.Commands, r.Path.Target)
+	}
+	return out, nil
+}
+
+// dceChain returns the live commands linking target to the requested command.
+// If a command has more than one live behavior then the first one with a
+// reason is followed.
+func dceChain(commands []*service.LiveCommand, target *path.Command) []*service.LiveCommand {
+	byCommand := map[string]*service.LiveCommand{}
+	for _, l := range commands {
+		if l.Command == nil {
+			continue
+		}
+		key := fmt.Sprint(l.Command.Indices)
+		if prev, ok := byCommand[key]; !ok || (prev.KeptAliveBy == nil && !prev.Requested) {
+			byCommand[key] = l
+		}
+	}
+
+	chain := []*service.LiveCommand{}
+	seen := map[string]bool{}
+	for next := target; next != nil; {
+		key := fmt.Sprint(next.Indices)
+		l, ok := byCommand[key]
+		if !ok || seen[key] {
+			break
+		}
+		seen[key] = true
+		chain = append(chain, l)
+		next = l.KeptAliveBy
+	}
+	return chain
+}
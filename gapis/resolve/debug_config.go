@@ -0,0 +1,31 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/config"
+	"github.com/google/gapid/gapis/service"
+)
+
+// debugConfig returns the debug configuration of ctx, to be stored in the
+// resolvables whose result depends on a replay.
+// The configuration is part of the identifier of such resolvables, so changes
+// to the server's configuration do not return stale replays from the
+// database.
+func debugConfig(ctx context.Context) *service.DebugConfig {
+	return service.NewDebugConfig(config.Get(ctx))
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/config"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+func TestDebugConfigKeysResolvables(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+	p := &path.Bisect{Command: path.NewCapture(id.ID{1}).Command(10)}
+
+	// store returns the database identifier of the bisect resolvable built
+	// with the debug configuration d, under which a result is cached.
+	store := func(d config.Debug) id.ID {
+		key, err := database.Store(ctx, &BisectResolvable{Path: p, DebugConfig: debugConfig(config.Put(ctx, d))})
+		assert.For(ctx, "store").ThatError(err).Succeeded()
+		return key
+	}
+
+	defaults := store(config.Debug{})
+	assert.For(ctx, "same configuration").That(store(config.Debug{})).Equals(defaults)
+	for _, test := range []struct {
+		name  string
+		debug config.Debug
+	}{
+		{"dead code elimination disabled", config.Debug{DisableDeadCodeElimination: true}},
+		{"separate mutate states", config.Debug{SeparateMutateStates: true}},
+		{"replay debugging", config.Debug{DebugReplay: true}},
+	} {
+		assert.For(ctx, "%v", test.name).That(store(test.debug)).NotEquals(defaults)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DependencyGraph resolves and returns the graph of the commands in the range
+// of p, linked by the state they read and write.
+func DependencyGraph(ctx context.Context, p *path.DependencyGraph) (*service.DependencyGraph, error) {
+	obj, err := database.Build(ctx, &DependencyGraphResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.DependencyGraph), nil
+}
+
+// dependencyGraphBuilder accumulates the nodes and edges of a
+// service.DependencyGraph.
+type dependencyGraphBuilder struct {
+	out   *service.DependencyGraph
+	from  uint64
+	edges map[[2]uint32]*service.DependencyGraphEdge
+}
+
+// Resolve implements the database.Resolver interface.
+// Only the state read and written by the commands in the range is considered.
+// A read is linked to the last write of the exact same state, state hierarchy
+// is not taken into account.
+func (r *DependencyGraphResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	p := r.Path
+	ctx = capture.Put(ctx, p.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.From >= uint64(len(c.Commands)) {
+		return nil, fmt.Errorf("Command %v out of range [0-%v]", p.From, len(c.Commands)-1)
+	}
+	to := p.To
+	if to >= uint64(len(c.Commands)) {
+		to = uint64(len(c.Commands)) - 1
+	}
+
+	frameStarts, err := Events(ctx, &path.Events{Capture: p.Capture, FirstInFrame: true})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &dependencyGraphBuilder{
+		out:   &service.DependencyGraph{},
+		from:  p.From,
+		edges: map[[2]uint32]*service.DependencyGraphEdge{},
+	}
+	frame, starts := uint32(0), frameStarts.List
+	for id := p.From; id <= to; id++ {
+		for len(starts) > 0 && starts[0].Command.Indices[0] <= id {
+			if starts[0].Command.Indices[0] > 0 {
+				frame++
+			}
+			starts = starts[1:]
+		}
+		b.out.Nodes = append(b.out.Nodes, &service.DependencyGraphNode{
+			Command: p.Capture.Command(id),
+			Name:    c.Commands[id].CmdName(),
+			Frame:   frame,
+		})
+	}
+
+	if p.Footprint {
+		ft, err := dependencygraph.GetFootprint(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.addFootprint(ft, to)
+	} else {
+		g, err := dependencygraph.GetDependencyGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.addGraph(g, to)
+	}
+	return b.out, nil
+}
+
+func (b *dependencyGraphBuilder) addGraph(g *dependencygraph.DependencyGraph, to uint64) {
+	lastWrite := map[dependencygraph.StateAddress]uint32{}
+	for id := b.from; id <= to; id++ {
+		node := uint32(id - b.from)
+		bh := &g.Behaviours[int(id)+g.NumInitialCommands]
+		for _, l := range [][]dependencygraph.StateAddress{bh.Reads, bh.Modifies} {
+			for _, a := range l {
+				if w, ok := lastWrite[a]; ok {
+					b.link(w, node, g.DescribeState(a))
+				}
+			}
+		}
+		for _, l := range [][]dependencygraph.StateAddress{bh.Writes, bh.Modifies} {
+			for _, a := range l {
+				lastWrite[a] = node
+			}
+		}
+	}
+}
+
+func (b *dependencyGraphBuilder) addFootprint(ft *dependencygraph.Footprint, to uint64) {
+	first := b.from + uint64(ft.NumInitialCommands)
+	last := to + uint64(ft.NumInitialCommands)
+	lastWrite := map[dependencygraph.DefUseVariable]uint32{}
+	for _, bh := range ft.Behaviors {
+		if bh.Owner[0] < first || bh.Owner[0] > last || bh.Aborted {
+			continue
+		}
+		// Behaviors of subcommands belong to the node of the command.
+		node := uint32(bh.Owner[0] - first)
+		for _, v := range bh.Reads {
+			if v == nil || !reflect.TypeOf(v).Comparable() {
+				continue
+			}
+			if w, ok := lastWrite[v]; ok && w != node {
+				b.link(w, node, fmt.Sprintf("%T%+v", v, v))
+			}
+		}
+		for _, v := range bh.Writes {
+			if v != nil && reflect.TypeOf(v).Comparable() {
+				lastWrite[v] = node
+			}
+		}
+	}
+}
+
+// link adds the state to the edge from the writer node to the reader node.
+func (b *dependencyGraphBuilder) link(writer, reader uint32, state string) {
+	key := [2]uint32{writer, reader}
+	e, ok := b.edges[key]
+	if !ok {
+		e = &service.DependencyGraphEdge{From: writer, To: reader}
+		b.edges[key] = e
+		b.out.Edges = append(b.out.Edges, e)
+	}
+	e.States = append(e.States, state)
+}
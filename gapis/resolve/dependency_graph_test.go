@@ -0,0 +1,116 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/service"
+)
+
+// testVar is a comparable variable of a footprint.
+type testVar struct{ name string }
+
+func (testVar) DefUseVariable() {}
+
+// testVars is a variable of a footprint that cannot be used as a map key.
+type testVars []string
+
+func (testVars) DefUseVariable() {}
+
+func newDependencyGraphBuilder(from uint64) *dependencyGraphBuilder {
+	return &dependencyGraphBuilder{
+		out:   &service.DependencyGraph{},
+		from:  from,
+		edges: map[[2]uint32]*service.DependencyGraphEdge{},
+	}
+}
+
+// edgeCounts returns the edges of the graph with their number of states.
+func edgeCounts(g *service.DependencyGraph) []string {
+	out := []string{}
+	for _, e := range g.Edges {
+		out = append(out, fmt.Sprintf("%d->%d x%d", e.From, e.To, len(e.States)))
+	}
+	return out
+}
+
+func TestDependencyGraphAddGraph(t *testing.T) {
+	ctx := log.Testing(t)
+	g := &dependencygraph.DependencyGraph{
+		NumInitialCommands: 1,
+		Behaviours: []dependencygraph.AtomBehaviour{
+			// The initial state, then the commands 0 to 3.
+			{Writes: []dependencygraph.StateAddress{1, 2, 3}},
+			{Writes: []dependencygraph.StateAddress{1, 3}},
+			{Reads: []dependencygraph.StateAddress{1, 3, 4}, Writes: []dependencygraph.StateAddress{2}},
+			{Modifies: []dependencygraph.StateAddress{2}},
+			{Reads: []dependencygraph.StateAddress{1, 2}},
+		},
+	}
+	for _, test := range []struct {
+		name     string
+		from, to uint64
+		expected []string
+	}{
+		{"all", 0, 3, []string{"0->1 x2", "1->2 x1", "0->3 x1", "2->3 x1"}},
+		{"from 1", 1, 3, []string{"0->1 x1", "1->2 x1"}},
+		{"to 1", 0, 1, []string{"0->1 x2"}},
+	} {
+		b := newDependencyGraphBuilder(test.from)
+		b.addGraph(g, test.to)
+		assert.For(ctx, "%v", test.name).ThatSlice(edgeCounts(b.out)).Equals(test.expected)
+	}
+}
+
+func TestDependencyGraphAddFootprint(t *testing.T) {
+	ctx := log.Testing(t)
+	a, b := testVar{"a"}, testVar{"b"}
+	ft := &dependencygraph.Footprint{
+		NumInitialCommands: 1,
+		Behaviors: []*dependencygraph.Behavior{
+			{Owner: api.SubCmdIdx{0}, Writes: []dependencygraph.DefUseVariable{a, b}},
+			{Owner: api.SubCmdIdx{1}, Writes: []dependencygraph.DefUseVariable{a}},
+			{Owner: api.SubCmdIdx{2, 0, 1}, Reads: []dependencygraph.DefUseVariable{a},
+				Writes: []dependencygraph.DefUseVariable{b}},
+			{Owner: api.SubCmdIdx{2}, Reads: []dependencygraph.DefUseVariable{b}},
+			{Owner: api.SubCmdIdx{3}, Reads: []dependencygraph.DefUseVariable{a},
+				Writes: []dependencygraph.DefUseVariable{a}, Aborted: true},
+			{Owner: api.SubCmdIdx{4}, Reads: []dependencygraph.DefUseVariable{a, b, testVars{"c"}, nil}},
+		},
+	}
+	for _, test := range []struct {
+		name     string
+		from, to uint64
+		expected []string
+	}{
+		{"all", 0, 3, []string{"0->1 x1", "0->3 x1", "1->3 x1"}},
+		{"from 1", 1, 3, []string{"0->2 x1"}},
+		{"to 2", 0, 2, []string{"0->1 x1"}},
+	} {
+		bd := newDependencyGraphBuilder(test.from)
+		bd.addFootprint(ft, test.to)
+		assert.For(ctx, "%v", test.name).ThatSlice(edgeCounts(bd.out)).Equals(test.expected)
+	}
+
+	bd := newDependencyGraphBuilder(0)
+	bd.addFootprint(ft, 3)
+	assert.For(ctx, "states").ThatSlice(bd.out.Edges[0].States).Equals([]string{"resolve.testVar{name:a}"})
+}
@@ -63,6 +63,17 @@ func (g *DependencyGraph) SetRoot(key StateKey) {
 	g.Roots[g.GetStateAddressOf(key)] = true
 }
 
+// GetStateKey returns the state key at the given address.
+func (g *DependencyGraph) GetStateKey(address StateAddress) StateKey {
+	return g.addressMap.key[address]
+}
+
+// DescribeState returns a description of the state at the given address.
+func (g *DependencyGraph) DescribeState(address StateAddress) string {
+	key := g.addressMap.key[address]
+	return fmt.Sprintf("[%v]%T%+v", address, key, key)
+}
+
 func (g *DependencyGraph) Print(ctx context.Context, b *AtomBehaviour) {
 	for _, read := range b.Reads {
 		key := g.addressMap.key[read]
@@ -160,7 +171,6 @@ func (r *DependencyGraphResolvable) Resolve(ctx context.Context) (interface{}, e
 		return nil, err
 	}
 	cmds := c.Commands
-	behaviourProviders := map[api.API]BehaviourProvider{}
 
 	// If the capture contains initial state, prepend the commands to build the state.
 	initialCmds, ranges := c.GetInitialCommands(ctx)
@@ -168,8 +178,18 @@ func (r *DependencyGraphResolvable) Resolve(ctx context.Context) (interface{}, e
 		cmds = append(initialCmds, cmds...)
 	}
 
-	g := &DependencyGraph{
-		NumInitialCommands: len(initialCmds),
+	g := NewDependencyGraph(cmds, len(initialCmds))
+	s := c.NewUninitializedState(ctx, ranges)
+
+	dependencyGraphBuildCounter.Time(func() { g.Build(ctx, s, nil) })
+	return g, nil
+}
+
+// NewDependencyGraph returns a dependency graph without behaviours for cmds,
+// the first numInitialCommands of which build the initial state.
+func NewDependencyGraph(cmds []api.Cmd, numInitialCommands int) *DependencyGraph {
+	return &DependencyGraph{
+		NumInitialCommands: numInitialCommands,
 		Commands:           cmds,
 		Behaviours:         make([]AtomBehaviour, len(cmds)),
 		Roots:              map[StateAddress]bool{},
@@ -179,34 +199,38 @@ func (r *DependencyGraphResolvable) Resolve(ctx context.Context) (interface{}, e
 			parent:  map[StateAddress]StateAddress{NullStateAddress: NullStateAddress},
 		},
 	}
+}
 
-	s := c.NewUninitializedState(ctx, ranges)
-
-	dependencyGraphBuildCounter.Time(func() {
-		api.ForeachCmd(ctx, cmds, func(ctx context.Context, index api.CmdID, cmd api.Cmd) error {
-			a := cmd.API()
-			id := g.GetCmdID(int(index))
-			if _, ok := behaviourProviders[a]; !ok {
-				if bp, ok := a.(DependencyGraphBehaviourProvider); ok {
-					behaviourProviders[a] = bp.GetDependencyGraphBehaviourProvider(ctx)
-				} else {
-					// API does not provide dependency information, always keep
-					// commands for such APIs.
-					g.Behaviours[index].KeepAlive = true
-					// Even if the command does not belong to an API that provides
-					// dependency info, we still need to mutate it in the new state,
-					// because following commands in other APIs may depends on the
-					// side effect of the current command.
-					if err := cmd.Mutate(ctx, id, s, nil /* builder */); err != nil {
-						log.W(ctx, "Command %v %v: %v", id, cmd, err)
-						g.Behaviours[index].Aborted = true
-					}
-					return nil
+// Build mutates the commands of the graph on s, recording their behaviours.
+// If before is not nil, it is called with the index of each command before the
+// command is mutated.
+func (g *DependencyGraph) Build(ctx context.Context, s *api.GlobalState, before func(index int)) {
+	behaviourProviders := map[api.API]BehaviourProvider{}
+	api.ForeachCmd(ctx, g.Commands, func(ctx context.Context, index api.CmdID, cmd api.Cmd) error {
+		if before != nil {
+			before(int(index))
+		}
+		a := cmd.API()
+		id := g.GetCmdID(int(index))
+		if _, ok := behaviourProviders[a]; !ok {
+			if bp, ok := a.(DependencyGraphBehaviourProvider); ok {
+				behaviourProviders[a] = bp.GetDependencyGraphBehaviourProvider(ctx)
+			} else {
+				// API does not provide dependency information, always keep
+				// commands for such APIs.
+				g.Behaviours[index].KeepAlive = true
+				// Even if the command does not belong to an API that provides
+				// dependency info, we still need to mutate it in the new state,
+				// because following commands in other APIs may depends on the
+				// side effect of the current command.
+				if err := cmd.Mutate(ctx, id, s, nil /* builder */); err != nil {
+					log.W(ctx, "Command %v %v: %v", id, cmd, err)
+					g.Behaviours[index].Aborted = true
 				}
+				return nil
 			}
-			g.Behaviours[index] = behaviourProviders[a].GetBehaviourForAtom(ctx, s, id, cmd, g)
-			return nil
-		})
+		}
+		g.Behaviours[index] = behaviourProviders[a].GetBehaviourForAtom(ctx, s, id, cmd, g)
+		return nil
 	})
-	return g, nil
 }
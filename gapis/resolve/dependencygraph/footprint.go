@@ -24,6 +24,7 @@ import (
 	"github.com/google/gapid/gapis/capture"
 	"github.com/google/gapid/gapis/database"
 	"github.com/google/gapid/gapis/resolve/initialcmds"
+	"github.com/google/gapid/gapis/service/path"
 )
 
 var footprintBuildCounter = benchmark.Duration("footprint.build")
@@ -204,14 +205,27 @@ func GetFootprint(ctx context.Context) (*Footprint, error) {
 
 // Resolve implements the database.Resolver interface.
 func (r *FootprintResolvable) Resolve(ctx context.Context) (interface{}, error) {
-	c, err := capture.ResolveFromPath(ctx, r.Capture)
+	return BuildFootprint(ctx, r.Capture, func(a api.API) FootprintBuilder {
+		if bp, ok := a.(FootprintBuilderProvider); ok {
+			return bp.FootprintBuilder(ctx)
+		}
+		return nil
+	})
+}
+
+// BuildFootprint builds the footprint of the capture p with the footprint
+// builders returned by builder for the APIs of its commands. The commands of
+// the APIs builder returns nil for are always kept alive.
+func BuildFootprint(ctx context.Context, p *path.Capture,
+	builder func(api.API) FootprintBuilder) (*Footprint, error) {
+	c, err := capture.ResolveFromPath(ctx, p)
 	if err != nil {
 		return nil, err
 	}
 	cmds := c.Commands
 	// If the capture contains initial state, prepend the commands to build the state.
 
-	initialCmds, ranges, err := initialcmds.InitialCommands(ctx, r.Capture)
+	initialCmds, ranges, err := initialcmds.InitialCommands(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -229,30 +243,31 @@ func (r *FootprintResolvable) Resolve(ctx context.Context) (interface{}, error)
 	defer footprintBuildCounter.Stop(t0)
 	api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
 		a := cmd.API()
-		if _, ok := builders[cmd.API()]; !ok {
-			if bp, ok := cmd.API().(FootprintBuilderProvider); ok {
-				builders[cmd.API()] = bp.FootprintBuilder(ctx)
-			} else {
-				// API does not provide execution footprint info, always keep commands
-				// from such APIs alive.
-				bh := NewBehavior(api.SubCmdIdx{uint64(id)}, &dummyMachine{})
-				bh.Alive = true
-				// Even if the command does not belong to an API that provides
-				// execution footprint info, we still need to mutate it in the new
-				// state, because following commands in other APIs may depends on the
-				// side effect of the this command.
-				if err := cmd.Mutate(ctx, id, s, nil); err != nil {
-					bh.Aborted = true
-					// Continue the footprint building even if errors are found. It is
-					// following mutate calls, which are to build the replay
-					// instructions, that are responsible to catch the error.
-					// TODO: This error should be moved to report view.
-				}
-				ft.AddBehavior(ctx, bh)
-				return nil
+		b, ok := builders[a]
+		if !ok {
+			b = builder(a)
+			builders[a] = b
+		}
+		if b == nil {
+			// API does not provide execution footprint info, always keep commands
+			// from such APIs alive.
+			bh := NewBehavior(api.SubCmdIdx{uint64(id)}, &dummyMachine{})
+			bh.Alive = true
+			// Even if the command does not belong to an API that provides
+			// execution footprint info, we still need to mutate it in the new
+			// state, because following commands in other APIs may depends on the
+			// side effect of the this command.
+			if err := cmd.Mutate(ctx, id, s, nil); err != nil {
+				bh.Aborted = true
+				// Continue the footprint building even if errors are found. It is
+				// following mutate calls, which are to build the replay
+				// instructions, that are responsible to catch the error.
+				// TODO: This error should be moved to report view.
 			}
+			ft.AddBehavior(ctx, bh)
+			return nil
 		}
-		builders[a].BuildFootprint(ctx, s, ft, id, cmd)
+		b.BuildFootprint(ctx, s, ft, id, cmd)
 		return nil
 	})
 	return ft, nil
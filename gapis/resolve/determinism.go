@@ -0,0 +1,156 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/gapid/core/app/crash"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/image"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// determinismCheck holds the state of a single command of a determinism check.
+type determinismCheck struct {
+	request *FramebufferAttachmentBytesResolvable
+	first   *image.Data        // The postback of the first replay.
+	results map[id.ID]struct{} // The distinct postbacks seen.
+	result  *service.DeterminismResult
+}
+
+// ReplayDeterminism replays the color attachment after each of the commands
+// replays times on the device of settings and compares the postbacks.
+// If cmds is empty then the last command of each frame is checked, along
+// with every draw call if drawCalls is true.
+// Unlike the other framebuffer resolves the replays are not cached in the
+// database, so each call performs fresh replays.
+func ReplayDeterminism(
+	ctx context.Context,
+	settings *service.ReplaySettings,
+	p *path.Capture,
+	cmds []*path.Command,
+	drawCalls bool,
+	replays uint32,
+	tolerance float32) (*service.DeterminismReport, error) {
+
+	if replays < 2 {
+		return nil, fmt.Errorf("At least two replays are required to check for determinism, got %d", replays)
+	}
+
+	ctx = capture.Put(ctx, p)
+
+	if len(cmds) == 0 {
+		events, err := Events(ctx, &path.Events{
+			Capture:     p,
+			LastInFrame: true,
+			DrawCalls:   drawCalls,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events.List {
+			cmds = append(cmds, e.Command)
+		}
+	}
+
+	changes, err := FramebufferChanges(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]*determinismCheck, len(cmds))
+	for i, cmd := range cmds {
+		check := &determinismCheck{
+			results: map[id.ID]struct{}{},
+			result:  &service.DeterminismResult{Command: cmd, BitExact: true, WithinTolerance: true},
+		}
+		checks[i] = check
+		fbInfo, err := changes.Get(ctx, cmd, api.FramebufferAttachment_Color0)
+		if err != nil {
+			check.result.Error = err.Error()
+			continue
+		}
+		check.request = &FramebufferAttachmentBytesResolvable{
+			ReplaySettings:   settings,
+			After:            cmd,
+			Width:            fbInfo.Width,
+			Height:           fbInfo.Height,
+			Attachment:       api.FramebufferAttachment_Color0,
+			FramebufferIndex: fbInfo.Index,
+			ImageFormat:      fbInfo.Format,
+		}
+	}
+
+	for i := uint32(0); i < replays; i++ {
+		log.I(ctx, "Determinism replay %d of %d", i+1, replays)
+
+		// All the requests of a pass are issued together so that they are
+		// batched into a single replay.
+		wg := sync.WaitGroup{}
+		for _, check := range checks {
+			if check.request == nil {
+				continue
+			}
+			check := check
+			wg.Add(1)
+			crash.Go(func() {
+				defer wg.Done()
+				data, err := check.request.replay(ctx)
+				check.add(data, err, tolerance)
+			})
+		}
+		wg.Wait()
+	}
+
+	out := &service.DeterminismReport{Replays: replays}
+	for _, check := range checks {
+		check.result.DistinctResults = uint32(len(check.results))
+		out.Results = append(out.Results, check.result)
+	}
+	return out, nil
+}
+
+// add compares the postback of a single replay against the first replay.
+// add is only called by one replay at a time for a given check.
+func (c *determinismCheck) add(data *image.Data, err error, tolerance float32) {
+	if err != nil {
+		c.result.Error = err.Error()
+		return
+	}
+	c.results[id.OfBytes(data.Bytes)] = struct{}{}
+	if c.first == nil {
+		c.first = data
+		return
+	}
+	if len(c.results) > 1 {
+		c.result.BitExact = false
+	}
+	diff, err := image.Difference(c.first, data)
+	if err != nil {
+		c.result.Error = err.Error()
+		return
+	}
+	if diff > c.result.MaxDifference {
+		c.result.MaxDifference = diff
+	}
+	c.result.WithinTolerance = c.result.MaxDifference <= tolerance
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/messages"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// DrawCallState resolves and returns the pipeline state used by the draw call
+// at the path p.
+func DrawCallState(ctx context.Context, p *path.DrawCallState) (*api.DrawCallState, error) {
+	cmd, err := Cmd(ctx, p.Command)
+	if err != nil {
+		return nil, err
+	}
+	if dp, ok := cmd.API().(api.DrawCallStateProvider); ok {
+		state, err := dp.DrawCallState(ctx, p)
+		if state != nil || err != nil {
+			return state, err
+		}
+	}
+	return nil, &service.ErrDataUnavailable{Reason: messages.ErrDrawCallStateNotAvailable()}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/google/gapid/core/image"
 	"github.com/google/gapid/core/log"
 	"github.com/google/gapid/gapis/api"
@@ -39,6 +40,8 @@ func FramebufferAttachment(
 	settings *service.RenderSettings,
 	hints *service.UsageHints,
 ) (*path.ImageInfo, error) {
+	// The settings are completed below, so work on a copy of the caller's.
+	replaySettings = proto.Clone(replaySettings).(*service.ReplaySettings)
 	if replaySettings.Device == nil {
 		devices, err := devices.ForReplay(ctx, after.Capture)
 		if err != nil {
@@ -49,6 +52,9 @@ func FramebufferAttachment(
 		}
 		replaySettings.Device = devices[0]
 	}
+	if replaySettings.DebugConfig == nil {
+		replaySettings.DebugConfig = debugConfig(ctx)
+	}
 
 	// Check the command is valid. If we don't do it here, we'll likely get an
 	// error deep in the bowels of the framebuffer data resolve.
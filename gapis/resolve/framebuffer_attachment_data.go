@@ -17,6 +17,7 @@ package resolve
 import (
 	"context"
 
+	"github.com/google/gapid/core/image"
 	"github.com/google/gapid/core/log"
 	"github.com/google/gapid/gapis/capture"
 	"github.com/google/gapid/gapis/messages"
@@ -27,12 +28,24 @@ import (
 
 // Resolve implements the database.Resolver interface.
 func (r *FramebufferAttachmentBytesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	res, err := r.replay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Bytes, nil
+}
+
+// replay performs the replay for the framebuffer attachment, returning the
+// converted image data. Unlike Resolve, the result is not cached.
+func (r *FramebufferAttachmentBytesResolvable) replay(ctx context.Context) (*image.Data, error) {
 	c := path.FindCapture(r.After)
 	ctx = capture.Put(ctx, c)
+	ctx = r.ReplaySettings.Put(ctx)
 
 	intent := replay.Intent{
-		Device:  r.ReplaySettings.Device,
-		Capture: c,
+		Device:     r.ReplaySettings.Device,
+		Capture:    c,
+		DevicePool: r.ReplaySettings.UseDevicePool,
 	}
 
 	after, err := Cmd(ctx, r.After)
@@ -90,5 +103,5 @@ func (r *FramebufferAttachmentBytesResolvable) Resolve(ctx context.Context) (int
 		return nil, log.Err(ctx, err, "Couldn't get framebuffer attachment")
 	}
 
-	return res.Bytes, nil
+	return res, nil
 }
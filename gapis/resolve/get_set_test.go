@@ -261,3 +261,26 @@ func TestSet(t *testing.T) {
 		}
 	}
 }
+
+func TestInsertCmds(t *testing.T) {
+	ctx := log.Testing(t)
+	a, b, c := &testcmd.A{ID: 1}, &testcmd.A{ID: 2}, &testcmd.A{ID: 3}
+	x, y, z := &testcmd.B{ID: 1}, &testcmd.B{ID: 2}, &testcmd.B{ID: 3}
+	for _, test := range []struct {
+		name    string
+		inserts map[uint64][]api.Cmd
+		idx     uint64
+		cmds    []api.Cmd
+		newIdx  uint64
+	}{
+		{"none", map[uint64][]api.Cmd{}, 1, []api.Cmd{a, b, c}, 1},
+		{"before the command", map[uint64][]api.Cmd{1: {x, y}}, 1, []api.Cmd{a, x, y, b, c}, 3},
+		{"before an earlier command", map[uint64][]api.Cmd{0: {x}}, 2, []api.Cmd{x, a, b, c}, 3},
+		{"before a later command", map[uint64][]api.Cmd{2: {x}}, 1, []api.Cmd{a, b, x, c}, 1},
+		{"several", map[uint64][]api.Cmd{0: {x}, 1: {y}, 2: {z}}, 1, []api.Cmd{x, a, y, b, z, c}, 3},
+	} {
+		cmds, newIdx := insertCmds([]api.Cmd{a, b, c}, test.inserts, test.idx)
+		assert.For(ctx, "%v cmds", test.name).ThatSlice(cmds).Equals(test.cmds)
+		assert.For(ctx, "%v index", test.name).That(newIdx).Equals(test.newIdx)
+	}
+}
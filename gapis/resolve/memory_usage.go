@@ -0,0 +1,131 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// MemoryUsage resolves the estimated GPU memory usage at the end of each frame
+// of the capture.
+func MemoryUsage(ctx context.Context, p *path.MemoryUsage) (*service.MemoryUsage, error) {
+	obj, err := database.Build(ctx, &MemoryUsageResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.MemoryUsage), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The state is estimated by the APIs implementing api.MemoryUsageEstimator
+// after each end of frame or swap command, and after the last command if it does not
+// end a frame.
+func (r *MemoryUsageResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	estimators := []api.MemoryUsageEstimator{}
+	for _, a := range c.APIs {
+		if e, ok := a.(api.MemoryUsageEstimator); ok {
+			estimators = append(estimators, e)
+		}
+	}
+
+	out := &service.MemoryUsage{}
+	s := c.NewState(ctx)
+	sample := func(id api.CmdID) {
+		allocs := []api.MemoryAllocation{}
+		for _, e := range estimators {
+			allocs = append(allocs, e.EstimateMemoryUsage(ctx, s)...)
+		}
+		out.Frames = append(out.Frames, r.frameUsage(uint32(len(out.Frames)), id, allocs))
+	}
+
+	last, endOfFrame := api.CmdID(0), false
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		// The GLES swaps are flagged as starting the next frame, and end the
+		// frame.
+		flags := cmd.CmdFlags(ctx, id, s)
+		last, endOfFrame = id, flags.IsEndOfFrame() || flags.IsStartOfFrame()
+		cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+		if endOfFrame {
+			sample(id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Commands) > 0 && !endOfFrame {
+		sample(last)
+	}
+	return out, nil
+}
+
+// frameUsage returns the memory usage of the frame ending with the command id,
+// from the live objects allocs.
+func (r *MemoryUsageResolvable) frameUsage(frame uint32, id api.CmdID, allocs []api.MemoryAllocation) *service.FrameMemoryUsage {
+	out := &service.FrameMemoryUsage{
+		Frame:   frame,
+		Command: r.Path.Capture.Command(uint64(id)),
+	}
+	categories := map[string]*service.MemoryCategoryUsage{}
+	for _, a := range allocs {
+		u, ok := categories[a.Category]
+		if !ok {
+			u = &service.MemoryCategoryUsage{Category: a.Category}
+			categories[a.Category] = u
+			out.Categories = append(out.Categories, u)
+		}
+		u.Bytes += a.Size
+		u.Count++
+	}
+	sort.Slice(out.Categories, func(i, j int) bool {
+		return out.Categories[i].Category < out.Categories[j].Category
+	})
+
+	sort.Slice(allocs, func(i, j int) bool {
+		a, b := allocs[i], allocs[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Name < b.Name
+	})
+	if top := int(r.Path.Top); len(allocs) > top {
+		allocs = allocs[:top]
+	}
+	for _, a := range allocs {
+		out.Largest = append(out.Largest, &service.MemoryObject{
+			Category: a.Category,
+			Name:     a.Name,
+			Bytes:    a.Size,
+		})
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/messages"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/stringtable"
+)
+
+// issueRedundantState is the kind of the issues of the commands that set the
+// state to the value it already has.
+const issueRedundantState = "Redundant state"
+
+// performanceReport runs the performance lint pass of each of the APIs of the
+// capture over its commands, and returns the issues found as a report.
+// Each item is tagged with the kind of issue, so the report is grouped by kind.
+// The commands that set the state to the value it already has are found with
+// api.MutateAndCompareState, as for the redundant state changes.
+// An error is returned if any of the APIs does not implement performance lints.
+func (r *ReportResolvable) performanceReport(ctx context.Context, c *capture.Capture, filter CommandFilter) (*service.Report, error) {
+	lints := map[api.API]api.PerformanceLint{}
+	for _, a := range c.APIs {
+		l, ok := a.(api.PerformanceLinter)
+		if !ok {
+			return nil, &service.ErrDataUnavailable{
+				Reason: messages.ErrPerformanceLintUnsupported(a.Name()),
+			}
+		}
+		lints[a] = l.NewPerformanceLint(ctx)
+	}
+
+	builder := service.NewReportBuilder()
+	state := c.NewState(ctx)
+	err := api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		l, ok := lints[cmd.API()]
+		if !ok || !filter(id, cmd, state) {
+			cmd.Mutate(ctx, id, state, nil /* no builder, just mutate */)
+			return nil
+		}
+		report := func(s log.Severity, issue string, m *stringtable.Msg) {
+			item := r.newReportItem(s, uint64(id), m)
+			item.Tags = append(item.Tags, messages.TagPerformance(issue), getAtomNameTag(cmd))
+			builder.Add(ctx, item)
+		}
+		l.Lint(ctx, id, cmd, state, report)
+		if redundant, _, _ := api.MutateAndCompareState(ctx, id, cmd, state); redundant {
+			report(log.Info, issueRedundantState, messages.PerfRedundantState(cmd.CmdName()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(), nil
+}
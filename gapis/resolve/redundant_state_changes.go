@@ -0,0 +1,95 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// RedundantStateChanges resolves and returns the state-setting commands of the
+// capture that set the state to the value it already had.
+func RedundantStateChanges(ctx context.Context, p *path.RedundantStateChanges) (*service.RedundantStateChanges, error) {
+	obj, err := database.Build(ctx, &RedundantStateChangesResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.RedundantStateChanges), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The state-setting commands are found with api.MutateAndCompareState, so
+// only the APIs implementing api.StateWriteInspector are considered.
+func (r *RedundantStateChangesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type commands struct {
+		total     uint64
+		redundant []*path.Command
+	}
+	byName := map[string]*commands{}
+
+	s := c.NewState(ctx)
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		redundant, known, err := api.MutateAndCompareState(ctx, id, cmd, s)
+		if err != nil || !known {
+			return nil
+		}
+
+		n, ok := byName[cmd.CmdName()]
+		if !ok {
+			n = &commands{}
+			byName[cmd.CmdName()] = n
+		}
+		n.total++
+		if redundant {
+			n.redundant = append(n.redundant, r.Path.Capture.Command(uint64(id)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &service.RedundantStateChanges{}
+	for name, n := range byName {
+		if len(n.redundant) > 0 {
+			out.Commands = append(out.Commands, &service.RedundantCommands{
+				Name:      name,
+				Total:     n.total,
+				Redundant: n.redundant,
+			})
+		}
+	}
+	sort.Slice(out.Commands, func(i, j int) bool {
+		a, b := out.Commands[i], out.Commands[j]
+		if len(a.Redundant) != len(b.Redundant) {
+			return len(a.Redundant) > len(b.Redundant)
+		}
+		return a.Name < b.Name
+	})
+	return out, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// RenderPassInstances resolves the render pass instances executed by the
+// capture, from the APIs implementing the api.RenderPassInstancesProvider
+// interface.
+func RenderPassInstances(ctx context.Context, p *path.RenderPassInstances) (*api.RenderPassInstances, error) {
+	ctx = capture.Put(ctx, p.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := &api.RenderPassInstances{}
+	for _, a := range c.APIs {
+		rp, ok := a.(api.RenderPassInstancesProvider)
+		if !ok {
+			continue
+		}
+		instances, err := rp.RenderPassInstances(ctx, p.Capture)
+		if err != nil {
+			return nil, err
+		}
+		out.Instances = append(out.Instances, instances.Instances...)
+	}
+	return out, nil
+}
@@ -16,6 +16,7 @@ package resolve
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/gapid/core/app/analytics"
 	"github.com/google/gapid/core/log"
@@ -31,7 +32,11 @@ import (
 
 // Report resolves the report for the given path.
 func Report(ctx context.Context, p *path.Report) (*service.Report, error) {
-	obj, err := database.Build(ctx, &ReportResolvable{p})
+	r := &ReportResolvable{Path: p}
+	if p.Device != nil {
+		r.DebugConfig = debugConfig(ctx)
+	}
+	obj, err := database.Build(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +57,7 @@ func (r *ReportResolvable) newReportItem(s log.Severity, c uint64, m *stringtabl
 // Resolve implements the database.Resolver interface.
 func (r *ReportResolvable) Resolve(ctx context.Context) (interface{}, error) {
 	ctx = capture.Put(ctx, r.Path.Capture)
+	ctx = r.DebugConfig.Put(ctx)
 
 	c, err := capture.Resolve(ctx)
 	if err != nil {
@@ -70,6 +76,16 @@ func (r *ReportResolvable) Resolve(ctx context.Context) (interface{}, error) {
 		return nil, err
 	}
 
+	if r.Path.Performance {
+		return r.performanceReport(ctx, c, filter)
+	}
+	if r.Path.UnusedResources {
+		return r.unusedResourcesReport(ctx)
+	}
+	if r.Path.ShaderCheck {
+		return r.shaderCheckReport(ctx)
+	}
+
 	builder := service.NewReportBuilder()
 
 	var currentAtom uint64
@@ -117,6 +133,50 @@ func (r *ReportResolvable) Resolve(ctx context.Context) (interface{}, error) {
 		}
 	}
 
+	// When requested, iterate the APIs in use looking for those that support
+	// the SyncHazardFinder interface, and gather the hazards by accessing
+	// command.
+	hazards := map[api.CmdID][]api.SyncHazard{}
+	if r.Path.SyncHazards {
+		for _, a := range c.APIs {
+			if f, ok := a.(api.SyncHazardFinder); ok {
+				apiHazards, err := f.FindSyncHazards(ctx, r.Path.Capture)
+				if err != nil {
+					issue := replay.Issue{
+						Command:  api.CmdNoID,
+						Severity: service.Severity_ErrorLevel,
+						Error:    err,
+					}
+					issues[api.CmdNoID] = append(issues[api.CmdNoID], issue)
+					continue
+				}
+				for _, h := range apiHazards {
+					id := api.CmdID(h.Access[0])
+					hazards[id] = append(hazards[id], h)
+				}
+			}
+		}
+	}
+
+	// When requested, gather the wasteful attachments of the render pass
+	// instances by beginning command.
+	wastes := map[api.CmdID][]*api.RenderPassInstance{}
+	if r.Path.RenderPassWaste {
+		if instances, err := RenderPassInstances(ctx, r.Path.Capture.RenderPassInstances()); err != nil {
+			issue := replay.Issue{
+				Command:  api.CmdNoID,
+				Severity: service.Severity_ErrorLevel,
+				Error:    err,
+			}
+			issues[api.CmdNoID] = append(issues[api.CmdNoID], issue)
+		} else {
+			for _, i := range instances.Instances {
+				id := api.CmdID(i.Command.Indices[0])
+				wastes[id] = append(wastes[id], i)
+			}
+		}
+	}
+
 	// Gather report items from the state mutator, and collect together all the
 	// APIs in use.
 	api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
@@ -147,6 +207,27 @@ func (r *ReportResolvable) Resolve(ctx context.Context) (interface{}, error) {
 				}
 				builder.Add(ctx, item)
 			}
+			for _, h := range hazards[id] {
+				access, write := fmt.Sprint(h.Access), fmt.Sprint(h.Write)
+				msg, tag := messages.ErrSyncReadAfterWrite(access, write), "read after write"
+				if h.IsWrite {
+					msg, tag = messages.ErrSyncWriteAfterWrite(access, write), "write after write"
+				}
+				item := r.newReportItem(log.Warning, uint64(id), msg)
+				item.Tags = append(item.Tags, getAtomNameTag(cmd), messages.TagSyncHazard(tag))
+				builder.Add(ctx, item)
+			}
+			for _, i := range wastes[id] {
+				begin := fmt.Sprint(i.Command.Indices)
+				for _, a := range i.Attachments {
+					for _, waste := range a.Waste {
+						item := r.newReportItem(log.Warning, uint64(id),
+							messages.WarnRenderPassWaste(a.Index, begin, waste.Description()))
+						item.Tags = append(item.Tags, getAtomNameTag(cmd), messages.TagRenderPassWaste(waste.String()))
+						builder.Add(ctx, item)
+					}
+				}
+			}
 		}
 		return nil
 	})
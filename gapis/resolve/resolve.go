@@ -262,6 +262,8 @@ func ResolveInternal(ctx context.Context, p path.Node) (interface{}, error) {
 		return ArrayIndex(ctx, p)
 	case *path.As:
 		return As(ctx, p)
+	case *path.Bisect:
+		return Bisect(ctx, p)
 	case *path.Blob:
 		return Blob(ctx, p)
 	case *path.Capture:
@@ -282,8 +284,16 @@ func ResolveInternal(ctx context.Context, p path.Node) (interface{}, error) {
 		return Context(ctx, p)
 	case *path.Contexts:
 		return Contexts(ctx, p)
+	case *path.DCEExplanation:
+		return DCEExplanation(ctx, p)
+	case *path.DependencyGraph:
+		return DependencyGraph(ctx, p)
+	case *path.DescriptorSets:
+		return DescriptorSets(ctx, p)
 	case *path.Device:
 		return Device(ctx, p)
+	case *path.DrawCallState:
+		return DrawCallState(ctx, p)
 	case *path.Events:
 		return Events(ctx, p)
 	case *path.FramebufferObservation:
@@ -298,18 +308,30 @@ func ResolveInternal(ctx context.Context, p path.Node) (interface{}, error) {
 		return MapIndex(ctx, p)
 	case *path.Memory:
 		return Memory(ctx, p)
+	case *path.MemoryUsage:
+		return MemoryUsage(ctx, p)
 	case *path.Mesh:
 		return Mesh(ctx, p)
 	case *path.Parameter:
 		return Parameter(ctx, p)
+	case *path.RedundantStateChanges:
+		return RedundantStateChanges(ctx, p)
+	case *path.RenderPassInstances:
+		return RenderPassInstances(ctx, p)
 	case *path.Report:
 		return Report(ctx, p)
 	case *path.ResourceData:
 		return ResourceData(ctx, p)
+	case *path.ResourceLifetimes:
+		return ResourceLifetimes(ctx, p)
 	case *path.Resources:
 		return Resources(ctx, p.Capture)
 	case *path.Result:
 		return Result(ctx, p)
+	case *path.ShaderCheck:
+		return ShaderCheck(ctx, p)
+	case *path.ShaderComplexities:
+		return ShaderComplexities(ctx, p)
 	case *path.Slice:
 		return Slice(ctx, p)
 	case *path.State:
@@ -320,8 +342,16 @@ func ResolveInternal(ctx context.Context, p path.Node) (interface{}, error) {
 		return StateTreeNode(ctx, p)
 	case *path.StateTreeNodeForPath:
 		return StateTreeNodeForPath(ctx, p)
+	case *path.Stats:
+		return Stats(ctx, p)
+	case *path.SyncGraph:
+		return SyncGraph(ctx, p)
 	case *path.Thumbnail:
 		return Thumbnail(ctx, p)
+	case *path.Timeline:
+		return Timeline(ctx, p)
+	case *path.UnusedResources:
+		return UnusedResources(ctx, p)
 	default:
 		return nil, fmt.Errorf("Unknown path type %T", p)
 	}
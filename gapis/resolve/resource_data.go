@@ -94,15 +94,18 @@ func buildResources(ctx context.Context, p *path.Command) (*ResolvedResources, e
 		}
 		return nil
 	})
+	var thread uint64
 	err = api.ForeachCmd(ctx, cmds, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
 		currentCmdResourceCount = 0
 		currentCmdIndex = uint64(id)
+		thread = cmd.Thread()
 		cmd.Mutate(ctx, id, state, nil)
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	ctx = api.PutResourceThread(ctx, thread)
 
 	resourceData := make(map[id.ID]interface{})
 	for k, v := range resources {
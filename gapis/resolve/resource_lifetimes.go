@@ -0,0 +1,267 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/resolve/initialcmds"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// ResourceLifetimes resolves the commands that created, used and destroyed
+// each of the resources of the capture.
+func ResourceLifetimes(ctx context.Context, p *path.ResourceLifetimes) (*service.ResourceLifetimes, error) {
+	obj, err := database.Build(ctx, &ResourceLifetimesResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.ResourceLifetimes), nil
+}
+
+type resourceLifetime struct {
+	// object is the object in the state. It is held so that the address of
+	// the object is not reused while it is in byPointer.
+	object    interface{}
+	lifetime  *service.ResourceLifetime
+	destroyed bool
+}
+
+// use returns the use of the resource by the command at id, adding it if
+// it is not the last use of the resource.
+func (l *resourceLifetime) use(p *path.Capture, id uint64) *service.ResourceUse {
+	uses := l.lifetime.Uses
+	if c := len(uses); c > 0 && uses[c-1].Command.Indices[0] == id {
+		return uses[c-1]
+	}
+	u := &service.ResourceUse{Command: p.Command(id)}
+	l.lifetime.Uses = append(l.lifetime.Uses, u)
+	return u
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The commands are mutated while building their dependency graph. Resources
+// are identified in the same way as by ResourcesResolvable, and the other
+// objects are those reported by the APIs implementing
+// api.ObjectLifetimeTracker, which also report the commands destroying the
+// objects. A command reads or writes an object if it reads or writes a state
+// key referencing it, or if it accesses the resource. The resources used by
+// draw calls are those accessed by draw call commands, and those reported by
+// the APIs implementing api.DrawnResourceTracker.
+func (r *ResourceLifetimesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	initialCmds, ranges, err := initialcmds.InitialCommands(ctx, r.Path.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]api.Cmd, 0, len(initialCmds)+len(c.Commands))
+	cmds = append(append(cmds, initialCmds...), c.Commands...)
+	g := dependencygraph.NewDependencyGraph(cmds, len(initialCmds))
+	s := c.NewUninitializedState(ctx, ranges)
+
+	lifetimes := []*resourceLifetime{}
+	byKey := map[interface{}]*resourceLifetime{}
+	byPointer := map[uintptr]*resourceLifetime{}
+
+	// The resources are counted as by ResourcesResolvable so that they get the
+	// same identifiers, and the other objects are counted separately.
+	var currentCmdIndex uint64
+	var currentCmdResourceCount, currentCmdObjectCount int
+	var currentCmdIsDraw bool
+	initial := true
+
+	// add adds the lifetime of an object created by the current command, value
+	// being the object in the state. The identifier of the lifetime must be
+	// set by the caller.
+	add := func(value interface{}, lifetime *service.ResourceLifetime) *resourceLifetime {
+		if !initial {
+			lifetime.Created = r.Path.Capture.Command(currentCmdIndex)
+		}
+		l := &resourceLifetime{object: value, lifetime: lifetime}
+		lifetimes = append(lifetimes, l)
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+			byPointer[v.Pointer()] = l
+		}
+		return l
+	}
+	// lookup returns the lifetime of the live object, or nil.
+	lookup := func(o api.Object) *resourceLifetime {
+		if l, ok := byKey[o.Key]; ok {
+			return l
+		}
+		if v := reflect.ValueOf(o.Value); v.Kind() == reflect.Ptr {
+			if l, ok := byPointer[v.Pointer()]; ok && !l.destroyed {
+				return l
+			}
+		}
+		return nil
+	}
+
+	s.OnResourceCreated = func(res api.Resource) {
+		ty := res.ResourceType(ctx)
+		currentCmdResourceCount++
+		add(res, &service.ResourceLifetime{
+			Id:     path.NewID(genResourceID(currentCmdIndex, currentCmdResourceCount)),
+			Type:   ty,
+			Kind:   ty.String(),
+			Handle: res.ResourceHandle(),
+			Label:  res.ResourceLabel(),
+		})
+	}
+	// use returns the use of the resource by the current command.
+	use := func(l *resourceLifetime) *service.ResourceUse {
+		u := l.use(r.Path.Capture, currentCmdIndex)
+		u.Draw = u.Draw || currentCmdIsDraw
+		return u
+	}
+	// access marks the resource as used by the current command.
+	access := func(res api.Resource) *service.ResourceUse {
+		if v := reflect.ValueOf(res); v.Kind() == reflect.Ptr && !initial {
+			if l, ok := byPointer[v.Pointer()]; ok && !l.destroyed {
+				return use(l)
+			}
+		}
+		return nil
+	}
+	s.OnResourceAccessed = func(res api.Resource) { access(res) }
+	for _, a := range c.APIs {
+		if t, ok := a.(api.DrawnResourceTracker); ok {
+			t.TrackDrawnResources(ctx, s, func(res api.Resource) {
+				if u := access(res); u != nil {
+					u.Draw = true
+				}
+			})
+		}
+	}
+
+	// destroyed holds the objects destroyed by the command being mutated.
+	destroyed := []api.Object{}
+
+	// resourcesOf returns the resources referenced by the state key at address.
+	resourcesOf := func(address dependencygraph.StateAddress) []*resourceLifetime {
+		v := reflect.ValueOf(g.GetStateKey(address))
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		out := []*resourceLifetime{}
+		for i, c := 0, v.NumField(); i < c; i++ {
+			if f := v.Field(i); f.Kind() == reflect.Ptr && !f.IsNil() {
+				if l, ok := byPointer[f.Pointer()]; ok {
+					out = append(out, l)
+				}
+			}
+		}
+		return out
+	}
+
+	// done is called after the command at index has been mutated.
+	done := func(index int) {
+		cmd := cmds[index]
+		if !initial {
+			b := g.Behaviours[index]
+			mark := func(addresses []dependencygraph.StateAddress, read, write bool) {
+				for _, a := range addresses {
+					for _, l := range resourcesOf(a) {
+						u := use(l)
+						u.Read, u.Write = u.Read || read, u.Write || write
+					}
+				}
+			}
+			mark(b.Reads, true, false)
+			mark(b.Modifies, true, true)
+			mark(b.Writes, false, true)
+		}
+		// Register the objects created by the command, including the
+		// resources that were already reported by OnResourceCreated.
+		if t, ok := cmd.API().(api.ObjectLifetimeTracker); ok {
+			for _, o := range t.CreatedObjects(ctx, g.GetCmdID(index), cmd, s) {
+				l := lookup(o)
+				if l == nil {
+					currentCmdObjectCount++
+					l = add(o.Value, &service.ResourceLifetime{
+						Id:     path.NewID(genObjectID(currentCmdIndex, currentCmdObjectCount)),
+						Kind:   o.Kind,
+						Handle: o.Handle,
+					})
+				}
+				byKey[o.Key] = l
+			}
+		}
+		for _, o := range destroyed {
+			if l := lookup(o); l != nil {
+				l.destroyed = true
+				if !initial {
+					l.lifetime.Destroyed = r.Path.Capture.Command(currentCmdIndex)
+				}
+				delete(byKey, o.Key)
+			}
+		}
+		destroyed = destroyed[:0]
+	}
+
+	g.Build(ctx, s, func(index int) {
+		if index > 0 {
+			done(index - 1)
+		}
+		if index >= g.NumInitialCommands {
+			initial = false
+			currentCmdIndex = uint64(index - g.NumInitialCommands)
+			currentCmdResourceCount, currentCmdObjectCount = 0, 0
+		}
+		cmd := cmds[index]
+		currentCmdIsDraw = !initial && cmd.CmdFlags(ctx, g.GetCmdID(index), s).IsDrawCall()
+		if t, ok := cmd.API().(api.ObjectLifetimeTracker); ok {
+			destroyed = append(destroyed, t.DestroyedObjects(ctx, g.GetCmdID(index), cmd, s)...)
+		}
+	})
+	if len(cmds) > 0 {
+		done(len(cmds) - 1)
+	}
+
+	out := &service.ResourceLifetimes{}
+	for _, l := range lifetimes {
+		// Skip the resources that did not survive until the start of the
+		// capture.
+		if l.destroyed && l.lifetime.Created == nil && l.lifetime.Destroyed == nil {
+			continue
+		}
+		out.Resources = append(out.Resources, l.lifetime)
+	}
+	return out, nil
+}
+
+// genObjectID returns the identifier of the rCount'th object that is not a
+// resource created by the command at createdAt. It differs from the
+// identifiers of the resources.
+func genObjectID(createdAt uint64, rCount int) id.ID {
+	return id.OfString(fmt.Sprintf("object %d %d", createdAt, rCount))
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/gles"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/resolve"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+func TestResourceLifetimeIDs(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = bind.PutRegistry(ctx, bind.NewRegistry())
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+
+	ctxHandle := memory.BytePtr(1, memory.ApplicationPool)
+	displayHandle := memory.BytePtr(2, memory.ApplicationPool)
+	surfaceHandle := memory.BytePtr(3, memory.ApplicationPool)
+	cb := gles.CommandBuilder{Thread: 0}
+	cmds := []api.Cmd{
+		cb.EglCreateContext(displayHandle, surfaceHandle, surfaceHandle, memory.Nullptr, ctxHandle),
+		api.WithExtras(
+			cb.EglMakeCurrent(displayHandle, surfaceHandle, surfaceHandle, ctxHandle, 0),
+			gles.NewStaticContextStateForTest(), gles.NewDynamicContextStateForTest(64, 64, false)),
+		cb.GlBindBuffer(gles.GLenum_GL_ARRAY_BUFFER, 1),
+		cb.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 1),
+		cb.GlCreateShader(gles.GLenum_GL_VERTEX_SHADER, 1),
+		cb.GlBindFramebuffer(gles.GLenum_GL_FRAMEBUFFER, 1),
+		cb.GlBindBuffer(gles.GLenum_GL_ELEMENT_ARRAY_BUFFER, 2),
+		cb.GlCreateProgram(2),
+		cb.GlBindTexture(gles.GLenum_GL_TEXTURE_CUBE_MAP, 3),
+	}
+	h := &capture.Header{Abi: device.WindowsX86_64}
+	p, err := capture.New(ctx, "lifetimes", h, cmds)
+	if !assert.For(ctx, "capture").ThatError(err).Succeeded() {
+		return
+	}
+
+	resources, err := resolve.Resources(ctx, p)
+	if !assert.For(ctx, "resources").ThatError(err).Succeeded() {
+		return
+	}
+	expected := []string{}
+	for _, ty := range resources.Types {
+		for _, r := range ty.Resources {
+			expected = append(expected, r.Id.ID().String())
+		}
+	}
+	sort.Strings(expected)
+
+	lifetimes, err := resolve.ResourceLifetimes(ctx, &path.ResourceLifetimes{Capture: p})
+	if !assert.For(ctx, "lifetimes").ThatError(err).Succeeded() {
+		return
+	}
+	got, objects := []string{}, map[string]bool{}
+	for _, l := range lifetimes.Resources {
+		if l.Type == api.ResourceType_UnknownResource {
+			objects[l.Id.ID().String()] = true
+		} else {
+			got = append(got, l.Id.ID().String())
+		}
+	}
+	sort.Strings(got)
+
+	assert.For(ctx, "resource count").ThatSlice(expected).IsLength(4)
+	assert.For(ctx, "resource ids").ThatSlice(got).Equals(expected)
+	assert.For(ctx, "object count").That(len(objects) >= 3).Equals(true)
+	for _, id := range expected {
+		assert.For(ctx, "object id %v", id).That(objects[id]).Equals(false)
+	}
+}
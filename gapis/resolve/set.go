@@ -85,15 +85,30 @@ func change(ctx context.Context, p path.Node, val interface{}) (path.Node, error
 			cmds[where] = with.(api.Cmd)
 		}
 
+		// The inserted commands are spliced in once all the edits are made, so
+		// that the indices of the other edits are stable.
+		inserts := map[uint64][]api.Cmd{}
+		insertCommands := func(where uint64, with []api.Cmd) {
+			inserts[where] = append(inserts[where], with...)
+		}
+
 		data, ok := val.(*api.ResourceData)
 		if !ok {
 			return nil, fmt.Errorf("Expected ResourceData, got %T", val)
 		}
 
-		if err := meta.Resource.SetResourceData(ctx, p.After, data, meta.IDMap, replaceCommands); err != nil {
+		if o, ok := meta.Resource.(api.ResourceOverrider); ok {
+			err = o.OverrideResourceData(ctx, p.After, data, insertCommands)
+		} else {
+			err = meta.Resource.SetResourceData(ctx, p.After, data, meta.IDMap, replaceCommands)
+		}
+		if err != nil {
 			return nil, err
 		}
 
+		indices := append([]uint64{}, p.After.Indices...)
+		cmds, indices[0] = insertCmds(cmds, inserts, cmdIdx)
+
 		// Store the new command list
 		c, err := changeCommands(ctx, p.After.Capture, cmds)
 		if err != nil {
@@ -104,7 +119,7 @@ func change(ctx context.Context, p path.Node, val interface{}) (path.Node, error
 			Id: p.Id, // TODO: Shouldn't this change?
 			After: &path.Command{
 				Capture: c,
-				Indices: p.After.Indices,
+				Indices: indices,
 			},
 		}, nil
 
@@ -364,3 +379,22 @@ func assign(dst, src reflect.Value) error {
 
 	return deep.Copy(dst.Addr().Interface(), src.Interface())
 }
+
+// insertCmds returns cmds with the commands of inserts inserted before the
+// commands at their indices, and the new index of the command at idx.
+func insertCmds(cmds []api.Cmd, inserts map[uint64][]api.Cmd, idx uint64) ([]api.Cmd, uint64) {
+	if len(inserts) == 0 {
+		return cmds, idx
+	}
+	out := make([]api.Cmd, 0, len(cmds))
+	newIdx := idx
+	for i, cmd := range cmds {
+		with := inserts[uint64(i)]
+		out = append(out, with...)
+		out = append(out, cmd)
+		if uint64(i) <= idx {
+			newIdx += uint64(len(with))
+		}
+	}
+	return out, newIdx
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/messages"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+	"github.com/google/gapid/gapis/stringtable"
+)
+
+// ShaderCheck resolves the result of compiling offline each of the shaders
+// built by the commands of the capture.
+func ShaderCheck(ctx context.Context, p *path.ShaderCheck) (*api.ShaderCheck, error) {
+	obj, err := database.Build(ctx, &ShaderCheckResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.ShaderCheck), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The commands are passed to the APIs implementing the api.ShaderChecker
+// interface after they are mutated. The creating command of a shader is the
+// last command creating a resource with the handle of the shader.
+func (r *ShaderCheckResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var d *device.Instance
+	if r.Path.Device != nil {
+		if d, err = Device(ctx, r.Path.Device); err != nil {
+			return nil, err
+		}
+	}
+
+	var currentCmd api.CmdID
+	created := map[string]api.CmdID{}
+	s := c.NewState(ctx)
+	s.OnResourceCreated = func(res api.Resource) {
+		created[res.ResourceHandle()] = currentCmd
+	}
+
+	out := &api.ShaderCheck{}
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		currentCmd = id
+		if err := cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */); err != nil {
+			return nil
+		}
+		checker, ok := cmd.API().(api.ShaderChecker)
+		if !ok {
+			return nil
+		}
+		if res := checker.CheckShader(ctx, cmd, s, d); res != nil {
+			if at, ok := created[res.Handle]; ok {
+				res.Created = r.Path.Capture.Command(uint64(at))
+			}
+			res.Built = r.Path.Capture.Command(uint64(id))
+			out.Shaders = append(out.Shaders, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// shaderCheckReport returns a report with an item for each of the errors and
+// warnings of the offline compilation of the shaders.
+func (r *ReportResolvable) shaderCheckReport(ctx context.Context) (*service.Report, error) {
+	check, err := ShaderCheck(ctx, r.Path.Capture.ShaderCheck(r.Path.Device))
+	if err != nil {
+		return nil, err
+	}
+
+	builder := service.NewReportBuilder()
+	for _, s := range check.Shaders {
+		add := func(severity log.Severity, msg *stringtable.Msg) {
+			item := r.newReportItem(severity, s.Built.Indices[0], msg)
+			item.Tags = append(item.Tags, messages.TagShaderCheck(s.Type.String()))
+			if s.Created != nil {
+				item.Tags = append(item.Tags, messages.TagShaderCreatedBy(s.Created.Indices[0]))
+			}
+			builder.Add(ctx, item)
+		}
+		for _, e := range s.Errors {
+			add(log.Error, messages.ErrShaderCheck(s.Handle, e))
+		}
+		for _, w := range s.Warnings {
+			add(log.Warning, messages.WarnShaderCheck(s.Handle, w))
+		}
+	}
+	return builder.Build(), nil
+}
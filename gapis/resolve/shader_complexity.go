@@ -0,0 +1,57 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// ShaderComplexities resolves the instruction counts of each of the shaders
+// built by the commands of the capture.
+func ShaderComplexities(ctx context.Context, p *path.ShaderComplexities) (*api.ShaderComplexities, error) {
+	obj, err := database.Build(ctx, &ShaderComplexitiesResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.ShaderComplexities), nil
+}
+
+// Resolve implements the database.Resolver interface.
+func (r *ShaderComplexitiesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &api.ShaderComplexities{}
+	for _, a := range c.APIs {
+		provider, ok := a.(api.ShaderComplexityProvider)
+		if !ok {
+			continue
+		}
+		shaders, err := provider.ShaderComplexities(ctx, r.Path.Capture)
+		if err != nil {
+			return nil, err
+		}
+		out.Shaders = append(out.Shaders, shaders...)
+	}
+	return out, nil
+}
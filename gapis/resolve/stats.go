@@ -0,0 +1,148 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// Stats resolves the statistics of the commands of the capture.
+func Stats(ctx context.Context, p *path.Stats) (*service.Stats, error) {
+	obj, err := database.Build(ctx, &StatsResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.Stats), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// Observation bytes count every observation of the commands, while resource
+// bytes count each distinct observed resource once. The frames end with the end
+// of frame commands and the swaps flagged as starting the next frame. The
+// commands after the last end of frame are an unfinished frame.
+func (r *StatsResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := Contexts(ctx, r.Path.Capture.Contexts())
+	if err != nil {
+		return nil, err
+	}
+
+	out := &service.Stats{Contexts: uint32(len(contexts))}
+	resources := map[id.ID]uint64{}
+	if c.InitialState != nil {
+		for _, o := range c.InitialState.Memory {
+			resources[o.ID] = o.Range.Size
+		}
+	}
+
+	commands := map[string]*service.CommandStats{}
+	apis := map[string]*service.APIStats{}
+	threads := map[uint64]bool{}
+	var frame *service.FrameStats
+	s := c.NewState(ctx)
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, cmdID api.CmdID, cmd api.Cmd) error {
+		flags := cmd.CmdFlags(ctx, cmdID, s)
+		cmd.Mutate(ctx, cmdID, s, nil /* no builder, just mutate */)
+
+		read, written := uint64(0), uint64(0)
+		if o := cmd.Extras().Observations(); o != nil {
+			for _, rd := range o.Reads {
+				read += rd.Range.Size
+				resources[rd.ID] = rd.Range.Size
+			}
+			for _, w := range o.Writes {
+				written += w.Range.Size
+				resources[w.ID] = w.Range.Size
+			}
+		}
+		draws := uint64(0)
+		if flags.IsDrawCall() {
+			draws = 1
+		}
+		threads[cmd.Thread()] = true
+		out.ObservationBytesRead += read
+		out.ObservationBytesWritten += written
+
+		apiName := "No API"
+		if a := cmd.API(); a != nil {
+			apiName = a.Name()
+		}
+		cs, ok := commands[cmd.CmdName()]
+		if !ok {
+			cs = &service.CommandStats{Name: cmd.CmdName(), Api: apiName}
+			commands[cmd.CmdName()] = cs
+			out.Commands = append(out.Commands, cs)
+		}
+		cs.Count++
+		cs.ObservationBytesRead += read
+		cs.ObservationBytesWritten += written
+		cs.DrawCalls += draws
+
+		as, ok := apis[apiName]
+		if !ok {
+			as = &service.APIStats{Name: apiName}
+			apis[apiName] = as
+			out.Apis = append(out.Apis, as)
+		}
+		as.Commands++
+		as.ObservationBytesRead += read
+		as.ObservationBytesWritten += written
+		as.DrawCalls += draws
+
+		if frame == nil {
+			frame = &service.FrameStats{
+				Frame:      uint32(len(out.Frames)),
+				First:      r.Path.Capture.Command(uint64(cmdID)),
+				Unfinished: true,
+			}
+			out.Frames = append(out.Frames, frame)
+		}
+		frame.Last = r.Path.Capture.Command(uint64(cmdID))
+		frame.Commands++
+		frame.ObservationBytesRead += read
+		frame.ObservationBytesWritten += written
+		frame.DrawCalls += draws
+		if flags.IsEndOfFrame() || flags.IsStartOfFrame() {
+			frame.Unfinished = false
+			frame = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out.Threads = uint32(len(threads))
+	out.Resources = uint64(len(resources))
+	for _, size := range resources {
+		out.ResourceBytes += size
+	}
+	sort.Slice(out.Commands, func(i, j int) bool { return out.Commands[i].Name < out.Commands[j].Name })
+	sort.Slice(out.Apis, func(i, j int) bool { return out.Apis[i].Name < out.Apis[j].Name })
+	return out, nil
+}
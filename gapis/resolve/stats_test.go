@@ -0,0 +1,113 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve_test
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/gles"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/resolve"
+)
+
+type commandStats struct {
+	name                 string
+	count, read, written uint64
+	draws                uint64
+}
+
+type frameStats struct {
+	first, last, commands uint64
+	draws                 uint64
+	unfinished            bool
+}
+
+func TestStats(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = bind.PutRegistry(ctx, bind.NewRegistry())
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+
+	ctxHandle := memory.BytePtr(1, memory.ApplicationPool)
+	displayHandle := memory.BytePtr(2, memory.ApplicationPool)
+	surfaceHandle := memory.BytePtr(3, memory.ApplicationPool)
+	data := memory.BytePtr(0x1000, memory.ApplicationPool)
+	cb := gles.CommandBuilder{Thread: 0}
+	rng, res := memory.Store(ctx, device.Little32, data, []uint32{1, 2, 3, 4})
+	cmds := []api.Cmd{
+		cb.EglCreateContext(displayHandle, surfaceHandle, surfaceHandle, memory.Nullptr, ctxHandle),
+		api.WithExtras(
+			cb.EglMakeCurrent(displayHandle, surfaceHandle, surfaceHandle, ctxHandle, 0),
+			gles.NewStaticContextStateForTest(), gles.NewDynamicContextStateForTest(64, 64, false)),
+		cb.GlClear(gles.GLbitfield_GL_COLOR_BUFFER_BIT),
+		cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 3).AddRead(rng, res),
+		cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 3).AddRead(rng, res),
+		cb.EglSwapBuffers(displayHandle, surfaceHandle, gles.EGLBoolean(1)),
+		cb.GlDrawArrays(gles.GLenum_GL_TRIANGLES, 0, 3).AddWrite(rng, res),
+		gles.CommandBuilder{Thread: 1}.GlFlush(),
+	}
+	h := &capture.Header{Abi: device.WindowsX86_64}
+	p, err := capture.New(ctx, "stats", h, cmds)
+	if !assert.For(ctx, "capture").ThatError(err).Succeeded() {
+		return
+	}
+
+	stats, err := resolve.Stats(ctx, p.Stats())
+	if !assert.For(ctx, "stats").ThatError(err).Succeeded() {
+		return
+	}
+
+	commands := []commandStats{}
+	for _, c := range stats.Commands {
+		assert.For(ctx, "%v api", c.Name).That(c.Api).Equals("gles")
+		commands = append(commands, commandStats{c.Name, c.Count, c.ObservationBytesRead, c.ObservationBytesWritten, c.DrawCalls})
+	}
+	assert.For(ctx, "commands").ThatSlice(commands).DeepEquals([]commandStats{
+		{"eglCreateContext", 1, 0, 0, 0},
+		{"eglMakeCurrent", 1, 0, 0, 0},
+		{"eglSwapBuffers", 1, 0, 0, 0},
+		{"glClear", 1, 0, 0, 0},
+		{"glDrawArrays", 3, 2 * rng.Size, rng.Size, 3},
+		{"glFlush", 1, 0, 0, 0},
+	})
+
+	if assert.For(ctx, "apis").ThatSlice(stats.Apis).IsLength(1) {
+		a := stats.Apis[0]
+		assert.For(ctx, "api commands").That(a.Commands).Equals(uint64(len(cmds)))
+		assert.For(ctx, "api draw calls").That(a.DrawCalls).Equals(uint64(3))
+	}
+
+	frames := []frameStats{}
+	for _, f := range stats.Frames {
+		frames = append(frames, frameStats{f.First.Indices[0], f.Last.Indices[0], f.Commands, f.DrawCalls, f.Unfinished})
+	}
+	assert.For(ctx, "frames").ThatSlice(frames).DeepEquals([]frameStats{
+		{0, 5, 6, 2, false},
+		{6, 7, 2, 1, true},
+	})
+
+	assert.For(ctx, "bytes read").That(stats.ObservationBytesRead).Equals(2 * rng.Size)
+	assert.For(ctx, "bytes written").That(stats.ObservationBytesWritten).Equals(rng.Size)
+	assert.For(ctx, "resources").That(stats.Resources).Equals(uint64(1))
+	assert.For(ctx, "resource bytes").That(stats.ResourceBytes).Equals(rng.Size)
+	assert.For(ctx, "threads").That(stats.Threads).Equals(uint32(2))
+	assert.For(ctx, "contexts").That(stats.Contexts).Equals(uint32(1))
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/sync"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// SyncGraph resolves the graph of the queue submissions of the capture and of
+// the synchronization primitives relating them.
+func SyncGraph(ctx context.Context, p *path.SyncGraph) (*api.SyncGraph, error) {
+	obj, err := database.Build(ctx, &SyncGraphResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*api.SyncGraph), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The graphs of the APIs implementing the sync.SyncGraphProvider interface
+// are merged, sharing the host queue.
+func (r *SyncGraphResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d, err := SyncData(ctx, r.Path.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &api.SyncGraph{Queues: []*api.SyncQueue{{Name: "Host"}}}
+	for _, a := range c.APIs {
+		p, ok := a.(sync.SyncGraphProvider)
+		if !ok {
+			continue
+		}
+		g, err := p.SyncGraph(ctx, r.Path.Capture, d)
+		if err != nil {
+			return nil, err
+		}
+		queues, nodes := uint32(len(out.Queues)-1), uint32(len(out.Nodes))
+		if len(g.Queues) > 1 {
+			out.Queues = append(out.Queues, g.Queues[1:]...)
+		}
+		for _, n := range g.Nodes {
+			if n.Queue > 0 {
+				n.Queue += queues
+			}
+			out.Nodes = append(out.Nodes, n)
+		}
+		for _, e := range g.Edges {
+			e.From += nodes
+			e.To += nodes
+			out.Edges = append(out.Edges, e)
+		}
+	}
+	return out, nil
+}
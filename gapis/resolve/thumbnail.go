@@ -44,8 +44,7 @@ func Thumbnail(ctx context.Context, p *path.Thumbnail) (*image.Info, error) {
 func CommandThumbnail(ctx context.Context, w, h uint32, f *image.Format, noOpt bool, p *path.Command) (*image.Info, error) {
 	imageInfoPath, err := FramebufferAttachment(ctx,
 		&service.ReplaySettings{
-			nil,
-			noOpt,
+			DisableReplayOptimization: noOpt,
 		},
 		p,
 		api.FramebufferAttachment_Color0,
@@ -0,0 +1,289 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// Timeline resolves the timeline of the commands of the capture, with a track
+// per thread and two tracks per context.
+func Timeline(ctx context.Context, p *path.Timeline) (*service.Timeline, error) {
+	obj, err := database.Build(ctx, &TimelineResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.Timeline), nil
+}
+
+// Resolve implements the database.Resolver interface.
+func (r *TimelineResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctxInfos, err := ContextsByID(ctx, r.Path.Capture.Contexts())
+	if err != nil {
+		return nil, err
+	}
+
+	b := newTimelineBuilder(r.Path.Capture)
+	s := c.NewState(ctx)
+	err = api.ForeachCmd(ctx, c.Commands, func(ctx context.Context, id api.CmdID, cmd api.Cmd) error {
+		flags := cmd.CmdFlags(ctx, id, s)
+		cmd.Mutate(ctx, id, s, nil /* no builder, just mutate */)
+
+		thread := cmd.Thread()
+		var tc *timelineContext
+		if a := cmd.API(); a != nil {
+			if context := a.Context(s, thread); context != nil {
+				name := "Context"
+				if info, ok := ctxInfos[context.ID()]; ok {
+					name = info.Name
+				}
+				tc = b.context(id, context.ID(), name, thread)
+			}
+		}
+
+		label := ""
+		if l, ok := cmd.(api.Labeled); ok && flags.IsPushUserMarker() {
+			label = l.Label(ctx, s)
+		}
+		b.command(id, cmd.CmdName(), thread, tc, flags, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := b.finish()
+	if err := r.addSharedObjectFlows(ctx, out, b.cmdContexts); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// timelineThread is the state of a thread track while building a timeline.
+type timelineThread struct {
+	track   uint32
+	run     *service.TimelineSlice
+	context *timelineContext
+}
+
+// timelineContext is the state of the tracks of a context while building a
+// timeline.
+type timelineContext struct {
+	track      uint32
+	frameTrack uint32
+	name       string
+	thread     uint64
+	last       api.CmdID
+	frameStart api.CmdID
+	markers    []*service.TimelineSlice
+}
+
+// timelineBuilder builds a timeline from the commands of a capture.
+//
+// The consecutive commands of a thread in the same context are a single slice
+// on the track of the thread. User markers and draw calls are slices on the
+// track of the context current on the thread of the command, and frames are
+// slices on a separate frame track of the context, as user markers can cross
+// frame boundaries.
+type timelineBuilder struct {
+	capture     *path.Capture
+	out         *service.Timeline
+	threads     map[uint64]*timelineThread
+	contexts    []*timelineContext
+	contextIDs  map[api.ContextID]*timelineContext
+	cmdContexts map[api.CmdID]*timelineContext
+	lastThread  *timelineThread
+}
+
+func newTimelineBuilder(capture *path.Capture) *timelineBuilder {
+	return &timelineBuilder{
+		capture:     capture,
+		out:         &service.Timeline{},
+		threads:     map[uint64]*timelineThread{},
+		contextIDs:  map[api.ContextID]*timelineContext{},
+		cmdContexts: map[api.CmdID]*timelineContext{},
+	}
+}
+
+func (b *timelineBuilder) newTrack(kind service.TimelineTrackKind, name string) uint32 {
+	b.out.Tracks = append(b.out.Tracks, &service.TimelineTrack{Kind: kind, Name: name})
+	return uint32(len(b.out.Tracks) - 1)
+}
+
+func (b *timelineBuilder) addSlice(track uint32, kind service.TimelineSliceKind, name string, first, last api.CmdID) *service.TimelineSlice {
+	slice := &service.TimelineSlice{
+		Track: track,
+		Kind:  kind,
+		Name:  name,
+		First: b.capture.Command(uint64(first)),
+		Last:  b.capture.Command(uint64(last)),
+	}
+	b.out.Slices = append(b.out.Slices, slice)
+	return slice
+}
+
+// thread returns the state of the given thread, adding its track if this is
+// its first command.
+func (b *timelineBuilder) thread(thread uint64) *timelineThread {
+	t, ok := b.threads[thread]
+	if !ok {
+		t = &timelineThread{track: b.newTrack(service.TimelineTrackKind_ThreadTrack, fmt.Sprintf("Thread: 0x%x", thread))}
+		b.threads[thread] = t
+	}
+	return t
+}
+
+// context returns the state of the context with the given identifier, adding
+// its tracks if the command id on the given thread is its first command.
+func (b *timelineBuilder) context(id api.CmdID, ctxID api.ContextID, name string, thread uint64) *timelineContext {
+	tc, ok := b.contextIDs[ctxID]
+	if !ok {
+		tc = &timelineContext{
+			track:      b.newTrack(service.TimelineTrackKind_ContextTrack, name),
+			frameTrack: b.newTrack(service.TimelineTrackKind_FrameTrack, name+" frames"),
+			name:       name,
+			thread:     thread,
+			frameStart: id,
+		}
+		b.contextIDs[ctxID] = tc
+		b.contexts = append(b.contexts, tc)
+	}
+	return tc
+}
+
+// command adds the command with the given identifier to the timeline. tc is
+// the context current on the thread of the command, if any, and label is the
+// label of the user marker pushed by the command, if any.
+func (b *timelineBuilder) command(id api.CmdID, name string, thread uint64, tc *timelineContext, flags api.CmdFlags, label string) {
+	t := b.thread(thread)
+	if t == b.lastThread && t.context == tc && t.run != nil {
+		t.run.Last = b.capture.Command(uint64(id))
+	} else {
+		runName := "Commands"
+		if tc != nil {
+			runName = tc.name
+		}
+		t.run = b.addSlice(t.track, service.TimelineSliceKind_CommandSlice, runName, id, id)
+		t.context = tc
+	}
+	b.lastThread = t
+
+	if tc == nil {
+		return
+	}
+	if tc.thread != thread {
+		b.out.Flows = append(b.out.Flows, &service.TimelineFlow{
+			Kind:      service.TimelineFlowKind_ContextSwitch,
+			Name:      name,
+			FromTrack: b.threads[tc.thread].track,
+			From:      b.capture.Command(uint64(tc.last)),
+			ToTrack:   t.track,
+			To:        b.capture.Command(uint64(id)),
+		})
+		tc.thread = thread
+	}
+	tc.last = id
+	b.cmdContexts[id] = tc
+
+	if flags.IsPushUserMarker() {
+		markerName := fmt.Sprintf("Marker %d", len(tc.markers))
+		if len(label) > 0 {
+			markerName = fmt.Sprintf("\"%s\"", label)
+		}
+		tc.markers = append(tc.markers, b.addSlice(tc.track, service.TimelineSliceKind_UserMarkerSlice, markerName, id, id))
+	}
+	if flags.IsPopUserMarker() && len(tc.markers) > 0 {
+		tc.markers[len(tc.markers)-1].Last = b.capture.Command(uint64(id))
+		tc.markers = tc.markers[:len(tc.markers)-1]
+	}
+	if flags.IsDrawCall() {
+		b.addSlice(tc.track, service.TimelineSliceKind_DrawCallSlice, name, id, id)
+	}
+	// The GLES swaps are flagged as starting the next frame, and end the frame.
+	if flags.IsEndOfFrame() || flags.IsStartOfFrame() {
+		b.addSlice(tc.frameTrack, service.TimelineSliceKind_FrameSlice, "Frame", tc.frameStart, id)
+		tc.frameStart = id + 1
+	}
+}
+
+// finish closes the frames and user markers still open at the end of the
+// capture, and returns the timeline with its slices in command order.
+func (b *timelineBuilder) finish() *service.Timeline {
+	for _, tc := range b.contexts {
+		if tc.frameStart <= tc.last {
+			b.addSlice(tc.frameTrack, service.TimelineSliceKind_FrameSlice, "Frame", tc.frameStart, tc.last)
+		}
+		for _, m := range tc.markers {
+			m.Last = b.capture.Command(uint64(tc.last))
+		}
+		tc.markers = nil
+	}
+	sort.SliceStable(b.out.Slices, func(i, j int) bool {
+		return b.out.Slices[i].First.Indices[0] < b.out.Slices[j].First.Indices[0]
+	})
+	return b.out
+}
+
+// addSharedObjectFlows adds a flow to out for the first use of each resource
+// by each context other than the one that created it. cmdContexts holds the
+// context of each command.
+func (r *TimelineResolvable) addSharedObjectFlows(ctx context.Context, out *service.Timeline, cmdContexts map[api.CmdID]*timelineContext) error {
+	lifetimes, err := ResourceLifetimes(ctx, r.Path.Capture.ResourceLifetimes())
+	if err != nil {
+		return err
+	}
+	for _, l := range lifetimes.Resources {
+		if l.Created == nil {
+			continue
+		}
+		creator := cmdContexts[api.CmdID(l.Created.Indices[0])]
+		if creator == nil {
+			continue
+		}
+		seen := map[*timelineContext]bool{creator: true}
+		for _, u := range l.Uses {
+			user := cmdContexts[api.CmdID(u.Command.Indices[0])]
+			if user == nil || seen[user] {
+				continue
+			}
+			seen[user] = true
+			out.Flows = append(out.Flows, &service.TimelineFlow{
+				Kind:      service.TimelineFlowKind_SharedObject,
+				Name:      l.Handle,
+				FromTrack: creator.track,
+				From:      l.Created,
+				ToTrack:   user.track,
+				To:        u.Command,
+			})
+		}
+	}
+	sort.SliceStable(out.Flows, func(i, j int) bool {
+		return out.Flows[i].To.Indices[0] < out.Flows[j].To.Indices[0]
+	})
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+type timelineSlice struct {
+	track       uint32
+	kind        service.TimelineSliceKind
+	name        string
+	first, last uint64
+}
+
+type timelineFlow struct {
+	kind     service.TimelineFlowKind
+	from, to uint64
+}
+
+func TestTimeline(t *testing.T) {
+	ctx := log.Testing(t)
+	b := newTimelineBuilder(path.NewCapture(id.ID{}))
+	ctxA, ctxB := api.ContextID{1}, api.ContextID{2}
+
+	for i, cmd := range []struct {
+		thread  uint64
+		context *api.ContextID
+		flags   api.CmdFlags
+		label   string
+	}{
+		{1, nil, 0, ""},
+		{1, &ctxA, api.PushUserMarker, "outer"},
+		{1, &ctxA, api.PushUserMarker, ""},
+		{1, &ctxA, api.DrawCall, ""},
+		{2, &ctxB, api.DrawCall, ""},
+		{1, &ctxA, api.PopUserMarker, ""},
+		{1, &ctxA, api.EndOfFrame, ""},
+		{2, &ctxA, api.DrawCall, ""},
+		{2, &ctxA, api.PopUserMarker | api.StartOfFrame, ""},
+		{2, &ctxA, api.PushUserMarker, "unclosed"},
+		{2, &ctxA, 0, ""},
+	} {
+		cmdID := api.CmdID(i)
+		var tc *timelineContext
+		if cmd.context != nil {
+			tc = b.context(cmdID, *cmd.context, "Context", cmd.thread)
+		}
+		b.command(cmdID, "cmd", cmd.thread, tc, cmd.flags, cmd.label)
+	}
+	out := b.finish()
+
+	tracks := []service.TimelineTrackKind{}
+	for _, track := range out.Tracks {
+		tracks = append(tracks, track.Kind)
+	}
+	assert.For(ctx, "tracks").ThatSlice(tracks).Equals([]service.TimelineTrackKind{
+		service.TimelineTrackKind_ThreadTrack,
+		service.TimelineTrackKind_ContextTrack,
+		service.TimelineTrackKind_FrameTrack,
+		service.TimelineTrackKind_ContextTrack,
+		service.TimelineTrackKind_FrameTrack,
+		service.TimelineTrackKind_ThreadTrack,
+	})
+
+	// The "outer" marker crosses the end of the first frame of context A, so
+	// the frames are on their own track.
+	slices := []timelineSlice{}
+	for _, s := range out.Slices {
+		slices = append(slices, timelineSlice{s.Track, s.Kind, s.Name, s.First.Indices[0], s.Last.Indices[0]})
+	}
+	assert.For(ctx, "slices").ThatSlice(slices).DeepEquals([]timelineSlice{
+		{0, service.TimelineSliceKind_CommandSlice, "Commands", 0, 0},
+		{0, service.TimelineSliceKind_CommandSlice, "Context", 1, 3},
+		{1, service.TimelineSliceKind_UserMarkerSlice, `"outer"`, 1, 8},
+		{2, service.TimelineSliceKind_FrameSlice, "Frame", 1, 6},
+		{1, service.TimelineSliceKind_UserMarkerSlice, "Marker 1", 2, 5},
+		{1, service.TimelineSliceKind_DrawCallSlice, "cmd", 3, 3},
+		{5, service.TimelineSliceKind_CommandSlice, "Context", 4, 4},
+		{3, service.TimelineSliceKind_DrawCallSlice, "cmd", 4, 4},
+		{4, service.TimelineSliceKind_FrameSlice, "Frame", 4, 4},
+		{0, service.TimelineSliceKind_CommandSlice, "Context", 5, 6},
+		{5, service.TimelineSliceKind_CommandSlice, "Context", 7, 10},
+		{1, service.TimelineSliceKind_DrawCallSlice, "cmd", 7, 7},
+		{2, service.TimelineSliceKind_FrameSlice, "Frame", 7, 8},
+		{1, service.TimelineSliceKind_UserMarkerSlice, `"unclosed"`, 9, 10},
+		{2, service.TimelineSliceKind_FrameSlice, "Frame", 9, 10},
+	})
+
+	flows := []timelineFlow{}
+	for _, f := range out.Flows {
+		flows = append(flows, timelineFlow{f.Kind, f.From.Indices[0], f.To.Indices[0]})
+	}
+	assert.For(ctx, "flows").ThatSlice(flows).DeepEquals([]timelineFlow{
+		{service.TimelineFlowKind_ContextSwitch, 6, 7},
+	})
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/messages"
+	"github.com/google/gapid/gapis/resolve/dependencygraph"
+	"github.com/google/gapid/gapis/service"
+	"github.com/google/gapid/gapis/service/path"
+)
+
+// UnusedResources resolves the resources of the capture that are never used by
+// a draw call, never read after they are written, or uploaded multiple times
+// with the same content.
+func UnusedResources(ctx context.Context, p *path.UnusedResources) (*service.UnusedResources, error) {
+	obj, err := database.Build(ctx, &UnusedResourcesResolvable{p})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*service.UnusedResources), nil
+}
+
+// Resolve implements the database.Resolver interface.
+//
+// The uses of the resources, including their uses by draw calls, are taken
+// from their lifetimes. Two uploads are the same if they are made by commands
+// with the same parameters, other than pointers, that read the same
+// observations.
+// Only textures and programs are expected to be used by draw calls, and only
+// the resources with known reads and writes are checked for unread writes and
+// duplicate uploads. The reads and writes are those of the dependency graph,
+// so the APIs that do not implement
+// dependencygraph.DependencyGraphBehaviourProvider, such as Vulkan, are listed
+// as unchecked.
+func (r *UnusedResourcesResolvable) Resolve(ctx context.Context) (interface{}, error) {
+	ctx = capture.Put(ctx, r.Path.Capture)
+	c, err := capture.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lifetimes, err := ResourceLifetimes(ctx, r.Path.Capture.ResourceLifetimes())
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := map[uint64]string{}
+	for _, l := range lifetimes.Resources {
+		for _, u := range l.Uses {
+			if u.Write {
+				uploads[u.Command.Indices[0]] = ""
+			}
+		}
+	}
+
+	for i := range uploads {
+		if i < uint64(len(c.Commands)) {
+			uploads[i] = uploadKey(c.Commands[i])
+		}
+	}
+
+	out := &service.UnusedResources{UncheckedApis: uncheckedAPIs(c.APIs)}
+	for _, l := range lifetimes.Resources {
+		u := &service.UnusedResource{
+			Id:      l.Id,
+			Type:    l.Type,
+			Handle:  l.Handle,
+			Label:   l.Label,
+			Created: l.Created,
+		}
+		if l.Type == api.ResourceType_TextureResource || l.Type == api.ResourceType_ProgramResource {
+			u.NeverDrawn = true
+			for _, use := range l.Uses {
+				if use.Draw {
+					u.NeverDrawn = false
+					break
+				}
+			}
+		}
+
+		var lastWrite *path.Command
+		originals := map[string]*path.Command{}
+		for _, use := range l.Uses {
+			if use.Read {
+				lastWrite = nil
+			}
+			if !use.Write {
+				continue
+			}
+			lastWrite = use.Command
+			key := uploads[use.Command.Indices[0]]
+			if key == "" {
+				continue
+			}
+			if original, ok := originals[key]; ok {
+				u.DuplicateUploads = append(u.DuplicateUploads, &service.DuplicateUpload{
+					Command:  use.Command,
+					Original: original,
+				})
+			} else {
+				originals[key] = use.Command
+			}
+		}
+		u.UnreadWrite = lastWrite
+
+		if u.NeverDrawn || u.UnreadWrite != nil || len(u.DuplicateUploads) > 0 {
+			out.Resources = append(out.Resources, u)
+		}
+	}
+	return out, nil
+}
+
+// uncheckedAPIs returns the sorted names of the APIs that do not report the
+// state read and written by their commands to the dependency graph.
+func uncheckedAPIs(apis []api.API) []string {
+	out := []string{}
+	for _, a := range apis {
+		if _, ok := a.(dependencygraph.DependencyGraphBehaviourProvider); !ok {
+			out = append(out, a.Name())
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// uploadKey returns a key identifying the upload made by cmd, or an empty
+// string if cmd does not read memory. The key holds the name of the command,
+// its parameters other than pointers, which give the target of the upload,
+// and the observations of the memory it reads.
+func uploadKey(cmd api.Cmd) string {
+	o := cmd.Extras().Observations()
+	if o == nil || len(o.Reads) == 0 {
+		return ""
+	}
+	parts := []string{cmd.CmdName()}
+	v := reflect.ValueOf(cmd)
+	for v.Kind() != reflect.Struct {
+		v = v.Elem()
+	}
+	t := v.Type()
+	for i, count := 0, t.NumField(); i < count; i++ {
+		name, ok := t.Field(i).Tag.Lookup("param")
+		if !ok {
+			continue
+		}
+		param := v.Field(i).Interface()
+		if _, ok := param.(memory.ReflectPointer); ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%v=%v", name, param))
+	}
+	for _, r := range o.Reads {
+		parts = append(parts, r.ID.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// unusedResourcesReport returns a report with an item for each of the ways the
+// resources of the capture are unused or wasted.
+func (r *ReportResolvable) unusedResourcesReport(ctx context.Context) (*service.Report, error) {
+	unused, err := UnusedResources(ctx, r.Path.Capture.UnusedResources())
+	if err != nil {
+		return nil, err
+	}
+
+	builder := service.NewReportBuilder()
+	for _, a := range unused.UncheckedApis {
+		item := r.newReportItem(log.Info, uint64(api.CmdNoID), messages.UnusedResourceUnchecked(a))
+		item.Tags = append(item.Tags, messages.TagUnusedResource("not checked"))
+		builder.Add(ctx, item)
+	}
+	for _, u := range unused.Resources {
+		if u.NeverDrawn {
+			at := uint64(api.CmdNoID)
+			if u.Created != nil {
+				at = u.Created.Indices[0]
+			}
+			item := r.newReportItem(log.Warning, at, messages.UnusedResourceNeverDrawn(u.Handle))
+			item.Tags = append(item.Tags, messages.TagUnusedResource("never drawn"))
+			builder.Add(ctx, item)
+		}
+		if u.UnreadWrite != nil {
+			item := r.newReportItem(log.Info, u.UnreadWrite.Indices[0], messages.UnusedResourceNotRead(u.Handle))
+			item.Tags = append(item.Tags, messages.TagUnusedResource("not read"))
+			builder.Add(ctx, item)
+		}
+		for _, d := range u.DuplicateUploads {
+			item := r.newReportItem(log.Warning, d.Command.Indices[0],
+				messages.UnusedResourceDuplicateUpload(u.Handle, d.Original.Indices[0]))
+			item.Tags = append(item.Tags, messages.TagUnusedResource("duplicate upload"))
+			builder.Add(ctx, item)
+		}
+	}
+	return builder.Build(), nil
+}
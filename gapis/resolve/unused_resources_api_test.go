@@ -0,0 +1,73 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve_test
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/device"
+	"github.com/google/gapid/core/os/device/bind"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/gles"
+	"github.com/google/gapid/gapis/api/vulkan"
+	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/database"
+	"github.com/google/gapid/gapis/memory"
+	"github.com/google/gapid/gapis/resolve"
+)
+
+func TestUnusedResourcesUncheckedAPIs(t *testing.T) {
+	ctx := log.Testing(t)
+	ctx = bind.PutRegistry(ctx, bind.NewRegistry())
+	ctx = database.Put(ctx, database.NewInMemory(ctx))
+
+	ctxHandle := memory.BytePtr(1, memory.ApplicationPool)
+	displayHandle := memory.BytePtr(2, memory.ApplicationPool)
+	surfaceHandle := memory.BytePtr(3, memory.ApplicationPool)
+	gl := gles.CommandBuilder{Thread: 0}
+	glCmds := []api.Cmd{
+		gl.EglCreateContext(displayHandle, surfaceHandle, surfaceHandle, memory.Nullptr, ctxHandle),
+		api.WithExtras(
+			gl.EglMakeCurrent(displayHandle, surfaceHandle, surfaceHandle, ctxHandle, 0),
+			gles.NewStaticContextStateForTest(), gles.NewDynamicContextStateForTest(64, 64, false)),
+		gl.GlBindTexture(gles.GLenum_GL_TEXTURE_2D, 1),
+	}
+	vk := vulkan.CommandBuilder{Thread: 1}
+	vkCmds := []api.Cmd{
+		vk.VkDestroyDevice(1, memory.Nullptr),
+	}
+
+	for _, test := range []struct {
+		name     string
+		cmds     []api.Cmd
+		expected []string
+	}{
+		{"GLES", glCmds, []string{}},
+		{"Vulkan", vkCmds, []string{"vulkan"}},
+		{"GLES and Vulkan", append(append([]api.Cmd{}, glCmds...), vkCmds...), []string{"vulkan"}},
+	} {
+		h := &capture.Header{Abi: device.WindowsX86_64}
+		p, err := capture.New(ctx, test.name, h, test.cmds)
+		if !assert.For(ctx, "%v capture", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+		unused, err := resolve.UnusedResources(ctx, p.UnusedResources())
+		if assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded() {
+			assert.For(ctx, "%v", test.name).ThatSlice(unused.UncheckedApis).Equals(test.expected)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/data/id"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/api/testcmd"
+	"github.com/google/gapid/gapis/memory"
+)
+
+type upload struct {
+	testcmd.A
+	name   string
+	extras api.CmdExtras
+	Level  int            `param:"Level"`
+	Data   memory.Pointer `param:"Data"`
+}
+
+func (u *upload) CmdName() string        { return u.name }
+func (u *upload) Extras() *api.CmdExtras { return &u.extras }
+
+func newUpload(name string, level int, addr uint64, reads ...id.ID) *upload {
+	u := &upload{name: name, Level: level, Data: memory.BytePtr(addr, memory.ApplicationPool)}
+	if len(reads) > 0 {
+		o := u.extras.GetOrAppendObservations()
+		for _, r := range reads {
+			o.AddRead(memory.Range{Base: addr, Size: 4}, r)
+		}
+	}
+	return u
+}
+
+func TestUploadKey(t *testing.T) {
+	ctx := log.Testing(t)
+	a, b := id.ID{1}, id.ID{2}
+	original := newUpload("upload", 0, 0x1000, a)
+	for _, test := range []struct {
+		name string
+		cmd  *upload
+		same bool
+	}{
+		{"same upload", newUpload("upload", 0, 0x1000, a), true},
+		{"other pointer", newUpload("upload", 0, 0x2000, a), true},
+		{"other content", newUpload("upload", 0, 0x1000, b), false},
+		{"more content", newUpload("upload", 0, 0x1000, a, b), false},
+		{"other target", newUpload("upload", 1, 0x1000, a), false},
+		{"other command", newUpload("copy", 0, 0x1000, a), false},
+	} {
+		assert.For(ctx, "%v", test.name).That(uploadKey(test.cmd) == uploadKey(original)).Equals(test.same)
+	}
+	assert.For(ctx, "no reads").That(uploadKey(newUpload("upload", 0, 0x1000))).Equals("")
+}
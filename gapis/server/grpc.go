@@ -226,6 +226,24 @@ func (s *grpcServer) GetProfile(ctx xctx.Context, req *service.GetProfileRequest
 	return &service.GetProfileResponse{Res: &service.GetProfileResponse_Data{Data: data}}, nil
 }
 
+func (s *grpcServer) SetDebugConfig(ctx xctx.Context, req *service.SetDebugConfigRequest) (*service.SetDebugConfigResponse, error) {
+	defer s.inRPC()()
+	err := s.handler.SetDebugConfig(s.bindCtx(ctx), req.Config)
+	if err := service.NewError(err); err != nil {
+		return &service.SetDebugConfigResponse{Error: err}, nil
+	}
+	return &service.SetDebugConfigResponse{}, nil
+}
+
+func (s *grpcServer) GetDebugConfig(ctx xctx.Context, req *service.GetDebugConfigRequest) (*service.GetDebugConfigResponse, error) {
+	defer s.inRPC()()
+	c, err := s.handler.GetDebugConfig(s.bindCtx(ctx))
+	if err := service.NewError(err); err != nil {
+		return &service.GetDebugConfigResponse{Res: &service.GetDebugConfigResponse_Error{Error: err}}, nil
+	}
+	return &service.GetDebugConfigResponse{Res: &service.GetDebugConfigResponse_Config{Config: c}}, nil
+}
+
 func (s *grpcServer) GetAvailableStringTables(ctx xctx.Context, req *service.GetAvailableStringTablesRequest) (*service.GetAvailableStringTablesResponse, error) {
 	defer s.inRPC()()
 	tables, err := s.handler.GetAvailableStringTables(s.bindCtx(ctx))
@@ -317,6 +335,23 @@ func (s *grpcServer) GetFramebufferAttachment(ctx xctx.Context, req *service.Get
 	return &service.GetFramebufferAttachmentResponse{Res: &service.GetFramebufferAttachmentResponse_Image{Image: image}}, nil
 }
 
+func (s *grpcServer) CheckReplayDeterminism(ctx xctx.Context, req *service.CheckReplayDeterminismRequest) (*service.CheckReplayDeterminismResponse, error) {
+	defer s.inRPC()()
+	report, err := s.handler.CheckReplayDeterminism(
+		s.bindCtx(ctx),
+		req.ReplaySettings,
+		req.Capture,
+		req.Commands,
+		req.DrawCalls,
+		req.Replays,
+		req.Tolerance,
+	)
+	if err := service.NewError(err); err != nil {
+		return &service.CheckReplayDeterminismResponse{Res: &service.CheckReplayDeterminismResponse_Error{Error: err}}, nil
+	}
+	return &service.CheckReplayDeterminismResponse{Res: &service.CheckReplayDeterminismResponse_Report{Report: report}}, nil
+}
+
 func (s *grpcServer) GetLogStream(req *service.GetLogStreamRequest, server service.Gapid_GetLogStreamServer) error {
 	defer s.inRPC()()
 	ctx := server.Context()
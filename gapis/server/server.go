@@ -36,6 +36,7 @@ import (
 	"github.com/google/gapid/core/os/device/bind"
 	"github.com/google/gapid/gapis/api"
 	"github.com/google/gapid/gapis/capture"
+	"github.com/google/gapid/gapis/config"
 	"github.com/google/gapid/gapis/messages"
 	"github.com/google/gapid/gapis/replay/devices"
 	"github.com/google/gapid/gapis/resolve"
@@ -251,6 +252,31 @@ func (s *server) GetFramebufferAttachment(
 	return resolve.FramebufferAttachment(ctx, replaySettings, after, attachment, settings, hints)
 }
 
+func (s *server) CheckReplayDeterminism(
+	ctx context.Context,
+	replaySettings *service.ReplaySettings,
+	c *path.Capture,
+	cmds []*path.Command,
+	drawCalls bool,
+	replays uint32,
+	tolerance float32,
+) (*service.DeterminismReport, error) {
+
+	ctx = log.Enter(ctx, "CheckReplayDeterminism")
+	if err := replaySettings.Device.Validate(); err != nil {
+		return nil, log.Errf(ctx, err, "Invalid path: %v", replaySettings.Device)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, log.Errf(ctx, err, "Invalid path: %v", c)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Validate(); err != nil {
+			return nil, log.Errf(ctx, err, "Invalid path: %v", cmd)
+		}
+	}
+	return resolve.ReplayDeterminism(ctx, replaySettings, c, cmds, drawCalls, replays, tolerance)
+}
+
 func (s *server) Get(ctx context.Context, p *path.Any) (interface{}, error) {
 	ctx = log.Enter(ctx, "Get")
 	if err := p.Validate(); err != nil {
@@ -330,6 +356,24 @@ func (s *server) GetProfile(ctx context.Context, name string, debug int32) ([]by
 	return b.Bytes(), nil
 }
 
+func (s *server) SetDebugConfig(ctx context.Context, c *service.DebugConfig) error {
+	ctx = log.Enter(ctx, "SetDebugConfig")
+	if c == nil {
+		return log.Err(ctx, nil, "Missing debug config")
+	}
+	global := config.Global()
+	if c.LogTransformsToFile != global.LogTransformsToFile || c.TransformTraceFile != global.TransformTraceFile {
+		return log.Err(ctx, nil, "The options writing files can only be set on the gapis command line")
+	}
+	config.SetGlobal(c.Config(global))
+	return nil
+}
+
+func (s *server) GetDebugConfig(ctx context.Context) (*service.DebugConfig, error) {
+	ctx = log.Enter(ctx, "GetDebugConfig")
+	return service.NewDebugConfig(config.Global()), nil
+}
+
 func (s *server) EnableCrashReporting(ctx context.Context, enable bool) error {
 	if enable {
 		reporting.Enable(ctx, app.Name, app.Version.String())
@@ -59,6 +59,7 @@ func printIndices(index []uint64) string {
 func (n *API) Path() *Any                       { return &Any{&Any_Api{n}} }
 func (n *ArrayIndex) Path() *Any                { return &Any{&Any_ArrayIndex{n}} }
 func (n *As) Path() *Any                        { return &Any{&Any_As{n}} }
+func (n *Bisect) Path() *Any                    { return &Any{&Any_Bisect{n}} }
 func (n *Blob) Path() *Any                      { return &Any{&Any_Blob{n}} }
 func (n *Capture) Path() *Any                   { return &Any{&Any_Capture{n}} }
 func (n *ConstantSet) Path() *Any               { return &Any{&Any_ConstantSet{n}} }
@@ -69,7 +70,11 @@ func (n *CommandTreeNode) Path() *Any           { return &Any{&Any_CommandTreeNo
 func (n *CommandTreeNodeForCommand) Path() *Any { return &Any{&Any_CommandTreeNodeForCommand{n}} }
 func (n *Context) Path() *Any                   { return &Any{&Any_Context{n}} }
 func (n *Contexts) Path() *Any                  { return &Any{&Any_Contexts{n}} }
+func (n *DCEExplanation) Path() *Any            { return &Any{&Any_DceExplanation{n}} }
+func (n *DependencyGraph) Path() *Any           { return &Any{&Any_DependencyGraph{n}} }
+func (n *DescriptorSets) Path() *Any            { return &Any{&Any_DescriptorSets{n}} }
 func (n *Device) Path() *Any                    { return &Any{&Any_Device{n}} }
+func (n *DrawCallState) Path() *Any             { return &Any{&Any_DrawCallState{n}} }
 func (n *Events) Path() *Any                    { return &Any{&Any_Events{n}} }
 func (n *FramebufferObservation) Path() *Any    { return &Any{&Any_Fbo{n}} }
 func (n *Field) Path() *Any                     { return &Any{&Any_Field{n}} }
@@ -77,22 +82,33 @@ func (n *GlobalState) Path() *Any               { return &Any{&Any_GlobalState{n
 func (n *ImageInfo) Path() *Any                 { return &Any{&Any_ImageInfo{n}} }
 func (n *MapIndex) Path() *Any                  { return &Any{&Any_MapIndex{n}} }
 func (n *Memory) Path() *Any                    { return &Any{&Any_Memory{n}} }
+func (n *MemoryUsage) Path() *Any               { return &Any{&Any_MemoryUsage{n}} }
 func (n *Mesh) Path() *Any                      { return &Any{&Any_Mesh{n}} }
 func (n *Parameter) Path() *Any                 { return &Any{&Any_Parameter{n}} }
+func (n *RedundantStateChanges) Path() *Any     { return &Any{&Any_RedundantStateChanges{n}} }
+func (n *RenderPassInstances) Path() *Any       { return &Any{&Any_RenderPassInstances{n}} }
 func (n *Report) Path() *Any                    { return &Any{&Any_Report{n}} }
 func (n *ResourceData) Path() *Any              { return &Any{&Any_ResourceData{n}} }
+func (n *ResourceLifetimes) Path() *Any         { return &Any{&Any_ResourceLifetimes{n}} }
 func (n *Resources) Path() *Any                 { return &Any{&Any_Resources{n}} }
 func (n *Result) Path() *Any                    { return &Any{&Any_Result{n}} }
+func (n *ShaderCheck) Path() *Any               { return &Any{&Any_ShaderCheck{n}} }
+func (n *ShaderComplexities) Path() *Any        { return &Any{&Any_ShaderComplexities{n}} }
 func (n *Slice) Path() *Any                     { return &Any{&Any_Slice{n}} }
 func (n *State) Path() *Any                     { return &Any{&Any_State{n}} }
 func (n *StateTree) Path() *Any                 { return &Any{&Any_StateTree{n}} }
 func (n *StateTreeNode) Path() *Any             { return &Any{&Any_StateTreeNode{n}} }
 func (n *StateTreeNodeForPath) Path() *Any      { return &Any{&Any_StateTreeNodeForPath{n}} }
+func (n *Stats) Path() *Any                     { return &Any{&Any_Stats{n}} }
+func (n *SyncGraph) Path() *Any                 { return &Any{&Any_SyncGraph{n}} }
 func (n *Thumbnail) Path() *Any                 { return &Any{&Any_Thumbnail{n}} }
+func (n *Timeline) Path() *Any                  { return &Any{&Any_Timeline{n}} }
+func (n *UnusedResources) Path() *Any           { return &Any{&Any_UnusedResources{n}} }
 
 func (n API) Parent() Node                       { return nil }
 func (n ArrayIndex) Parent() Node                { return oneOfNode(n.Array) }
 func (n As) Parent() Node                        { return oneOfNode(n.From) }
+func (n Bisect) Parent() Node                    { return n.Command }
 func (n Blob) Parent() Node                      { return nil }
 func (n Capture) Parent() Node                   { return nil }
 func (n ConstantSet) Parent() Node               { return n.Api }
@@ -103,7 +119,11 @@ func (n CommandTreeNode) Parent() Node           { return nil }
 func (n CommandTreeNodeForCommand) Parent() Node { return n.Command }
 func (n Context) Parent() Node                   { return n.Capture }
 func (n Contexts) Parent() Node                  { return n.Capture }
+func (n DCEExplanation) Parent() Node            { return n.Command }
+func (n DependencyGraph) Parent() Node           { return n.Capture }
+func (n DescriptorSets) Parent() Node            { return n.Command }
 func (n Device) Parent() Node                    { return nil }
+func (n DrawCallState) Parent() Node             { return n.Command }
 func (n Events) Parent() Node                    { return n.Capture }
 func (n FramebufferObservation) Parent() Node    { return n.Command }
 func (n Field) Parent() Node                     { return oneOfNode(n.Struct) }
@@ -111,20 +131,31 @@ func (n GlobalState) Parent() Node               { return n.After }
 func (n ImageInfo) Parent() Node                 { return nil }
 func (n MapIndex) Parent() Node                  { return oneOfNode(n.Map) }
 func (n Memory) Parent() Node                    { return n.After }
+func (n MemoryUsage) Parent() Node               { return n.Capture }
 func (n Mesh) Parent() Node                      { return oneOfNode(n.Object) }
 func (n Parameter) Parent() Node                 { return n.Command }
+func (n RedundantStateChanges) Parent() Node     { return n.Capture }
+func (n RenderPassInstances) Parent() Node       { return n.Capture }
 func (n Report) Parent() Node                    { return n.Capture }
 func (n ResourceData) Parent() Node              { return n.After }
+func (n ResourceLifetimes) Parent() Node         { return n.Capture }
 func (n Resources) Parent() Node                 { return n.Capture }
 func (n Result) Parent() Node                    { return n.Command }
+func (n ShaderCheck) Parent() Node               { return n.Capture }
+func (n ShaderComplexities) Parent() Node        { return n.Capture }
 func (n Slice) Parent() Node                     { return oneOfNode(n.Array) }
 func (n State) Parent() Node                     { return n.After }
 func (n StateTree) Parent() Node                 { return n.State }
 func (n StateTreeNode) Parent() Node             { return nil }
 func (n StateTreeNodeForPath) Parent() Node      { return nil }
+func (n Stats) Parent() Node                     { return n.Capture }
+func (n SyncGraph) Parent() Node                 { return n.Capture }
 func (n Thumbnail) Parent() Node                 { return oneOfNode(n.Object) }
+func (n Timeline) Parent() Node                  { return n.Capture }
+func (n UnusedResources) Parent() Node           { return n.Capture }
 
 func (n *API) SetParent(p Node)                       {}
+func (n *Bisect) SetParent(p Node)                    { n.Command, _ = p.(*Command) }
 func (n *Blob) SetParent(p Node)                      {}
 func (n *Capture) SetParent(p Node)                   {}
 func (n *ConstantSet) SetParent(p Node)               { n.Api, _ = p.(*API) }
@@ -135,21 +166,35 @@ func (n *CommandTreeNode) SetParent(p Node)           {}
 func (n *CommandTreeNodeForCommand) SetParent(p Node) { n.Command, _ = p.(*Command) }
 func (n *Context) SetParent(p Node)                   { n.Capture, _ = p.(*Capture) }
 func (n *Contexts) SetParent(p Node)                  { n.Capture, _ = p.(*Capture) }
+func (n *DCEExplanation) SetParent(p Node)            { n.Command, _ = p.(*Command) }
+func (n *DependencyGraph) SetParent(p Node)           { n.Capture, _ = p.(*Capture) }
+func (n *DescriptorSets) SetParent(p Node)            { n.Command, _ = p.(*Command) }
 func (n *Device) SetParent(p Node)                    {}
+func (n *DrawCallState) SetParent(p Node)             { n.Command, _ = p.(*Command) }
 func (n *Events) SetParent(p Node)                    { n.Capture, _ = p.(*Capture) }
 func (n *FramebufferObservation) SetParent(p Node)    { n.Command, _ = p.(*Command) }
 func (n *GlobalState) SetParent(p Node)               { n.After, _ = p.(*Command) }
 func (n *ImageInfo) SetParent(p Node)                 {}
 func (n *Memory) SetParent(p Node)                    { n.After, _ = p.(*Command) }
+func (n *MemoryUsage) SetParent(p Node)               { n.Capture, _ = p.(*Capture) }
 func (n *Parameter) SetParent(p Node)                 { n.Command, _ = p.(*Command) }
+func (n *RedundantStateChanges) SetParent(p Node)     { n.Capture, _ = p.(*Capture) }
+func (n *RenderPassInstances) SetParent(p Node)       { n.Capture, _ = p.(*Capture) }
 func (n *Report) SetParent(p Node)                    { n.Capture, _ = p.(*Capture) }
 func (n *ResourceData) SetParent(p Node)              { n.After, _ = p.(*Command) }
+func (n *ResourceLifetimes) SetParent(p Node)         { n.Capture, _ = p.(*Capture) }
 func (n *Resources) SetParent(p Node)                 { n.Capture, _ = p.(*Capture) }
 func (n *Result) SetParent(p Node)                    { n.Command, _ = p.(*Command) }
+func (n *ShaderCheck) SetParent(p Node)               { n.Capture, _ = p.(*Capture) }
+func (n *ShaderComplexities) SetParent(p Node)        { n.Capture, _ = p.(*Capture) }
 func (n *State) SetParent(p Node)                     { n.After, _ = p.(*Command) }
 func (n *StateTree) SetParent(p Node)                 { n.State, _ = p.(*State) }
 func (n *StateTreeNode) SetParent(p Node)             {}
 func (n *StateTreeNodeForPath) SetParent(p Node)      {}
+func (n *Stats) SetParent(p Node)                     { n.Capture, _ = p.(*Capture) }
+func (n *SyncGraph) SetParent(p Node)                 { n.Capture, _ = p.(*Capture) }
+func (n *Timeline) SetParent(p Node)                  { n.Capture, _ = p.(*Capture) }
+func (n *UnusedResources) SetParent(p Node)           { n.Capture, _ = p.(*Capture) }
 
 // Format implements fmt.Formatter to print the version.
 func (n ArrayIndex) Format(f fmt.State, c rune) {
@@ -159,6 +204,11 @@ func (n ArrayIndex) Format(f fmt.State, c rune) {
 // Format implements fmt.Formatter to print the version.
 func (n API) Format(f fmt.State, c rune) { fmt.Fprintf(f, "api<%v>", n.Id) }
 
+// Format implements fmt.Formatter to print the version.
+func (n Bisect) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.bisect<device: %v, reference: %v>", n.Parent(), n.Device, n.ReferenceDevice)
+}
+
 // Format implements fmt.Formatter to print the version.
 func (n As) Format(f fmt.State, c rune) {
 	fmt.Fprintf(f, "%v.as<%v>", n.Parent(), protoutil.OneOf(n.To))
@@ -204,9 +254,25 @@ func (n Context) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.[%x]", n.Paren
 // Format implements fmt.Formatter to print the version.
 func (n Contexts) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.contexts", n.Parent()) }
 
+// Format implements fmt.Formatter to print the version.
+func (n DCEExplanation) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.dce-explanation<target: %v>", n.Parent(), n.Target)
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n DependencyGraph) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.dependency-graph[%v-%v]<footprint: %v>", n.Parent(), n.From, n.To, n.Footprint)
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n DescriptorSets) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.descriptor-sets", n.Parent()) }
+
 // Format implements fmt.Formatter to print the version.
 func (n Device) Format(f fmt.State, c rune) { fmt.Fprintf(f, "device<%x>", n.Id) }
 
+// Format implements fmt.Formatter to print the version.
+func (n DrawCallState) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.draw-call-state", n.Parent()) }
+
 // Format implements fmt.Formatter to print the version.
 func (n Events) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.events", n.Parent()) }
 
@@ -225,6 +291,11 @@ func (n MapIndex) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v[%x]", n.Paren
 // Format implements fmt.Formatter to print the version.
 func (n Memory) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.memory-after", n.Parent()) }
 
+// Format implements fmt.Formatter to print the version.
+func (n MemoryUsage) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.memory-usage<top: %v>", n.Parent(), n.Top)
+}
+
 // Format implements fmt.Formatter to print the version.
 func (n Mesh) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.mesh", n.Parent()) }
 
@@ -232,7 +303,33 @@ func (n Mesh) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.mesh", n.Parent()
 func (n Parameter) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.%v", n.Parent(), n.Name) }
 
 // Format implements fmt.Formatter to print the version.
-func (n Report) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.report", n.Parent()) }
+func (n RedundantStateChanges) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.redundant-state-changes", n.Parent())
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n RenderPassInstances) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.render-pass-instances", n.Parent())
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n ResourceLifetimes) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.resource-lifetimes", n.Parent())
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n Report) Format(f fmt.State, c rune) {
+	switch {
+	case n.Performance:
+		fmt.Fprintf(f, "%v.report<performance>", n.Parent())
+	case n.UnusedResources:
+		fmt.Fprintf(f, "%v.report<unused-resources>", n.Parent())
+	case n.ShaderCheck:
+		fmt.Fprintf(f, "%v.report<shader-check>", n.Parent())
+	default:
+		fmt.Fprintf(f, "%v.report", n.Parent())
+	}
+}
 
 // Format implements fmt.Formatter to print the version.
 func (n ResourceData) Format(f fmt.State, c rune) {
@@ -268,9 +365,31 @@ func (n StateTreeNodeForPath) Format(f fmt.State, c rune) {
 	fmt.Fprintf(f, "state-tree-for<%v, %v>", n.Tree, n.Member)
 }
 
+// Format implements fmt.Formatter to print the version.
+func (n ShaderCheck) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.shader-check", n.Parent()) }
+
+// Format implements fmt.Formatter to print the version.
+func (n ShaderComplexities) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.shader-complexities", n.Parent())
+}
+
+// Format implements fmt.Formatter to print the version.
+func (n Stats) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.stats", n.Parent()) }
+
+// Format implements fmt.Formatter to print the version.
+func (n SyncGraph) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.sync-graph", n.Parent()) }
+
 // Format implements fmt.Formatter to print the version.
 func (n Thumbnail) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.thumbnail", n.Parent()) }
 
+// Format implements fmt.Formatter to print the version.
+func (n Timeline) Format(f fmt.State, c rune) { fmt.Fprintf(f, "%v.timeline", n.Parent()) }
+
+// Format implements fmt.Formatter to print the version.
+func (n UnusedResources) Format(f fmt.State, c rune) {
+	fmt.Fprintf(f, "%v.unused-resources", n.Parent())
+}
+
 func (n *As) SetParent(p Node) {
 	switch p := p.(type) {
 	case nil:
@@ -511,11 +630,75 @@ func (n *Capture) Resources() *Resources {
 	return &Resources{Capture: n}
 }
 
+// MemoryUsage returns the path node to the capture's estimated memory usage,
+// listing the top largest objects of each frame.
+func (n *Capture) MemoryUsage(top uint32) *MemoryUsage {
+	return &MemoryUsage{Capture: n, Top: top}
+}
+
+// ShaderCheck returns the path node to the offline compilation of the
+// capture's shaders, against the profile of the optional device.
+func (n *Capture) ShaderCheck(d *Device) *ShaderCheck {
+	return &ShaderCheck{Capture: n, Device: d}
+}
+
+// ShaderComplexities returns the path node to the instruction counts of the
+// capture's shaders.
+func (n *Capture) ShaderComplexities() *ShaderComplexities {
+	return &ShaderComplexities{Capture: n}
+}
+
+// Stats returns the path node to the capture's statistics.
+func (n *Capture) Stats() *Stats {
+	return &Stats{Capture: n}
+}
+
+// SyncGraph returns the path node to the capture's queue submission and
+// synchronization graph.
+func (n *Capture) SyncGraph() *SyncGraph {
+	return &SyncGraph{Capture: n}
+}
+
+// Timeline returns the path node to the capture's thread and context timeline.
+func (n *Capture) Timeline() *Timeline {
+	return &Timeline{Capture: n}
+}
+
+// UnusedResources returns the path node to the capture's unused resources.
+func (n *Capture) UnusedResources() *UnusedResources {
+	return &UnusedResources{Capture: n}
+}
+
+// ResourceLifetimes returns the path node to the lifetimes of the capture's
+// resources.
+func (n *Capture) ResourceLifetimes() *ResourceLifetimes {
+	return &ResourceLifetimes{Capture: n}
+}
+
 // Report returns the path node to the capture's report.
 func (n *Capture) Report(d *Device, f *CommandFilter) *Report {
 	return &Report{Capture: n, Device: d, Filter: f}
 }
 
+// RedundantStateChanges returns the path node to the capture's redundant
+// state-setting commands.
+func (n *Capture) RedundantStateChanges() *RedundantStateChanges {
+	return &RedundantStateChanges{Capture: n}
+}
+
+// RenderPassInstances returns the path node to the capture's render pass
+// instances.
+func (n *Capture) RenderPassInstances() *RenderPassInstances {
+	return &RenderPassInstances{Capture: n}
+}
+
+// DependencyGraph returns the path node to the graph of the commands in the
+// range [from, to], linked by the state they read and write. If footprint is
+// true then the execution footprint is used instead of the dependency graph.
+func (n *Capture) DependencyGraph(from, to uint64, footprint bool) *DependencyGraph {
+	return &DependencyGraph{Capture: n, From: from, To: to, Footprint: footprint}
+}
+
 // Contexts returns the path node to the capture's contexts.
 func (n *Capture) Contexts() *Contexts {
 	return &Contexts{Capture: n}
@@ -592,6 +775,21 @@ func (n *Command) ResourceAfter(id *ID) *ResourceData {
 	}
 }
 
+// Bisect returns the path node to the result of bisecting the draw calls of the
+// frame ending at the command, replaying on d and comparing against ref, or
+// the command's framebuffer observation if ref is nil.
+func (n *Command) Bisect(d, ref *Device, threshold float32) *Bisect {
+	return &Bisect{Command: n, Device: d, ReferenceDevice: ref, Threshold: threshold}
+}
+
+// DCEExplanation returns the path node to the explanation of why each of the
+// commands is kept alive by the dead code elimination when replaying up to
+// the command. If target is not nil then only the commands linking target to
+// the command are explained.
+func (n *Command) DCEExplanation(target *Command) *DCEExplanation {
+	return &DCEExplanation{Command: n, Target: target}
+}
+
 // FramebufferObservation returns the path node to framebuffer observation
 // after this command.
 func (n *Command) FramebufferObservation() *FramebufferObservation {
@@ -606,6 +804,18 @@ func (n *Command) Mesh(faceted bool) *Mesh {
 	}
 }
 
+// DrawCallState returns the path node to the pipeline state used by the draw
+// call of this command.
+func (n *Command) DrawCallState() *DrawCallState {
+	return &DrawCallState{Command: n}
+}
+
+// DescriptorSets returns the path node to the descriptor sets bound for the
+// draw or dispatch of this command.
+func (n *Command) DescriptorSets() *DescriptorSets {
+	return &DescriptorSets{Command: n}
+}
+
 // GlobalStateAfter returns the path node to the state after this command.
 func (n *Command) GlobalStateAfter() *GlobalState {
 	return &GlobalState{After: n}
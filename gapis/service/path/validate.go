@@ -107,6 +107,14 @@ func (n *As) Validate() error {
 	)
 }
 
+// Validate checks the path is valid.
+func (n *Bisect) Validate() error {
+	return anyErr(
+		checkNotNilAndValidate(n, n.Command, "command"),
+		checkNotNilAndValidate(n, n.Device, "device"),
+	)
+}
+
 // Validate checks the path is valid.
 func (n *Blob) Validate() error {
 	return checkIsValid(n, n.Id, "id")
@@ -172,6 +180,29 @@ func (n *Contexts) Validate() error {
 	return checkNotNilAndValidate(n, n.Capture, "capture")
 }
 
+// Validate checks the path is valid.
+func (n *DCEExplanation) Validate() error {
+	if n.Target != nil {
+		if err := n.Target.Validate(); err != nil {
+			return err
+		}
+	}
+	return checkNotNilAndValidate(n, n.Command, "command")
+}
+
+// Validate checks the path is valid.
+func (n *DependencyGraph) Validate() error {
+	if n.From > n.To {
+		return fmt.Errorf("Invalid path '%v': from must not be greater than to", n)
+	}
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *DescriptorSets) Validate() error {
+	return checkNotNilAndValidate(n, n.Command, "command")
+}
+
 // Validate checks the path is valid.
 func (n *Device) Validate() error {
 	return checkIsValid(n, n.Id, "id")
@@ -182,6 +213,11 @@ func (n *Events) Validate() error {
 	return checkNotNilAndValidate(n, protoutil.OneOf(n.Capture), "capture")
 }
 
+// Validate checks the path is valid.
+func (n *DrawCallState) Validate() error {
+	return checkNotNilAndValidate(n, n.Command, "command")
+}
+
 // Validate checks the path is valid.
 func (n *FramebufferObservation) Validate() error {
 	return checkNotNilAndValidate(n, protoutil.OneOf(n.Command), "command")
@@ -195,6 +231,31 @@ func (n *Field) Validate() error {
 	)
 }
 
+// Validate checks the path is valid.
+func (n *RedundantStateChanges) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *RenderPassInstances) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *MemoryUsage) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *UnusedResources) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *ResourceLifetimes) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
 // Validate checks the path is valid.
 func (n *GlobalState) Validate() error {
 	return checkNotNilAndValidate(n, n.After, "after")
@@ -282,6 +343,31 @@ func (n *StateTreeNodeForPath) Validate() error {
 	)
 }
 
+// Validate checks the path is valid.
+func (n *Timeline) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *ShaderCheck) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *ShaderComplexities) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *Stats) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
+// Validate checks the path is valid.
+func (n *SyncGraph) Validate() error {
+	return checkNotNilAndValidate(n, n.Capture, "capture")
+}
+
 // Validate checks the path is valid.
 func (n *Thumbnail) Validate() error {
 	return checkNotNilAndValidate(n, protoutil.OneOf(n.Object), "object")
@@ -24,6 +24,7 @@ import (
 	"github.com/google/gapid/core/log"
 	"github.com/google/gapid/core/os/device"
 	"github.com/google/gapid/gapis/api"
+	"github.com/google/gapid/gapis/config"
 	"github.com/google/gapid/gapis/memory"
 	"github.com/google/gapid/gapis/service/box"
 	"github.com/google/gapid/gapis/service/path"
@@ -90,6 +91,20 @@ type Service interface {
 		settings *RenderSettings,
 		hints *UsageHints) (*path.ImageInfo, error)
 
+	// CheckReplayDeterminism replays the color attachment after each of the
+	// commands replays times on the same device, comparing the postbacks of
+	// each replay. If cmds is empty then the last command of each frame is
+	// checked, along with every draw call if drawCalls is true.
+	// The replays bypass the server's cache.
+	CheckReplayDeterminism(
+		ctx context.Context,
+		replaySettings *ReplaySettings,
+		c *path.Capture,
+		cmds []*path.Command,
+		drawCalls bool,
+		replays uint32,
+		tolerance float32) (*DeterminismReport, error)
+
 	// Get resolves and returns the object, value or memory at the path p.
 	Get(ctx context.Context, p *path.Any) (interface{}, error)
 
@@ -119,6 +134,14 @@ type Service interface {
 	// GetProfile returns the pprof profile with the given name.
 	GetProfile(ctx context.Context, name string, debug int32) ([]byte, error)
 
+	// SetDebugConfig replaces the debug configuration of the server.
+	// This is a debug API, and may be removed in the future.
+	SetDebugConfig(ctx context.Context, c *DebugConfig) error
+
+	// GetDebugConfig returns the debug configuration of the server.
+	// This is a debug API, and may be removed in the future.
+	GetDebugConfig(ctx context.Context) (*DebugConfig, error)
+
 	// GetLogStream calls the handler with each log record raised until the
 	// context is cancelled.
 	GetLogStream(context.Context, log.Handler) error
@@ -182,8 +205,26 @@ func NewValue(v interface{}) *Value {
 	switch v := v.(type) {
 	case nil:
 		return &Value{}
+	case *BisectResult:
+		return &Value{&Value_BisectResult{v}}
 	case *Capture:
 		return &Value{&Value_Capture{v}}
+	case *DCEExplanation:
+		return &Value{&Value_DceExplanation{v}}
+	case *DependencyGraph:
+		return &Value{&Value_DependencyGraph{v}}
+	case *RedundantStateChanges:
+		return &Value{&Value_RedundantStateChanges{v}}
+	case *ResourceLifetimes:
+		return &Value{&Value_ResourceLifetimes{v}}
+	case *MemoryUsage:
+		return &Value{&Value_MemoryUsage{v}}
+	case *UnusedResources:
+		return &Value{&Value_UnusedResources{v}}
+	case *Timeline:
+		return &Value{&Value_Timeline{v}}
+	case *Stats:
+		return &Value{&Value_Stats{v}}
 	case *Context:
 		return &Value{&Value_Context{v}}
 	case *Contexts:
@@ -216,10 +257,22 @@ func NewValue(v interface{}) *Value {
 		return &Value{&Value_StateTreeNode{v}}
 	case *api.Command:
 		return &Value{&Value_Command{v}}
+	case *api.DescriptorSets:
+		return &Value{&Value_DescriptorSets{v}}
+	case *api.DrawCallState:
+		return &Value{&Value_DrawCallState{v}}
 	case *api.Mesh:
 		return &Value{&Value_Mesh{v}}
+	case *api.RenderPassInstances:
+		return &Value{&Value_RenderPassInstances{v}}
 	case *api.ResourceData:
 		return &Value{&Value_ResourceData{v}}
+	case *api.ShaderCheck:
+		return &Value{&Value_ShaderCheck{v}}
+	case *api.ShaderComplexities:
+		return &Value{&Value_ShaderComplexities{v}}
+	case *api.SyncGraph:
+		return &Value{&Value_SyncGraph{v}}
 	case *image.Info:
 		return &Value{&Value_ImageInfo{v}}
 	case *device.Instance:
@@ -245,6 +298,58 @@ func (v *Value) Get() interface{} {
 	}
 }
 
+// NewDebugConfig returns a new DebugConfig from the debug configuration d.
+func NewDebugConfig(d config.Debug) *DebugConfig {
+	return &DebugConfig{
+		DebugReplay:                d.DebugReplay,
+		DebugReplayBuilder:         d.DebugReplayBuilder,
+		DisableDeadCodeElimination: d.DisableDeadCodeElimination,
+		DebugDeadCodeElimination:   d.DebugDeadCodeElimination,
+		LogExtrasInTransforms:      d.LogExtrasInTransforms,
+		LogMemoryInExtras:          d.LogMemoryInExtras,
+		LogTransformsToFile:        d.LogTransformsToFile,
+		SeparateMutateStates:       d.SeparateMutateStates,
+		ProfileTransforms:          d.ProfileTransforms,
+		TransformTraceFile:         d.TransformTraceFile,
+	}
+}
+
+// Config returns the debug configuration described by c.
+// The options writing files on the server host can only be set on the gapis
+// command line, so they are taken from local rather than from c.
+func (c *DebugConfig) Config(local config.Debug) config.Debug {
+	return config.Debug{
+		DebugReplay:                c.DebugReplay,
+		DebugReplayBuilder:         c.DebugReplayBuilder,
+		DisableDeadCodeElimination: c.DisableDeadCodeElimination,
+		DebugDeadCodeElimination:   c.DebugDeadCodeElimination,
+		LogExtrasInTransforms:      c.LogExtrasInTransforms,
+		LogMemoryInExtras:          c.LogMemoryInExtras,
+		LogTransformsToFile:        local.LogTransformsToFile,
+		SeparateMutateStates:       c.SeparateMutateStates,
+		ProfileTransforms:          c.ProfileTransforms,
+		TransformTraceFile:         local.TransformTraceFile,
+	}
+}
+
+// Put attaches the debug configuration c to the returned context. If c is nil
+// then ctx is returned.
+func (c *DebugConfig) Put(ctx context.Context) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return config.Put(ctx, c.Config(config.Get(ctx)))
+}
+
+// Put attaches the debug configuration of the replay settings to the returned
+// context. If s does not specify a debug configuration then ctx is returned.
+func (s *ReplaySettings) Put(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return s.DebugConfig.Put(ctx)
+}
+
 // NewMemoryRange constructs and returns a new MemoryRange from the
 // memory.Range.
 func NewMemoryRange(rng memory.Range) *MemoryRange {
@@ -292,7 +292,12 @@ code_with_debug_info_t* convertGlsl(const char* input, size_t length, const conv
     strcpy(result->disassembly_string, tmp.c_str());
   }
 
-  std::string source = spirv2glsl(std::move(spirv_new), options->strip_optimizations);
+  int target_version = options->target_glsl_version;
+  if (target_version == 0) {
+    target_version = 330;
+  }
+  std::string source = spirv2glsl(std::move(spirv_new), options->strip_optimizations,
+                                  target_version, options->target_es);
 
   result->source_code = new char[source.length() + 1];
   strcpy(result->source_code, source.c_str());
@@ -300,7 +305,8 @@ code_with_debug_info_t* convertGlsl(const char* input, size_t length, const conv
 
   // check if changed source code compiles again
   if (options->check_after_changes) {
-    parseGlslang(result->source_code, nullptr, &err_msg, options->shader_type, OPENGL, false);
+    parseGlslang(result->source_code, nullptr, &err_msg, options->shader_type,
+                 options->target_es ? OPENGLES : OPENGL, false);
   }
 
   if (!err_msg.empty()) {
@@ -365,6 +371,51 @@ void deleteDisassembleText(const char* text) {
     delete[] text;
 }
 
+/**
+ * Returns the SPIRV-Tools environment of the Vulkan vulkan_major.vulkan_minor
+ * version. Versions newer than Vulkan 1.1 are mapped to Vulkan 1.1, the latest
+ * environment known by the bundled SPIRV-Tools.
+ **/
+spv_target_env vulkan_env(uint32_t vulkan_major, uint32_t vulkan_minor) {
+  if (vulkan_major == 1 && vulkan_minor == 0) {
+    return SPV_ENV_VULKAN_1_0;
+  }
+  return SPV_ENV_VULKAN_1_1;
+}
+
+/**
+ * Returns pointer to the messages of the validation of the SPIR-V binary for
+ * the Vulkan vulkan_major.vulkan_minor environment, or nullptr if the binary
+ * is valid.
+ **/
+const char* validateSpirv(uint32_t* spirv_binary, size_t length,
+                          uint32_t vulkan_major, uint32_t vulkan_minor) {
+  std::vector<uint32_t> spirv_vec(spirv_binary, spirv_binary + length);
+
+  spvtools::SpirvTools tools(vulkan_env(vulkan_major, vulkan_minor));
+  std::string messages;
+  tools.SetMessageConsumer([&messages](spv_message_level_t, const char*,
+                                       const spv_position_t& position, const char* message) {
+    messages += "At word " + std::to_string(position.index) + ": " + message + "\n";
+  });
+
+  if (tools.Validate(spirv_vec)) {
+    return nullptr;
+  }
+  if (messages.empty()) {
+    messages = "Validation failed.";
+  }
+
+  char* chars = new char[messages.size() + 1];
+  strcpy(chars, messages.c_str());
+  return chars;
+}
+
+void deleteValidationText(const char* text) {
+  if (text)
+    delete[] text;
+}
+
 spirv_binary_t* assembleToBinary(const char* text) {
   if (!text) {
     return nullptr;
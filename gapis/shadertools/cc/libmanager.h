@@ -76,6 +76,8 @@ typedef struct convert_options_t {
   bool disassemble;
   bool relaxed;
   bool strip_optimizations;
+  int target_glsl_version; /* optional, 330 if 0 */
+  bool target_es;
 } convert_options_t;
 
 typedef struct compile_options_t {
@@ -103,6 +105,10 @@ const char* getDisassembleText(uint32_t*, size_t);
 
 void deleteDisassembleText(const char*);
 
+const char* validateSpirv(uint32_t*, size_t, uint32_t, uint32_t);
+
+void deleteValidationText(const char*);
+
 spirv_binary_t* assembleToBinary(const char*);
 
 void deleteBinary(spirv_binary_t*);
@@ -21,11 +21,12 @@
 // so it is important we never include both at the same time.
 #include "third_party/SPIRV-Cross/spirv_glsl.hpp"
 
-std::string spirv2glsl(std::vector<uint32_t> spirv, bool strip_optimizations) {
+std::string spirv2glsl(std::vector<uint32_t> spirv, bool strip_optimizations,
+                       int version, bool es) {
   spirv_cross::CompilerGLSL glsl(std::move(spirv));
   spirv_cross::CompilerGLSL::Options cross_options;
-  cross_options.version = 330;
-  cross_options.es = false;
+  cross_options.version = version;
+  cross_options.es = es;
   cross_options.force_temporary = false;
   cross_options.vertex.fixup_clipspace = false;
   glsl.set_common_options(cross_options);
@@ -17,4 +17,5 @@
 #include <string>
 #include <vector>
 
-std::string spirv2glsl(std::vector<uint32_t> spirv, bool strip_optimizations);
+std::string spirv2glsl(std::vector<uint32_t> spirv, bool strip_optimizations,
+                       int version, bool es);
@@ -140,6 +140,10 @@ type ConvertOptions struct {
 	// result of the shader, but may impact performance.
 	// Example: Early Fragment Test.
 	StripOptimizations bool
+	// The GLSL version of the generated code, 330 if 0.
+	TargetGLSLVersion int
+	// Whether the generated code is GLSL ES.
+	TargetES bool
 }
 
 // ConvertGlsl modifies the given GLSL according to the options specified via
@@ -175,6 +179,8 @@ func ConvertGlsl(source string, o *ConvertOptions) (CodeWithDebugInfo, error) {
 		disassemble:            C.bool(o.Disassemble),
 		relaxed:                C.bool(o.Relaxed),
 		strip_optimizations:    C.bool(o.StripOptimizations),
+		target_glsl_version:    C.int(o.TargetGLSLVersion),
+		target_es:              C.bool(o.TargetES),
 	}
 	result := C.convertGlsl(cstr(source), C.size_t(len(source)), &opts)
 	defer C.deleteGlslCodeWithDebug(result)
@@ -231,6 +237,21 @@ func DisassembleSpirvBinary(words []uint32) string {
 	return source
 }
 
+// ValidateSpirvBinary validates the given SPIR-V binary words for the Vulkan
+// vulkanMajor.vulkanMinor environment by calling SPIRV-Tools and returns the
+// validation messages. Versions newer than Vulkan 1.1 are validated as Vulkan
+// 1.1, the latest version known by SPIRV-Tools. Returns an empty string if the
+// binary is valid.
+func ValidateSpirvBinary(words []uint32, vulkanMajor, vulkanMinor uint32) string {
+	if len(words) == 0 {
+		return "The binary is empty."
+	}
+	messages := C.validateSpirv((*C.uint32_t)(&words[0]), C.size_t(len(words)),
+		C.uint32_t(vulkanMajor), C.uint32_t(vulkanMinor))
+	defer C.deleteValidationText(messages)
+	return C.GoString(messages)
+}
+
 // AssembleSpirvText assembles the given SPIR-V text chars by calling
 // SPIRV-Tools and returns the slice for the encoded binary. Returns nil
 // if assembling fails.
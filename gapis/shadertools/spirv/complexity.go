@@ -0,0 +1,117 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+// Complexity holds the instruction counts and the resource usage of an entry
+// point, estimating the cost of running it.
+type Complexity struct {
+	// ALU is the number of arithmetic, conversion, composite, relational,
+	// bitwise, derivative and extended instructions.
+	ALU uint32
+	// Texture is the number of image sampling, fetching, gathering, reading
+	// and writing instructions.
+	Texture uint32
+	// ControlFlow is the number of branching, switching, killing, returning
+	// and calling instructions.
+	ControlFlow uint32
+	// Other is the number of the other instructions, such as memory accesses.
+	Other uint32
+	// Loops is the number of loops.
+	Loops uint32
+	// Samplers is the number of sampler and sampled image descriptors
+	// statically used.
+	Samplers uint32
+	// Interpolants is the number of inputs of fragment shaders, or outputs of
+	// the other graphics stages, that are not built-ins.
+	Interpolants uint32
+}
+
+// Total returns the number of instructions counted by the complexity.
+func (c *Complexity) Total() uint32 {
+	return c.ALU + c.Texture + c.ControlFlow + c.Other
+}
+
+// Complexity returns the complexity of the entry point with the name and
+// execution model. Only the instructions of the functions called by the entry
+// point, directly or not, are counted.
+func (m *Module) Complexity(name string, model ExecutionModel) (*Complexity, error) {
+	r, err := m.Reflect(name, model)
+	if err != nil {
+		return nil, err
+	}
+	accessed := m.accessed(r.EntryPoint.Function)
+
+	out := &Complexity{}
+	current := ID(0)
+	for _, inst := range m.Instructions {
+		switch inst.Opcode {
+		case OpFunction:
+			current = inst.Result()
+			continue
+		case OpFunctionEnd:
+			current = 0
+			continue
+		}
+		if current == 0 || !accessed[current] {
+			continue
+		}
+		switch op := inst.Opcode; {
+		case op == OpFunctionParameter, op == OpLabel, op == OpSelectionMerge,
+			op == OpLine, op == OpNoLine, op == OpNop:
+		case op == OpLoopMerge:
+			out.Loops++
+		case op >= OpImageSampleImplicitLod && op <= OpImageWrite,
+			op >= OpImageSparseSampleImplicitLod && op <= OpImageSparseDrefGather,
+			op == OpImageSparseRead:
+			out.Texture++
+		case op == OpExtInst,
+			op >= OpVectorExtractDynamic && op <= OpTranspose,
+			op >= OpConvertFToU && op <= OpFwidthCoarse:
+			out.ALU++
+		case op >= OpBranch && op <= OpUnreachable, op == OpFunctionCall:
+			out.ControlFlow++
+		default:
+			out.Other++
+		}
+	}
+
+	for _, b := range r.DescriptorBindings {
+		if !b.Accessed {
+			continue
+		}
+		switch b.DescriptorType {
+		case DescriptorSampler, DescriptorCombinedImageSampler, DescriptorSampledImage:
+			if b.Count == 0 {
+				out.Samplers++
+			} else {
+				out.Samplers += b.Count
+			}
+		}
+	}
+
+	interpolants := r.Outputs
+	switch model {
+	case ExecutionModelFragment:
+		interpolants = r.Inputs
+	case ExecutionModelGLCompute:
+		interpolants = nil
+	}
+	for _, v := range interpolants {
+		if !v.IsBuiltIn {
+			out.Interpolants++
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,368 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// decodedOperand is an operand of an instruction with its words.
+type decodedOperand struct {
+	kind  operandKind
+	enum  *enum
+	words []uint32
+}
+
+// ids returns the ids referenced by the operand.
+func (o decodedOperand) ids() []ID {
+	switch o.kind {
+	case kindResultType, kindResult, kindID:
+		return []ID{ID(o.words[0])}
+	case kindIDID:
+		return []ID{ID(o.words[0]), ID(o.words[1])}
+	case kindLiteralID:
+		return []ID{ID(o.words[1])}
+	case kindIDLiteral:
+		return []ID{ID(o.words[0])}
+	default:
+		return nil
+	}
+}
+
+// decodeOperands splits the operands of the instruction following its
+// grammar. The words left over by the grammar are returned as literals.
+// m is used to size the literal numbers by their type, and may be nil.
+func decodeOperands(inst Instruction, m *Module) []decodedOperand {
+	out := []decodedOperand{}
+	words := inst.Operands
+	pending := append([]operand{}, instructions[inst.Opcode].operands...)
+	for len(pending) > 0 && len(words) > 0 {
+		op := pending[0]
+		pending = pending[1:]
+		if op.variadic {
+			// Repeat the operand until the end of the instruction.
+			pending = append([]operand{op}, pending...)
+		}
+		n := 1
+		switch op.kind {
+		case kindString:
+			_, n = literalString(words)
+		case kindNumber:
+			if m != nil {
+				if t, ok := m.Types[inst.ResultType()]; ok && t.Width > 32 {
+					n = int(t.Width / 32)
+				}
+			}
+		case kindLiteralID, kindIDID, kindIDLiteral:
+			n = 2
+		}
+		if n > len(words) {
+			n = len(words)
+		}
+		if n < 2 && (op.kind == kindLiteralID || op.kind == kindIDID || op.kind == kindIDLiteral) {
+			op.kind = kindLiteral
+		}
+		out = append(out, decodedOperand{op.kind, op.enum, words[:n]})
+		if op.kind == kindEnum {
+			pending = append(op.enum.params(words[0]), pending...)
+		}
+		words = words[n:]
+	}
+	for _, w := range words {
+		out = append(out, decodedOperand{kindLiteral, nil, []uint32{w}})
+	}
+	return out
+}
+
+// Disassemble returns the disassembly of the SPIR-V module words, in the
+// format of spirv-dis with friendly names.
+func Disassemble(words []uint32) (string, error) {
+	m, err := Decode(words)
+	if err != nil {
+		return "", err
+	}
+	return m.Disassemble(), nil
+}
+
+// Disassemble returns the disassembly of the module, in the format of
+// spirv-dis with friendly names.
+func (m *Module) Disassemble() string {
+	names := m.friendlyNames()
+	name := func(id ID) string {
+		if n, ok := names[id]; ok {
+			return "%" + n
+		}
+		return fmt.Sprintf("%%%d", id)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "; SPIR-V")
+	fmt.Fprintf(buf, "; Version: %d.%d\n", (m.Version>>16)&0xff, (m.Version>>8)&0xff)
+	vendor, version := m.Generator>>16, m.Generator&0xffff
+	if vendor < uint32(len(generators)) {
+		fmt.Fprintf(buf, "; Generator: %v; %d\n", generators[vendor], version)
+	} else {
+		fmt.Fprintf(buf, "; Generator: Unknown(%d); %d\n", vendor, version)
+	}
+	fmt.Fprintf(buf, "; Bound: %d\n", m.Bound)
+	fmt.Fprintf(buf, "; Schema: %d\n", m.Schema)
+
+	const indent = 15
+	for _, inst := range m.Instructions {
+		ops := decodeOperands(inst, m)
+		line := []string{inst.Opcode.String()}
+		result := ""
+		for _, op := range ops {
+			switch op.kind {
+			case kindResult:
+				result = name(ID(op.words[0]))
+			case kindResultType, kindID:
+				line = append(line, name(ID(op.words[0])))
+			case kindIDID:
+				line = append(line, name(ID(op.words[0])), name(ID(op.words[1])))
+			case kindLiteralID:
+				line = append(line, fmt.Sprint(op.words[0]), name(ID(op.words[1])))
+			case kindIDLiteral:
+				line = append(line, name(ID(op.words[0])), fmt.Sprint(op.words[1]))
+			case kindString:
+				s, _ := literalString(op.words)
+				line = append(line, strconv.Quote(s))
+			case kindNumber:
+				line = append(line, m.number(inst, op.words))
+			case kindExtInst:
+				line = append(line, m.extInst(inst, op.words[0]))
+			case kindSpecOp:
+				line = append(line, strings.TrimPrefix(Opcode(op.words[0]).String(), "Op"))
+			case kindEnum:
+				line = append(line, op.enum.format(op.words[0]))
+			default:
+				line = append(line, fmt.Sprint(op.words[0]))
+			}
+		}
+		if result != "" {
+			fmt.Fprintf(buf, "%*s = ", indent-3, result)
+		} else {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		fmt.Fprintln(buf, strings.Join(line, " "))
+	}
+	return buf.String()
+}
+
+// number returns the literal number of the instruction, formatted using the
+// result type of the instruction.
+func (m *Module) number(inst Instruction, words []uint32) string {
+	t, ok := m.Types[inst.ResultType()]
+	if !ok {
+		return fmt.Sprint(words[0])
+	}
+	v := uint64(words[0])
+	if len(words) > 1 {
+		v |= uint64(words[1]) << 32
+	}
+	switch t.Opcode {
+	case OpTypeFloat:
+		switch t.Width {
+		case 32:
+			return formatFloat(float64(math.Float32frombits(uint32(v))), 9)
+		case 64:
+			return formatFloat(math.Float64frombits(v), 17)
+		}
+	case OpTypeInt:
+		if t.Signed {
+			switch t.Width {
+			case 64:
+				return fmt.Sprint(int64(v))
+			case 32:
+				return fmt.Sprint(int32(v))
+			case 16:
+				return fmt.Sprint(int16(v))
+			case 8:
+				return fmt.Sprint(int8(v))
+			}
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// formatFloat formats the float with the precision, as the C++ streams of
+// spirv-dis do.
+func formatFloat(f float64, precision int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	s := strconv.FormatFloat(f, 'g', precision, 64)
+	if strings.ContainsAny(s, "e") {
+		return s
+	}
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// extInst returns the name of the extended instruction of the OpExtInst.
+func (m *Module) extInst(inst Instruction, n uint32) string {
+	if len(inst.Operands) > 2 && m.ExtInstImports[ID(inst.Operands[2])] == "GLSL.std.450" &&
+		n < uint32(len(glslStd450)) && n > 0 {
+		return glslStd450[n]
+	}
+	return fmt.Sprint(n)
+}
+
+// friendlyNames returns the names of the ids, as given by OpName or derived
+// from the types and constants as spirv-dis does.
+func (m *Module) friendlyNames() map[ID]string {
+	out := map[ID]string{}
+	used := map[string]bool{}
+	save := func(id ID, name string) {
+		if _, ok := out[id]; ok {
+			return
+		}
+		name = sanitize(name)
+		unique := name
+		for i := 0; used[unique]; i++ {
+			unique = fmt.Sprintf("%v_%d", name, i)
+		}
+		used[unique] = true
+		out[id] = unique
+	}
+	nameOf := func(id ID) string {
+		if n, ok := out[id]; ok {
+			return n
+		}
+		return fmt.Sprint(id)
+	}
+
+	for _, inst := range m.Instructions {
+		args := inst.Operands
+		switch inst.Opcode {
+		case OpName:
+			if len(args) > 0 {
+				n, _ := literalString(args[1:])
+				save(ID(args[0]), n)
+			}
+		case OpTypeVoid:
+			if len(args) >= 1 {
+				save(ID(args[0]), "void")
+			}
+		case OpTypeBool:
+			if len(args) >= 1 {
+				save(ID(args[0]), "bool")
+			}
+		case OpTypeInt:
+			if len(args) < 3 {
+				continue
+			}
+			sign, root := "", ""
+			switch args[1] {
+			case 8:
+				root = "char"
+			case 16:
+				root = "short"
+			case 32:
+				root = "int"
+			case 64:
+				root = "long"
+			default:
+				sign, root = "i", fmt.Sprint(args[1])
+			}
+			if args[2] == 0 {
+				sign = "u"
+			}
+			save(ID(args[0]), sign+root)
+		case OpTypeFloat:
+			if len(args) < 2 {
+				continue
+			}
+			switch args[1] {
+			case 16:
+				save(ID(args[0]), "half")
+			case 32:
+				save(ID(args[0]), "float")
+			case 64:
+				save(ID(args[0]), "double")
+			default:
+				save(ID(args[0]), fmt.Sprintf("fp%d", args[1]))
+			}
+		case OpTypeVector:
+			if len(args) >= 3 {
+				save(ID(args[0]), fmt.Sprintf("v%d%v", args[2], nameOf(ID(args[1]))))
+			}
+		case OpTypeMatrix:
+			if len(args) >= 3 {
+				save(ID(args[0]), fmt.Sprintf("mat%d%v", args[2], nameOf(ID(args[1]))))
+			}
+		case OpTypeArray:
+			if len(args) >= 3 {
+				save(ID(args[0]), fmt.Sprintf("_arr_%v_%v", nameOf(ID(args[1])), nameOf(ID(args[2]))))
+			}
+		case OpTypeRuntimeArray:
+			if len(args) >= 2 {
+				save(ID(args[0]), fmt.Sprintf("_runtimearr_%v", nameOf(ID(args[1]))))
+			}
+		case OpTypePointer:
+			if len(args) >= 3 {
+				save(ID(args[0]), fmt.Sprintf("_ptr_%v_%v", StorageClass(args[1]), nameOf(ID(args[2]))))
+			}
+		case OpTypeStruct:
+			if len(args) >= 1 {
+				save(ID(args[0]), fmt.Sprintf("_struct_%d", args[0]))
+			}
+		case OpConstantTrue:
+			if len(args) >= 2 {
+				save(ID(args[1]), "true")
+			}
+		case OpConstantFalse:
+			if len(args) >= 2 {
+				save(ID(args[1]), "false")
+			}
+		case OpConstant:
+			if len(args) < 3 {
+				continue
+			}
+			t, ok := m.Types[ID(args[0])]
+			if !ok || (t.Opcode != OpTypeInt && t.Opcode != OpTypeFloat) {
+				continue
+			}
+			v := strings.Replace(m.number(inst, args[2:]), "-", "n", -1)
+			save(ID(args[1]), nameOf(ID(args[0]))+"_"+v)
+		}
+	}
+	return out
+}
+
+// sanitize replaces the characters of the name that are not valid in ids.
+func sanitize(name string) string {
+	if name == "" {
+		return "_"
+	}
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
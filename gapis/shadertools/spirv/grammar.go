@@ -0,0 +1,663 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operandKind is the kind of an operand of an instruction.
+type operandKind int
+
+const (
+	kindResultType operandKind = iota
+	kindResult
+	kindID
+	kindLiteral
+	kindString
+	// kindNumber is a literal number sized by the result type.
+	kindNumber
+	// kindExtInst is the instruction number of an extended instruction set.
+	kindExtInst
+	// kindSpecOp is the opcode of an OpSpecConstantOp.
+	kindSpecOp
+	// kindLiteralID and kindIDID are the pairs of OpSwitch and OpPhi.
+	kindLiteralID
+	kindIDID
+	kindIDLiteral
+	kindEnum
+)
+
+// operand is an operand of the grammar of an instruction.
+type operand struct {
+	kind operandKind
+	enum *enum
+	// optional is true if the operand may be omitted, variadic is true if the
+	// operand is repeated until the end of the instruction.
+	optional, variadic bool
+}
+
+// enumerant is a value of an enum operand, with the kinds of its parameters.
+type enumerant struct {
+	name   string
+	params []operand
+}
+
+// enum is the set of values of an enum operand. The values of mask enums are
+// bits, combined with '|'.
+type enum struct {
+	name   string
+	mask   bool
+	values map[uint32]enumerant
+}
+
+// format returns the name of the value v of the enum.
+func (e *enum) format(v uint32) string {
+	if !e.mask {
+		if n, ok := e.values[v]; ok {
+			return n.name
+		}
+		return fmt.Sprint(v)
+	}
+	if v == 0 {
+		return e.values[0].name
+	}
+	names := []string{}
+	for bit := uint32(1); bit != 0; bit <<= 1 {
+		if v&bit == 0 {
+			continue
+		}
+		if n, ok := e.values[bit]; ok {
+			names = append(names, n.name)
+		} else {
+			names = append(names, fmt.Sprintf("0x%x", bit))
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// params returns the kinds of the parameters of the value v of the enum.
+func (e *enum) params(v uint32) []operand {
+	if !e.mask {
+		return e.values[v].params
+	}
+	out := []operand{}
+	for bit := uint32(1); bit != 0; bit <<= 1 {
+		if v&bit != 0 {
+			out = append(out, e.values[bit].params...)
+		}
+	}
+	return out
+}
+
+// instructionInfo is the grammar of an opcode.
+type instructionInfo struct {
+	name     string
+	operands []operand
+}
+
+var (
+	enums = map[string]*enum{}
+	// instructions is the grammar of the core instructions by opcode.
+	instructions = map[Opcode]instructionInfo{}
+)
+
+// parseOperands parses the operand list of the grammar. Each operand is one
+// of:
+//
+//	T  the result type id
+//	R  the result id
+//	I  an id
+//	L  a literal integer
+//	S  a literal string
+//	N  a literal number, sized by the result type
+//	X  an extended instruction
+//	O  an opcode of OpSpecConstantOp
+//	LI, II, IL a pair of literal and id, ids, or id and literal
+//	or the name of an enum
+//
+// followed by '?' if optional or '*' if variadic.
+func parseOperands(s string) []operand {
+	out := []operand{}
+	for _, f := range strings.Fields(s) {
+		op := operand{}
+		switch {
+		case strings.HasSuffix(f, "?"):
+			op.optional, f = true, f[:len(f)-1]
+		case strings.HasSuffix(f, "*"):
+			op.variadic, f = true, f[:len(f)-1]
+		}
+		switch f {
+		case "T":
+			op.kind = kindResultType
+		case "R":
+			op.kind = kindResult
+		case "I":
+			op.kind = kindID
+		case "L":
+			op.kind = kindLiteral
+		case "S":
+			op.kind = kindString
+		case "N":
+			op.kind = kindNumber
+		case "X":
+			op.kind = kindExtInst
+		case "O":
+			op.kind = kindSpecOp
+		case "LI":
+			op.kind = kindLiteralID
+		case "II":
+			op.kind = kindIDID
+		case "IL":
+			op.kind = kindIDLiteral
+		default:
+			e, ok := enums[f]
+			if !ok {
+				panic(fmt.Errorf("Unknown operand kind %v", f))
+			}
+			op.kind, op.enum = kindEnum, e
+		}
+		out = append(out, op)
+	}
+	return out
+}
+
+// addEnum adds the enum with the values. Each value is the name of the
+// enumerant, optionally followed by its parameters separated by spaces.
+func addEnum(name string, mask bool, values map[uint32]string) {
+	e := &enum{name: name, mask: mask, values: map[uint32]enumerant{}}
+	enums[name] = e
+	for v, s := range values {
+		f := strings.SplitN(s, " ", 2)
+		n := enumerant{name: f[0]}
+		if len(f) > 1 {
+			n.params = parseOperands(f[1])
+		}
+		e.values[v] = n
+	}
+}
+
+func init() {
+	addEnum("SourceLanguage", false, map[uint32]string{
+		0: "Unknown", 1: "ESSL", 2: "GLSL", 3: "OpenCL_C", 4: "OpenCL_CPP", 5: "HLSL",
+	})
+	addEnum("ExecutionModel", false, map[uint32]string{
+		0: "Vertex", 1: "TessellationControl", 2: "TessellationEvaluation",
+		3: "Geometry", 4: "Fragment", 5: "GLCompute", 6: "Kernel",
+	})
+	addEnum("AddressingModel", false, map[uint32]string{
+		0: "Logical", 1: "Physical32", 2: "Physical64",
+	})
+	addEnum("MemoryModel", false, map[uint32]string{
+		0: "Simple", 1: "GLSL450", 2: "OpenCL", 3: "VulkanKHR",
+	})
+	addEnum("ExecutionMode", false, map[uint32]string{
+		0: "Invocations L", 1: "SpacingEqual", 2: "SpacingFractionalEven",
+		3: "SpacingFractionalOdd", 4: "VertexOrderCw", 5: "VertexOrderCcw",
+		6: "PixelCenterInteger", 7: "OriginUpperLeft", 8: "OriginLowerLeft",
+		9: "EarlyFragmentTests", 10: "PointMode", 11: "Xfb", 12: "DepthReplacing",
+		14: "DepthGreater", 15: "DepthLess", 16: "DepthUnchanged",
+		17: "LocalSize L L L", 18: "LocalSizeHint L L L", 19: "InputPoints",
+		20: "InputLines", 21: "InputLinesAdjacency", 22: "Triangles",
+		23: "InputTrianglesAdjacency", 24: "Quads", 25: "Isolines",
+		26: "OutputVertices L", 27: "OutputPoints", 28: "OutputLineStrip",
+		29: "OutputTriangleStrip", 30: "VecTypeHint L", 31: "ContractionOff",
+		33: "Initializer", 34: "Finalizer", 35: "SubgroupSize L",
+		36: "SubgroupsPerWorkgroup L", 37: "SubgroupsPerWorkgroupId I",
+		38: "LocalSizeId I I I", 39: "LocalSizeHintId I",
+	})
+	addEnum("StorageClass", false, map[uint32]string{
+		0: "UniformConstant", 1: "Input", 2: "Uniform", 3: "Output",
+		4: "Workgroup", 5: "CrossWorkgroup", 6: "Private", 7: "Function",
+		8: "Generic", 9: "PushConstant", 10: "AtomicCounter", 11: "Image",
+		12: "StorageBuffer",
+	})
+	addEnum("Dim", false, map[uint32]string{
+		0: "1D", 1: "2D", 2: "3D", 3: "Cube", 4: "Rect", 5: "Buffer", 6: "SubpassData",
+	})
+	addEnum("SamplerAddressingMode", false, map[uint32]string{
+		0: "None", 1: "ClampToEdge", 2: "Clamp", 3: "Repeat", 4: "RepeatMirrored",
+	})
+	addEnum("SamplerFilterMode", false, map[uint32]string{
+		0: "Nearest", 1: "Linear",
+	})
+	addEnum("ImageFormat", false, map[uint32]string{
+		0: "Unknown", 1: "Rgba32f", 2: "Rgba16f", 3: "R32f", 4: "Rgba8",
+		5: "Rgba8Snorm", 6: "Rg32f", 7: "Rg16f", 8: "R11fG11fB10f", 9: "R16f",
+		10: "Rgba16", 11: "Rgb10A2", 12: "Rg16", 13: "Rg8", 14: "R16", 15: "R8",
+		16: "Rgba16Snorm", 17: "Rg16Snorm", 18: "Rg8Snorm", 19: "R16Snorm",
+		20: "R8Snorm", 21: "Rgba32i", 22: "Rgba16i", 23: "Rgba8i", 24: "R32i",
+		25: "Rg32i", 26: "Rg16i", 27: "Rg8i", 28: "R16i", 29: "R8i",
+		30: "Rgba32ui", 31: "Rgba16ui", 32: "Rgba8ui", 33: "R32ui",
+		34: "Rgb10a2ui", 35: "Rg32ui", 36: "Rg16ui", 37: "Rg8ui", 38: "R16ui",
+		39: "R8ui",
+	})
+	addEnum("AccessQualifier", false, map[uint32]string{
+		0: "ReadOnly", 1: "WriteOnly", 2: "ReadWrite",
+	})
+	addEnum("FunctionParameterAttribute", false, map[uint32]string{
+		0: "Zext", 1: "Sext", 2: "ByVal", 3: "Sret", 4: "NoAlias",
+		5: "NoCapture", 6: "NoWrite", 7: "NoReadWrite",
+	})
+	addEnum("FPRoundingMode", false, map[uint32]string{
+		0: "RTE", 1: "RTZ", 2: "RTP", 3: "RTN",
+	})
+	addEnum("LinkageType", false, map[uint32]string{
+		0: "Export", 1: "Import",
+	})
+	addEnum("BuiltIn", false, builtIns)
+	addEnum("Decoration", false, map[uint32]string{
+		0: "RelaxedPrecision", 1: "SpecId L", 2: "Block", 3: "BufferBlock",
+		4: "RowMajor", 5: "ColMajor", 6: "ArrayStride L", 7: "MatrixStride L",
+		8: "GLSLShared", 9: "GLSLPacked", 10: "CPacked", 11: "BuiltIn BuiltIn",
+		13: "NoPerspective", 14: "Flat", 15: "Patch", 16: "Centroid",
+		17: "Sample", 18: "Invariant", 19: "Restrict", 20: "Aliased",
+		21: "Volatile", 22: "Constant", 23: "Coherent", 24: "NonWritable",
+		25: "NonReadable", 26: "Uniform", 28: "SaturatedConversion",
+		29: "Stream L", 30: "Location L", 31: "Component L", 32: "Index L",
+		33: "Binding L", 34: "DescriptorSet L", 35: "Offset L",
+		36: "XfbBuffer L", 37: "XfbStride L",
+		38: "FuncParamAttr FunctionParameterAttribute",
+		39: "FPRoundingMode FPRoundingMode", 40: "FPFastMathMode L",
+		41: "LinkageAttributes S LinkageType", 42: "NoContraction",
+		43: "InputAttachmentIndex L", 44: "Alignment L", 45: "MaxByteOffset L",
+		46: "AlignmentId I", 47: "MaxByteOffsetId I",
+		5634: "HlslCounterBufferGOOGLE I", 5635: "HlslSemanticGOOGLE S",
+	})
+	addEnum("Capability", false, map[uint32]string{
+		0: "Matrix", 1: "Shader", 2: "Geometry", 3: "Tessellation",
+		4: "Addresses", 5: "Linkage", 6: "Kernel", 7: "Vector16",
+		8: "Float16Buffer", 9: "Float16", 10: "Float64", 11: "Int64",
+		12: "Int64Atomics", 13: "ImageBasic", 14: "ImageReadWrite",
+		15: "ImageMipmap", 17: "Pipes", 18: "Groups", 19: "DeviceEnqueue",
+		20: "LiteralSampler", 21: "AtomicStorage", 22: "Int16",
+		23: "TessellationPointSize", 24: "GeometryPointSize",
+		25: "ImageGatherExtended", 27: "StorageImageMultisample",
+		28: "UniformBufferArrayDynamicIndexing",
+		29: "SampledImageArrayDynamicIndexing",
+		30: "StorageBufferArrayDynamicIndexing",
+		31: "StorageImageArrayDynamicIndexing", 32: "ClipDistance",
+		33: "CullDistance", 34: "ImageCubeArray", 35: "SampleRateShading",
+		36: "ImageRect", 37: "SampledRect", 38: "GenericPointer", 39: "Int8",
+		40: "InputAttachment", 41: "SparseResidency", 42: "MinLod",
+		43: "Sampled1D", 44: "Image1D", 45: "SampledCubeArray",
+		46: "SampledBuffer", 47: "ImageBuffer", 48: "ImageMSArray",
+		49: "StorageImageExtendedFormats", 50: "ImageQuery",
+		51: "DerivativeControl", 52: "InterpolationFunction",
+		53: "TransformFeedback", 54: "GeometryStreams",
+		55: "StorageImageReadWithoutFormat", 56: "StorageImageWriteWithoutFormat",
+		57: "MultiViewport", 58: "SubgroupDispatch", 59: "NamedBarrier",
+		60: "PipeStorage", 61: "GroupNonUniform", 62: "GroupNonUniformVote",
+		63: "GroupNonUniformArithmetic", 64: "GroupNonUniformBallot",
+		65: "GroupNonUniformShuffle", 66: "GroupNonUniformShuffleRelative",
+		67: "GroupNonUniformClustered", 68: "GroupNonUniformQuad",
+		4423: "SubgroupBallotKHR", 4427: "DrawParameters",
+		4431: "SubgroupVoteKHR", 4433: "StorageBuffer16BitAccess",
+		4434: "UniformAndStorageBuffer16BitAccess",
+		4435: "StoragePushConstant16", 4436: "StorageInputOutput16",
+		4437: "DeviceGroup", 4439: "MultiView",
+		4441: "VariablePointersStorageBuffer", 4442: "VariablePointers",
+	})
+	addEnum("FunctionControl", true, map[uint32]string{
+		0: "None", 1: "Inline", 2: "DontInline", 4: "Pure", 8: "Const",
+	})
+	addEnum("SelectionControl", true, map[uint32]string{
+		0: "None", 1: "Flatten", 2: "DontFlatten",
+	})
+	addEnum("LoopControl", true, map[uint32]string{
+		0: "None", 1: "Unroll", 2: "DontUnroll", 4: "DependencyInfinite",
+		8: "DependencyLength L",
+	})
+	addEnum("MemoryAccess", true, map[uint32]string{
+		0: "None", 1: "Volatile", 2: "Aligned L", 4: "Nontemporal",
+	})
+	addEnum("ImageOperands", true, map[uint32]string{
+		0: "None", 1: "Bias I", 2: "Lod I", 4: "Grad I I", 8: "ConstOffset I",
+		0x10: "Offset I", 0x20: "ConstOffsets I", 0x40: "Sample I",
+		0x80: "MinLod I",
+	})
+	addEnum("GroupOperation", false, map[uint32]string{
+		0: "Reduce", 1: "InclusiveScan", 2: "ExclusiveScan", 3: "ClusteredReduce",
+	})
+
+	for op, s := range map[Opcode]string{
+		OpNop:                                  "Nop",
+		OpUndef:                                "Undef T R",
+		OpSourceContinued:                      "SourceContinued S",
+		OpSource:                               "Source SourceLanguage L I? S?",
+		OpSourceExtension:                      "SourceExtension S",
+		OpName:                                 "Name I S",
+		OpMemberName:                           "MemberName I L S",
+		OpString:                               "String R S",
+		OpLine:                                 "Line I L L",
+		OpExtension:                            "Extension S",
+		OpExtInstImport:                        "ExtInstImport R S",
+		OpExtInst:                              "ExtInst T R I X I*",
+		OpMemoryModel:                          "MemoryModel AddressingModel MemoryModel",
+		OpEntryPoint:                           "EntryPoint ExecutionModel I S I*",
+		OpExecutionMode:                        "ExecutionMode I ExecutionMode",
+		OpCapability:                           "Capability Capability",
+		OpTypeVoid:                             "TypeVoid R",
+		OpTypeBool:                             "TypeBool R",
+		OpTypeInt:                              "TypeInt R L L",
+		OpTypeFloat:                            "TypeFloat R L",
+		OpTypeVector:                           "TypeVector R I L",
+		OpTypeMatrix:                           "TypeMatrix R I L",
+		OpTypeImage:                            "TypeImage R I Dim L L L L ImageFormat AccessQualifier?",
+		OpTypeSampler:                          "TypeSampler R",
+		OpTypeSampledImage:                     "TypeSampledImage R I",
+		OpTypeArray:                            "TypeArray R I I",
+		OpTypeRuntimeArray:                     "TypeRuntimeArray R I",
+		OpTypeStruct:                           "TypeStruct R I*",
+		OpTypeOpaque:                           "TypeOpaque R S",
+		OpTypePointer:                          "TypePointer R StorageClass I",
+		OpTypeFunction:                         "TypeFunction R I I*",
+		OpTypeEvent:                            "TypeEvent R",
+		OpTypeDeviceEvent:                      "TypeDeviceEvent R",
+		OpTypeReserveID:                        "TypeReserveId R",
+		OpTypeQueue:                            "TypeQueue R",
+		OpTypePipe:                             "TypePipe R AccessQualifier",
+		OpTypeForwardPointer:                   "TypeForwardPointer I StorageClass",
+		OpConstantTrue:                         "ConstantTrue T R",
+		OpConstantFalse:                        "ConstantFalse T R",
+		OpConstant:                             "Constant T R N",
+		OpConstantComposite:                    "ConstantComposite T R I*",
+		OpConstantSampler:                      "ConstantSampler T R SamplerAddressingMode L SamplerFilterMode",
+		OpConstantNull:                         "ConstantNull T R",
+		OpSpecConstantTrue:                     "SpecConstantTrue T R",
+		OpSpecConstantFalse:                    "SpecConstantFalse T R",
+		OpSpecConstant:                         "SpecConstant T R N",
+		OpSpecConstantComposite:                "SpecConstantComposite T R I*",
+		OpSpecConstantOp:                       "SpecConstantOp T R O I*",
+		OpFunction:                             "Function T R FunctionControl I",
+		OpFunctionParameter:                    "FunctionParameter T R",
+		OpFunctionEnd:                          "FunctionEnd",
+		OpFunctionCall:                         "FunctionCall T R I I*",
+		OpVariable:                             "Variable T R StorageClass I?",
+		OpImageTexelPointer:                    "ImageTexelPointer T R I I I",
+		OpLoad:                                 "Load T R I MemoryAccess?",
+		OpStore:                                "Store I I MemoryAccess?",
+		OpCopyMemory:                           "CopyMemory I I MemoryAccess?",
+		OpCopyMemorySized:                      "CopyMemorySized I I I MemoryAccess?",
+		OpAccessChain:                          "AccessChain T R I I*",
+		OpInBoundsAccessChain:                  "InBoundsAccessChain T R I I*",
+		OpPtrAccessChain:                       "PtrAccessChain T R I I I*",
+		OpArrayLength:                          "ArrayLength T R I L",
+		OpGenericPtrMemSemantics:               "GenericPtrMemSemantics T R I",
+		OpInBoundsPtrAccessChain:               "InBoundsPtrAccessChain T R I I I*",
+		OpDecorate:                             "Decorate I Decoration",
+		OpMemberDecorate:                       "MemberDecorate I L Decoration",
+		OpDecorationGroup:                      "DecorationGroup R",
+		OpGroupDecorate:                        "GroupDecorate I I*",
+		OpGroupMemberDecorate:                  "GroupMemberDecorate I IL*",
+		OpVectorExtractDynamic:                 "VectorExtractDynamic T R I I",
+		OpVectorInsertDynamic:                  "VectorInsertDynamic T R I I I",
+		OpVectorShuffle:                        "VectorShuffle T R I I L*",
+		OpCompositeConstruct:                   "CompositeConstruct T R I*",
+		OpCompositeExtract:                     "CompositeExtract T R I L*",
+		OpCompositeInsert:                      "CompositeInsert T R I I L*",
+		OpCopyObject:                           "CopyObject T R I",
+		OpTranspose:                            "Transpose T R I",
+		OpSampledImage:                         "SampledImage T R I I",
+		OpImageSampleImplicitLod:               "ImageSampleImplicitLod T R I I ImageOperands?",
+		OpImageSampleExplicitLod:               "ImageSampleExplicitLod T R I I ImageOperands",
+		OpImageSampleDrefImplicitLod:           "ImageSampleDrefImplicitLod T R I I I ImageOperands?",
+		OpImageSampleDrefExplicitLod:           "ImageSampleDrefExplicitLod T R I I I ImageOperands",
+		OpImageSampleProjImplicitLod:           "ImageSampleProjImplicitLod T R I I ImageOperands?",
+		OpImageSampleProjExplicitLod:           "ImageSampleProjExplicitLod T R I I ImageOperands",
+		OpImageSampleProjDrefImplicitLod:       "ImageSampleProjDrefImplicitLod T R I I I ImageOperands?",
+		OpImageSampleProjDrefExplicitLod:       "ImageSampleProjDrefExplicitLod T R I I I ImageOperands",
+		OpImageFetch:                           "ImageFetch T R I I ImageOperands?",
+		OpImageGather:                          "ImageGather T R I I I ImageOperands?",
+		OpImageDrefGather:                      "ImageDrefGather T R I I I ImageOperands?",
+		OpImageRead:                            "ImageRead T R I I ImageOperands?",
+		OpImageWrite:                           "ImageWrite I I I ImageOperands?",
+		OpImage:                                "Image T R I",
+		OpImageQueryFormat:                     "ImageQueryFormat T R I",
+		OpImageQueryOrder:                      "ImageQueryOrder T R I",
+		OpImageQuerySizeLod:                    "ImageQuerySizeLod T R I I",
+		OpImageQuerySize:                       "ImageQuerySize T R I",
+		OpImageQueryLod:                        "ImageQueryLod T R I I",
+		OpImageQueryLevels:                     "ImageQueryLevels T R I",
+		OpImageQuerySamples:                    "ImageQuerySamples T R I",
+		OpConvertFToU:                          "ConvertFToU T R I",
+		OpConvertFToS:                          "ConvertFToS T R I",
+		OpConvertSToF:                          "ConvertSToF T R I",
+		OpConvertUToF:                          "ConvertUToF T R I",
+		OpUConvert:                             "UConvert T R I",
+		OpSConvert:                             "SConvert T R I",
+		OpFConvert:                             "FConvert T R I",
+		OpQuantizeToF16:                        "QuantizeToF16 T R I",
+		OpConvertPtrToU:                        "ConvertPtrToU T R I",
+		OpSatConvertSToU:                       "SatConvertSToU T R I",
+		OpSatConvertUToS:                       "SatConvertUToS T R I",
+		OpConvertUToPtr:                        "ConvertUToPtr T R I",
+		OpPtrCastToGeneric:                     "PtrCastToGeneric T R I",
+		OpGenericCastToPtr:                     "GenericCastToPtr T R I",
+		OpGenericCastToPtrExplicit:             "GenericCastToPtrExplicit T R I StorageClass",
+		OpBitcast:                              "Bitcast T R I",
+		OpSNegate:                              "SNegate T R I",
+		OpFNegate:                              "FNegate T R I",
+		OpIAdd:                                 "IAdd T R I I",
+		OpFAdd:                                 "FAdd T R I I",
+		OpISub:                                 "ISub T R I I",
+		OpFSub:                                 "FSub T R I I",
+		OpIMul:                                 "IMul T R I I",
+		OpFMul:                                 "FMul T R I I",
+		OpUDiv:                                 "UDiv T R I I",
+		OpSDiv:                                 "SDiv T R I I",
+		OpFDiv:                                 "FDiv T R I I",
+		OpUMod:                                 "UMod T R I I",
+		OpSRem:                                 "SRem T R I I",
+		OpSMod:                                 "SMod T R I I",
+		OpFRem:                                 "FRem T R I I",
+		OpFMod:                                 "FMod T R I I",
+		OpVectorTimesScalar:                    "VectorTimesScalar T R I I",
+		OpMatrixTimesScalar:                    "MatrixTimesScalar T R I I",
+		OpVectorTimesMatrix:                    "VectorTimesMatrix T R I I",
+		OpMatrixTimesVector:                    "MatrixTimesVector T R I I",
+		OpMatrixTimesMatrix:                    "MatrixTimesMatrix T R I I",
+		OpOuterProduct:                         "OuterProduct T R I I",
+		OpDot:                                  "Dot T R I I",
+		OpIAddCarry:                            "IAddCarry T R I I",
+		OpISubBorrow:                           "ISubBorrow T R I I",
+		OpUMulExtended:                         "UMulExtended T R I I",
+		OpSMulExtended:                         "SMulExtended T R I I",
+		OpAny:                                  "Any T R I",
+		OpAll:                                  "All T R I",
+		OpIsNan:                                "IsNan T R I",
+		OpIsInf:                                "IsInf T R I",
+		OpIsFinite:                             "IsFinite T R I",
+		OpIsNormal:                             "IsNormal T R I",
+		OpSignBitSet:                           "SignBitSet T R I",
+		OpLessOrGreater:                        "LessOrGreater T R I I",
+		OpOrdered:                              "Ordered T R I I",
+		OpUnordered:                            "Unordered T R I I",
+		OpLogicalEqual:                         "LogicalEqual T R I I",
+		OpLogicalNotEqual:                      "LogicalNotEqual T R I I",
+		OpLogicalOr:                            "LogicalOr T R I I",
+		OpLogicalAnd:                           "LogicalAnd T R I I",
+		OpLogicalNot:                           "LogicalNot T R I",
+		OpSelect:                               "Select T R I I I",
+		OpIEqual:                               "IEqual T R I I",
+		OpINotEqual:                            "INotEqual T R I I",
+		OpUGreaterThan:                         "UGreaterThan T R I I",
+		OpSGreaterThan:                         "SGreaterThan T R I I",
+		OpUGreaterThanEqual:                    "UGreaterThanEqual T R I I",
+		OpSGreaterThanEqual:                    "SGreaterThanEqual T R I I",
+		OpULessThan:                            "ULessThan T R I I",
+		OpSLessThan:                            "SLessThan T R I I",
+		OpULessThanEqual:                       "ULessThanEqual T R I I",
+		OpSLessThanEqual:                       "SLessThanEqual T R I I",
+		OpFOrdEqual:                            "FOrdEqual T R I I",
+		OpFUnordEqual:                          "FUnordEqual T R I I",
+		OpFOrdNotEqual:                         "FOrdNotEqual T R I I",
+		OpFUnordNotEqual:                       "FUnordNotEqual T R I I",
+		OpFOrdLessThan:                         "FOrdLessThan T R I I",
+		OpFUnordLessThan:                       "FUnordLessThan T R I I",
+		OpFOrdGreaterThan:                      "FOrdGreaterThan T R I I",
+		OpFUnordGreaterThan:                    "FUnordGreaterThan T R I I",
+		OpFOrdLessThanEqual:                    "FOrdLessThanEqual T R I I",
+		OpFUnordLessThanEqual:                  "FUnordLessThanEqual T R I I",
+		OpFOrdGreaterThanEqual:                 "FOrdGreaterThanEqual T R I I",
+		OpFUnordGreaterThanEqual:               "FUnordGreaterThanEqual T R I I",
+		OpShiftRightLogical:                    "ShiftRightLogical T R I I",
+		OpShiftRightArithmetic:                 "ShiftRightArithmetic T R I I",
+		OpShiftLeftLogical:                     "ShiftLeftLogical T R I I",
+		OpBitwiseOr:                            "BitwiseOr T R I I",
+		OpBitwiseXor:                           "BitwiseXor T R I I",
+		OpBitwiseAnd:                           "BitwiseAnd T R I I",
+		OpNot:                                  "Not T R I",
+		OpBitFieldInsert:                       "BitFieldInsert T R I I I I",
+		OpBitFieldSExtract:                     "BitFieldSExtract T R I I I",
+		OpBitFieldUExtract:                     "BitFieldUExtract T R I I I",
+		OpBitReverse:                           "BitReverse T R I",
+		OpBitCount:                             "BitCount T R I",
+		OpDPdx:                                 "DPdx T R I",
+		OpDPdy:                                 "DPdy T R I",
+		OpFwidth:                               "Fwidth T R I",
+		OpDPdxFine:                             "DPdxFine T R I",
+		OpDPdyFine:                             "DPdyFine T R I",
+		OpFwidthFine:                           "FwidthFine T R I",
+		OpDPdxCoarse:                           "DPdxCoarse T R I",
+		OpDPdyCoarse:                           "DPdyCoarse T R I",
+		OpFwidthCoarse:                         "FwidthCoarse T R I",
+		OpEmitVertex:                           "EmitVertex",
+		OpEndPrimitive:                         "EndPrimitive",
+		OpEmitStreamVertex:                     "EmitStreamVertex I",
+		OpEndStreamPrimitive:                   "EndStreamPrimitive I",
+		OpControlBarrier:                       "ControlBarrier I I I",
+		OpMemoryBarrier:                        "MemoryBarrier I I",
+		OpAtomicLoad:                           "AtomicLoad T R I I I",
+		OpAtomicStore:                          "AtomicStore I I I I",
+		OpAtomicExchange:                       "AtomicExchange T R I I I I",
+		OpAtomicCompareExchange:                "AtomicCompareExchange T R I I I I I I",
+		OpAtomicCompareExchangeWeak:            "AtomicCompareExchangeWeak T R I I I I I I",
+		OpAtomicIIncrement:                     "AtomicIIncrement T R I I I",
+		OpAtomicIDecrement:                     "AtomicIDecrement T R I I I",
+		OpAtomicIAdd:                           "AtomicIAdd T R I I I I",
+		OpAtomicISub:                           "AtomicISub T R I I I I",
+		OpAtomicSMin:                           "AtomicSMin T R I I I I",
+		OpAtomicUMin:                           "AtomicUMin T R I I I I",
+		OpAtomicSMax:                           "AtomicSMax T R I I I I",
+		OpAtomicUMax:                           "AtomicUMax T R I I I I",
+		OpAtomicAnd:                            "AtomicAnd T R I I I I",
+		OpAtomicOr:                             "AtomicOr T R I I I I",
+		OpAtomicXor:                            "AtomicXor T R I I I I",
+		OpPhi:                                  "Phi T R II*",
+		OpLoopMerge:                            "LoopMerge I I LoopControl",
+		OpSelectionMerge:                       "SelectionMerge I SelectionControl",
+		OpLabel:                                "Label R",
+		OpBranch:                               "Branch I",
+		OpBranchConditional:                    "BranchConditional I I I L*",
+		OpSwitch:                               "Switch I I LI*",
+		OpKill:                                 "Kill",
+		OpReturn:                               "Return",
+		OpReturnValue:                          "ReturnValue I",
+		OpUnreachable:                          "Unreachable",
+		OpLifetimeStart:                        "LifetimeStart I L",
+		OpLifetimeStop:                         "LifetimeStop I L",
+		OpImageSparseSampleImplicitLod:         "ImageSparseSampleImplicitLod T R I I ImageOperands?",
+		OpImageSparseSampleExplicitLod:         "ImageSparseSampleExplicitLod T R I I ImageOperands",
+		OpImageSparseSampleDrefImplicitLod:     "ImageSparseSampleDrefImplicitLod T R I I I ImageOperands?",
+		OpImageSparseSampleDrefExplicitLod:     "ImageSparseSampleDrefExplicitLod T R I I I ImageOperands",
+		OpImageSparseSampleProjImplicitLod:     "ImageSparseSampleProjImplicitLod T R I I ImageOperands?",
+		OpImageSparseSampleProjExplicitLod:     "ImageSparseSampleProjExplicitLod T R I I ImageOperands",
+		OpImageSparseSampleProjDrefImplicitLod: "ImageSparseSampleProjDrefImplicitLod T R I I I ImageOperands?",
+		OpImageSparseSampleProjDrefExplicitLod: "ImageSparseSampleProjDrefExplicitLod T R I I I ImageOperands",
+		OpImageSparseFetch:                     "ImageSparseFetch T R I I ImageOperands?",
+		OpImageSparseGather:                    "ImageSparseGather T R I I I ImageOperands?",
+		OpImageSparseDrefGather:                "ImageSparseDrefGather T R I I I ImageOperands?",
+		OpImageSparseTexelsResident:            "ImageSparseTexelsResident T R I",
+		OpNoLine:                               "NoLine",
+		OpImageSparseRead:                      "ImageSparseRead T R I I ImageOperands?",
+		OpModuleProcessed:                      "ModuleProcessed S",
+		OpDecorateID:                           "DecorateId I Decoration",
+		OpGroupNonUniformElect:                 "GroupNonUniformElect T R I",
+		OpGroupNonUniformAll:                   "GroupNonUniformAll T R I I",
+		OpGroupNonUniformAny:                   "GroupNonUniformAny T R I I",
+		OpGroupNonUniformAllEqual:              "GroupNonUniformAllEqual T R I I",
+		OpGroupNonUniformBroadcast:             "GroupNonUniformBroadcast T R I I I",
+		OpGroupNonUniformBroadcastFirst:        "GroupNonUniformBroadcastFirst T R I I",
+		OpGroupNonUniformBallot:                "GroupNonUniformBallot T R I I",
+		OpGroupNonUniformIAdd:                  "GroupNonUniformIAdd T R I GroupOperation I I?",
+		OpGroupNonUniformFAdd:                  "GroupNonUniformFAdd T R I GroupOperation I I?",
+		OpGroupNonUniformIMul:                  "GroupNonUniformIMul T R I GroupOperation I I?",
+		OpGroupNonUniformFMul:                  "GroupNonUniformFMul T R I GroupOperation I I?",
+		OpGroupNonUniformSMin:                  "GroupNonUniformSMin T R I GroupOperation I I?",
+		OpGroupNonUniformUMin:                  "GroupNonUniformUMin T R I GroupOperation I I?",
+		OpGroupNonUniformFMin:                  "GroupNonUniformFMin T R I GroupOperation I I?",
+		OpGroupNonUniformSMax:                  "GroupNonUniformSMax T R I GroupOperation I I?",
+		OpGroupNonUniformUMax:                  "GroupNonUniformUMax T R I GroupOperation I I?",
+		OpGroupNonUniformFMax:                  "GroupNonUniformFMax T R I GroupOperation I I?",
+	} {
+		f := strings.SplitN(s, " ", 2)
+		info := instructionInfo{name: "Op" + f[0]}
+		if len(f) > 1 {
+			info.operands = parseOperands(f[1])
+		}
+		instructions[op] = info
+	}
+}
+
+// builtIns are the names of the BuiltIn decoration values.
+var builtIns = map[uint32]string{
+	0: "Position", 1: "PointSize", 3: "ClipDistance", 4: "CullDistance",
+	5: "VertexId", 6: "InstanceId", 7: "PrimitiveId", 8: "InvocationId",
+	9: "Layer", 10: "ViewportIndex", 11: "TessLevelOuter",
+	12: "TessLevelInner", 13: "TessCoord", 14: "PatchVertices",
+	15: "FragCoord", 16: "PointCoord", 17: "FrontFacing", 18: "SampleId",
+	19: "SamplePosition", 20: "SampleMask", 22: "FragDepth",
+	23: "HelperInvocation", 24: "NumWorkgroups", 25: "WorkgroupSize",
+	26: "WorkgroupId", 27: "LocalInvocationId", 28: "GlobalInvocationId",
+	29: "LocalInvocationIndex", 30: "WorkDim", 31: "GlobalSize",
+	32: "EnqueuedWorkgroupSize", 33: "GlobalOffset", 34: "GlobalLinearId",
+	36: "SubgroupSize", 37: "SubgroupMaxSize", 38: "NumSubgroups",
+	39: "NumEnqueuedSubgroups", 40: "SubgroupId",
+	41: "SubgroupLocalInvocationId", 42: "VertexIndex", 43: "InstanceIndex",
+	4416: "SubgroupEqMask", 4417: "SubgroupGeMask", 4418: "SubgroupGtMask",
+	4419: "SubgroupLeMask", 4420: "SubgroupLtMask", 4424: "BaseVertex",
+	4425: "BaseInstance", 4426: "DrawIndex", 4438: "DeviceIndex",
+	4440: "ViewIndex",
+}
+
+// glslStd450 are the names of the instructions of the GLSL.std.450 extended
+// instruction set.
+var glslStd450 = []string{
+	"", "Round", "RoundEven", "Trunc", "FAbs", "SAbs", "FSign", "SSign",
+	"Floor", "Ceil", "Fract", "Radians", "Degrees", "Sin", "Cos", "Tan",
+	"Asin", "Acos", "Atan", "Sinh", "Cosh", "Tanh", "Asinh", "Acosh", "Atanh",
+	"Atan2", "Pow", "Exp", "Log", "Exp2", "Log2", "Sqrt", "InverseSqrt",
+	"Determinant", "MatrixInverse", "Modf", "ModfStruct", "FMin", "UMin",
+	"SMin", "FMax", "UMax", "SMax", "FClamp", "UClamp", "SClamp", "FMix",
+	"IMix", "Step", "SmoothStep", "Fma", "Frexp", "FrexpStruct", "Ldexp",
+	"PackSnorm4x8", "PackUnorm4x8", "PackSnorm2x16", "PackUnorm2x16",
+	"PackHalf2x16", "PackDouble2x32", "UnpackSnorm2x16", "UnpackUnorm2x16",
+	"UnpackHalf2x16", "UnpackSnorm4x8", "UnpackUnorm4x8", "UnpackDouble2x32",
+	"Length", "Distance", "Cross", "Normalize", "FaceForward", "Reflect",
+	"Refract", "FindILsb", "FindSMsb", "FindUMsb", "InterpolateAtCentroid",
+	"InterpolateAtSample", "InterpolateAtOffset", "NMin", "NMax", "NClamp",
+}
+
+// generators are the names of the vendors of the SPIR-V generators.
+var generators = []string{
+	"Khronos", "LunarG", "Valve", "Codeplay", "NVIDIA", "ARM",
+	"Khronos LLVM/SPIR-V Translator", "Khronos SPIR-V Tools Assembler",
+	"Khronos Glslang Reference Front End", "Qualcomm", "AMD", "Intel",
+	"Imagination", "Google Shaderc over Glslang", "Google spiregg",
+	"Google rspirv", "X-LEGEND Mesa-IR/SPIR-V Translator",
+	"Khronos SPIR-V Tools Linker",
+}
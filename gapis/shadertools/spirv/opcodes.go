@@ -0,0 +1,301 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import "fmt"
+
+// Opcode is the opcode of a SPIR-V instruction.
+type Opcode uint16
+
+// The core SPIR-V opcodes.
+const (
+	OpNop                                  Opcode = 0
+	OpUndef                                Opcode = 1
+	OpSourceContinued                      Opcode = 2
+	OpSource                               Opcode = 3
+	OpSourceExtension                      Opcode = 4
+	OpName                                 Opcode = 5
+	OpMemberName                           Opcode = 6
+	OpString                               Opcode = 7
+	OpLine                                 Opcode = 8
+	OpExtension                            Opcode = 10
+	OpExtInstImport                        Opcode = 11
+	OpExtInst                              Opcode = 12
+	OpMemoryModel                          Opcode = 14
+	OpEntryPoint                           Opcode = 15
+	OpExecutionMode                        Opcode = 16
+	OpCapability                           Opcode = 17
+	OpTypeVoid                             Opcode = 19
+	OpTypeBool                             Opcode = 20
+	OpTypeInt                              Opcode = 21
+	OpTypeFloat                            Opcode = 22
+	OpTypeVector                           Opcode = 23
+	OpTypeMatrix                           Opcode = 24
+	OpTypeImage                            Opcode = 25
+	OpTypeSampler                          Opcode = 26
+	OpTypeSampledImage                     Opcode = 27
+	OpTypeArray                            Opcode = 28
+	OpTypeRuntimeArray                     Opcode = 29
+	OpTypeStruct                           Opcode = 30
+	OpTypeOpaque                           Opcode = 31
+	OpTypePointer                          Opcode = 32
+	OpTypeFunction                         Opcode = 33
+	OpTypeEvent                            Opcode = 34
+	OpTypeDeviceEvent                      Opcode = 35
+	OpTypeReserveID                        Opcode = 36
+	OpTypeQueue                            Opcode = 37
+	OpTypePipe                             Opcode = 38
+	OpTypeForwardPointer                   Opcode = 39
+	OpConstantTrue                         Opcode = 41
+	OpConstantFalse                        Opcode = 42
+	OpConstant                             Opcode = 43
+	OpConstantComposite                    Opcode = 44
+	OpConstantSampler                      Opcode = 45
+	OpConstantNull                         Opcode = 46
+	OpSpecConstantTrue                     Opcode = 48
+	OpSpecConstantFalse                    Opcode = 49
+	OpSpecConstant                         Opcode = 50
+	OpSpecConstantComposite                Opcode = 51
+	OpSpecConstantOp                       Opcode = 52
+	OpFunction                             Opcode = 54
+	OpFunctionParameter                    Opcode = 55
+	OpFunctionEnd                          Opcode = 56
+	OpFunctionCall                         Opcode = 57
+	OpVariable                             Opcode = 59
+	OpImageTexelPointer                    Opcode = 60
+	OpLoad                                 Opcode = 61
+	OpStore                                Opcode = 62
+	OpCopyMemory                           Opcode = 63
+	OpCopyMemorySized                      Opcode = 64
+	OpAccessChain                          Opcode = 65
+	OpInBoundsAccessChain                  Opcode = 66
+	OpPtrAccessChain                       Opcode = 67
+	OpArrayLength                          Opcode = 68
+	OpGenericPtrMemSemantics               Opcode = 69
+	OpInBoundsPtrAccessChain               Opcode = 70
+	OpDecorate                             Opcode = 71
+	OpMemberDecorate                       Opcode = 72
+	OpDecorationGroup                      Opcode = 73
+	OpGroupDecorate                        Opcode = 74
+	OpGroupMemberDecorate                  Opcode = 75
+	OpVectorExtractDynamic                 Opcode = 77
+	OpVectorInsertDynamic                  Opcode = 78
+	OpVectorShuffle                        Opcode = 79
+	OpCompositeConstruct                   Opcode = 80
+	OpCompositeExtract                     Opcode = 81
+	OpCompositeInsert                      Opcode = 82
+	OpCopyObject                           Opcode = 83
+	OpTranspose                            Opcode = 84
+	OpSampledImage                         Opcode = 86
+	OpImageSampleImplicitLod               Opcode = 87
+	OpImageSampleExplicitLod               Opcode = 88
+	OpImageSampleDrefImplicitLod           Opcode = 89
+	OpImageSampleDrefExplicitLod           Opcode = 90
+	OpImageSampleProjImplicitLod           Opcode = 91
+	OpImageSampleProjExplicitLod           Opcode = 92
+	OpImageSampleProjDrefImplicitLod       Opcode = 93
+	OpImageSampleProjDrefExplicitLod       Opcode = 94
+	OpImageFetch                           Opcode = 95
+	OpImageGather                          Opcode = 96
+	OpImageDrefGather                      Opcode = 97
+	OpImageRead                            Opcode = 98
+	OpImageWrite                           Opcode = 99
+	OpImage                                Opcode = 100
+	OpImageQueryFormat                     Opcode = 101
+	OpImageQueryOrder                      Opcode = 102
+	OpImageQuerySizeLod                    Opcode = 103
+	OpImageQuerySize                       Opcode = 104
+	OpImageQueryLod                        Opcode = 105
+	OpImageQueryLevels                     Opcode = 106
+	OpImageQuerySamples                    Opcode = 107
+	OpConvertFToU                          Opcode = 109
+	OpConvertFToS                          Opcode = 110
+	OpConvertSToF                          Opcode = 111
+	OpConvertUToF                          Opcode = 112
+	OpUConvert                             Opcode = 113
+	OpSConvert                             Opcode = 114
+	OpFConvert                             Opcode = 115
+	OpQuantizeToF16                        Opcode = 116
+	OpConvertPtrToU                        Opcode = 117
+	OpSatConvertSToU                       Opcode = 118
+	OpSatConvertUToS                       Opcode = 119
+	OpConvertUToPtr                        Opcode = 120
+	OpPtrCastToGeneric                     Opcode = 121
+	OpGenericCastToPtr                     Opcode = 122
+	OpGenericCastToPtrExplicit             Opcode = 123
+	OpBitcast                              Opcode = 124
+	OpSNegate                              Opcode = 126
+	OpFNegate                              Opcode = 127
+	OpIAdd                                 Opcode = 128
+	OpFAdd                                 Opcode = 129
+	OpISub                                 Opcode = 130
+	OpFSub                                 Opcode = 131
+	OpIMul                                 Opcode = 132
+	OpFMul                                 Opcode = 133
+	OpUDiv                                 Opcode = 134
+	OpSDiv                                 Opcode = 135
+	OpFDiv                                 Opcode = 136
+	OpUMod                                 Opcode = 137
+	OpSRem                                 Opcode = 138
+	OpSMod                                 Opcode = 139
+	OpFRem                                 Opcode = 140
+	OpFMod                                 Opcode = 141
+	OpVectorTimesScalar                    Opcode = 142
+	OpMatrixTimesScalar                    Opcode = 143
+	OpVectorTimesMatrix                    Opcode = 144
+	OpMatrixTimesVector                    Opcode = 145
+	OpMatrixTimesMatrix                    Opcode = 146
+	OpOuterProduct                         Opcode = 147
+	OpDot                                  Opcode = 148
+	OpIAddCarry                            Opcode = 149
+	OpISubBorrow                           Opcode = 150
+	OpUMulExtended                         Opcode = 151
+	OpSMulExtended                         Opcode = 152
+	OpAny                                  Opcode = 154
+	OpAll                                  Opcode = 155
+	OpIsNan                                Opcode = 156
+	OpIsInf                                Opcode = 157
+	OpIsFinite                             Opcode = 158
+	OpIsNormal                             Opcode = 159
+	OpSignBitSet                           Opcode = 160
+	OpLessOrGreater                        Opcode = 161
+	OpOrdered                              Opcode = 162
+	OpUnordered                            Opcode = 163
+	OpLogicalEqual                         Opcode = 164
+	OpLogicalNotEqual                      Opcode = 165
+	OpLogicalOr                            Opcode = 166
+	OpLogicalAnd                           Opcode = 167
+	OpLogicalNot                           Opcode = 168
+	OpSelect                               Opcode = 169
+	OpIEqual                               Opcode = 170
+	OpINotEqual                            Opcode = 171
+	OpUGreaterThan                         Opcode = 172
+	OpSGreaterThan                         Opcode = 173
+	OpUGreaterThanEqual                    Opcode = 174
+	OpSGreaterThanEqual                    Opcode = 175
+	OpULessThan                            Opcode = 176
+	OpSLessThan                            Opcode = 177
+	OpULessThanEqual                       Opcode = 178
+	OpSLessThanEqual                       Opcode = 179
+	OpFOrdEqual                            Opcode = 180
+	OpFUnordEqual                          Opcode = 181
+	OpFOrdNotEqual                         Opcode = 182
+	OpFUnordNotEqual                       Opcode = 183
+	OpFOrdLessThan                         Opcode = 184
+	OpFUnordLessThan                       Opcode = 185
+	OpFOrdGreaterThan                      Opcode = 186
+	OpFUnordGreaterThan                    Opcode = 187
+	OpFOrdLessThanEqual                    Opcode = 188
+	OpFUnordLessThanEqual                  Opcode = 189
+	OpFOrdGreaterThanEqual                 Opcode = 190
+	OpFUnordGreaterThanEqual               Opcode = 191
+	OpShiftRightLogical                    Opcode = 194
+	OpShiftRightArithmetic                 Opcode = 195
+	OpShiftLeftLogical                     Opcode = 196
+	OpBitwiseOr                            Opcode = 197
+	OpBitwiseXor                           Opcode = 198
+	OpBitwiseAnd                           Opcode = 199
+	OpNot                                  Opcode = 200
+	OpBitFieldInsert                       Opcode = 201
+	OpBitFieldSExtract                     Opcode = 202
+	OpBitFieldUExtract                     Opcode = 203
+	OpBitReverse                           Opcode = 204
+	OpBitCount                             Opcode = 205
+	OpDPdx                                 Opcode = 207
+	OpDPdy                                 Opcode = 208
+	OpFwidth                               Opcode = 209
+	OpDPdxFine                             Opcode = 210
+	OpDPdyFine                             Opcode = 211
+	OpFwidthFine                           Opcode = 212
+	OpDPdxCoarse                           Opcode = 213
+	OpDPdyCoarse                           Opcode = 214
+	OpFwidthCoarse                         Opcode = 215
+	OpEmitVertex                           Opcode = 218
+	OpEndPrimitive                         Opcode = 219
+	OpEmitStreamVertex                     Opcode = 220
+	OpEndStreamPrimitive                   Opcode = 221
+	OpControlBarrier                       Opcode = 224
+	OpMemoryBarrier                        Opcode = 225
+	OpAtomicLoad                           Opcode = 227
+	OpAtomicStore                          Opcode = 228
+	OpAtomicExchange                       Opcode = 229
+	OpAtomicCompareExchange                Opcode = 230
+	OpAtomicCompareExchangeWeak            Opcode = 231
+	OpAtomicIIncrement                     Opcode = 232
+	OpAtomicIDecrement                     Opcode = 233
+	OpAtomicIAdd                           Opcode = 234
+	OpAtomicISub                           Opcode = 235
+	OpAtomicSMin                           Opcode = 236
+	OpAtomicUMin                           Opcode = 237
+	OpAtomicSMax                           Opcode = 238
+	OpAtomicUMax                           Opcode = 239
+	OpAtomicAnd                            Opcode = 240
+	OpAtomicOr                             Opcode = 241
+	OpAtomicXor                            Opcode = 242
+	OpPhi                                  Opcode = 245
+	OpLoopMerge                            Opcode = 246
+	OpSelectionMerge                       Opcode = 247
+	OpLabel                                Opcode = 248
+	OpBranch                               Opcode = 249
+	OpBranchConditional                    Opcode = 250
+	OpSwitch                               Opcode = 251
+	OpKill                                 Opcode = 252
+	OpReturn                               Opcode = 253
+	OpReturnValue                          Opcode = 254
+	OpUnreachable                          Opcode = 255
+	OpLifetimeStart                        Opcode = 256
+	OpLifetimeStop                         Opcode = 257
+	OpImageSparseSampleImplicitLod         Opcode = 305
+	OpImageSparseSampleExplicitLod         Opcode = 306
+	OpImageSparseSampleDrefImplicitLod     Opcode = 307
+	OpImageSparseSampleDrefExplicitLod     Opcode = 308
+	OpImageSparseSampleProjImplicitLod     Opcode = 309
+	OpImageSparseSampleProjExplicitLod     Opcode = 310
+	OpImageSparseSampleProjDrefImplicitLod Opcode = 311
+	OpImageSparseSampleProjDrefExplicitLod Opcode = 312
+	OpImageSparseFetch                     Opcode = 313
+	OpImageSparseGather                    Opcode = 314
+	OpImageSparseDrefGather                Opcode = 315
+	OpImageSparseTexelsResident            Opcode = 316
+	OpNoLine                               Opcode = 317
+	OpImageSparseRead                      Opcode = 320
+	OpModuleProcessed                      Opcode = 330
+	OpDecorateID                           Opcode = 332
+	OpGroupNonUniformElect                 Opcode = 333
+	OpGroupNonUniformAll                   Opcode = 334
+	OpGroupNonUniformAny                   Opcode = 335
+	OpGroupNonUniformAllEqual              Opcode = 336
+	OpGroupNonUniformBroadcast             Opcode = 337
+	OpGroupNonUniformBroadcastFirst        Opcode = 338
+	OpGroupNonUniformBallot                Opcode = 339
+	OpGroupNonUniformIAdd                  Opcode = 349
+	OpGroupNonUniformFAdd                  Opcode = 350
+	OpGroupNonUniformIMul                  Opcode = 351
+	OpGroupNonUniformFMul                  Opcode = 352
+	OpGroupNonUniformSMin                  Opcode = 353
+	OpGroupNonUniformUMin                  Opcode = 354
+	OpGroupNonUniformFMin                  Opcode = 355
+	OpGroupNonUniformSMax                  Opcode = 356
+	OpGroupNonUniformUMax                  Opcode = 357
+	OpGroupNonUniformFMax                  Opcode = 358
+)
+
+// String returns the name of the opcode, as in the SPIR-V specification.
+func (o Opcode) String() string {
+	if info, ok := instructions[o]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("OpUnknown(%d)", uint16(o))
+}
@@ -0,0 +1,348 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DescriptorType is the type of descriptor used by a descriptor binding.
+type DescriptorType int
+
+// The types of descriptors.
+const (
+	DescriptorSampler DescriptorType = iota
+	DescriptorCombinedImageSampler
+	DescriptorSampledImage
+	DescriptorStorageImage
+	DescriptorUniformTexelBuffer
+	DescriptorStorageTexelBuffer
+	DescriptorUniformBuffer
+	DescriptorStorageBuffer
+	DescriptorInputAttachment
+)
+
+func (t DescriptorType) String() string {
+	switch t {
+	case DescriptorSampler:
+		return "sampler"
+	case DescriptorCombinedImageSampler:
+		return "combined image sampler"
+	case DescriptorSampledImage:
+		return "sampled image"
+	case DescriptorStorageImage:
+		return "storage image"
+	case DescriptorUniformTexelBuffer:
+		return "uniform texel buffer"
+	case DescriptorStorageTexelBuffer:
+		return "storage texel buffer"
+	case DescriptorUniformBuffer:
+		return "uniform buffer"
+	case DescriptorStorageBuffer:
+		return "storage buffer"
+	case DescriptorInputAttachment:
+		return "input attachment"
+	default:
+		return fmt.Sprintf("DescriptorType(%d)", int(t))
+	}
+}
+
+// InterfaceVariable is an input or output variable of an entry point. The
+// members of the blocks of built-ins, such as gl_PerVertex, are separate
+// variables.
+type InterfaceVariable struct {
+	// ID is the id of the variable.
+	ID   ID
+	Name string
+	Type *Type
+	// Location is the location of the variable, or -1 for built-ins.
+	Location int32
+	// BuiltIn is the built-in of the variable, if IsBuiltIn is true.
+	BuiltIn   BuiltIn
+	IsBuiltIn bool
+}
+
+// DescriptorBinding is a resource variable bound to a descriptor set.
+type DescriptorBinding struct {
+	ID      ID
+	Name    string
+	Type    *Type
+	Set     uint32
+	Binding uint32
+	// DescriptorType is the type of the descriptors of the binding.
+	DescriptorType DescriptorType
+	// Count is the number of descriptors of the binding, 0 for runtime sized
+	// arrays.
+	Count uint32
+	// Accessed is true if the entry point statically uses the variable.
+	Accessed bool
+}
+
+// PushConstantBlock is the push constant block of a module.
+type PushConstantBlock struct {
+	ID   ID
+	Name string
+	Type *Type
+	// Offset and Size are the range of the members of the block.
+	Offset uint32
+	Size   uint32
+	// Accessed is true if the entry point statically uses the block.
+	Accessed bool
+}
+
+// SpecConstant is a specialization constant of a module.
+type SpecConstant struct {
+	ID     ID
+	SpecID uint32
+	Name   string
+	Type   *Type
+	// Default is the default value of the constant.
+	Default uint64
+}
+
+// Reflection is the interface of an entry point of a module.
+type Reflection struct {
+	EntryPoint EntryPoint
+	// Inputs and Outputs are sorted by location, with the built-ins last.
+	Inputs  []InterfaceVariable
+	Outputs []InterfaceVariable
+	// DescriptorBindings are the descriptor bindings of the module, sorted by
+	// set and binding.
+	DescriptorBindings []DescriptorBinding
+	PushConstants      []PushConstantBlock
+	// SpecConstants are the specialization constants, sorted by id.
+	SpecConstants []SpecConstant
+}
+
+// FindEntryPoint returns the entry point with the name and execution model.
+func (m *Module) FindEntryPoint(name string, model ExecutionModel) (EntryPoint, bool) {
+	for _, e := range m.EntryPoints {
+		if e.Name == name && e.Model == model {
+			return e, true
+		}
+	}
+	return EntryPoint{}, false
+}
+
+// Reflect returns the interface of the entry point with the name and
+// execution model. The descriptor bindings and push constants are all those
+// declared by the module, flagged if the entry point statically uses them.
+func (m *Module) Reflect(name string, model ExecutionModel) (*Reflection, error) {
+	e, ok := m.FindEntryPoint(name, model)
+	if !ok {
+		return nil, fmt.Errorf("Entry point %v '%v' not found", model, name)
+	}
+	accessed := m.accessed(e.Function)
+	interfaces := map[ID]bool{}
+	for _, id := range e.Interface {
+		interfaces[id] = true
+	}
+
+	out := &Reflection{EntryPoint: e}
+	for _, v := range m.Variables {
+		if v.Type == nil {
+			continue
+		}
+		switch v.StorageClass {
+		case StorageInput:
+			if interfaces[v.ID] {
+				out.Inputs = append(out.Inputs, m.interfaceVariables(v)...)
+			}
+		case StorageOutput:
+			if interfaces[v.ID] {
+				out.Outputs = append(out.Outputs, m.interfaceVariables(v)...)
+			}
+		case StorageUniformConstant, StorageUniform, StorageStorageBuffer:
+			if b, ok := m.descriptorBinding(v); ok {
+				b.Accessed = accessed[v.ID]
+				out.DescriptorBindings = append(out.DescriptorBindings, b)
+			}
+		case StoragePushConstant:
+			name := m.Names[v.ID]
+			if name == "" {
+				name = v.Type.Name
+			}
+			offset, size := m.MembersRange(v.Type)
+			out.PushConstants = append(out.PushConstants, PushConstantBlock{
+				ID:       v.ID,
+				Name:     name,
+				Type:     v.Type,
+				Offset:   offset,
+				Size:     size,
+				Accessed: accessed[v.ID],
+			})
+		}
+	}
+
+	for id, d := range m.Decorations {
+		c, ok := m.Constants[id]
+		if !ok {
+			continue
+		}
+		if specID, ok := d.Get(DecorationSpecID); ok {
+			out.SpecConstants = append(out.SpecConstants, SpecConstant{
+				ID:      id,
+				SpecID:  specID,
+				Name:    m.Names[id],
+				Type:    c.Type,
+				Default: c.Uint(),
+			})
+		}
+	}
+
+	for _, vars := range [][]InterfaceVariable{out.Inputs, out.Outputs} {
+		sort.SliceStable(vars, func(i, j int) bool {
+			// The built-ins have a location of -1, placing them last.
+			return uint32(vars[i].Location) < uint32(vars[j].Location)
+		})
+	}
+	b := out.DescriptorBindings
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].Set != b[j].Set {
+			return b[i].Set < b[j].Set
+		}
+		return b[i].Binding < b[j].Binding
+	})
+	s := out.SpecConstants
+	sort.Slice(s, func(i, j int) bool { return s[i].SpecID < s[j].SpecID })
+	return out, nil
+}
+
+// interfaceVariables returns the input or output variables of the variable v.
+func (m *Module) interfaceVariables(v *Variable) []InterfaceVariable {
+	t := v.Type
+	if t.Opcode == OpTypeStruct && m.Decorations[t.ID].Has(DecorationBlock) {
+		builtIns := false
+		for _, d := range m.MemberDecorations[t.ID] {
+			builtIns = builtIns || d.Has(DecorationBuiltIn)
+		}
+		if builtIns {
+			out := make([]InterfaceVariable, len(t.Members))
+			for i, member := range t.Members {
+				out[i] = m.interfaceVariable(v.ID, m.MemberNames[t.ID][uint32(i)], member,
+					m.MemberDecorations[t.ID][uint32(i)])
+			}
+			return out
+		}
+	}
+	return []InterfaceVariable{m.interfaceVariable(v.ID, m.Names[v.ID], t, m.Decorations[v.ID])}
+}
+
+func (m *Module) interfaceVariable(id ID, name string, t *Type, d Decorations) InterfaceVariable {
+	out := InterfaceVariable{ID: id, Name: name, Type: t, Location: -1}
+	if b, ok := d.Get(DecorationBuiltIn); ok {
+		out.BuiltIn, out.IsBuiltIn = BuiltIn(b), true
+	} else if l, ok := d.Get(DecorationLocation); ok {
+		out.Location = int32(l)
+	}
+	return out
+}
+
+// descriptorBinding returns the descriptor binding of the variable v, if it
+// is decorated with a descriptor set and binding.
+func (m *Module) descriptorBinding(v *Variable) (DescriptorBinding, bool) {
+	d := m.Decorations[v.ID]
+	set, hasSet := d.Get(DecorationDescriptorSet)
+	binding, hasBinding := d.Get(DecorationBinding)
+	if !hasSet || !hasBinding {
+		return DescriptorBinding{}, false
+	}
+	out := DescriptorBinding{
+		ID:      v.ID,
+		Name:    m.Names[v.ID],
+		Type:    v.Type,
+		Set:     set,
+		Binding: binding,
+		Count:   1,
+	}
+	elem := v.Type
+	switch elem.Opcode {
+	case OpTypeArray:
+		out.Count, elem = elem.Count, elem.Elem
+	case OpTypeRuntimeArray:
+		out.Count, elem = 0, elem.Elem
+	}
+	switch elem.Opcode {
+	case OpTypeSampler:
+		out.DescriptorType = DescriptorSampler
+	case OpTypeSampledImage:
+		out.DescriptorType = DescriptorCombinedImageSampler
+	case OpTypeImage:
+		switch storage := elem.Sampled == 2; {
+		case elem.Dim == DimBuffer && storage:
+			out.DescriptorType = DescriptorStorageTexelBuffer
+		case elem.Dim == DimBuffer:
+			out.DescriptorType = DescriptorUniformTexelBuffer
+		case elem.Dim == DimSubpassData:
+			out.DescriptorType = DescriptorInputAttachment
+		case storage:
+			out.DescriptorType = DescriptorStorageImage
+		default:
+			out.DescriptorType = DescriptorSampledImage
+		}
+	case OpTypeStruct:
+		if v.StorageClass == StorageStorageBuffer || m.Decorations[elem.ID].Has(DecorationBufferBlock) {
+			out.DescriptorType = DescriptorStorageBuffer
+		} else {
+			out.DescriptorType = DescriptorUniformBuffer
+		}
+	default:
+		return DescriptorBinding{}, false
+	}
+	return out, true
+}
+
+// accessed returns the ids referenced by the function and by the functions it
+// calls, directly or not.
+func (m *Module) accessed(function ID) map[ID]bool {
+	refs := map[ID][]ID{}
+	current := ID(0)
+	for _, inst := range m.Instructions {
+		switch inst.Opcode {
+		case OpFunction:
+			current = inst.Result()
+			continue
+		case OpFunctionEnd:
+			current = 0
+			continue
+		}
+		if current == 0 {
+			continue
+		}
+		for _, op := range decodeOperands(inst, m) {
+			refs[current] = append(refs[current], op.ids()...)
+		}
+	}
+
+	out := map[ID]bool{}
+	pending := []ID{function}
+	for len(pending) > 0 {
+		f := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if out[f] {
+			continue
+		}
+		out[f] = true
+		for _, id := range refs[f] {
+			if _, isFunction := refs[id]; isFunction && !out[id] {
+				pending = append(pending, id)
+			} else {
+				out[id] = true
+			}
+		}
+	}
+	return out
+}
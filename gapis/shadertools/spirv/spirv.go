@@ -0,0 +1,406 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spirv decodes, reflects and disassembles SPIR-V modules in pure Go,
+// without depending on the native SPIR-V tools.
+package spirv
+
+import (
+	"fmt"
+)
+
+// Magic is the first word of a SPIR-V module.
+const Magic = 0x07230203
+
+// ID is the identifier of a result of a SPIR-V module.
+type ID uint32
+
+// StorageClass is the storage class of a pointer or variable.
+type StorageClass uint32
+
+// The storage classes used by the reflection.
+const (
+	StorageUniformConstant StorageClass = 0
+	StorageInput           StorageClass = 1
+	StorageUniform         StorageClass = 2
+	StorageOutput          StorageClass = 3
+	StorageFunction        StorageClass = 7
+	StoragePushConstant    StorageClass = 9
+	StorageStorageBuffer   StorageClass = 12
+)
+
+func (c StorageClass) String() string { return enums["StorageClass"].format(uint32(c)) }
+
+// Decoration is the kind of a decoration of an id or struct member.
+type Decoration uint32
+
+// The decorations used by the reflection.
+const (
+	DecorationSpecID               Decoration = 1
+	DecorationBlock                Decoration = 2
+	DecorationBufferBlock          Decoration = 3
+	DecorationArrayStride          Decoration = 6
+	DecorationMatrixStride         Decoration = 7
+	DecorationBuiltIn              Decoration = 11
+	DecorationLocation             Decoration = 30
+	DecorationBinding              Decoration = 33
+	DecorationDescriptorSet        Decoration = 34
+	DecorationOffset               Decoration = 35
+	DecorationInputAttachmentIndex Decoration = 43
+)
+
+func (d Decoration) String() string { return enums["Decoration"].format(uint32(d)) }
+
+// BuiltIn is the built-in of a variable decorated with DecorationBuiltIn.
+type BuiltIn uint32
+
+func (b BuiltIn) String() string { return enums["BuiltIn"].format(uint32(b)) }
+
+// ExecutionModel is the execution model of an entry point.
+type ExecutionModel uint32
+
+// The execution models of the graphics and compute shaders.
+const (
+	ExecutionModelVertex                 ExecutionModel = 0
+	ExecutionModelTessellationControl    ExecutionModel = 1
+	ExecutionModelTessellationEvaluation ExecutionModel = 2
+	ExecutionModelGeometry               ExecutionModel = 3
+	ExecutionModelFragment               ExecutionModel = 4
+	ExecutionModelGLCompute              ExecutionModel = 5
+)
+
+func (m ExecutionModel) String() string { return enums["ExecutionModel"].format(uint32(m)) }
+
+// Dim is the dimensionality of an image type.
+type Dim uint32
+
+// The image dimensionalities.
+const (
+	Dim1D          Dim = 0
+	Dim2D          Dim = 1
+	Dim3D          Dim = 2
+	DimCube        Dim = 3
+	DimRect        Dim = 4
+	DimBuffer      Dim = 5
+	DimSubpassData Dim = 6
+)
+
+func (d Dim) String() string { return enums["Dim"].format(uint32(d)) }
+
+// Header is the header of a SPIR-V module.
+type Header struct {
+	// Version is the SPIR-V version, with the major version in the bits 16-23
+	// and the minor version in the bits 8-15.
+	Version uint32
+	// Generator is the vendor of the generator in the upper 16 bits, and its
+	// version in the lower 16 bits.
+	Generator uint32
+	// Bound is the upper bound of the ids of the module.
+	Bound  uint32
+	Schema uint32
+}
+
+// Instruction is a decoded SPIR-V instruction.
+type Instruction struct {
+	Opcode Opcode
+	// Operands are the words of the instruction following the opcode.
+	Operands []uint32
+	// Offset is the index of the first word of the instruction in the module.
+	Offset int
+}
+
+// ResultType returns the id of the result type of the instruction, or 0 if
+// the instruction has no result type.
+func (i Instruction) ResultType() ID {
+	info, ok := instructions[i.Opcode]
+	if ok && len(info.operands) > 0 && info.operands[0].kind == kindResultType && len(i.Operands) > 0 {
+		return ID(i.Operands[0])
+	}
+	return 0
+}
+
+// Result returns the result id of the instruction, or 0 if the instruction
+// has no result.
+func (i Instruction) Result() ID {
+	info, ok := instructions[i.Opcode]
+	if !ok {
+		return 0
+	}
+	for j, op := range info.operands {
+		if j >= len(i.Operands) || j > 1 {
+			break
+		}
+		if op.kind == kindResult {
+			return ID(i.Operands[j])
+		}
+	}
+	return 0
+}
+
+// DecorationValue is a decoration with its literal operands.
+type DecorationValue struct {
+	Decoration Decoration
+	Operands   []uint32
+}
+
+// Decorations are the decorations of an id or of a struct member.
+type Decorations []DecorationValue
+
+// Get returns the first operand of the decoration d, and whether the
+// decoration is present. The operand is 0 if the decoration has none.
+func (l Decorations) Get(d Decoration) (uint32, bool) {
+	for _, v := range l {
+		if v.Decoration == d {
+			if len(v.Operands) > 0 {
+				return v.Operands[0], true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// Has returns true if the decoration d is present.
+func (l Decorations) Has(d Decoration) bool {
+	_, ok := l.Get(d)
+	return ok
+}
+
+// EntryPoint is an entry point of a module.
+type EntryPoint struct {
+	Model    ExecutionModel
+	Function ID
+	Name     string
+	// Interface are the ids of the global variables of the interface of the
+	// entry point.
+	Interface []ID
+}
+
+// Constant is a constant or specialization constant of a module.
+type Constant struct {
+	ID     ID
+	Type   *Type
+	Opcode Opcode
+	// Value are the literal words of the value of scalar constants.
+	Value []uint32
+	// Constituents are the ids of the constituents of composite constants.
+	Constituents []ID
+}
+
+// Uint returns the value of the scalar constant as an unsigned integer.
+func (c *Constant) Uint() uint64 {
+	switch {
+	case c.Opcode == OpConstantTrue || c.Opcode == OpSpecConstantTrue:
+		return 1
+	case len(c.Value) == 1:
+		return uint64(c.Value[0])
+	case len(c.Value) >= 2:
+		return uint64(c.Value[0]) | uint64(c.Value[1])<<32
+	default:
+		return 0
+	}
+}
+
+// Variable is a global variable of a module.
+type Variable struct {
+	ID ID
+	// Type is the type pointed by the type of the variable.
+	Type         *Type
+	StorageClass StorageClass
+}
+
+// Module is a decoded SPIR-V module.
+type Module struct {
+	Header
+	Instructions []Instruction
+	Names        map[ID]string
+	MemberNames  map[ID]map[uint32]string
+	Decorations  map[ID]Decorations
+	// MemberDecorations are the decorations of the members of the structs.
+	MemberDecorations map[ID]map[uint32]Decorations
+	Types             map[ID]*Type
+	Constants         map[ID]*Constant
+	Variables         []*Variable
+	EntryPoints       []EntryPoint
+	// ExtInstImports are the names of the imported extended instruction sets.
+	ExtInstImports map[ID]string
+}
+
+// Decode decodes the SPIR-V module words.
+func Decode(words []uint32) (*Module, error) {
+	if len(words) < 5 {
+		return nil, fmt.Errorf("SPIR-V module too short: %d words", len(words))
+	}
+	if words[0] != Magic {
+		return nil, fmt.Errorf("Invalid SPIR-V magic number: 0x%08x", words[0])
+	}
+	m := &Module{
+		Header: Header{
+			Version:   words[1],
+			Generator: words[2],
+			Bound:     words[3],
+			Schema:    words[4],
+		},
+		Names:             map[ID]string{},
+		MemberNames:       map[ID]map[uint32]string{},
+		Decorations:       map[ID]Decorations{},
+		MemberDecorations: map[ID]map[uint32]Decorations{},
+		Types:             map[ID]*Type{},
+		Constants:         map[ID]*Constant{},
+		ExtInstImports:    map[ID]string{},
+	}
+	for i := 5; i < len(words); {
+		count, op := int(words[i]>>16), Opcode(words[i]&0xffff)
+		if count == 0 || i+count > len(words) {
+			return nil, fmt.Errorf("Invalid word count %d of %v at word %d", count, op, i)
+		}
+		inst := Instruction{Opcode: op, Operands: words[i+1 : i+count], Offset: i}
+		if err := m.add(inst); err != nil {
+			return nil, fmt.Errorf("%v at word %d: %v", op, i, err)
+		}
+		m.Instructions = append(m.Instructions, inst)
+		i += count
+	}
+	return m, nil
+}
+
+// Type returns the type of the id, creating it if it is not declared yet, as
+// for forward references.
+func (m *Module) Type(id ID) *Type {
+	t, ok := m.Types[id]
+	if !ok {
+		t = &Type{ID: id}
+		m.Types[id] = t
+	}
+	return t
+}
+
+// add adds the declarations of the instruction to the module.
+func (m *Module) add(inst Instruction) error {
+	args := inst.Operands
+	need := func(n int) error {
+		if len(args) < n {
+			return fmt.Errorf("expected %d operands, got %d", n, len(args))
+		}
+		return nil
+	}
+	switch op := inst.Opcode; op {
+	case OpName:
+		if err := need(1); err != nil {
+			return err
+		}
+		m.Names[ID(args[0])], _ = literalString(args[1:])
+
+	case OpMemberName:
+		if err := need(2); err != nil {
+			return err
+		}
+		if m.MemberNames[ID(args[0])] == nil {
+			m.MemberNames[ID(args[0])] = map[uint32]string{}
+		}
+		m.MemberNames[ID(args[0])][args[1]], _ = literalString(args[2:])
+
+	case OpExtInstImport:
+		if err := need(1); err != nil {
+			return err
+		}
+		m.ExtInstImports[ID(args[0])], _ = literalString(args[1:])
+
+	case OpEntryPoint:
+		if err := need(2); err != nil {
+			return err
+		}
+		name, n := literalString(args[2:])
+		e := EntryPoint{Model: ExecutionModel(args[0]), Function: ID(args[1]), Name: name}
+		for _, id := range args[2+n:] {
+			e.Interface = append(e.Interface, ID(id))
+		}
+		m.EntryPoints = append(m.EntryPoints, e)
+
+	case OpDecorate:
+		if err := need(2); err != nil {
+			return err
+		}
+		id := ID(args[0])
+		m.Decorations[id] = append(m.Decorations[id], DecorationValue{Decoration(args[1]), args[2:]})
+
+	case OpMemberDecorate:
+		if err := need(3); err != nil {
+			return err
+		}
+		id := ID(args[0])
+		if m.MemberDecorations[id] == nil {
+			m.MemberDecorations[id] = map[uint32]Decorations{}
+		}
+		d := DecorationValue{Decoration(args[2]), args[3:]}
+		m.MemberDecorations[id][args[1]] = append(m.MemberDecorations[id][args[1]], d)
+
+	case OpTypeVoid, OpTypeBool, OpTypeInt, OpTypeFloat, OpTypeVector, OpTypeMatrix,
+		OpTypeImage, OpTypeSampler, OpTypeSampledImage, OpTypeArray, OpTypeRuntimeArray,
+		OpTypeStruct, OpTypeOpaque, OpTypePointer, OpTypeFunction:
+		if err := need(1); err != nil {
+			return err
+		}
+		return m.addType(op, m.Type(ID(args[0])), args[1:])
+
+	case OpConstantTrue, OpConstantFalse, OpConstant, OpConstantComposite, OpConstantNull,
+		OpSpecConstantTrue, OpSpecConstantFalse, OpSpecConstant, OpSpecConstantComposite:
+		if err := need(2); err != nil {
+			return err
+		}
+		c := &Constant{ID: ID(args[1]), Type: m.Type(ID(args[0])), Opcode: op}
+		switch op {
+		case OpConstant, OpSpecConstant:
+			c.Value = args[2:]
+		case OpConstantComposite, OpSpecConstantComposite:
+			for _, id := range args[2:] {
+				c.Constituents = append(c.Constituents, ID(id))
+			}
+		}
+		m.Constants[c.ID] = c
+
+	case OpVariable:
+		if err := need(3); err != nil {
+			return err
+		}
+		ptr := m.Type(ID(args[0]))
+		if ptr.Opcode != OpTypePointer {
+			return fmt.Errorf("%v is not a pointer type", ID(args[0]))
+		}
+		if StorageClass(args[2]) != StorageFunction {
+			m.Variables = append(m.Variables, &Variable{
+				ID:           ID(args[1]),
+				Type:         ptr.Elem,
+				StorageClass: StorageClass(args[2]),
+			})
+		}
+	}
+	return nil
+}
+
+// literalString returns the literal string at the start of words, and the
+// number of words it uses.
+func literalString(words []uint32) (string, int) {
+	b := []byte{}
+	for i, w := range words {
+		for j := uint(0); j < 4; j++ {
+			c := byte(w >> (8 * j))
+			if c == 0 {
+				return string(b), i + 1
+			}
+			b = append(b, c)
+		}
+	}
+	return string(b), len(words)
+}
@@ -0,0 +1,339 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv_test
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/gapis/shadertools/spirv"
+)
+
+// vertexShader is compiled from:
+//
+//	#version 450
+//	layout(location=0) in vec3 position;
+//	void main() {
+//		gl_Position = vec4(position, 1.0);
+//	}
+var vertexShader = []uint32{
+	0x07230203, 0x00010000, 0x00070000, 0x0000001b, 0x00000000, 0x00020011,
+	0x00000001, 0x0006000b, 0x00000001, 0x4c534c47, 0x6474732e, 0x3035342e,
+	0x00000000, 0x0003000e, 0x00000000, 0x00000001, 0x0007000f, 0x00000000,
+	0x00000002, 0x6e69616d, 0x00000000, 0x00000003, 0x00000004, 0x00030003,
+	0x00000002, 0x000001c2, 0x00040005, 0x00000002, 0x6e69616d, 0x00000000,
+	0x00060005, 0x00000005, 0x505f6c67, 0x65567265, 0x78657472, 0x00000000,
+	0x00060006, 0x00000005, 0x00000000, 0x505f6c67, 0x7469736f, 0x006e6f69,
+	0x00070006, 0x00000005, 0x00000001, 0x505f6c67, 0x746e696f, 0x657a6953,
+	0x00000000, 0x00070006, 0x00000005, 0x00000002, 0x435f6c67, 0x4470696c,
+	0x61747369, 0x0065636e, 0x00070006, 0x00000005, 0x00000003, 0x435f6c67,
+	0x446c6c75, 0x61747369, 0x0065636e, 0x00030005, 0x00000003, 0x00000000,
+	0x00050005, 0x00000004, 0x69736f70, 0x6e6f6974, 0x00000000, 0x00050048,
+	0x00000005, 0x00000000, 0x0000000b, 0x00000000, 0x00050048, 0x00000005,
+	0x00000001, 0x0000000b, 0x00000001, 0x00050048, 0x00000005, 0x00000002,
+	0x0000000b, 0x00000003, 0x00050048, 0x00000005, 0x00000003, 0x0000000b,
+	0x00000004, 0x00030047, 0x00000005, 0x00000002, 0x00040047, 0x00000004,
+	0x0000001e, 0x00000000, 0x00020013, 0x00000006, 0x00030021, 0x00000007,
+	0x00000006, 0x00030016, 0x00000008, 0x00000020, 0x00040017, 0x00000009,
+	0x00000008, 0x00000004, 0x00040015, 0x0000000a, 0x00000020, 0x00000000,
+	0x0004002b, 0x0000000a, 0x0000000b, 0x00000001, 0x0004001c, 0x0000000c,
+	0x00000008, 0x0000000b, 0x0006001e, 0x00000005, 0x00000009, 0x00000008,
+	0x0000000c, 0x0000000c, 0x00040020, 0x0000000d, 0x00000003, 0x00000005,
+	0x0004003b, 0x0000000d, 0x00000003, 0x00000003, 0x00040015, 0x0000000e,
+	0x00000020, 0x00000001, 0x0004002b, 0x0000000e, 0x0000000f, 0x00000000,
+	0x00040017, 0x00000010, 0x00000008, 0x00000003, 0x00040020, 0x00000011,
+	0x00000001, 0x00000010, 0x0004003b, 0x00000011, 0x00000004, 0x00000001,
+	0x0004002b, 0x00000008, 0x00000012, 0x3f800000, 0x00040020, 0x00000013,
+	0x00000003, 0x00000009, 0x00050036, 0x00000006, 0x00000002, 0x00000000,
+	0x00000007, 0x000200f8, 0x00000014, 0x0004003d, 0x00000010, 0x00000015,
+	0x00000004, 0x00050051, 0x00000008, 0x00000016, 0x00000015, 0x00000000,
+	0x00050051, 0x00000008, 0x00000017, 0x00000015, 0x00000001, 0x00050051,
+	0x00000008, 0x00000018, 0x00000015, 0x00000002, 0x00070050, 0x00000009,
+	0x00000019, 0x00000016, 0x00000017, 0x00000018, 0x00000012, 0x00050041,
+	0x00000013, 0x0000001a, 0x00000003, 0x0000000f, 0x0003003e, 0x0000001a,
+	0x00000019, 0x000100fd, 0x00010038,
+}
+
+// fragmentShader is compiled from:
+//
+//	#version 450
+//	precision highp int;
+//	precision highp float;
+//	out float gl_FragDepth;
+//	layout(input_attachment_index = 0, binding = 0, set = 0) uniform usubpassInput in_depth;
+//	void main() {
+//		gl_FragDepth = subpassLoad(in_depth).r / 16777215.0;
+//	}
+var fragmentShader = []uint32{
+	0x07230203, 0x00010000, 0x00070000, 0x00000019, 0x00000000, 0x00020011,
+	0x00000001, 0x00020011, 0x00000028, 0x0006000b, 0x00000001, 0x4c534c47,
+	0x6474732e, 0x3035342e, 0x00000000, 0x0003000e, 0x00000000, 0x00000001,
+	0x0006000f, 0x00000004, 0x00000002, 0x6e69616d, 0x00000000, 0x00000003,
+	0x00030010, 0x00000002, 0x00000007, 0x00030010, 0x00000002, 0x0000000c,
+	0x00030003, 0x00000002, 0x000001c2, 0x00040005, 0x00000002, 0x6e69616d,
+	0x00000000, 0x00060005, 0x00000003, 0x465f6c67, 0x44676172, 0x68747065,
+	0x00000000, 0x00050005, 0x00000004, 0x645f6e69, 0x68747065, 0x00000000,
+	0x00040047, 0x00000003, 0x0000000b, 0x00000016, 0x00040047, 0x00000004,
+	0x00000022, 0x00000000, 0x00040047, 0x00000004, 0x00000021, 0x00000000,
+	0x00040047, 0x00000004, 0x0000002b, 0x00000000, 0x00020013, 0x00000005,
+	0x00030021, 0x00000006, 0x00000005, 0x00030016, 0x00000007, 0x00000020,
+	0x00040020, 0x00000008, 0x00000003, 0x00000007, 0x0004003b, 0x00000008,
+	0x00000003, 0x00000003, 0x00040015, 0x00000009, 0x00000020, 0x00000000,
+	0x00090019, 0x0000000a, 0x00000009, 0x00000006, 0x00000000, 0x00000000,
+	0x00000000, 0x00000002, 0x00000000, 0x00040020, 0x0000000b, 0x00000000,
+	0x0000000a, 0x0004003b, 0x0000000b, 0x00000004, 0x00000000, 0x00040015,
+	0x0000000c, 0x00000020, 0x00000001, 0x0004002b, 0x0000000c, 0x0000000d,
+	0x00000000, 0x00040017, 0x0000000e, 0x0000000c, 0x00000002, 0x0005002c,
+	0x0000000e, 0x0000000f, 0x0000000d, 0x0000000d, 0x00040017, 0x00000010,
+	0x00000009, 0x00000004, 0x0004002b, 0x00000009, 0x00000011, 0x00000000,
+	0x0004002b, 0x00000007, 0x00000012, 0x4b7ffff0, 0x00050036, 0x00000005,
+	0x00000002, 0x00000000, 0x00000006, 0x000200f8, 0x00000013, 0x0004003d,
+	0x0000000a, 0x00000014, 0x00000004, 0x00050062, 0x00000010, 0x00000015,
+	0x00000014, 0x0000000f, 0x00050051, 0x00000009, 0x00000016, 0x00000015,
+	0x00000000, 0x00040070, 0x00000007, 0x00000017, 0x00000016, 0x00050088,
+	0x00000007, 0x00000018, 0x00000017, 0x00000012, 0x0003003e, 0x00000003,
+	0x00000018, 0x000100fd, 0x00010038,
+}
+
+// texturedShader is the SPIR-V of:
+//
+//	#version 450
+//	layout(binding = 0) uniform sampler2D tex;
+//	layout(location = 0) in vec2 uv;
+//	layout(location = 0) out vec4 color;
+//	void main() {
+//		color = texture(tex, uv);
+//	}
+var texturedShader = []uint32{
+	0x07230203, 0x00010000, 0x00070000, 0x00000014, 0x00000000, 0x00020011,
+	0x00000001, 0x0006000b, 0x00000001, 0x4c534c47, 0x6474732e, 0x3035342e,
+	0x00000000, 0x0003000e, 0x00000000, 0x00000001, 0x0007000f, 0x00000004,
+	0x00000004, 0x6e69616d, 0x00000000, 0x00000009, 0x00000010, 0x00030010,
+	0x00000004, 0x00000007, 0x00030003, 0x00000002, 0x000001c2, 0x00040005,
+	0x00000004, 0x6e69616d, 0x00000000, 0x00040005, 0x00000009, 0x6f6c6f63,
+	0x00000072, 0x00030005, 0x0000000d, 0x00786574, 0x00030005, 0x00000010,
+	0x00007675, 0x00040047, 0x00000009, 0x0000001e, 0x00000000, 0x00040047,
+	0x0000000d, 0x00000022, 0x00000000, 0x00040047, 0x0000000d, 0x00000021,
+	0x00000000, 0x00040047, 0x00000010, 0x0000001e, 0x00000000, 0x00020013,
+	0x00000002, 0x00030021, 0x00000003, 0x00000002, 0x00030016, 0x00000006,
+	0x00000020, 0x00040017, 0x00000007, 0x00000006, 0x00000004, 0x00040020,
+	0x00000008, 0x00000003, 0x00000007, 0x0004003b, 0x00000008, 0x00000009,
+	0x00000003, 0x00090019, 0x0000000a, 0x00000006, 0x00000001, 0x00000000,
+	0x00000000, 0x00000000, 0x00000001, 0x00000000, 0x0003001b, 0x0000000b,
+	0x0000000a, 0x00040020, 0x0000000c, 0x00000000, 0x0000000b, 0x0004003b,
+	0x0000000c, 0x0000000d, 0x00000000, 0x00040017, 0x0000000e, 0x00000006,
+	0x00000002, 0x00040020, 0x0000000f, 0x00000001, 0x0000000e, 0x0004003b,
+	0x0000000f, 0x00000010, 0x00000001, 0x00050036, 0x00000002, 0x00000004,
+	0x00000000, 0x00000003, 0x000200f8, 0x00000005, 0x0004003d, 0x0000000b,
+	0x00000011, 0x0000000d, 0x0004003d, 0x0000000e, 0x00000012, 0x00000010,
+	0x00050057, 0x00000007, 0x00000013, 0x00000011, 0x00000012, 0x0003003e,
+	0x00000009, 0x00000013, 0x000100fd, 0x00010038,
+}
+
+func TestDecode(t *testing.T) {
+	ctx := log.Testing(t)
+	m, err := spirv.Decode(vertexShader)
+	if !assert.For(ctx, "err").ThatError(err).Succeeded() {
+		return
+	}
+	assert.For(ctx, "version").That(m.Version).Equals(uint32(0x00010000))
+	assert.For(ctx, "bound").That(m.Bound).Equals(uint32(27))
+	assert.For(ctx, "instructions").ThatSlice(m.Instructions).IsLength(47)
+	assert.For(ctx, "first").That(m.Instructions[0].Opcode).Equals(spirv.OpCapability)
+	assert.For(ctx, "entry points").ThatSlice(m.EntryPoints).DeepEquals([]spirv.EntryPoint{{
+		Model:     spirv.ExecutionModelVertex,
+		Function:  2,
+		Name:      "main",
+		Interface: []spirv.ID{3, 4},
+	}})
+	assert.For(ctx, "name").ThatString(m.Names[4]).Equals("position")
+	assert.For(ctx, "member name").ThatString(m.MemberNames[5][1]).Equals("gl_PointSize")
+
+	for _, test := range []struct {
+		id       spirv.ID
+		expected string
+	}{
+		{5, "gl_PerVertex"},
+		{9, "vec4"},
+		{12, "float[1]"},
+		{13, "gl_PerVertex*"},
+		{14, "int"},
+	} {
+		assert.For(ctx, "type %v", test.id).ThatString(m.Types[test.id].String()).Equals(test.expected)
+	}
+
+	for _, test := range []struct {
+		desc  string
+		words []uint32
+	}{
+		{"empty", nil},
+		{"bad magic", []uint32{0x03022307, 0x00010000, 0, 1, 0}},
+		{"truncated", vertexShader[:len(vertexShader)-3]},
+	} {
+		_, err := spirv.Decode(test.words)
+		assert.For(ctx, "%v", test.desc).ThatError(err).Failed()
+	}
+}
+
+func TestReflect(t *testing.T) {
+	ctx := log.Testing(t)
+	m, err := spirv.Decode(vertexShader)
+	if !assert.For(ctx, "err").ThatError(err).Succeeded() {
+		return
+	}
+	_, err = m.Reflect("main", spirv.ExecutionModelFragment)
+	assert.For(ctx, "wrong model").ThatError(err).Failed()
+
+	r, err := m.Reflect("main", spirv.ExecutionModelVertex)
+	if !assert.For(ctx, "err").ThatError(err).Succeeded() {
+		return
+	}
+	if assert.For(ctx, "inputs").ThatSlice(r.Inputs).IsLength(1) {
+		in := r.Inputs[0]
+		assert.For(ctx, "input name").ThatString(in.Name).Equals("position")
+		assert.For(ctx, "input type").ThatString(in.Type.String()).Equals("vec3")
+		assert.For(ctx, "input location").That(in.Location).Equals(int32(0))
+		assert.For(ctx, "input built-in").That(in.IsBuiltIn).Equals(false)
+	}
+	outputs := []string{}
+	for _, out := range r.Outputs {
+		assert.For(ctx, "output %v location", out.Name).That(out.Location).Equals(int32(-1))
+		outputs = append(outputs, out.Name+":"+out.BuiltIn.String())
+	}
+	assert.For(ctx, "outputs").ThatSlice(outputs).Equals([]string{
+		"gl_Position:Position",
+		"gl_PointSize:PointSize",
+		"gl_ClipDistance:ClipDistance",
+		"gl_CullDistance:CullDistance",
+	})
+	assert.For(ctx, "bindings").ThatSlice(r.DescriptorBindings).IsEmpty()
+
+	m, err = spirv.Decode(fragmentShader)
+	if !assert.For(ctx, "err").ThatError(err).Succeeded() {
+		return
+	}
+	r, err = m.Reflect("main", spirv.ExecutionModelFragment)
+	if !assert.For(ctx, "err").ThatError(err).Succeeded() {
+		return
+	}
+	assert.For(ctx, "inputs").ThatSlice(r.Inputs).IsEmpty()
+	if assert.For(ctx, "outputs").ThatSlice(r.Outputs).IsLength(1) {
+		assert.For(ctx, "output").ThatString(r.Outputs[0].BuiltIn.String()).Equals("FragDepth")
+	}
+	if assert.For(ctx, "bindings").ThatSlice(r.DescriptorBindings).IsLength(1) {
+		b := r.DescriptorBindings[0]
+		assert.For(ctx, "binding name").ThatString(b.Name).Equals("in_depth")
+		assert.For(ctx, "binding type").ThatString(b.Type.String()).Equals("usubpassInput")
+		assert.For(ctx, "binding descriptor").That(b.DescriptorType).Equals(spirv.DescriptorInputAttachment)
+		assert.For(ctx, "binding set").That(b.Set).Equals(uint32(0))
+		assert.For(ctx, "binding count").That(b.Count).Equals(uint32(1))
+		assert.For(ctx, "binding accessed").That(b.Accessed).Equals(true)
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	ctx := log.Testing(t)
+	out, err := spirv.Disassemble(vertexShader)
+	if assert.For(ctx, "err").ThatError(err).Succeeded() {
+		assert.For(ctx, "disassembly").ThatString(out).Equals(`; SPIR-V
+; Version: 1.0
+; Generator: Khronos SPIR-V Tools Assembler; 0
+; Bound: 27
+; Schema: 0
+               OpCapability Shader
+          %1 = OpExtInstImport "GLSL.std.450"
+               OpMemoryModel Logical GLSL450
+               OpEntryPoint Vertex %main "main" %_ %position
+               OpSource GLSL 450
+               OpName %main "main"
+               OpName %gl_PerVertex "gl_PerVertex"
+               OpMemberName %gl_PerVertex 0 "gl_Position"
+               OpMemberName %gl_PerVertex 1 "gl_PointSize"
+               OpMemberName %gl_PerVertex 2 "gl_ClipDistance"
+               OpMemberName %gl_PerVertex 3 "gl_CullDistance"
+               OpName %_ ""
+               OpName %position "position"
+               OpMemberDecorate %gl_PerVertex 0 BuiltIn Position
+               OpMemberDecorate %gl_PerVertex 1 BuiltIn PointSize
+               OpMemberDecorate %gl_PerVertex 2 BuiltIn ClipDistance
+               OpMemberDecorate %gl_PerVertex 3 BuiltIn CullDistance
+               OpDecorate %gl_PerVertex Block
+               OpDecorate %position Location 0
+       %void = OpTypeVoid
+          %7 = OpTypeFunction %void
+      %float = OpTypeFloat 32
+    %v4float = OpTypeVector %float 4
+       %uint = OpTypeInt 32 0
+     %uint_1 = OpConstant %uint 1
+%_arr_float_uint_1 = OpTypeArray %float %uint_1
+%gl_PerVertex = OpTypeStruct %v4float %float %_arr_float_uint_1 %_arr_float_uint_1
+%_ptr_Output_gl_PerVertex = OpTypePointer Output %gl_PerVertex
+          %_ = OpVariable %_ptr_Output_gl_PerVertex Output
+        %int = OpTypeInt 32 1
+      %int_0 = OpConstant %int 0
+    %v3float = OpTypeVector %float 3
+%_ptr_Input_v3float = OpTypePointer Input %v3float
+   %position = OpVariable %_ptr_Input_v3float Input
+    %float_1 = OpConstant %float 1
+%_ptr_Output_v4float = OpTypePointer Output %v4float
+       %main = OpFunction %void None %7
+         %20 = OpLabel
+         %21 = OpLoad %v3float %position
+         %22 = OpCompositeExtract %float %21 0
+         %23 = OpCompositeExtract %float %21 1
+         %24 = OpCompositeExtract %float %21 2
+         %25 = OpCompositeConstruct %v4float %22 %23 %24 %float_1
+         %26 = OpAccessChain %_ptr_Output_v4float %_ %int_0
+               OpStore %26 %25
+               OpReturn
+               OpFunctionEnd
+`)
+	}
+
+	out, err = spirv.Disassemble(fragmentShader)
+	if assert.For(ctx, "err").ThatError(err).Succeeded() {
+		assert.For(ctx, "image").ThatString(out).Contains(`
+         %10 = OpTypeImage %uint SubpassData 0 0 0 2 Unknown
+%_ptr_UniformConstant_10 = OpTypePointer UniformConstant %10
+`)
+		assert.For(ctx, "read").ThatString(out).Contains(`
+         %21 = OpImageRead %v4uint %20 %15
+`)
+	}
+}
+
+func TestComplexity(t *testing.T) {
+	ctx := log.Testing(t)
+	for _, test := range []struct {
+		name     string
+		words    []uint32
+		model    spirv.ExecutionModel
+		expected spirv.Complexity
+	}{
+		{"vertex", vertexShader, spirv.ExecutionModelVertex, spirv.Complexity{ALU: 4, ControlFlow: 1, Other: 3}},
+		{"fragment", fragmentShader, spirv.ExecutionModelFragment, spirv.Complexity{ALU: 3, Texture: 1, ControlFlow: 1, Other: 2}},
+		{"textured", texturedShader, spirv.ExecutionModelFragment, spirv.Complexity{Texture: 1, ControlFlow: 1, Other: 3, Samplers: 1, Interpolants: 1}},
+	} {
+		m, err := spirv.Decode(test.words)
+		if !assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded() {
+			continue
+		}
+		c, err := m.Complexity("main", test.model)
+		if assert.For(ctx, "%v err", test.name).ThatError(err).Succeeded() {
+			assert.For(ctx, "%v", test.name).That(*c).Equals(test.expected)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spirv
+
+import (
+	"fmt"
+)
+
+// Type is a type declared by a SPIR-V module.
+type Type struct {
+	ID ID
+	// Opcode is the opcode declaring the type, such as OpTypeFloat.
+	Opcode Opcode
+	// Name is the name of the type given by OpName, if any.
+	Name string
+	// Width and Signed are the width and signedness of the scalar types.
+	Width  uint32
+	Signed bool
+	// Elem is the component of vectors, the column of matrices, the element of
+	// arrays, the type pointed by pointers, the sampled type of images, the
+	// image of sampled images and the return type of functions.
+	Elem *Type
+	// Count is the number of components of vectors, of columns of matrices and
+	// of elements of arrays. It is 0 for runtime arrays and for arrays sized by
+	// specialization constants.
+	Count uint32
+	// Length is the constant of the length of arrays.
+	Length *Constant
+	// Members are the members of structs and the parameters of functions.
+	Members      []*Type
+	StorageClass StorageClass
+	// The image properties.
+	Dim     Dim
+	Depth   uint32
+	Arrayed bool
+	MS      bool
+	// Sampled is 1 for images used with a sampler, 2 for storage images.
+	Sampled uint32
+	Format  uint32
+}
+
+// addType sets the type t declared by the instruction op with the operands
+// args, following the result id.
+func (m *Module) addType(op Opcode, t *Type, args []uint32) error {
+	if t.Opcode != 0 {
+		return fmt.Errorf("%v is declared twice", t.ID)
+	}
+	t.Opcode = op
+	t.Name = m.Names[t.ID]
+	need := func(n int) error {
+		if len(args) < n {
+			return fmt.Errorf("expected %d type operands, got %d", n, len(args))
+		}
+		return nil
+	}
+	switch op {
+	case OpTypeInt:
+		if err := need(2); err != nil {
+			return err
+		}
+		t.Width, t.Signed = args[0], args[1] != 0
+	case OpTypeFloat:
+		if err := need(1); err != nil {
+			return err
+		}
+		t.Width, t.Signed = args[0], true
+	case OpTypeVector, OpTypeMatrix:
+		if err := need(2); err != nil {
+			return err
+		}
+		t.Elem, t.Count = m.Type(ID(args[0])), args[1]
+	case OpTypeImage:
+		if err := need(7); err != nil {
+			return err
+		}
+		t.Elem = m.Type(ID(args[0]))
+		t.Dim, t.Depth, t.Arrayed, t.MS = Dim(args[1]), args[2], args[3] != 0, args[4] != 0
+		t.Sampled, t.Format = args[5], args[6]
+	case OpTypeSampledImage, OpTypeRuntimeArray:
+		if err := need(1); err != nil {
+			return err
+		}
+		t.Elem = m.Type(ID(args[0]))
+	case OpTypeArray:
+		if err := need(2); err != nil {
+			return err
+		}
+		t.Elem = m.Type(ID(args[0]))
+		if c, ok := m.Constants[ID(args[1])]; ok {
+			t.Length = c
+			if c.Opcode == OpConstant {
+				t.Count = uint32(c.Uint())
+			}
+		}
+	case OpTypeStruct:
+		for _, id := range args {
+			t.Members = append(t.Members, m.Type(ID(id)))
+		}
+	case OpTypePointer:
+		if err := need(2); err != nil {
+			return err
+		}
+		t.StorageClass, t.Elem = StorageClass(args[0]), m.Type(ID(args[1]))
+	case OpTypeFunction:
+		if err := need(1); err != nil {
+			return err
+		}
+		t.Elem = m.Type(ID(args[0]))
+		for _, id := range args[1:] {
+			t.Members = append(t.Members, m.Type(ID(id)))
+		}
+	}
+	return nil
+}
+
+// Scalar returns the scalar type of the vectors and matrices, or the type
+// itself for other types.
+func (t *Type) Scalar() *Type {
+	for (t.Opcode == OpTypeVector || t.Opcode == OpTypeMatrix) && t.Elem != nil {
+		t = t.Elem
+	}
+	return t
+}
+
+// String returns the GLSL like name of the type.
+func (t *Type) String() string {
+	switch t.Opcode {
+	case OpTypeVoid:
+		return "void"
+	case OpTypeBool:
+		return "bool"
+	case OpTypeInt:
+		name := "int"
+		if !t.Signed {
+			name = "uint"
+		}
+		if t.Width != 32 {
+			name = fmt.Sprintf("%v%d_t", name, t.Width)
+		}
+		return name
+	case OpTypeFloat:
+		switch t.Width {
+		case 32:
+			return "float"
+		case 64:
+			return "double"
+		default:
+			return fmt.Sprintf("float%d_t", t.Width)
+		}
+	case OpTypeVector:
+		return fmt.Sprintf("%vvec%d", t.Elem.prefix(), t.Count)
+	case OpTypeMatrix:
+		col := t.Elem
+		if col.Count == t.Count {
+			return fmt.Sprintf("%vmat%d", col.Elem.prefix(), t.Count)
+		}
+		return fmt.Sprintf("%vmat%dx%d", col.Scalar().prefix(), t.Count, col.Count)
+	case OpTypeImage:
+		return t.imageName(false)
+	case OpTypeSampler:
+		return "sampler"
+	case OpTypeSampledImage:
+		return t.Elem.imageName(true)
+	case OpTypeArray:
+		if t.Count == 0 && t.Length != nil {
+			return fmt.Sprintf("%v[%%%d]", t.Elem, t.Length.ID)
+		}
+		return fmt.Sprintf("%v[%d]", t.Elem, t.Count)
+	case OpTypeRuntimeArray:
+		return fmt.Sprintf("%v[]", t.Elem)
+	case OpTypeStruct:
+		if t.Name != "" {
+			return t.Name
+		}
+		return fmt.Sprintf("struct_%d", t.ID)
+	case OpTypePointer:
+		return fmt.Sprintf("%v*", t.Elem)
+	default:
+		return fmt.Sprintf("%%%d", t.ID)
+	}
+}
+
+// prefix returns the GLSL prefix of the vectors, matrices and images with the
+// scalar type t.
+func (t *Type) prefix() string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Opcode == OpTypeBool:
+		return "b"
+	case t.Opcode == OpTypeInt && t.Signed:
+		return "i"
+	case t.Opcode == OpTypeInt:
+		return "u"
+	case t.Opcode == OpTypeFloat && t.Width == 64:
+		return "d"
+	default:
+		return ""
+	}
+}
+
+// imageName returns the GLSL name of the image type, or of the sampler of the
+// image if sampler is true.
+func (t *Type) imageName(sampler bool) string {
+	if t.Opcode != OpTypeImage {
+		return "image"
+	}
+	if t.Dim == DimSubpassData {
+		name := t.Elem.prefix() + "subpassInput"
+		if t.MS {
+			name += "MS"
+		}
+		return name
+	}
+	name := "texture"
+	switch {
+	case sampler:
+		name = "sampler"
+	case t.Sampled == 2:
+		name = "image"
+	}
+	dims := map[Dim]string{Dim1D: "1D", Dim2D: "2D", Dim3D: "3D", DimCube: "Cube", DimRect: "2DRect", DimBuffer: "Buffer"}
+	name = t.Elem.prefix() + name + dims[t.Dim]
+	if t.MS {
+		name += "MS"
+	}
+	if t.Arrayed {
+		name += "Array"
+	}
+	if sampler && t.Depth == 1 {
+		name += "Shadow"
+	}
+	return name
+}
+
+// Size returns the size in bytes of the type t, using the explicit layout
+// decorations of the module. Opaque types and runtime arrays have a size of 0.
+func (m *Module) Size(t *Type) uint32 {
+	return m.size(t, 0)
+}
+
+// size returns the size of the type t. matrixStride is the stride of the
+// columns if t is a matrix member of a struct, or 0.
+func (m *Module) size(t *Type, matrixStride uint32) uint32 {
+	switch t.Opcode {
+	case OpTypeBool:
+		return 4
+	case OpTypeInt, OpTypeFloat:
+		return t.Width / 8
+	case OpTypeVector:
+		return t.Count * m.size(t.Elem, 0)
+	case OpTypeMatrix:
+		if matrixStride == 0 {
+			matrixStride = m.size(t.Elem, 0)
+		}
+		return t.Count * matrixStride
+	case OpTypeArray:
+		stride, ok := m.Decorations[t.ID].Get(DecorationArrayStride)
+		if !ok {
+			stride = m.size(t.Elem, matrixStride)
+		}
+		return t.Count * stride
+	case OpTypeStruct:
+		offset, size := m.MembersRange(t)
+		return offset + size
+	default:
+		return 0
+	}
+}
+
+// MembersRange returns the offset of the first member of the struct t and
+// the size of the range of its members, using their offset decorations.
+func (m *Module) MembersRange(t *Type) (offset, size uint32) {
+	if t.Opcode != OpTypeStruct || len(t.Members) == 0 {
+		return 0, m.Size(t)
+	}
+	start, end := ^uint32(0), uint32(0)
+	for i, member := range t.Members {
+		d := m.MemberDecorations[t.ID][uint32(i)]
+		o, _ := d.Get(DecorationOffset)
+		stride, _ := d.Get(DecorationMatrixStride)
+		if o < start {
+			start = o
+		}
+		if e := o + m.size(member, stride); e > end {
+			end = e
+		}
+	}
+	return start, end - start
+}
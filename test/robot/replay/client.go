@@ -43,14 +43,17 @@ const (
 )
 
 type client struct {
-	store   *stash.Client
-	manager Manager
-	tempDir file.Path
+	store              *stash.Client
+	manager            Manager
+	tempDir            file.Path
+	determinismReplays int
 }
 
 // Run starts new replay client if any hardware is available.
-func Run(ctx context.Context, store *stash.Client, manager Manager, tempDir file.Path) error {
-	c := &client{store: store, manager: manager, tempDir: tempDir}
+// If determinismReplays is positive, each trace is also replayed that many
+// times to check the determinism of the replay.
+func Run(ctx context.Context, store *stash.Client, manager Manager, tempDir file.Path, determinismReplays int) error {
+	c := &client{store: store, manager: manager, tempDir: tempDir, determinismReplays: determinismReplays}
 	job.OnDeviceAdded(ctx, c.onDeviceAdded)
 	host := host.Instance(ctx)
 	return manager.Register(ctx, host, host, c.replay)
@@ -88,7 +91,7 @@ func (c *client) replay(ctx context.Context, t *Task) error {
 	err := worker.RetryFunction(ctx, 4, time.Millisecond*100, func() (err error) {
 		ctx, cancel := task.WithTimeout(ctx, replayTimeout)
 		defer cancel()
-		output, err = doReplay(ctx, t.Action, t.Input, c.store, c.tempDir)
+		output, err = doReplay(ctx, t.Action, t.Input, c.store, c.tempDir, c.determinismReplays)
 		return err
 	})
 
@@ -106,9 +109,10 @@ func (c *client) replay(ctx context.Context, t *Task) error {
 
 // doReplay extracts input files and runs `gapit video` on them, capturing the output. The output object will
 // be partially filled in the event of an upload error from store in order to allow examination of the logs.
-func doReplay(ctx context.Context, action string, in *Input, store *stash.Client, tempDir file.Path) (*Output, error) {
+func doReplay(ctx context.Context, action string, in *Input, store *stash.Client, tempDir file.Path, determinismReplays int) (*Output, error) {
 	tracefile := tempDir.Join(action + ".gfxtrace")
 	videofile := tempDir.Join(action + "_replay.mp4")
+	determinismfile := tempDir.Join(action + "_determinism.txt")
 
 	extractedDir := tempDir.Join(action + "_tools")
 	extractedLayout, err := layout.NewPkgLayout(extractedDir, true)
@@ -140,6 +144,7 @@ func doReplay(ctx context.Context, action string, in *Input, store *stash.Client
 	defer func() {
 		file.Remove(tracefile)
 		file.Remove(videofile)
+		file.Remove(determinismfile)
 		file.RemoveAll(extractedDir)
 	}()
 
@@ -207,5 +212,44 @@ func doReplay(ctx context.Context, action string, in *Input, store *stash.Client
 		return outputObj, err
 	}
 	outputObj.Video = videoID
+
+	params = determinismParams(in, determinismReplays, tracefile, determinismfile)
+	if params == nil {
+		return outputObj, nil
+	}
+	determinismID, err := doDeterminism(ctx, gapit, params, determinismfile, store)
+	if err != nil {
+		return outputObj, err
+	}
+	outputObj.Determinism = determinismID
 	return outputObj, nil
 }
+
+// determinismParams returns the parameters of the `gapit determinism` call
+// replaying the trace the given number of times, or nil if the determinism
+// check is disabled.
+func determinismParams(in *Input, replays int, tracefile, determinismfile file.Path) []string {
+	if replays <= 0 {
+		return nil
+	}
+	return []string{
+		"determinism",
+		"-gapir-device", in.GetGapirDevice(),
+		"-replays", fmt.Sprint(replays),
+		"-out", determinismfile.System(),
+		tracefile.System(),
+	}
+}
+
+// doDeterminism runs `gapit determinism` on the trace, uploading the report.
+// Failures to check the determinism are recorded in the uploaded report
+// rather than failing the replay.
+func doDeterminism(ctx context.Context, gapit file.Path, params []string, determinismfile file.Path, store *stash.Client) (string, error) {
+	cmd := shell.Command(gapit.System(), params...)
+	errBuf := &bytes.Buffer{}
+	if err := cmd.Capture(nil, errBuf).Run(ctx); err != nil {
+		report := fmt.Sprintf("%s\n\n%s\n%s\n", cmd, err, strings.TrimSpace(errBuf.String()))
+		return store.UploadString(ctx, stash.Upload{Name: []string{"determinism.txt"}, Type: []string{"text/plain"}}, report)
+	}
+	return store.UploadFile(ctx, determinismfile)
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2018 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"testing"
+
+	"github.com/google/gapid/core/assert"
+	"github.com/google/gapid/core/log"
+	"github.com/google/gapid/core/os/file"
+)
+
+func TestDeterminismParams(t *testing.T) {
+	ctx := log.Testing(t)
+	in := &Input{GapirDevice: "device"}
+	trace := file.Abs("/tmp/action.gfxtrace")
+	out := file.Abs("/tmp/action_determinism.txt")
+	for _, test := range []struct {
+		replays  int
+		expected []string
+	}{
+		{-1, nil},
+		{0, nil},
+		{3, []string{
+			"determinism",
+			"-gapir-device", "device",
+			"-replays", "3",
+			"-out", out.System(),
+			trace.System(),
+		}},
+	} {
+		got := determinismParams(in, test.replays, trace, out)
+		assert.For(ctx, "replays %v", test.replays).ThatSlice(got).Equals(test.expected)
+	}
+}